@@ -68,6 +68,7 @@ type MetricRequest struct {
 	// required: true
 	// example: [ { "refId": "A", "intervalMs": 86400000, "maxDataPoints": 1092, "datasource":{ "uid":"PD8C576611E62080A" }, "rawSql": "SELECT 1 as valueOne, 2 as valueTwo", "format": "table" } ]
 	Queries []*simplejson.Json `json:"queries"`
+	// Debug adds the trace of the expressions to the response, with the inputs, results, and execution time of each query and expression.
 	// required: false
 	Debug bool `json:"debug"`
 
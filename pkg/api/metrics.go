@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -9,9 +10,12 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/util/errutil"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -52,14 +56,94 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext) response.Response {
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
 
-	resp, err := hs.queryDataService.QueryData(c.Req.Context(), c.SignedInUser, c.SkipCache, reqDTO)
+	ctx := c.Req.Context()
+	var trace *expr.Trace
+	if reqDTO.Debug {
+		ctx, trace = expr.WithTrace(ctx)
+	}
+
+	ctx, cacheStatuses := query.WithCacheStatuses(ctx)
+
+	resp, err := hs.queryDataService.QueryData(ctx, c.SignedInUser, c.SkipCache, reqDTO)
 	if err != nil {
 		return hs.handleQueryMetricsError(err)
 	}
+	if status := cacheStatuses.Status(); status != "" {
+		c.Resp.Header().Set(query.HeaderCacheStatus, string(status))
+	}
+	if trace != nil {
+		return response.JSONStreaming(hs.queryDataStatusCode(resp), expr.TracedResponse{
+			Results: resp.Responses,
+			Trace:   trace,
+		})
+	}
 	return hs.toJsonStreamingResponse(resp)
 }
 
+// QueryMetricsStream streams the results of the single query of a request to a data source that supports streaming,
+// as data frames in JSON, one per line. The frames are written as the data source produces them, and the query is
+// canceled when the request is canceled. An error after the first frame is written as a line with an error message.
+func (hs *HTTPServer) QueryMetricsStream(c *models.ReqContext) response.Response {
+	reqDTO := dtos.MetricRequest{}
+	if err := web.Bind(c.Req, &reqDTO); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	w := &queryStreamWriter{w: c.Resp}
+	err := hs.queryDataService.QueryDataStream(c.Req.Context(), c.SignedInUser, reqDTO, backend.NewStreamSender(w))
+	if err == nil {
+		return nil
+	}
+	if !w.started {
+		return hs.handleQueryMetricsError(err)
+	}
+	if c.Req.Context().Err() != nil {
+		return nil
+	}
+	c.Logger.Error("Query data stream failed", "error", err)
+	message := "Query data error"
+	grafanaErr := &errutil.Error{}
+	if errors.As(err, grafanaErr) {
+		message = grafanaErr.Public().Message
+	}
+	w.writeError(message)
+	return nil
+}
+
+// queryStreamWriter writes the packets of a stream to the response, one per line, and flushes them so that the
+// client receives each packet as soon as it is sent. Writing blocks while the client does not read the response.
+type queryStreamWriter struct {
+	w       web.ResponseWriter
+	started bool
+}
+
+func (s *queryStreamWriter) Send(packet *backend.StreamPacket) error {
+	if !s.started {
+		s.w.Header().Set("Content-Type", "application/x-ndjson")
+		s.w.Header().Set("Cache-Control", "no-store")
+		s.w.WriteHeader(http.StatusOK)
+		s.started = true
+	}
+	if _, err := s.w.Write(append(packet.Data, '\n')); err != nil {
+		return err
+	}
+	s.w.Flush()
+	return nil
+}
+
+func (s *queryStreamWriter) writeError(message string) {
+	b, err := json.Marshal(map[string]string{"error": message})
+	if err != nil {
+		return
+	}
+	_ = s.Send(&backend.StreamPacket{Data: b})
+}
+
 func (hs *HTTPServer) toJsonStreamingResponse(qdr *backend.QueryDataResponse) response.Response {
+	return response.JSONStreaming(hs.queryDataStatusCode(qdr), qdr)
+}
+
+func (hs *HTTPServer) queryDataStatusCode(qdr *backend.QueryDataResponse) int {
 	statusWhenError := http.StatusBadRequest
 	if hs.Features.IsEnabled(featuremgmt.FlagDatasourceQueryMultiStatus) {
 		statusWhenError = http.StatusMultiStatus
@@ -71,8 +155,7 @@ func (hs *HTTPServer) toJsonStreamingResponse(qdr *backend.QueryDataResponse) re
 			statusCode = statusWhenError
 		}
 	}
-
-	return response.JSONStreaming(statusCode, qdr)
+	return statusCode
 }
 
 // swagger:parameters queryMetricsWithExpressions
@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/plugins"
@@ -61,6 +62,7 @@ func TestAPIEndpoint_Metrics_QueryMetricsV2(t *testing.T) {
 				return &backend.QueryDataResponse{Responses: resp}, nil
 			},
 		},
+		nil,
 	)
 	serverFeatureEnabled := SetupAPITestServer(t, func(hs *HTTPServer) {
 		hs.queryDataService = qds
@@ -109,6 +111,7 @@ func TestAPIEndpoint_Metrics_PluginDecryptionFailure(t *testing.T) {
 				return &backend.QueryDataResponse{Responses: resp}, nil
 			},
 		},
+		nil,
 	)
 	httpServer := SetupAPITestServer(t, func(hs *HTTPServer) {
 		hs.queryDataService = qds
@@ -133,6 +136,95 @@ func TestAPIEndpoint_Metrics_PluginDecryptionFailure(t *testing.T) {
 	})
 }
 
+// `/ds/query/stream` endpoint test
+func TestAPIEndpoint_Metrics_QueryMetricsStream(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.QueryStreaming.Enabled = true
+	failsBeforeFrames := false
+	qds := query.ProvideService(
+		cfg,
+		nil,
+		nil,
+		&fakePluginRequestValidator{},
+		&fakeDatasources.FakeDataSourceService{},
+		&fakeStreamPluginClient{
+			RunStreamFunc: func(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+				if failsBeforeFrames {
+					return errors.New("connection refused")
+				}
+				for i := 0; i < 2; i++ {
+					if err := sender.SendFrame(data.NewFrame("", data.NewField("value", nil, []int64{int64(i)})), data.IncludeAll); err != nil {
+						return err
+					}
+				}
+				return errors.New("connection lost")
+			},
+		},
+		nil,
+	)
+	httpServer := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.queryDataService = qds
+		hs.QuotaService = quotatest.New(false, nil)
+	})
+
+	t.Run("Writes the frames of the stream, one per line, and the error of the stream", func(t *testing.T) {
+		req := httpServer.NewPostRequest("/api/ds/query/stream", strings.NewReader(reqValid))
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleViewer})
+		resp, err := httpServer.SendJSON(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+		require.Len(t, lines, 3)
+		for _, line := range lines[:2] {
+			frame := &data.Frame{}
+			require.NoError(t, json.Unmarshal([]byte(line), frame))
+			require.Equal(t, 1, frame.Rows())
+		}
+		require.JSONEq(t, `{"error": "connection lost"}`, lines[2])
+	})
+
+	t.Run("Status code is 502 when the stream fails before the first frame", func(t *testing.T) {
+		failsBeforeFrames = true
+		t.Cleanup(func() {
+			failsBeforeFrames = false
+		})
+		req := httpServer.NewPostRequest("/api/ds/query/stream", strings.NewReader(reqValid))
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleViewer})
+		resp, err := httpServer.SendJSON(req)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusBadGateway, resp.StatusCode)
+		require.Contains(t, string(body), "connection refused")
+	})
+
+	t.Run("Status code is 400 when the request has more than one query", func(t *testing.T) {
+		body := `{"queries": [{"datasource": {"uid": "grafana"}, "refId": "A"}, {"datasource": {"uid": "grafana"}, "refId": "B"}]}`
+		req := httpServer.NewPostRequest("/api/ds/query/stream", strings.NewReader(body))
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleViewer})
+		resp, err := httpServer.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+type fakeStreamPluginClient struct {
+	plugins.Client
+
+	RunStreamFunc func(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error
+}
+
+func (c *fakeStreamPluginClient) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	return c.RunStreamFunc(ctx, req, sender)
+}
+
 var reqValid = `{
 	"from": "",
 	"to": "",
@@ -262,6 +354,7 @@ func TestDataSourceQueryError(t *testing.T) {
 					&fakePluginRequestValidator{},
 					&fakeDatasources.FakeDataSourceService{},
 					pluginClient.ProvideService(r, &config.Cfg{}),
+					nil,
 				)
 				hs.QuotaService = quotatest.New(false, nil)
 			})
@@ -0,0 +1,194 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+const (
+	// AnomalyOutputScore returns the deviation score of each series.
+	AnomalyOutputScore = "score"
+	// AnomalyOutputBaseline returns the baseline of each series.
+	AnomalyOutputBaseline = "baseline"
+	// AnomalyOutputUpper returns the upper band of each series.
+	AnomalyOutputUpper = "upper"
+	// AnomalyOutputLower returns the lower band of each series.
+	AnomalyOutputLower = "lower"
+	// AnomalyOutputAll returns all of the above for each series, with the anomalyLabel label set to the name of the output.
+	AnomalyOutputAll = "all"
+
+	anomalyLabel = "anomaly"
+)
+
+var (
+	supportedAnomalyMethods = []string{mathexp.AnomalySeasonalMedian, mathexp.AnomalyHoltWinters}
+	supportedAnomalyOutputs = []string{AnomalyOutputScore, AnomalyOutputBaseline, AnomalyOutputUpper, AnomalyOutputLower, AnomalyOutputAll}
+)
+
+// AnomalyCommand is an expression command that computes a seasonal baseline of each time series, and returns
+// its bands or the deviation score of each point, which is the number of spreads between the value and the baseline.
+type AnomalyCommand struct {
+	VarToAnalyze string
+	Method       string
+	Params       mathexp.AnomalyParams
+	Output       string
+	refID        string
+}
+
+// AnomalyCommandJSON is the model of an anomaly expression in Grafana's frontend query.
+type AnomalyCommandJSON struct {
+	Expression string  `json:"expression"`
+	Method     string  `json:"method"`
+	Season     string  `json:"season"`
+	Seasons    int     `json:"seasons"`
+	Alpha      float64 `json:"alpha"`
+	Beta       float64 `json:"beta"`
+	Gamma      float64 `json:"gamma"`
+	Deviations float64 `json:"deviations"`
+	Output     string  `json:"output"`
+}
+
+// defaultAnomalyCommandJSON has the values of the settings that are not in the query.
+func defaultAnomalyCommandJSON() AnomalyCommandJSON {
+	return AnomalyCommandJSON{
+		Method:     mathexp.AnomalySeasonalMedian,
+		Seasons:    4,
+		Alpha:      0.5,
+		Beta:       0.1,
+		Gamma:      0.1,
+		Deviations: 3,
+		Output:     AnomalyOutputScore,
+	}
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand.
+func NewAnomalyCommand(refID, varToAnalyze, method string, params mathexp.AnomalyParams, output string) (*AnomalyCommand, error) {
+	if !isOneOf(method, supportedAnomalyMethods) {
+		return nil, fmt.Errorf("expected anomaly detection method to be one of %s, got %s", strings.Join(supportedAnomalyMethods, ", "), method)
+	}
+	if !isOneOf(output, supportedAnomalyOutputs) {
+		return nil, fmt.Errorf("expected anomaly output to be one of %s, got %s", strings.Join(supportedAnomalyOutputs, ", "), output)
+	}
+	if params.Season <= 0 {
+		return nil, fmt.Errorf("anomaly season must be a positive duration")
+	}
+	if params.Deviations <= 0 {
+		return nil, fmt.Errorf("anomaly deviations must be positive, got %v", params.Deviations)
+	}
+	if method == mathexp.AnomalySeasonalMedian && params.Seasons < 2 {
+		return nil, fmt.Errorf("the seasonal median needs at least 2 earlier seasons, got %d", params.Seasons)
+	}
+	return &AnomalyCommand{
+		VarToAnalyze: varToAnalyze,
+		Method:       method,
+		Params:       params,
+		Output:       output,
+		refID:        refID,
+	}, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	jsonFromM, err := json.Marshal(rn.Query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remarshal anomaly expression body: %w", err)
+	}
+	model := defaultAnomalyCommandJSON()
+	if err := json.Unmarshal(jsonFromM, &model); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal remarshaled anomaly expression body: %w", err)
+	}
+
+	if model.Expression == "" {
+		return nil, fmt.Errorf("no variable specified to analyze for refId %v", rn.RefID)
+	}
+	if model.Season == "" {
+		return nil, fmt.Errorf("no season specified in anomaly expression for refId %v", rn.RefID)
+	}
+	season, err := gtime.ParseDuration(model.Season)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse anomaly "season" duration field %q: %w`, model.Season, err)
+	}
+
+	return NewAnomalyCommand(rn.RefID, strings.TrimPrefix(model.Expression, "$"), model.Method, mathexp.AnomalyParams{
+		Season:     season,
+		Seasons:    model.Seasons,
+		Alpha:      model.Alpha,
+		Beta:       model.Beta,
+		Gamma:      model.Gamma,
+		Deviations: model.Deviations,
+	}, model.Output)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AnomalyCommand) NeedsVars() []string {
+	return []string{ac.VarToAnalyze}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AnomalyCommand) Execute(_ context.Context, _ time.Time, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range vars[ac.VarToAnalyze].Values {
+		var series mathexp.Series
+		switch v := val.(type) {
+		case mathexp.Series:
+			series = v
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+			continue
+		default:
+			return newRes, fmt.Errorf("can only detect anomalies of type series, got type %v", val.Type())
+		}
+
+		a, err := series.Anomaly(ac.refID, ac.Method, ac.Params)
+		if err != nil {
+			return newRes, fmt.Errorf("failed to compute the baseline of series %s: %w", series.GetLabels(), err)
+		}
+		switch ac.Output {
+		case AnomalyOutputScore:
+			newRes.Values = append(newRes.Values, a.Score)
+		case AnomalyOutputBaseline:
+			newRes.Values = append(newRes.Values, a.Baseline)
+		case AnomalyOutputUpper:
+			newRes.Values = append(newRes.Values, a.Upper)
+		case AnomalyOutputLower:
+			newRes.Values = append(newRes.Values, a.Lower)
+		case AnomalyOutputAll:
+			for _, out := range []struct {
+				name   string
+				series mathexp.Series
+			}{
+				{AnomalyOutputBaseline, a.Baseline},
+				{AnomalyOutputUpper, a.Upper},
+				{AnomalyOutputLower, a.Lower},
+				{AnomalyOutputScore, a.Score},
+			} {
+				labels := out.series.GetLabels()
+				if labels == nil {
+					labels = map[string]string{}
+				}
+				labels[anomalyLabel] = out.name
+				out.series.SetLabels(labels)
+				newRes.Values = append(newRes.Values, out.series)
+			}
+		}
+	}
+	return newRes, nil
+}
+
+func isOneOf(s string, values []string) bool {
+	for _, v := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	tests := []struct {
+		description   string
+		query         string
+		expected      *AnomalyCommand
+		expectedError string
+	}{
+		{
+			description: "defaults",
+			query:       `{"type": "anomaly", "expression": "$A", "season": "1w"}`,
+			expected: &AnomalyCommand{
+				VarToAnalyze: "A",
+				Method:       mathexp.AnomalySeasonalMedian,
+				Params: mathexp.AnomalyParams{
+					Season:     7 * 24 * time.Hour,
+					Seasons:    4,
+					Alpha:      0.5,
+					Beta:       0.1,
+					Gamma:      0.1,
+					Deviations: 3,
+				},
+				Output: AnomalyOutputScore,
+				refID:  "B",
+			},
+		},
+		{
+			description: "holt-winters",
+			query:       `{"type": "anomaly", "expression": "A", "method": "holt_winters", "season": "1d", "alpha": 0.3, "beta": 0, "gamma": 0.2, "deviations": 2, "output": "all"}`,
+			expected: &AnomalyCommand{
+				VarToAnalyze: "A",
+				Method:       mathexp.AnomalyHoltWinters,
+				Params: mathexp.AnomalyParams{
+					Season:     24 * time.Hour,
+					Seasons:    4,
+					Alpha:      0.3,
+					Beta:       0,
+					Gamma:      0.2,
+					Deviations: 2,
+				},
+				Output: AnomalyOutputAll,
+				refID:  "B",
+			},
+		},
+		{
+			description:   "season is required",
+			query:         `{"type": "anomaly", "expression": "A"}`,
+			expectedError: "no season specified",
+		},
+		{
+			description:   "invalid season",
+			query:         `{"type": "anomaly", "expression": "A", "season": "weekly"}`,
+			expectedError: "failed to parse anomaly \"season\"",
+		},
+		{
+			description:   "unknown method",
+			query:         `{"type": "anomaly", "expression": "A", "season": "1d", "method": "prophet"}`,
+			expectedError: "expected anomaly detection method to be one of",
+		},
+		{
+			description:   "unknown output",
+			query:         `{"type": "anomaly", "expression": "A", "season": "1d", "output": "bands"}`,
+			expectedError: "expected anomaly output to be one of",
+		},
+		{
+			description:   "seasonal median needs two seasons",
+			query:         `{"type": "anomaly", "expression": "A", "season": "1d", "seasons": 1}`,
+			expectedError: "at least 2 earlier seasons",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			q := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal([]byte(tc.query), &q))
+			cmd, err := UnmarshalAnomalyCommand(&rawNode{RefID: "B", Query: q})
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cmd)
+		})
+	}
+}
+
+func TestAnomalyCommandExecute(t *testing.T) {
+	s := mathexp.NewSeries("A", data.Labels{"host": "a"}, 4)
+	for i, v := range []float64{1, 2, 1.5, 9} {
+		s.SetPoint(i, time.Unix(int64(i)*60, 0), fp(v))
+	}
+	vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{s, mathexp.NoData{}.New()}}}
+
+	t.Run("score", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", mathexp.AnomalySeasonalMedian, mathexp.AnomalyParams{Season: time.Minute, Seasons: 2, Deviations: 3}, AnomalyOutputScore)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		score := res.Values[0].(mathexp.Series)
+		require.Equal(t, data.Labels{"host": "a"}, score.GetLabels())
+		require.Nil(t, score.GetValue(1))
+		// the earlier values of the last point are 2 and 1.5
+		require.InDelta(t, (9-1.75)/(1.4826*0.25), *score.GetValue(3), 1e-9)
+		_, ok := res.Values[1].(mathexp.NoData)
+		require.True(t, ok)
+	})
+
+	t.Run("all outputs are labeled", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", mathexp.AnomalySeasonalMedian, mathexp.AnomalyParams{Season: time.Minute, Seasons: 2, Deviations: 3}, AnomalyOutputAll)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 5)
+		for i, output := range []string{"baseline", "upper", "lower", "score"} {
+			require.Equal(t, data.Labels{"host": "a", "anomaly": output}, res.Values[i].GetLabels())
+		}
+		upper := res.Values[1].(mathexp.Series)
+		require.InDelta(t, 1.75+3*1.4826*0.25, *upper.GetValue(3), 1e-9)
+	})
+
+	t.Run("numbers are not supported", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", mathexp.AnomalySeasonalMedian, mathexp.AnomalyParams{Season: time.Minute, Seasons: 2, Deviations: 3}, AnomalyOutputScore)
+		require.NoError(t, err)
+		_, err = cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("A", nil)}},
+		})
+		require.Error(t, err)
+	})
+}
@@ -23,7 +23,9 @@ type Command interface {
 type MathCommand struct {
 	RawExpression string
 	Expression    *mathexp.Expr
-	refID         string
+	// NullPolicy is how the operators handle operands that are null or NaN.
+	NullPolicy mathexp.NullPolicy
+	refID      string
 }
 
 // NewMathCommand creates a new MathCommand. It will return an error
@@ -36,6 +38,7 @@ func NewMathCommand(refID, expr string) (*MathCommand, error) {
 	return &MathCommand{
 		RawExpression: expr,
 		Expression:    parsedExpr,
+		NullPolicy:    mathexp.NullPolicyPropagate,
 		refID:         refID,
 	}, nil
 }
@@ -55,9 +58,26 @@ func UnmarshalMathCommand(rn *rawNode) (*MathCommand, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid math command type: %w", err)
 	}
+	gm.NullPolicy, err = unmarshalNullPolicy(rn)
+	if err != nil {
+		return nil, err
+	}
 	return gm, nil
 }
 
+// unmarshalNullPolicy returns the null policy of Grafana's frontend query, which is NullPolicyPropagate if it is not set.
+func unmarshalNullPolicy(rn *rawNode) (mathexp.NullPolicy, error) {
+	rawPolicy, ok := rn.Query["nullPolicy"]
+	if !ok || rawPolicy == nil {
+		return mathexp.NullPolicyPropagate, nil
+	}
+	policy, ok := rawPolicy.(string)
+	if !ok {
+		return "", fmt.Errorf("expected null policy to be a string, got %T for refId %v", rawPolicy, rn.RefID)
+	}
+	return mathexp.ParseNullPolicy(policy)
+}
+
 // NeedsVars returns the variable names (refIds) that are dependencies
 // to execute the command and allows the command to fulfill the Command interface.
 func (gm *MathCommand) NeedsVars() []string {
@@ -67,12 +87,13 @@ func (gm *MathCommand) NeedsVars() []string {
 // Execute runs the command and returns the results or an error if the command
 // failed to execute.
 func (gm *MathCommand) Execute(_ context.Context, _ time.Time, vars mathexp.Vars) (mathexp.Results, error) {
-	return gm.Expression.Execute(gm.refID, vars)
+	return gm.Expression.ExecuteWithNullPolicy(gm.refID, vars, gm.NullPolicy)
 }
 
 // ReduceCommand is an expression command for reduction of a timeseries such as a min, mean, or max.
 type ReduceCommand struct {
 	Reducer      string
+	Params       mathexp.ReduceParams
 	VarToReduce  string
 	refID        string
 	seriesMapper mathexp.ReduceMapper
@@ -80,13 +101,19 @@ type ReduceCommand struct {
 
 // NewReduceCommand creates a new ReduceCMD.
 func NewReduceCommand(refID, reducer, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
-	_, err := mathexp.GetReduceFunc(reducer)
+	return NewReduceCommandWithParams(refID, reducer, mathexp.ReduceParams{}, varToReduce, mapper)
+}
+
+// NewReduceCommandWithParams creates a new ReduceCMD for a reducer that requires parameters, such as percentile.
+func NewReduceCommandWithParams(refID, reducer string, params mathexp.ReduceParams, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
+	_, err := mathexp.GetReduceFunc(reducer, params)
 	if err != nil {
 		return nil, err
 	}
 
 	return &ReduceCommand{
 		Reducer:      reducer,
+		Params:       params,
 		VarToReduce:  varToReduce,
 		refID:        refID,
 		seriesMapper: mapper,
@@ -143,7 +170,23 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 			return nil, fmt.Errorf("field settings must be an object, got %T for refId %v", s, rn.RefID)
 		}
 	}
-	return NewReduceCommand(rn.RefID, redFunc, varToReduce, mapper)
+
+	params := mathexp.ReduceParams{}
+	rawParams, ok := rn.Query["reducerParams"]
+	if ok {
+		p, ok := rawParams.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("field reducerParams must be an object, got %T for refId %v", rawParams, rn.RefID)
+		}
+		if rawPercentile, ok := p["percentile"]; ok {
+			percentile, ok := rawPercentile.(float64)
+			if !ok {
+				return nil, fmt.Errorf("reducer parameter percentile must be a number, got %T", rawPercentile)
+			}
+			params.Percentile = percentile
+		}
+	}
+	return NewReduceCommandWithParams(rn.RefID, redFunc, params, varToReduce, mapper)
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -159,7 +202,7 @@ func (gr *ReduceCommand) Execute(_ context.Context, _ time.Time, vars mathexp.Va
 	for _, val := range vars[gr.VarToReduce].Values {
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Reduce(gr.refID, gr.Reducer, gr.seriesMapper)
+			num, err := v.ReduceWithParams(gr.refID, gr.Reducer, gr.Params, gr.seriesMapper)
 			if err != nil {
 				return newRes, err
 			}
@@ -295,6 +338,12 @@ const (
 	TypeClassicConditions
 	// TypeThreshold is the CMDType for checking if a threshold has been crossed
 	TypeThreshold
+	// TypeSQL is the CMDType for running a SQL query over the results of other queries and expressions.
+	TypeSQL
+	// TypeAnomaly is the CMDType for detecting anomalies of timeseries against a seasonal baseline.
+	TypeAnomaly
+	// TypeRuleState is the CMDType for reading the current state of the alert instances of another alert rule.
+	TypeRuleState
 )
 
 func (gt CommandType) String() string {
@@ -307,6 +356,14 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeThreshold:
+		return "threshold"
+	case TypeSQL:
+		return "sql"
+	case TypeAnomaly:
+		return "anomaly"
+	case TypeRuleState:
+		return "rule_state"
 	default:
 		return "unknown"
 	}
@@ -325,6 +382,12 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeClassicConditions, nil
 	case "threshold":
 		return TypeThreshold, nil
+	case "sql":
+		return TypeSQL, nil
+	case "anomaly":
+		return TypeAnomaly, nil
+	case "rule_state":
+		return TypeRuleState, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
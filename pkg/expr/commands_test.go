@@ -97,6 +97,104 @@ func Test_UnmarshalReduceCommand_Settings(t *testing.T) {
 	}
 }
 
+func Test_UnmarshalReduceCommand_Params(t *testing.T) {
+	var tests = []struct {
+		name           string
+		query          string
+		isError        bool
+		expectedParams mathexp.ReduceParams
+	}{
+		{
+			name:           "no parameters when reducerParams is not specified",
+			query:          `{ "expression" : "$A", "reducer": "median" }`,
+			expectedParams: mathexp.ReduceParams{},
+		},
+		{
+			name:           "percentile is read from reducerParams",
+			query:          `{ "expression" : "$A", "reducer": "percentile", "reducerParams": { "percentile": 95 } }`,
+			expectedParams: mathexp.ReduceParams{Percentile: 95},
+		},
+		{
+			name:    "error when percentile is not specified",
+			query:   `{ "expression" : "$A", "reducer": "percentile" }`,
+			isError: true,
+		},
+		{
+			name:    "error when reducerParams is not an object",
+			query:   `{ "expression" : "$A", "reducer": "percentile", "reducerParams": 95 }`,
+			isError: true,
+		},
+		{
+			name:    "error when percentile is not a number",
+			query:   `{ "expression" : "$A", "reducer": "percentile", "reducerParams": { "percentile": "95" } }`,
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalReduceCommand(&rawNode{
+				RefID:     "A",
+				Query:     qmap,
+				TimeRange: RelativeTimeRange{},
+			})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expectedParams, cmd.Params)
+		})
+	}
+}
+
+func TestUnmarshalMathCommandNullPolicy(t *testing.T) {
+	for _, test := range []struct {
+		name           string
+		query          string
+		expectedPolicy mathexp.NullPolicy
+		expectedError  string
+	}{
+		{
+			name:           "propagate by default",
+			query:          `{ "expression": "$A > 1" }`,
+			expectedPolicy: mathexp.NullPolicyPropagate,
+		},
+		{
+			name:           "policy from the query",
+			query:          `{ "expression": "$A > 1", "nullPolicy": "dropPoint" }`,
+			expectedPolicy: mathexp.NullPolicyDropPoint,
+		},
+		{
+			name:          "unknown policy",
+			query:         `{ "expression": "$A > 1", "nullPolicy": "ignore" }`,
+			expectedError: "expected null policy to be one of",
+		},
+		{
+			name:          "policy is not a string",
+			query:         `{ "expression": "$A > 1", "nullPolicy": 1 }`,
+			expectedError: "expected null policy to be a string",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			q := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal([]byte(test.query), &q))
+			cmd, err := UnmarshalMathCommand(&rawNode{RefID: "B", Query: q})
+			if test.expectedError != "" {
+				require.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedPolicy, cmd.NullPolicy)
+		})
+	}
+}
+
 func TestReduceExecute(t *testing.T) {
 	varToReduce := util.GenerateShortUID()
 	cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, nil)
@@ -168,5 +266,11 @@ func TestReduceExecute(t *testing.T) {
 
 func randomReduceFunc() string {
 	res := mathexp.GetSupportedReduceFuncs()
-	return res[rand.Intn(len(res)-1)]
+	for {
+		r := res[rand.Intn(len(res)-1)]
+		// percentile cannot be created without parameters
+		if r != "percentile" {
+			return r
+		}
+	}
 }
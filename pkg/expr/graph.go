@@ -46,11 +46,15 @@ type Node interface {
 type DataPipeline []Node
 
 // execute runs all the command/datasource requests in the pipeline return a
-// map of the refId of the of each command
+// map of the refId of the of each command. If the context has a trace, the
+// execution of each node is added to it.
 func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (mathexp.Vars, error) {
+	trace := traceFromContext(c)
 	vars := make(mathexp.Vars)
 	for _, node := range *dp {
+		start := time.Now()
 		res, err := node.Execute(c, now, vars, s)
+		trace.addNode(node, res, err, time.Since(start))
 		if err != nil {
 			return nil, err
 		}
@@ -187,6 +191,10 @@ func buildGraphEdges(dp *simple.DirectedGraph, registry map[string]Node) error {
 
 		cmdNode := node.(*CMDNode)
 
+		if sqlCmd, ok := cmdNode.Command.(*SQLCommand); ok {
+			sqlCmd.matchRefIDs(registry)
+		}
+
 		for _, neededVar := range cmdNode.Command.NeedsVars() {
 			neededNode, ok := registry[neededVar]
 			if !ok {
@@ -203,6 +211,14 @@ func buildGraphEdges(dp *simple.DirectedGraph, registry map[string]Node) error {
 				}
 			}
 
+			if dsNode, ok := neededNode.(*DSNode); ok {
+				isSQL := cmdNode.CMDType == TypeSQL
+				if dsNode.isInputToSQLExpr != isSQL && dp.From(dsNode.ID()).Len() > 0 {
+					return fmt.Errorf("query %v can not be the input of both sql expressions and other expressions", neededVar)
+				}
+				dsNode.isInputToSQLExpr = isSQL
+			}
+
 			if neededNode.NodeType() == TypeCMDNode {
 				if neededNode.(*CMDNode).CMDType == TypeClassicConditions {
 					return fmt.Errorf("classic conditions may not be the input for other expressions, but %v is the input for %v", neededVar, cmdNode.RefID())
@@ -230,6 +230,60 @@ func TestServicebuildPipeLine(t *testing.T) {
 			},
 			expectedOrder: []string{"B", "A"},
 		},
+		{
+			name: "sql expression requires the tables it reads",
+			req: &Request{
+				Queries: []Query{
+					{
+						RefID:      "A",
+						DataSource: DataSourceModel(),
+						JSON: json.RawMessage(`{
+							"expression": "SELECT host, avg(value) AS value FROM B GROUP BY host",
+							"type": "sql"
+						}`),
+					},
+					{
+						RefID: "B",
+						DataSource: &datasources.DataSource{
+							Uid: "Fake",
+						},
+						TimeRange: AbsoluteTimeRange{},
+					},
+				},
+			},
+			expectedOrder: []string{"B", "A"},
+		},
+		{
+			name: "query can not be the input of sql and other expressions",
+			req: &Request{
+				Queries: []Query{
+					{
+						RefID:      "A",
+						DataSource: DataSourceModel(),
+						JSON: json.RawMessage(`{
+							"expression": "SELECT * FROM B",
+							"type": "sql"
+						}`),
+					},
+					{
+						RefID: "B",
+						DataSource: &datasources.DataSource{
+							Uid: "Fake",
+						},
+						TimeRange: AbsoluteTimeRange{},
+					},
+					{
+						RefID:      "C",
+						DataSource: DataSourceModel(),
+						JSON: json.RawMessage(`{
+							"expression": "$B",
+							"type": "math"
+						}`),
+					},
+				},
+			},
+			expectErrContains: "can not be the input of both sql expressions and other expressions",
+		},
 	}
 	s := Service{}
 	for _, tt := range tests {
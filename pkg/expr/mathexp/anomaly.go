@@ -0,0 +1,303 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// AnomalySeasonalMedian is the method that uses the median of the values at the same
+	// time in the earlier seasons as the baseline.
+	AnomalySeasonalMedian = "seasonal_median"
+	// AnomalyHoltWinters is the method that uses additive Holt-Winters (triple exponential smoothing)
+	// to forecast the baseline.
+	AnomalyHoltWinters = "holt_winters"
+)
+
+// madScale scales the median absolute deviation to be an estimate of the standard deviation of normally distributed values.
+const madScale = 1.4826
+
+// AnomalyParams are the parameters of the anomaly detection methods.
+type AnomalyParams struct {
+	// Season is the length of the seasonal pattern, for example a day or a week.
+	Season time.Duration
+	// Seasons is the number of earlier seasons used by the seasonal median method.
+	Seasons int
+	// Alpha, Beta, and Gamma are the smoothing factors of the level, trend, and
+	// seasonal components of the Holt-Winters method.
+	Alpha, Beta, Gamma float64
+	// Deviations is the number of spreads between the baseline and each band.
+	Deviations float64
+}
+
+// Baseline is the expected value of each point of a series and the spread of the values around it.
+// Both are null for the points that do not have enough history.
+type Baseline struct {
+	Expected []*float64
+	Spread   []*float64
+}
+
+// Anomaly holds the baseline, the bands, and the deviation score of a series.
+type Anomaly struct {
+	Baseline Series
+	Upper    Series
+	Lower    Series
+	// Score is the number of spreads between the value and the baseline. It is positive
+	// if the value is above the baseline, and infinite if the value differs from a baseline without spread.
+	Score Series
+}
+
+// Anomaly computes the baseline of the series with the given method, and returns the baseline,
+// the bands, and the deviation score of each point.
+func (s Series) Anomaly(refID, method string, params AnomalyParams) (Anomaly, error) {
+	var b Baseline
+	var err error
+	switch method {
+	case AnomalySeasonalMedian:
+		b, err = s.SeasonalMedianBaseline(params.Season, params.Seasons)
+	case AnomalyHoltWinters:
+		b, err = s.HoltWintersBaseline(params.Season, params.Alpha, params.Beta, params.Gamma)
+	default:
+		err = fmt.Errorf("anomaly detection method %q is not supported", method)
+	}
+	if err != nil {
+		return Anomaly{}, err
+	}
+
+	a := Anomaly{
+		Baseline: NewSeries(refID, s.GetLabels().Copy(), s.Len()),
+		Upper:    NewSeries(refID, s.GetLabels().Copy(), s.Len()),
+		Lower:    NewSeries(refID, s.GetLabels().Copy(), s.Len()),
+		Score:    NewSeries(refID, s.GetLabels().Copy(), s.Len()),
+	}
+	for i := 0; i < s.Len(); i++ {
+		t, v := s.GetPoint(i)
+		var upper, lower, score *float64
+		if expected, spread := b.Expected[i], b.Spread[i]; expected != nil && spread != nil {
+			u := *expected + params.Deviations**spread
+			l := *expected - params.Deviations**spread
+			upper, lower = &u, &l
+			if v != nil && !math.IsNaN(*v) {
+				var sc float64
+				if diff := *v - *expected; diff != 0 {
+					sc = diff / *spread
+				}
+				score = &sc
+			}
+		}
+		a.Baseline.SetPoint(i, t, b.Expected[i])
+		a.Upper.SetPoint(i, t, upper)
+		a.Lower.SetPoint(i, t, lower)
+		a.Score.SetPoint(i, t, score)
+	}
+	return a, nil
+}
+
+// SeasonalMedianBaseline returns, for each point, the median of the values at the same time in the given
+// number of earlier seasons as the expected value, and the scaled median absolute deviation of these values
+// as the spread. The value of an earlier season is the value of the point that is the closest to that time,
+// if it is within half the median interval of the series. Points with fewer than two such values have no baseline.
+func (s Series) SeasonalMedianBaseline(season time.Duration, seasons int) (Baseline, error) {
+	if season <= 0 {
+		return Baseline{}, fmt.Errorf("the season must be a positive duration")
+	}
+	if seasons < 2 {
+		return Baseline{}, fmt.Errorf("the seasonal median needs at least 2 earlier seasons, got %d", seasons)
+	}
+
+	points := sortedPoints(s)
+	tolerance := medianInterval(points) / 2
+	b := Baseline{Expected: make([]*float64, s.Len()), Spread: make([]*float64, s.Len())}
+	for i := 0; i < s.Len(); i++ {
+		t := s.GetTime(i)
+		earlier := make([]float64, 0, seasons)
+		for k := 1; k <= seasons; k++ {
+			if v, ok := points.closest(t.Add(-time.Duration(k)*season), tolerance); ok {
+				earlier = append(earlier, v)
+			}
+		}
+		if len(earlier) < 2 {
+			continue
+		}
+		expected := median(earlier)
+		deviations := make([]float64, len(earlier))
+		for j, v := range earlier {
+			deviations[j] = math.Abs(v - expected)
+		}
+		spread := madScale * median(deviations)
+		b.Expected[i], b.Spread[i] = &expected, &spread
+	}
+	return b, nil
+}
+
+// HoltWintersBaseline returns, for each point, the one step ahead forecast of additive Holt-Winters as the
+// expected value, and the standard deviation of the errors of the earlier forecasts as the spread. The points
+// must be regularly spaced, which can be done with a resample expression. The number of points in a season is
+// the season divided by the median interval of the series. The first season is used for initialization, so it
+// has no baseline, and the series must have at least two seasons. Null and NaN values are replaced by their forecast.
+func (s Series) HoltWintersBaseline(season time.Duration, alpha, beta, gamma float64) (Baseline, error) {
+	for _, f := range []struct {
+		name  string
+		value float64
+	}{{"alpha", alpha}, {"beta", beta}, {"gamma", gamma}} {
+		if f.value < 0 || f.value > 1 || math.IsNaN(f.value) {
+			return Baseline{}, fmt.Errorf("%s must be between 0 and 1, got %v", f.name, f.value)
+		}
+	}
+
+	points := sortedPoints(s)
+	interval := medianInterval(points)
+	if interval <= 0 {
+		return Baseline{}, fmt.Errorf("the series must have at least two points at different times")
+	}
+	length := int(math.Round(float64(season) / float64(interval)))
+	if length < 2 {
+		return Baseline{}, fmt.Errorf("the season %v must be at least two intervals of the series (%v)", season, interval)
+	}
+	n := len(points)
+	if n < 2*length {
+		return Baseline{}, fmt.Errorf("holt-winters needs at least two seasons of %d points, but the series has %d points", length, n)
+	}
+
+	first, ok1 := meanOf(points[:length])
+	second, ok2 := meanOf(points[length : 2*length])
+	if !ok1 || !ok2 {
+		return Baseline{}, fmt.Errorf("holt-winters needs values in the first two seasons")
+	}
+	level := first
+	trend := (second - first) / float64(length)
+	seasonal := make([]float64, length)
+	for i := 0; i < length; i++ {
+		if v, ok := points[i].number(); ok {
+			seasonal[i] = v - first
+		}
+	}
+
+	expected := make([]*float64, n)
+	spread := make([]*float64, n)
+	var errCount int
+	var errMean, errM2 float64
+	for i := length; i < n; i++ {
+		forecast := level + trend + seasonal[i%length]
+		f := forecast
+		expected[i] = &f
+		if errCount >= 2 {
+			sd := math.Sqrt(errM2 / float64(errCount))
+			spread[i] = &sd
+		}
+
+		v, ok := points[i].number()
+		if !ok {
+			v = forecast
+		} else {
+			// Welford's algorithm for the standard deviation of the errors
+			e := v - forecast
+			errCount++
+			delta := e - errMean
+			errMean += delta / float64(errCount)
+			errM2 += delta * (e - errMean)
+		}
+		prevLevel := level
+		level = alpha*(v-seasonal[i%length]) + (1-alpha)*(level+trend)
+		trend = beta*(level-prevLevel) + (1-beta)*trend
+		seasonal[i%length] = gamma*(v-level) + (1-gamma)*seasonal[i%length]
+	}
+
+	// map the baseline of the sorted points back to the points of the series
+	b := Baseline{Expected: make([]*float64, n), Spread: make([]*float64, n)}
+	for i, p := range points {
+		b.Expected[p.idx], b.Spread[p.idx] = expected[i], spread[i]
+	}
+	return b, nil
+}
+
+type point struct {
+	idx   int
+	t     time.Time
+	value *float64
+}
+
+// number returns the value of the point if it is not null or NaN.
+func (p point) number() (float64, bool) {
+	if p.value == nil || math.IsNaN(*p.value) {
+		return 0, false
+	}
+	return *p.value, true
+}
+
+type points []point
+
+// sortedPoints returns the points of the series sorted by time, without changing the series.
+func sortedPoints(s Series) points {
+	ps := make(points, s.Len())
+	for i := range ps {
+		t, v := s.GetPoint(i)
+		ps[i] = point{idx: i, t: t, value: v}
+	}
+	sort.SliceStable(ps, func(i, j int) bool { return ps[i].t.Before(ps[j].t) })
+	return ps
+}
+
+// closest returns the value of the point that is the closest to t, if it is within the tolerance and is a number.
+func (ps points) closest(t time.Time, tolerance time.Duration) (float64, bool) {
+	i := sort.Search(len(ps), func(i int) bool { return !ps[i].t.Before(t) })
+	best := -1
+	for _, j := range []int{i - 1, i} {
+		if j < 0 || j >= len(ps) {
+			continue
+		}
+		if best == -1 || absDuration(ps[j].t.Sub(t)) < absDuration(ps[best].t.Sub(t)) {
+			best = j
+		}
+	}
+	if best == -1 || absDuration(ps[best].t.Sub(t)) > tolerance {
+		return 0, false
+	}
+	return ps[best].number()
+}
+
+// medianInterval returns the median of the intervals between consecutive points.
+func medianInterval(ps points) time.Duration {
+	if len(ps) < 2 {
+		return 0
+	}
+	intervals := make([]float64, 0, len(ps)-1)
+	for i := 1; i < len(ps); i++ {
+		intervals = append(intervals, float64(ps[i].t.Sub(ps[i-1].t)))
+	}
+	return time.Duration(median(intervals))
+}
+
+func meanOf(ps points) (float64, bool) {
+	var sum float64
+	var count int
+	for _, p := range ps {
+		if v, ok := p.number(); ok {
+			sum += v
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return sum / float64(count), true
+}
+
+// median returns the median of the values. The values are sorted in place.
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return values[mid]
+	}
+	return (values[mid-1] + values[mid]) / 2
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,90 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+// seasonalSeries returns a series with a point every hour for the given number of days, that follows
+// a daily pattern with a small variation between days. The value of the last point is last.
+func seasonalSeries(days int, last float64) Series {
+	n := days * 24
+	s := NewSeries("", data.Labels{"host": "a"}, n)
+	start := time.Unix(0, 0).UTC()
+	for i := 0; i < n; i++ {
+		v := 10 + 5*math.Sin(2*math.Pi*float64(i%24)/24) + float64(i/24%3)*0.2
+		if i == n-1 {
+			v = last
+		}
+		s.SetPoint(i, start.Add(time.Duration(i)*time.Hour), &v)
+	}
+	return s
+}
+
+func TestSeasonalMedianBaseline(t *testing.T) {
+	s := seasonalSeries(5, 10)
+	a, err := s.Anomaly("B", AnomalySeasonalMedian, AnomalyParams{Season: 24 * time.Hour, Seasons: 3, Deviations: 3})
+	require.NoError(t, err)
+
+	require.Equal(t, s.Len(), a.Score.Len())
+	require.Equal(t, data.Labels{"host": "a"}, a.Score.GetLabels())
+
+	// the first two days do not have two earlier seasons
+	require.Nil(t, a.Baseline.GetValue(0))
+	require.Nil(t, a.Score.GetValue(47))
+	require.NotNil(t, a.Baseline.GetValue(48))
+
+	// a normal value is within the bands
+	i := 4*24 + 3
+	_, v := s.GetPoint(i)
+	require.LessOrEqual(t, *a.Lower.GetValue(i), *v)
+	require.GreaterOrEqual(t, *a.Upper.GetValue(i), *v)
+	require.Less(t, math.Abs(*a.Score.GetValue(i)), 3.0)
+
+	// the last value is 10 instead of about 8.9
+	last := s.Len() - 1
+	require.InDelta(t, 10+5*math.Sin(2*math.Pi*23/24)+0.2, *a.Baseline.GetValue(last), 0.21)
+	require.Greater(t, *a.Score.GetValue(last), 3.0)
+	require.Greater(t, 10.0, *a.Upper.GetValue(last))
+}
+
+func TestSeasonalMedianBaselineWithoutSpread(t *testing.T) {
+	s := NewSeries("", nil, 3)
+	for i, v := range []float64{1, 1, 2} {
+		v := v
+		s.SetPoint(i, time.Unix(int64(i)*60, 0), &v)
+	}
+	a, err := s.Anomaly("B", AnomalySeasonalMedian, AnomalyParams{Season: time.Minute, Seasons: 2, Deviations: 3})
+	require.NoError(t, err)
+	require.Equal(t, 1.0, *a.Baseline.GetValue(2))
+	require.True(t, math.IsInf(*a.Score.GetValue(2), 1))
+}
+
+func TestHoltWintersBaseline(t *testing.T) {
+	s := seasonalSeries(5, 30)
+	a, err := s.Anomaly("B", AnomalyHoltWinters, AnomalyParams{Season: 24 * time.Hour, Alpha: 0.5, Beta: 0.1, Gamma: 0.5, Deviations: 3})
+	require.NoError(t, err)
+
+	// the first season is used for initialization
+	require.Nil(t, a.Baseline.GetValue(23))
+	require.NotNil(t, a.Baseline.GetValue(24))
+
+	last := s.Len() - 1
+	require.InDelta(t, 10+5*math.Sin(2*math.Pi*23/24), *a.Baseline.GetValue(last), 1)
+	require.Greater(t, *a.Score.GetValue(last), 3.0)
+	require.Less(t, math.Abs(*a.Score.GetValue(last - 1)), 3.0)
+
+	t.Run("needs two seasons", func(t *testing.T) {
+		_, err := seasonalSeries(1, 1).HoltWintersBaseline(24*time.Hour, 0.5, 0.1, 0.1)
+		require.Error(t, err)
+	})
+
+	t.Run("smoothing factors must be between 0 and 1", func(t *testing.T) {
+		_, err := s.HoltWintersBaseline(24*time.Hour, 1.5, 0.1, 0.1)
+		require.ErrorContains(t, err, "alpha")
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"math"
 	"reflect"
 	"runtime"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
@@ -22,8 +23,13 @@ type State struct {
 	Vars Vars
 	// Could hold more properties that change behavior around:
 	//  - Unions (How many result A and many Result B in case A + B are joined)
-	//  - NaN/Null behavior
 	RefID string
+	// NullPolicy is how the operators handle operands that are null or NaN.
+	NullPolicy NullPolicy
+
+	// unmatched describes the items that were dropped by binary operations with
+	// on/ignoring modifiers because they had no match on the other side.
+	unmatched []string
 }
 
 // Vars holds the results of datasource queries or other expression commands.
@@ -44,10 +50,17 @@ func New(expr string, funcs ...map[string]parse.Func) (*Expr, error) {
 
 // Execute applies a parse expression to the context and executes it
 func (e *Expr) Execute(refID string, vars Vars) (r Results, err error) {
+	return e.ExecuteWithNullPolicy(refID, vars, NullPolicyPropagate)
+}
+
+// ExecuteWithNullPolicy executes the expression like Execute, with the operators
+// handling null and NaN operands according to the policy.
+func (e *Expr) ExecuteWithNullPolicy(refID string, vars Vars, policy NullPolicy) (r Results, err error) {
 	s := &State{
-		Expr:  e,
-		Vars:  vars,
-		RefID: refID,
+		Expr:       e,
+		Vars:       vars,
+		RefID:      refID,
+		NullPolicy: policy,
 	}
 	return e.executeState(s)
 }
@@ -55,9 +68,42 @@ func (e *Expr) Execute(refID string, vars Vars) (r Results, err error) {
 func (e *Expr) executeState(s *State) (r Results, err error) {
 	defer errRecover(&err, s)
 	r, err = s.walk(e.Tree.Root)
+	if err == nil {
+		r = s.addUnmatchedNotice(r)
+	}
 	return
 }
 
+// maxUnmatchedInNotice is the number of unmatched items that are listed in the notice, so
+// that the notice stays readable when many items are dropped.
+const maxUnmatchedInNotice = 10
+
+// addUnmatchedNotice adds a warning that lists the unmatched items of binary operations
+// to each value of the results. If there are no values, because nothing matched, a NoData
+// value is returned with the warning so that the reason is not lost.
+func (e *State) addUnmatchedNotice(r Results) Results {
+	if len(e.unmatched) == 0 {
+		return r
+	}
+	text := "Some items were not matched and were dropped: "
+	if len(e.unmatched) > maxUnmatchedInNotice {
+		text += strings.Join(e.unmatched[:maxUnmatchedInNotice], "; ") + fmt.Sprintf(" and %d more", len(e.unmatched)-maxUnmatchedInNotice)
+	} else {
+		text += strings.Join(e.unmatched, "; ")
+	}
+	notice := data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text:     text,
+	}
+	if len(r.Values) == 0 {
+		r.Values = append(r.Values, NoData{}.New())
+	}
+	for _, v := range r.Values {
+		v.AddNotice(notice)
+	}
+	return r
+}
+
 // errRecover is the handler that turns panics into returns from the top
 // level of Parse.
 func errRecover(errp *error, s *State) {
@@ -104,17 +150,17 @@ func (e *State) walkUnary(node *parse.UnaryNode) (Results, error) {
 		var newVal Value
 		switch rt := val.(type) {
 		case Scalar:
-			newVal = NewScalar(e.RefID, nil)
-			f := rt.GetFloat64Value()
-			if f != nil {
-				newF, err := unaryOp(node.OpStr, *f)
-				if err != nil {
-					return newResults, err
-				}
-				newVal = NewScalar(e.RefID, &newF)
+			newF, _, err := e.nullableUnaryOp(node.OpStr, rt.GetFloat64Value())
+			if err != nil {
+				return newResults, err
 			}
+			newVal = NewScalar(e.RefID, newF)
 		case Number:
-			newVal, err = e.unaryNumber(rt, node.OpStr)
+			var keep bool
+			newVal, keep, err = e.unaryNumber(rt, node.OpStr)
+			if err == nil && !keep {
+				continue
+			}
 		case Series:
 			newVal, err = e.unarySeries(rt, node.OpStr)
 		case NoData:
@@ -131,34 +177,29 @@ func (e *State) walkUnary(node *parse.UnaryNode) (Results, error) {
 }
 
 func (e *State) unarySeries(s Series, op string) (Series, error) {
-	newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+	newSeries := NewSeries(e.RefID, s.GetLabels(), 0)
 	for i := 0; i < s.Len(); i++ {
 		t, f := s.GetPoint(i)
-		if f == nil {
-			newSeries.SetPoint(i, t, nil)
-			continue
-		}
-		newF, err := unaryOp(op, *f)
+		newF, keep, err := e.nullableUnaryOp(op, f)
 		if err != nil {
 			return newSeries, err
 		}
-		newSeries.SetPoint(i, t, &newF)
+		if keep {
+			newSeries.AppendPoint(t, newF)
+		}
 	}
 	return newSeries, nil
 }
 
-func (e *State) unaryNumber(n Number, op string) (Number, error) {
+// unaryNumber performs the unary operation on the number. It returns false if the number must be dropped.
+func (e *State) unaryNumber(n Number, op string) (Number, bool, error) {
 	newNumber := NewNumber(e.RefID, n.GetLabels())
-
-	f := n.GetFloat64Value()
-	if f != nil {
-		newF, err := unaryOp(op, *f)
-		if err != nil {
-			return newNumber, err
-		}
-		newNumber.SetValue(&newF)
+	newF, keep, err := e.nullableUnaryOp(op, n.GetFloat64Value())
+	if err != nil {
+		return newNumber, keep, err
 	}
-	return newNumber, nil
+	newNumber.SetValue(newF)
+	return newNumber, keep, nil
 }
 
 // unaryOp performs a unary operation on a float.
@@ -247,6 +288,144 @@ func union(aResults, bResults Results) []*Union {
 	return unions
 }
 
+// matchingSignature returns the labels used to match an item in a binary operation with on/ignoring modifiers.
+func matchingSignature(labels data.Labels, m *parse.VectorMatching) data.Labels {
+	sig := data.Labels{}
+	if m.On {
+		for _, name := range m.MatchingLabels {
+			if v, ok := labels[name]; ok {
+				sig[name] = v
+			}
+		}
+		return sig
+	}
+	for k, v := range labels {
+		sig[k] = v
+	}
+	for _, name := range m.MatchingLabels {
+		delete(sig, name)
+	}
+	return sig
+}
+
+// matchUnion creates Union objects for a binary operation with on/ignoring modifiers. Items
+// match if they have the same values for the labels given with on, or for all labels except
+// the ones given with ignoring. Each item may only match one item on the other side, unless the
+// operation has a group_left or group_right modifier, in which case many items of the left or
+// right side respectively may match one item of the other side. Items without a match are
+// recorded in the state so they can be reported.
+func (e *State) matchUnion(node *parse.BinaryNode, aResults, bResults Results) ([]*Union, error) {
+	m := node.Matching
+	aValues := make([]Value, 0, len(aResults.Values))
+	for _, v := range aResults.Values {
+		if v.Type() != parse.TypeNoData {
+			aValues = append(aValues, v)
+		}
+	}
+	bValues := make([]Value, 0, len(bResults.Values))
+	for _, v := range bResults.Values {
+		if v.Type() != parse.TypeNoData {
+			bValues = append(bValues, v)
+		}
+	}
+	if len(aValues) == 0 || len(bValues) == 0 {
+		return nil, nil
+	}
+	for _, v := range append(aValues[:len(aValues):len(aValues)], bValues...) {
+		if v.Type() == parse.TypeScalar {
+			return nil, fmt.Errorf("%s can not be used with type %v in %s", m, v.Type(), node)
+		}
+	}
+
+	// the "one" side of the matching must have unique signatures, for one-to-one both sides must.
+	indexUnique := func(values []Value, side string) (map[string]Value, error) {
+		idx := make(map[string]Value, len(values))
+		for _, v := range values {
+			sig := matchingSignature(v.GetLabels(), m).String()
+			if _, ok := idx[sig]; ok {
+				return nil, fmt.Errorf("found more than one item with labels %s on the %s side of %s, use group_left or group_right to match many items with one", sig, side, node)
+			}
+			idx[sig] = v
+		}
+		return idx, nil
+	}
+
+	var manyValues []Value
+	var one map[string]Value
+	var err error
+	switch m.Card {
+	case parse.CardOneToOne:
+		if _, err = indexUnique(aValues, "left"); err != nil {
+			return nil, err
+		}
+		manyValues = aValues
+		one, err = indexUnique(bValues, "right")
+	case parse.CardManyToOne:
+		manyValues = aValues
+		one, err = indexUnique(bValues, "right")
+	case parse.CardOneToMany:
+		manyValues = bValues
+		one, err = indexUnique(aValues, "left")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	manySide, oneSide := "left", "right"
+	if m.Card == parse.CardOneToMany {
+		manySide, oneSide = "right", "left"
+	}
+
+	unions := []*Union{}
+	matched := map[string]bool{}
+	for _, many := range manyValues {
+		sig := matchingSignature(many.GetLabels(), m).String()
+		o, ok := one[sig]
+		if !ok {
+			e.unmatched = append(e.unmatched, fmt.Sprintf("{%s} on the %s side of %s", many.GetLabels(), manySide, node))
+			continue
+		}
+		matched[sig] = true
+
+		var labels data.Labels
+		if m.Card == parse.CardOneToOne {
+			labels = matchingSignature(many.GetLabels(), m)
+		} else {
+			labels = many.GetLabels().Copy()
+			if labels == nil {
+				labels = data.Labels{}
+			}
+			for _, name := range m.Include {
+				if v, ok := o.GetLabels()[name]; ok {
+					labels[name] = v
+				} else {
+					delete(labels, name)
+				}
+			}
+		}
+		if len(labels) == 0 {
+			labels = nil
+		}
+
+		u := &Union{Labels: labels, A: many, B: o}
+		if m.Card == parse.CardOneToMany {
+			u.A, u.B = o, many
+		}
+		unions = append(unions, u)
+	}
+
+	oneValues := bValues
+	if m.Card == parse.CardOneToMany {
+		oneValues = aValues
+	}
+	for _, o := range oneValues {
+		if !matched[matchingSignature(o.GetLabels(), m).String()] {
+			e.unmatched = append(e.unmatched, fmt.Sprintf("{%s} on the %s side of %s", o.GetLabels(), oneSide, node))
+		}
+	}
+	return unions, nil
+}
+
 func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	res := Results{Values{}}
 	ar, err := e.walk(node.Args[0])
@@ -257,31 +436,30 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := union(ar, br)
+	var unions []*Union
+	if node.Matching != nil {
+		unions, err = e.matchUnion(node, ar, br)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		unions = union(ar, br)
+	}
 	for _, uni := range unions {
 		var value Value
+		keep := true
 		switch at := uni.A.(type) {
 		case Scalar:
 			aFloat := at.GetFloat64Value()
 			switch bt := uni.B.(type) {
 			// Scalar op Scalar
 			case Scalar:
-				bFloat := bt.GetFloat64Value()
-				if aFloat == nil || bFloat == nil {
-					value = NewScalar(e.RefID, nil)
-					break
-				}
-				f := math.NaN()
-				if aFloat != nil && bFloat != nil {
-					f, err = binaryOp(node.OpStr, *aFloat, *bFloat)
-					if err != nil {
-						return res, err
-					}
-				}
-				value = NewScalar(e.RefID, &f)
-			// Scalar op Scalar
+				var f *float64
+				f, _, err = e.nullableBinaryOp(node.OpStr, aFloat, bt.GetFloat64Value())
+				value = NewScalar(e.RefID, f)
+			// Scalar op Number
 			case Number:
-				value, err = e.biScalarNumber(uni.Labels, node.OpStr, bt, aFloat, false)
+				value, keep, err = e.biScalarNumber(uni.Labels, node.OpStr, bt, aFloat, false)
 			// Scalar op Series
 			case Series:
 				value, err = e.biSeriesNumber(uni.Labels, node.OpStr, bt, aFloat, false)
@@ -309,10 +487,10 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 			switch bt := uni.B.(type) {
 			case Scalar:
 				bFloat := bt.GetFloat64Value()
-				value, err = e.biScalarNumber(uni.Labels, node.OpStr, at, bFloat, true)
+				value, keep, err = e.biScalarNumber(uni.Labels, node.OpStr, at, bFloat, true)
 			case Number:
 				bFloat := bt.GetFloat64Value()
-				value, err = e.biScalarNumber(uni.Labels, node.OpStr, at, bFloat, true)
+				value, keep, err = e.biScalarNumber(uni.Labels, node.OpStr, at, bFloat, true)
 			case Series:
 				value, err = e.biSeriesNumber(uni.Labels, node.OpStr, bt, aFloat, false)
 			default:
@@ -324,7 +502,9 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 		if err != nil {
 			return res, err
 		}
-		res.Values = append(res.Values, value)
+		if keep {
+			res.Values = append(res.Values, value)
+		}
 	}
 	return res, nil
 }
@@ -414,46 +594,42 @@ func binaryOp(op string, a, b float64) (r float64, err error) {
 	return r, nil
 }
 
-func (e *State) biScalarNumber(labels data.Labels, op string, number Number, scalarVal *float64, numberFirst bool) (Number, error) {
+// biScalarNumber performs the binary operation on the number and the scalar value. It returns false if the number must be dropped.
+func (e *State) biScalarNumber(labels data.Labels, op string, number Number, scalarVal *float64, numberFirst bool) (Number, bool, error) {
 	newNumber := NewNumber(e.RefID, labels)
-	f := number.GetFloat64Value()
-	if f == nil || scalarVal == nil {
-		newNumber.SetValue(nil)
-		return newNumber, nil
-	}
-	nF := math.NaN()
+	var nF *float64
+	var keep bool
 	var err error
 	if numberFirst {
-		nF, err = binaryOp(op, *f, *scalarVal)
+		nF, keep, err = e.nullableBinaryOp(op, number.GetFloat64Value(), scalarVal)
 	} else {
-		nF, err = binaryOp(op, *scalarVal, *f)
+		nF, keep, err = e.nullableBinaryOp(op, scalarVal, number.GetFloat64Value())
 	}
 	if err != nil {
-		return newNumber, err
+		return newNumber, keep, err
 	}
-	newNumber.SetValue(&nF)
-	return newNumber, nil
+	newNumber.SetValue(nF)
+	return newNumber, keep, nil
 }
 
 func (e *State) biSeriesNumber(labels data.Labels, op string, s Series, scalarVal *float64, seriesFirst bool) (Series, error) {
-	newSeries := NewSeries(e.RefID, labels, s.Len())
-	var err error
+	newSeries := NewSeries(e.RefID, labels, 0)
 	for i := 0; i < s.Len(); i++ {
-		nF := math.NaN()
+		var nF *float64
+		var keep bool
+		var err error
 		t, f := s.GetPoint(i)
-		if f == nil || scalarVal == nil {
-			newSeries.SetPoint(i, t, nil)
-			continue
-		}
 		if seriesFirst {
-			nF, err = binaryOp(op, *f, *scalarVal)
+			nF, keep, err = e.nullableBinaryOp(op, f, scalarVal)
 		} else {
-			nF, err = binaryOp(op, *scalarVal, *f)
+			nF, keep, err = e.nullableBinaryOp(op, scalarVal, f)
 		}
 		if err != nil {
 			return newSeries, err
 		}
-		newSeries.SetPoint(i, t, &nF)
+		if keep {
+			newSeries.AppendPoint(t, nF)
+		}
 	}
 	return newSeries, nil
 }
@@ -475,15 +651,13 @@ func (e *State) biSeriesSeries(labels data.Labels, op string, aSeries, bSeries S
 		if !ok {
 			continue
 		}
-		if aF == nil || bF == nil {
-			newSeries.AppendPoint(aTime, nil)
-			continue
-		}
-		nF, err := binaryOp(op, *aF, *bF)
+		nF, keep, err := e.nullableBinaryOp(op, aF, bF)
 		if err != nil {
 			return newSeries, err
 		}
-		newSeries.AppendPoint(aTime, &nF)
+		if keep {
+			newSeries.AppendPoint(aTime, nF)
+		}
 	}
 	return newSeries, nil
 }
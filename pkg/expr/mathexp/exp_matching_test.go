@@ -0,0 +1,207 @@
+package mathexp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+var errorsAndTotals = Vars{
+	"A": Results{
+		[]Value{
+			makeNumber("", data.Labels{"host": "a", "code": "500"}, float64Pointer(2)),
+			makeNumber("", data.Labels{"host": "a", "code": "502"}, float64Pointer(4)),
+			makeNumber("", data.Labels{"host": "b", "code": "500"}, float64Pointer(1)),
+		},
+	},
+	"B": Results{
+		[]Value{
+			makeNumber("", data.Labels{"host": "a", "dc": "east"}, float64Pointer(10)),
+			makeNumber("", data.Labels{"host": "b", "dc": "west"}, float64Pointer(20)),
+			makeNumber("", data.Labels{"host": "c", "dc": "west"}, float64Pointer(30)),
+		},
+	},
+}
+
+func TestBinaryMatching(t *testing.T) {
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+		unmatched int
+	}{
+		{
+			name: "on matches labels that are not subsets of each other",
+			expr: "$A / on(host) $B",
+			vars: Vars{
+				"A": Results{[]Value{
+					makeNumber("", data.Labels{"host": "a", "code": "500"}, float64Pointer(2)),
+				}},
+				"B": Results{[]Value{
+					makeNumber("", data.Labels{"host": "a", "dc": "east"}, float64Pointer(10)),
+				}},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(0.2)),
+			}},
+		},
+		{
+			name: "ignoring drops the ignored labels",
+			expr: "$A - ignoring(code, dc) $B",
+			vars: Vars{
+				"A": Results{[]Value{
+					makeNumber("", data.Labels{"host": "a", "code": "500"}, float64Pointer(2)),
+				}},
+				"B": Results{[]Value{
+					makeNumber("", data.Labels{"host": "a", "dc": "east"}, float64Pointer(10)),
+				}},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(-8)),
+			}},
+		},
+		{
+			name:      "group_left matches many items on the left with one on the right",
+			expr:      "$A / on(host) group_left(dc) $B",
+			vars:      errorsAndTotals,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a", "code": "500", "dc": "east"}, float64Pointer(0.2)),
+				makeNumber("", data.Labels{"host": "a", "code": "502", "dc": "east"}, float64Pointer(0.4)),
+				makeNumber("", data.Labels{"host": "b", "code": "500", "dc": "west"}, float64Pointer(0.05)),
+			}},
+			unmatched: 1,
+		},
+		{
+			name:      "group_right matches one item on the left with many on the right",
+			expr:      "$B * on(host) group_right $A",
+			vars:      errorsAndTotals,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a", "code": "500"}, float64Pointer(20)),
+				makeNumber("", data.Labels{"host": "a", "code": "502"}, float64Pointer(40)),
+				makeNumber("", data.Labels{"host": "b", "code": "500"}, float64Pointer(20)),
+			}},
+			unmatched: 1,
+		},
+		{
+			name:      "one-to-one with many items for the same labels is an error",
+			expr:      "$A / on(host) $B",
+			vars:      errorsAndTotals,
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:     "matching can not be used with a scalar",
+			expr:     "$A / on(host) 2",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "matching requires a label list",
+			expr:     "$A / on $B",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "label can not be in both on and group_left",
+			expr:     "$A / on(host) group_left(host) $B",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "matching is checked in nested operations",
+			expr:     "1 + ($A / on(host) group_left(host) $B)",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "matching is checked in nested function arguments",
+			expr:     "abs($A / on(host) 2) * 3",
+			newErrIs: require.Error,
+		},
+		{
+			name: "nothing matched returns no data",
+			expr: "$A + on(host) $B",
+			vars: Vars{
+				"A": Results{[]Value{
+					makeNumber("", data.Labels{"host": "a"}, float64Pointer(1)),
+				}},
+				"B": Results{[]Value{
+					makeNumber("", data.Labels{"host": "b"}, float64Pointer(1)),
+				}},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   Results{[]Value{NoData{}.New()}},
+			unmatched: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e == nil {
+				return
+			}
+			res, err := e.Execute("", tt.vars)
+			tt.execErrIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Len(t, res.Values, len(tt.results.Values))
+			for i, expected := range tt.results.Values {
+				actual := res.Values[i]
+				require.Equal(t, expected.Type(), actual.Type())
+				require.Equal(t, expected.GetLabels(), actual.GetLabels())
+				if n, ok := expected.(Number); ok {
+					require.InDelta(t, *n.GetFloat64Value(), *actual.(Number).GetFloat64Value(), 1e-9)
+				}
+				meta := actual.AsDataFrame().Meta
+				if tt.unmatched == 0 {
+					require.Nil(t, meta)
+					continue
+				}
+				require.NotNil(t, meta)
+				require.Len(t, meta.Notices, 1)
+				require.Equal(t, data.NoticeSeverityWarning, meta.Notices[0].Severity)
+			}
+		})
+	}
+}
+
+func TestBinaryMatchingNoticeIsCapped(t *testing.T) {
+	var a []Value
+	for i := 0; i < maxUnmatchedInNotice+5; i++ {
+		a = append(a, makeNumber("", data.Labels{"host": fmt.Sprintf("a%d", i)}, float64Pointer(1)))
+	}
+	vars := Vars{
+		"A": Results{a},
+		"B": Results{[]Value{makeNumber("", data.Labels{"host": "a0"}, float64Pointer(1))}},
+	}
+
+	e, err := New("$A + on(host) $B")
+	require.NoError(t, err)
+	res, err := e.Execute("", vars)
+	require.NoError(t, err)
+	require.Len(t, res.Values, 1)
+	notices := res.Values[0].AsDataFrame().Meta.Notices
+	require.Len(t, notices, 1)
+	require.Equal(t, maxUnmatchedInNotice-1, strings.Count(notices[0].Text, "; "))
+	require.True(t, strings.HasSuffix(notices[0].Text, " and 4 more"), notices[0].Text)
+}
+
+func TestBinaryMatchingString(t *testing.T) {
+	e, err := New(`$A / ignoring(code) group_left(dc, "zone-id") $B`)
+	require.NoError(t, err)
+	require.Equal(t, `$A / ignoring(code) group_left(dc, zone-id) $B`, e.Tree.Root.String())
+}
@@ -422,3 +422,172 @@ func TestNullValues(t *testing.T) {
 		})
 	}
 }
+
+func TestNullPolicy(t *testing.T) {
+	series := Vars{
+		"A": Results{
+			[]Value{
+				makeSeries("temp", nil, tp{
+					time.Unix(5, 0), float64Pointer(2),
+				}, tp{
+					time.Unix(10, 0), nil,
+				}, tp{
+					time.Unix(15, 0), NaN,
+				}),
+			},
+		},
+	}
+	numbers := Vars{
+		"A": Results{
+			[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(2)),
+				makeNumber("", data.Labels{"host": "b"}, nil),
+				makeNumber("", data.Labels{"host": "c"}, NaN),
+			},
+		},
+	}
+
+	var tests = []struct {
+		name    string
+		expr    string
+		policy  NullPolicy
+		vars    Vars
+		results Results
+	}{
+		{
+			name:   "propagate: series comparison has null and NaN",
+			expr:   "$A > 1",
+			policy: NullPolicyPropagate,
+			vars:   series,
+			results: Results{[]Value{makeSeries("", nil,
+				tp{time.Unix(5, 0), float64Pointer(1)},
+				tp{time.Unix(10, 0), nil},
+				tp{time.Unix(15, 0), NaN},
+			)}},
+		},
+		{
+			name:   "treatAsZero: series comparison uses 0",
+			expr:   "$A < 1",
+			policy: NullPolicyTreatAsZero,
+			vars:   series,
+			results: Results{[]Value{makeSeries("", nil,
+				tp{time.Unix(5, 0), float64Pointer(0)},
+				tp{time.Unix(10, 0), float64Pointer(1)},
+				tp{time.Unix(15, 0), float64Pointer(1)},
+			)}},
+		},
+		{
+			name:   "dropPoint: series points are dropped",
+			expr:   "$A * 2",
+			policy: NullPolicyDropPoint,
+			vars:   series,
+			results: Results{[]Value{makeSeries("", nil,
+				tp{time.Unix(5, 0), float64Pointer(4)},
+			)}},
+		},
+		{
+			name:   "treatAsFalse: series comparison is false",
+			expr:   "$A < 1",
+			policy: NullPolicyTreatAsFalse,
+			vars:   series,
+			results: Results{[]Value{makeSeries("", nil,
+				tp{time.Unix(5, 0), float64Pointer(0)},
+				tp{time.Unix(10, 0), float64Pointer(0)},
+				tp{time.Unix(15, 0), float64Pointer(0)},
+			)}},
+		},
+		{
+			name:   "treatAsFalse: logical operators use 0",
+			expr:   "$A < 1 || !$A",
+			policy: NullPolicyTreatAsFalse,
+			vars:   series,
+			results: Results{[]Value{makeSeries("", nil,
+				tp{time.Unix(5, 0), float64Pointer(0)},
+				tp{time.Unix(10, 0), float64Pointer(1)},
+				tp{time.Unix(15, 0), float64Pointer(1)},
+			)}},
+		},
+		{
+			name:   "propagate: numbers are null and NaN",
+			expr:   "-$A",
+			policy: NullPolicyPropagate,
+			vars:   numbers,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(-2)),
+				makeNumber("", data.Labels{"host": "b"}, nil),
+				makeNumber("", data.Labels{"host": "c"}, NaN),
+			}},
+		},
+		{
+			name:   "dropPoint: numbers are dropped",
+			expr:   "$A > 1",
+			policy: NullPolicyDropPoint,
+			vars:   numbers,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(1)),
+			}},
+		},
+		{
+			name:   "dropPoint: unary operators drop numbers",
+			expr:   "-$A",
+			policy: NullPolicyDropPoint,
+			vars:   numbers,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(-2)),
+			}},
+		},
+		{
+			name:   "treatAsFalse: number comparison is false",
+			expr:   "$A > 1 && $A < 10",
+			policy: NullPolicyTreatAsFalse,
+			vars:   numbers,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(0)),
+				makeNumber("", data.Labels{"host": "c"}, float64Pointer(0)),
+			}},
+		},
+		{
+			name:    "dropPoint: scalars are null",
+			expr:    "null() + 1",
+			policy:  NullPolicyDropPoint,
+			results: NewScalarResults("", nil),
+		},
+		{
+			name:    "treatAsZero: scalars use 0",
+			expr:    "nan() + 1",
+			policy:  NullPolicyTreatAsZero,
+			results: NewScalarResults("", float64Pointer(1)),
+		},
+	}
+
+	opt := cmp.Comparer(func(x, y float64) bool {
+		return (math.IsNaN(x) && math.IsNaN(y)) || x == y
+	})
+	options := append([]cmp.Option{opt}, data.FrameTestCompareOptions()...)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			assert.NoError(t, err)
+			res, err := e.ExecuteWithNullPolicy("", tt.vars, tt.policy)
+			assert.NoError(t, err)
+			if diff := cmp.Diff(tt.results, res, options...); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseNullPolicy(t *testing.T) {
+	p, err := ParseNullPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, NullPolicyPropagate, p)
+
+	p, err = ParseNullPolicy("treatAsFalse")
+	assert.NoError(t, err)
+	assert.Equal(t, NullPolicyTreatAsFalse, p)
+
+	_, err = ParseNullPolicy("ignore")
+	assert.ErrorContains(t, err, "expected null policy to be one of")
+}
@@ -0,0 +1,273 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// aggregator reduces the non-null values of a group to a single value.
+type aggregator func(values []float64) *float64
+
+func aggregateSum(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return &sum
+}
+
+func aggregateAvg(values []float64) *float64 {
+	sum := aggregateSum(values)
+	if sum == nil {
+		return nil
+	}
+	avg := *sum / float64(len(values))
+	return &avg
+}
+
+func aggregateMin(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	f := values[0]
+	for _, v := range values[1:] {
+		f = math.Min(f, v)
+	}
+	return &f
+}
+
+func aggregateMax(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	f := values[0]
+	for _, v := range values[1:] {
+		f = math.Max(f, v)
+	}
+	return &f
+}
+
+func aggregateCount(values []float64) *float64 {
+	f := float64(len(values))
+	return &f
+}
+
+// parseLabelList parses a comma separated list of label names. An empty string is an empty list.
+func parseLabelList(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var labels []string
+	for _, l := range strings.Split(s, ",") {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			return nil, fmt.Errorf("empty label name in %q", s)
+		}
+		labels = append(labels, l)
+	}
+	return labels, nil
+}
+
+// checkAggregateBy validates at parse time the label list of an aggregation function.
+func checkAggregateBy(_ *parse.Tree, f *parse.FuncNode) error {
+	s, ok := f.Args[1].(*parse.StringNode)
+	if !ok {
+		return nil
+	}
+	if _, err := parseLabelList(s.Text); err != nil {
+		return fmt.Errorf("parse: invalid label list for argument 1 of %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// checkTopK validates at parse time that k of topk and bottomk is a positive integer.
+func checkTopK(_ *parse.Tree, f *parse.FuncNode) error {
+	n, ok := f.Args[1].(*parse.ScalarNode)
+	if !ok {
+		return nil
+	}
+	if !n.IsUint || n.Uint64 == 0 {
+		return fmt.Errorf("parse: expected a positive integer for argument 1 of %s, got %v", f.Name, n.Text)
+	}
+	return nil
+}
+
+// groupLabels returns the subset of labels with the given names. Names that are not in labels are skipped.
+func groupLabels(labels data.Labels, names []string) data.Labels {
+	group := data.Labels{}
+	for _, name := range names {
+		if v, ok := labels[name]; ok {
+			group[name] = v
+		}
+	}
+	return group
+}
+
+// aggregateBy returns a function that groups the items of a NumberSet or SeriesSet by the labels
+// given as a comma separated list, and combines the items of each group with agg. Items of a
+// SeriesSet are combined point by point, using the points of all items at the same time.
+// The result has one item per group, which is labeled with the grouping labels only.
+func aggregateBy(name string, agg aggregator) func(e *State, varSet Results, labelList string) (Results, error) {
+	return func(e *State, varSet Results, labelList string) (Results, error) {
+		names, err := parseLabelList(labelList)
+		if err != nil {
+			return Results{}, fmt.Errorf("%s: %w", name, err)
+		}
+
+		var keys []string
+		groups := map[string][]Value{}
+		groupLabelSets := map[string]data.Labels{}
+		var valType parse.ReturnType
+		for _, val := range varSet.Values {
+			switch val.(type) {
+			case Number, Series:
+			case NoData:
+				continue
+			default:
+				return Results{}, fmt.Errorf("%s: can only aggregate type %v or %v, got type %v", name, parse.TypeNumberSet, parse.TypeSeriesSet, val.Type())
+			}
+			if len(keys) > 0 && val.Type() != valType {
+				return Results{}, fmt.Errorf("%s: can not aggregate items of type %v and %v together", name, valType, val.Type())
+			}
+			valType = val.Type()
+			labels := groupLabels(val.GetLabels(), names)
+			key := labels.String()
+			if _, ok := groups[key]; !ok {
+				keys = append(keys, key)
+				groupLabelSets[key] = labels
+			}
+			groups[key] = append(groups[key], val)
+		}
+
+		if len(keys) == 0 {
+			return Results{Values: Values{NoData{}.New()}}, nil
+		}
+
+		newRes := Results{}
+		for _, key := range keys {
+			labels := groupLabelSets[key]
+			if len(labels) == 0 {
+				labels = nil
+			}
+			switch valType {
+			case parse.TypeNumberSet:
+				values := make([]float64, 0, len(groups[key]))
+				for _, val := range groups[key] {
+					if f := val.(Number).GetFloat64Value(); f != nil {
+						values = append(values, *f)
+					}
+				}
+				n := NewNumber(e.RefID, labels)
+				n.SetValue(agg(values))
+				newRes.Values = append(newRes.Values, n)
+			case parse.TypeSeriesSet:
+				newRes.Values = append(newRes.Values, aggregateSeries(e.RefID, labels, groups[key], agg))
+			}
+		}
+		return newRes, nil
+	}
+}
+
+// aggregateSeries combines the series point by point with agg.
+func aggregateSeries(refID string, labels data.Labels, group []Value, agg aggregator) Series {
+	var times []time.Time
+	points := map[time.Time][]float64{}
+	for _, val := range group {
+		s := val.(Series)
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			t = t.UTC()
+			values, ok := points[t]
+			if !ok {
+				times = append(times, t)
+			}
+			if f != nil {
+				values = append(values, *f)
+			}
+			points[t] = values
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	newSeries := NewSeries(refID, labels, len(times))
+	for i, t := range times {
+		newSeries.SetPoint(i, t, agg(points[t]))
+	}
+	return newSeries
+}
+
+// rankValue returns the value used to rank an item of a NumberSet or SeriesSet in topk and bottomk.
+// Items of a SeriesSet are ranked by the average of their non-null and non-NaN values.
+// Items without such a value are returned as NaN, and are always ranked last.
+func rankValue(val Value) float64 {
+	switch v := val.(type) {
+	case Number:
+		if f := v.GetFloat64Value(); f != nil {
+			return *f
+		}
+	case Series:
+		var values []float64
+		for i := 0; i < v.Len(); i++ {
+			if f := v.GetValue(i); f != nil && !math.IsNaN(*f) {
+				values = append(values, *f)
+			}
+		}
+		if avg := aggregateAvg(values); avg != nil {
+			return *avg
+		}
+	}
+	return math.NaN()
+}
+
+// topK returns a function that returns the k items of a NumberSet or SeriesSet with the highest rank
+// if top is true, or the lowest otherwise. The items keep their labels.
+func topK(name string, top bool) func(e *State, varSet Results, kArg Results) (Results, error) {
+	return func(e *State, varSet Results, kArg Results) (Results, error) {
+		k, err := scalarArg(name, kArg)
+		if err != nil {
+			return Results{}, err
+		}
+		if k == nil || *k < 1 || *k != math.Trunc(*k) {
+			return Results{}, fmt.Errorf("%s: expected a positive integer number of items", name)
+		}
+
+		ranked := make([]Value, 0, len(varSet.Values))
+		for _, val := range varSet.Values {
+			switch val.(type) {
+			case Number, Series:
+				ranked = append(ranked, val)
+			case NoData:
+			default:
+				return Results{}, fmt.Errorf("%s: can only rank type %v or %v, got type %v", name, parse.TypeNumberSet, parse.TypeSeriesSet, val.Type())
+			}
+		}
+		if len(ranked) == 0 {
+			return Results{Values: Values{NoData{}.New()}}, nil
+		}
+
+		sort.SliceStable(ranked, func(i, j int) bool {
+			a, b := rankValue(ranked[i]), rankValue(ranked[j])
+			if math.IsNaN(b) {
+				return !math.IsNaN(a)
+			}
+			if top {
+				return a > b
+			}
+			return a < b
+		})
+		if int(*k) < len(ranked) {
+			ranked = ranked[:int(*k)]
+		}
+		return Results{Values: ranked}, nil
+	}
+}
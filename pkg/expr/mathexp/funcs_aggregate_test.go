@@ -0,0 +1,192 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+var podNumbers = Vars{
+	"A": Results{
+		[]Value{
+			makeNumber("", data.Labels{"namespace": "a", "pod": "1"}, float64Pointer(1)),
+			makeNumber("", data.Labels{"namespace": "a", "pod": "2"}, float64Pointer(3)),
+			makeNumber("", data.Labels{"namespace": "b", "pod": "3"}, float64Pointer(5)),
+			makeNumber("", data.Labels{"namespace": "b", "pod": "4"}, nil),
+		},
+	},
+}
+
+var podSeries = Vars{
+	"A": Results{
+		[]Value{
+			makeSeries("", data.Labels{"namespace": "a", "pod": "1"},
+				tp{time.Unix(5, 0), float64Pointer(1)},
+				tp{time.Unix(10, 0), float64Pointer(2)}),
+			makeSeries("", data.Labels{"namespace": "a", "pod": "2"},
+				tp{time.Unix(10, 0), float64Pointer(4)},
+				tp{time.Unix(15, 0), float64Pointer(6)}),
+			makeSeries("", data.Labels{"namespace": "b", "pod": "3"},
+				tp{time.Unix(5, 0), float64Pointer(10)}),
+		},
+	},
+}
+
+func TestAggregateFuncs(t *testing.T) {
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "sum_by on numbers",
+			expr:      `sum_by($A, "namespace")`,
+			vars:      podNumbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"namespace": "a"}, float64Pointer(4)),
+				makeNumber("", data.Labels{"namespace": "b"}, float64Pointer(5)),
+			}},
+		},
+		{
+			name:      "sum_by with no labels aggregates everything",
+			expr:      `sum_by($A, "")`,
+			vars:      podNumbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", nil, float64Pointer(9)),
+			}},
+		},
+		{
+			name:      "avg_by on numbers",
+			expr:      `avg_by($A, "namespace")`,
+			vars:      podNumbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"namespace": "a"}, float64Pointer(2)),
+				makeNumber("", data.Labels{"namespace": "b"}, float64Pointer(5)),
+			}},
+		},
+		{
+			name:      "count_by counts non-null values",
+			expr:      `count_by($A, "namespace")`,
+			vars:      podNumbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"namespace": "a"}, float64Pointer(2)),
+				makeNumber("", data.Labels{"namespace": "b"}, float64Pointer(1)),
+			}},
+		},
+		{
+			name:      "max_by with several labels",
+			expr:      `max_by($A, "namespace, pod")`,
+			vars:      podNumbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"namespace": "a", "pod": "1"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"namespace": "a", "pod": "2"}, float64Pointer(3)),
+				makeNumber("", data.Labels{"namespace": "b", "pod": "3"}, float64Pointer(5)),
+				makeNumber("", data.Labels{"namespace": "b", "pod": "4"}, nil),
+			}},
+		},
+		{
+			name:      "sum_by on series aggregates points at the same time",
+			expr:      `sum_by($A, "namespace")`,
+			vars:      podSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"namespace": "a"},
+					tp{time.Unix(5, 0).UTC(), float64Pointer(1)},
+					tp{time.Unix(10, 0).UTC(), float64Pointer(6)},
+					tp{time.Unix(15, 0).UTC(), float64Pointer(6)}),
+				makeSeries("", data.Labels{"namespace": "b"},
+					tp{time.Unix(5, 0).UTC(), float64Pointer(10)}),
+			}},
+		},
+		{
+			name:      "min_by on series",
+			expr:      `min_by($A, "")`,
+			vars:      podSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(5, 0).UTC(), float64Pointer(1)},
+					tp{time.Unix(10, 0).UTC(), float64Pointer(2)},
+					tp{time.Unix(15, 0).UTC(), float64Pointer(6)}),
+			}},
+		},
+		{
+			name:     "invalid label list fails at parse time",
+			expr:     `sum_by($A, "namespace,,pod")`,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "label list must be a string",
+			expr:     `sum_by($A, 1)`,
+			newErrIs: require.Error,
+		},
+		{
+			name:      "topk on numbers ranks nulls last",
+			expr:      `topk($A, 3)`,
+			vars:      podNumbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"namespace": "b", "pod": "3"}, float64Pointer(5)),
+				makeNumber("", data.Labels{"namespace": "a", "pod": "2"}, float64Pointer(3)),
+				makeNumber("", data.Labels{"namespace": "a", "pod": "1"}, float64Pointer(1)),
+			}},
+		},
+		{
+			name:      "bottomk on series ranks by average",
+			expr:      `bottomk($A, 1)`,
+			vars:      podSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"namespace": "a", "pod": "1"},
+					tp{time.Unix(5, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(2)}),
+			}},
+		},
+		{
+			name:     "topk with zero items fails at parse time",
+			expr:     `topk($A, 0)`,
+			newErrIs: require.Error,
+		},
+		{
+			name: "aggregation of no data is no data",
+			expr: `sum_by($A, "namespace")`,
+			vars: Vars{
+				"A": Results{[]Value{NoData{}.New()}},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   Results{[]Value{NoData{}.New()}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e == nil {
+				return
+			}
+			res, err := e.Execute("", tt.vars)
+			tt.execErrIs(t, err)
+			require.Equal(t, tt.results, res)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// checkShift validates at parse time that the second argument of shift is a valid duration.
+func checkShift(_ *parse.Tree, f *parse.FuncNode) error {
+	s, ok := f.Args[1].(*parse.StringNode)
+	if !ok {
+		return nil
+	}
+	if _, err := gtime.ParseDuration(s.Text); err != nil {
+		return fmt.Errorf("parse: invalid duration %s for argument 1 of %s: %w", s.Quoted, f.Name, err)
+	}
+	return nil
+}
+
+// checkMovingAvg validates at parse time that the window of moving_avg is a positive integer.
+func checkMovingAvg(_ *parse.Tree, f *parse.FuncNode) error {
+	n, ok := f.Args[1].(*parse.ScalarNode)
+	if !ok {
+		return nil
+	}
+	if !n.IsUint || n.Uint64 == 0 {
+		return fmt.Errorf("parse: expected a positive integer for argument 1 of %s, got %v", f.Name, n.Text)
+	}
+	return nil
+}
+
+// checkClamp validates at parse time that the lower bound of clamp is not greater than the upper bound.
+func checkClamp(_ *parse.Tree, f *parse.FuncNode) error {
+	minNode, ok := f.Args[1].(*parse.ScalarNode)
+	if !ok {
+		return nil
+	}
+	maxNode, ok := f.Args[2].(*parse.ScalarNode)
+	if !ok {
+		return nil
+	}
+	if minNode.Float64 > maxNode.Float64 {
+		return fmt.Errorf("parse: the minimum %v of %s is greater than the maximum %v", minNode.Text, f.Name, maxNode.Text)
+	}
+	return nil
+}
+
+// scalarArg returns the value of a scalar function argument.
+func scalarArg(name string, r Results) (*float64, error) {
+	if len(r.Values) != 1 {
+		return nil, fmt.Errorf("%s: expected a single scalar argument, got %v values", name, len(r.Values))
+	}
+	s, ok := r.Values[0].(Scalar)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected a scalar argument, got %v", name, r.Values[0].Type())
+	}
+	return s.GetFloat64Value(), nil
+}
+
+// perSeries passes each series in varSet to seriesF. NoData values are passed through,
+// and any other type of value results in an error because the function needs the time of the points.
+func perSeries(name string, varSet Results, seriesF func(s Series) Series) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch v := res.(type) {
+		case Series:
+			newRes.Values = append(newRes.Values, seriesF(v))
+		case NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("%s: can only be applied to type %v, got type %v", name, parse.TypeSeriesSet, res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// shift moves each point of each series in the SeriesSet forward in time by the given duration,
+// so that shift($A, "1w") can be compared with $A to get the change since last week.
+func shift(e *State, varSet Results, duration string) (Results, error) {
+	d, err := gtime.ParseDuration(duration)
+	if err != nil {
+		return Results{}, fmt.Errorf("shift: invalid duration %q: %w", duration, err)
+	}
+	return perSeries("shift", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(d), f)
+		}
+		return newSeries
+	})
+}
+
+// movingAvg returns, for each point of each series in the SeriesSet, the average of the last
+// points values up to and including the point. Null and NaN values are ignored. The value is null
+// if there are no values to average.
+func movingAvg(e *State, varSet Results, points Results) (Results, error) {
+	p, err := scalarArg("moving_avg", points)
+	if err != nil {
+		return Results{}, err
+	}
+	if p == nil || *p < 1 || *p != math.Trunc(*p) {
+		return Results{}, fmt.Errorf("moving_avg: expected a positive integer number of points")
+	}
+	window := int(*p)
+	return perSeries("moving_avg", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			var sum float64
+			var count int
+			for j := i; j >= 0 && j > i-window; j-- {
+				f := s.GetValue(j)
+				if f == nil || math.IsNaN(*f) {
+					continue
+				}
+				sum += *f
+				count++
+			}
+			var avg *float64
+			if count > 0 {
+				a := sum / float64(count)
+				avg = &a
+			}
+			newSeries.SetPoint(i, s.GetTime(i), avg)
+		}
+		return newSeries
+	})
+}
+
+// perPointPair calls pairF with each point of each series in the SeriesSet and the point before it.
+// The resulting series does not have a value for the first point. The value is null if either point is null.
+func perPointPair(e *State, name string, varSet Results, pairF func(prev, cur float64, seconds float64) float64) (Results, error) {
+	return perSeries(name, varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), 0)
+		for i := 1; i < s.Len(); i++ {
+			prevT, prevF := s.GetPoint(i - 1)
+			t, f := s.GetPoint(i)
+			if prevF == nil || f == nil {
+				newSeries.AppendPoint(t, nil)
+				continue
+			}
+			nF := pairF(*prevF, *f, t.Sub(prevT).Seconds())
+			newSeries.AppendPoint(t, &nF)
+		}
+		return newSeries
+	})
+}
+
+// rate returns the per-second rate of increase between consecutive points of a counter, for
+// each series in the SeriesSet. A decrease of the value is considered a counter reset.
+func rate(e *State, varSet Results) (Results, error) {
+	return perPointPair(e, "rate", varSet, func(prev, cur float64, seconds float64) float64 {
+		if seconds <= 0 {
+			return math.NaN()
+		}
+		if cur < prev {
+			return cur / seconds
+		}
+		return (cur - prev) / seconds
+	})
+}
+
+// derivative returns the per-second change between consecutive points, for each series in the SeriesSet.
+func derivative(e *State, varSet Results) (Results, error) {
+	return perPointPair(e, "derivative", varSet, func(prev, cur float64, seconds float64) float64 {
+		if seconds <= 0 {
+			return math.NaN()
+		}
+		return (cur - prev) / seconds
+	})
+}
+
+// cumsum returns the running total of each series in the SeriesSet. Null values stay null
+// and do not change the total.
+func cumsum(e *State, varSet Results) (Results, error) {
+	return perSeries("cumsum", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		var sum float64
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f == nil {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			sum += *f
+			nF := sum
+			newSeries.SetPoint(i, t, &nF)
+		}
+		return newSeries
+	})
+}
+
+// timestamp returns, for each point of each series in the SeriesSet, the time of the point
+// as the number of seconds since the Unix epoch.
+func timestamp(e *State, varSet Results) (Results, error) {
+	return perSeries("timestamp", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t := s.GetTime(i)
+			nF := float64(t.UnixNano()) / float64(time.Second)
+			newSeries.SetPoint(i, t, &nF)
+		}
+		return newSeries
+	})
+}
+
+// clamp limits each value of each result in NumberSet, SeriesSet, or Scalar to the range [min, max].
+func clamp(e *State, varSet Results, minArg, maxArg Results) (Results, error) {
+	lower, err := scalarArg("clamp", minArg)
+	if err != nil {
+		return Results{}, err
+	}
+	upper, err := scalarArg("clamp", maxArg)
+	if err != nil {
+		return Results{}, err
+	}
+	if lower == nil || upper == nil {
+		return Results{}, fmt.Errorf("clamp: the minimum and maximum must not be null")
+	}
+	if *lower > *upper {
+		return Results{}, fmt.Errorf("clamp: the minimum %v is greater than the maximum %v", *lower, *upper)
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(f float64) float64 {
+			return math.Max(*lower, math.Min(*upper, f))
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
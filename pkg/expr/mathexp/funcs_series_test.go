@@ -0,0 +1,266 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var counterSeriesSet = Vars{
+	"A": Results{
+		[]Value{
+			makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(4)},
+				tp{time.Unix(10, 0), float64Pointer(10)},
+				tp{time.Unix(20, 0), float64Pointer(2)},
+				tp{time.Unix(30, 0), float64Pointer(8)}),
+		},
+	},
+}
+
+func TestSeriesFuncs(t *testing.T) {
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "shift moves points forward in time",
+			expr:      `shift($A, "1w")`,
+			vars:      counterSeriesSet,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(0, 0).Add(7 * 24 * time.Hour), float64Pointer(4)},
+					tp{time.Unix(10, 0).Add(7 * 24 * time.Hour), float64Pointer(10)},
+					tp{time.Unix(20, 0).Add(7 * 24 * time.Hour), float64Pointer(2)},
+					tp{time.Unix(30, 0).Add(7 * 24 * time.Hour), float64Pointer(8)}),
+			}},
+		},
+		{
+			name:     "shift with invalid duration fails at parse time",
+			expr:     `shift($A, "a week")`,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "shift on a number fails at parse time",
+			expr:     `shift(1, "1w")`,
+			newErrIs: require.Error,
+		},
+		{
+			name: "shift on a number set fails at execution time",
+			expr: `shift($A, "1w")`,
+			vars: Vars{
+				"A": Results{[]Value{makeNumber("", nil, float64Pointer(1))}},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:      "moving_avg averages the last points",
+			expr:      `moving_avg($A, 2)`,
+			vars:      counterSeriesSet,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(4)},
+					tp{time.Unix(10, 0), float64Pointer(7)},
+					tp{time.Unix(20, 0), float64Pointer(6)},
+					tp{time.Unix(30, 0), float64Pointer(5)}),
+			}},
+		},
+		{
+			name:     "moving_avg with zero points fails at parse time",
+			expr:     `moving_avg($A, 0)`,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "moving_avg with fractional points fails at parse time",
+			expr:     `moving_avg($A, 1.5)`,
+			newErrIs: require.Error,
+		},
+		{
+			name:      "rate handles counter resets",
+			expr:      `rate($A)`,
+			vars:      counterSeriesSet,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(0.6)},
+					tp{time.Unix(20, 0), float64Pointer(0.2)},
+					tp{time.Unix(30, 0), float64Pointer(0.6)}),
+			}},
+		},
+		{
+			name:      "derivative",
+			expr:      `derivative($A)`,
+			vars:      counterSeriesSet,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(0.6)},
+					tp{time.Unix(20, 0), float64Pointer(-0.8)},
+					tp{time.Unix(30, 0), float64Pointer(0.6)}),
+			}},
+		},
+		{
+			name: "derivative with null value",
+			expr: `derivative($A)`,
+			vars: Vars{
+				"A": Results{[]Value{
+					makeSeries("", nil,
+						tp{time.Unix(0, 0), float64Pointer(1)},
+						tp{time.Unix(10, 0), nil},
+						tp{time.Unix(20, 0), float64Pointer(3)}),
+				}},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), nil},
+					tp{time.Unix(20, 0), nil}),
+			}},
+		},
+		{
+			name: "cumsum skips null values",
+			expr: `cumsum($A)`,
+			vars: Vars{
+				"A": Results{[]Value{
+					makeSeries("", nil,
+						tp{time.Unix(0, 0), float64Pointer(1)},
+						tp{time.Unix(10, 0), nil},
+						tp{time.Unix(20, 0), float64Pointer(3)}),
+				}},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), nil},
+					tp{time.Unix(20, 0), float64Pointer(4)}),
+			}},
+		},
+		{
+			name:      "timestamp",
+			expr:      `timestamp($A)`,
+			vars:      counterSeriesSet,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(0)},
+					tp{time.Unix(10, 0), float64Pointer(10)},
+					tp{time.Unix(20, 0), float64Pointer(20)},
+					tp{time.Unix(30, 0), float64Pointer(30)}),
+			}},
+		},
+		{
+			name:      "clamp on series",
+			expr:      `clamp($A, 3, 9)`,
+			vars:      counterSeriesSet,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(4)},
+					tp{time.Unix(10, 0), float64Pointer(9)},
+					tp{time.Unix(20, 0), float64Pointer(3)},
+					tp{time.Unix(30, 0), float64Pointer(8)}),
+			}},
+		},
+		{
+			name: "clamp on number with negative bound",
+			expr: `clamp($A, -1, 1)`,
+			vars: Vars{
+				"A": Results{[]Value{makeNumber("", nil, float64Pointer(-7))}},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(-1))}},
+		},
+		{
+			name:     "clamp with minimum greater than maximum fails at parse time",
+			expr:     `clamp($A, 9, 3)`,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "clamp with a series bound fails at parse time",
+			expr:     `clamp($A, $B, 3)`,
+			newErrIs: require.Error,
+		},
+		{
+			name: "series functions pass no data through",
+			expr: `rate($A)`,
+			vars: Vars{
+				"A": Results{[]Value{NoData{}.New()}},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   Results{[]Value{NoData{}.New()}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e == nil {
+				return
+			}
+			res, err := e.Execute("", tt.vars)
+			tt.execErrIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Len(t, res.Values, len(tt.results.Values))
+			for i, expected := range tt.results.Values {
+				actual := res.Values[i]
+				require.Equal(t, expected.Type(), actual.Type())
+				expectedSeries, ok := expected.(Series)
+				if !ok {
+					require.Equal(t, expected, actual)
+					continue
+				}
+				actualSeries := actual.(Series)
+				require.Equal(t, expectedSeries.Len(), actualSeries.Len())
+				for p := 0; p < expectedSeries.Len(); p++ {
+					expT, expF := expectedSeries.GetPoint(p)
+					actT, actF := actualSeries.GetPoint(p)
+					require.Truef(t, expT.Equal(actT), "point %d: expected time %v, got %v", p, expT, actT)
+					if expF == nil {
+						require.Nil(t, actF)
+						continue
+					}
+					require.NotNil(t, actF)
+					require.InDelta(t, *expF, *actF, 1e-9)
+				}
+			}
+		})
+	}
+}
+
+func TestMovingAvgIgnoresNaN(t *testing.T) {
+	e, err := New(`moving_avg($A, 3)`)
+	require.NoError(t, err)
+	res, err := e.Execute("", Vars{
+		"A": Results{[]Value{
+			makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(math.NaN())},
+				tp{time.Unix(10, 0), float64Pointer(2)}),
+		}},
+	})
+	require.NoError(t, err)
+	s := res.Values[0].(Series)
+	require.Nil(t, s.GetValue(0))
+	require.Equal(t, float64Pointer(2), s.GetValue(1))
+}
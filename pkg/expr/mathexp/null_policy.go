@@ -0,0 +1,106 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// NullPolicy is how the operators of an expression handle operands that are null or NaN.
+// It does not change functions, so functions such as is_null and is_nan still see the missing values.
+type NullPolicy string
+
+const (
+	// NullPolicyPropagate returns null if an operand is null, and NaN if an operand is NaN, except
+	// for the short circuit of the logical operators. It is the default.
+	NullPolicyPropagate NullPolicy = "propagate"
+	// NullPolicyTreatAsZero replaces null and NaN operands with 0.
+	NullPolicyTreatAsZero NullPolicy = "treatAsZero"
+	// NullPolicyDropPoint drops the points of a series, and the numbers, that have a null or NaN operand.
+	// Scalars can not be dropped, so they are null instead.
+	NullPolicyDropPoint NullPolicy = "dropPoint"
+	// NullPolicyTreatAsFalse makes null and NaN operands false: they are 0 in the logical operators
+	// (!, && and ||), and any other operation with them results in 0. This ensures that a condition
+	// is not met because of missing data.
+	NullPolicyTreatAsFalse NullPolicy = "treatAsFalse"
+)
+
+var supportedNullPolicies = []NullPolicy{NullPolicyPropagate, NullPolicyTreatAsZero, NullPolicyDropPoint, NullPolicyTreatAsFalse}
+
+// ParseNullPolicy returns the NullPolicy with the given name. An empty name is NullPolicyPropagate.
+func ParseNullPolicy(s string) (NullPolicy, error) {
+	if s == "" {
+		return NullPolicyPropagate, nil
+	}
+	for _, p := range supportedNullPolicies {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	names := make([]string, len(supportedNullPolicies))
+	for i, p := range supportedNullPolicies {
+		names[i] = string(p)
+	}
+	return "", fmt.Errorf("expected null policy to be one of %s, got %s", strings.Join(names, ", "), s)
+}
+
+func isMissing(f *float64) bool {
+	return f == nil || math.IsNaN(*f)
+}
+
+func isLogicalOp(op string) bool {
+	return op == "!" || op == "&&" || op == "||"
+}
+
+// nullableUnaryOp performs a unary operation on a value that may be null or NaN, according to the null policy.
+// It returns false if the point must be dropped.
+func (e *State) nullableUnaryOp(op string, a *float64) (*float64, bool, error) {
+	if isMissing(a) {
+		switch e.NullPolicy {
+		case NullPolicyDropPoint:
+			return nil, false, nil
+		case NullPolicyTreatAsZero, NullPolicyTreatAsFalse:
+			zero := float64(0)
+			a = &zero
+		default:
+			if a == nil {
+				return nil, true, nil
+			}
+		}
+	}
+	r, err := unaryOp(op, *a)
+	return &r, true, err
+}
+
+// nullableBinaryOp performs a binary operation on values that may be null or NaN, according to the null policy.
+// It returns false if the point must be dropped.
+func (e *State) nullableBinaryOp(op string, a, b *float64) (*float64, bool, error) {
+	if isMissing(a) || isMissing(b) {
+		switch e.NullPolicy {
+		case NullPolicyDropPoint:
+			return nil, false, nil
+		case NullPolicyTreatAsFalse:
+			if !isLogicalOp(op) {
+				zero := float64(0)
+				return &zero, true, nil
+			}
+			a, b = zeroIfMissing(a), zeroIfMissing(b)
+		case NullPolicyTreatAsZero:
+			a, b = zeroIfMissing(a), zeroIfMissing(b)
+		default:
+			if a == nil || b == nil {
+				return nil, true, nil
+			}
+		}
+	}
+	r, err := binaryOp(op, *a, *b)
+	return &r, true, err
+}
+
+func zeroIfMissing(f *float64) *float64 {
+	if isMissing(f) {
+		zero := float64(0)
+		return &zero
+	}
+	return f
+}
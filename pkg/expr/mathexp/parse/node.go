@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -298,6 +299,52 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	// Matching is how the items of the two arguments are matched. It is nil
+	// if the operation does not have on/ignoring modifiers.
+	Matching *VectorMatching
+}
+
+// Cardinality is the cardinality of the matching between the items of the two arguments of a binary operation.
+type Cardinality int
+
+const (
+	// CardOneToOne matches each item on one side with at most one item on the other side.
+	CardOneToOne Cardinality = iota
+	// CardManyToOne matches many items on the left side with one item on the right side (group_left).
+	CardManyToOne
+	// CardOneToMany matches one item on the left side with many items on the right side (group_right).
+	CardOneToMany
+)
+
+// VectorMatching describes how the items of the two arguments of a binary operation are matched by their labels.
+type VectorMatching struct {
+	// Card is the cardinality of the matching.
+	Card Cardinality
+	// On is true if the items are matched on MatchingLabels only, and false if MatchingLabels are ignored.
+	On bool
+	// MatchingLabels are the labels used to match the items, or ignored when matching the items.
+	MatchingLabels []string
+	// Include are the labels copied from the "one" side to the result of a many-to-one or one-to-many matching.
+	Include []string
+}
+
+// String returns the modifiers of the binary operation the VectorMatching was parsed from.
+func (m *VectorMatching) String() string {
+	s := "ignoring"
+	if m.On {
+		s = "on"
+	}
+	s += "(" + strings.Join(m.MatchingLabels, ", ") + ")"
+	switch m.Card {
+	case CardManyToOne:
+		s += " group_left"
+	case CardOneToMany:
+		s += " group_right"
+	}
+	if len(m.Include) > 0 {
+		s += "(" + strings.Join(m.Include, ", ") + ")"
+	}
+	return s
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -306,16 +353,42 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.Matching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s(%s, %s)", b.Operator.val, b.Matching, b.Args[0], b.Args[1])
+	}
 	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
 }
 
 // Check performs parse time checking on the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) Check(t *Tree) error {
+	for _, arg := range b.Args {
+		if err := arg.Check(t); err != nil {
+			return err
+		}
+	}
+	if b.Matching == nil {
+		return nil
+	}
+	for _, arg := range b.Args {
+		if rt := arg.Return(); rt != TypeNumberSet && rt != TypeSeriesSet {
+			return fmt.Errorf("parse: %s can only be used between %v or %v, got %v in %s", b.Matching, TypeNumberSet, TypeSeriesSet, rt, b)
+		}
+	}
+	for _, l := range b.Matching.Include {
+		for _, m := range b.Matching.MatchingLabels {
+			if b.Matching.On && l == m {
+				return fmt.Errorf("parse: label %q must not be in both on and group modifiers in %s", l, b)
+			}
+		}
+	}
 	return nil
 }
 
@@ -403,6 +476,8 @@ const (
 	TypeVariantSet
 	// TypeNoData is a no data response without a known data type.
 	TypeNoData
+	// TypeTableData is a tabular data response that is not a collection of numbers or time series.
+	TypeTableData
 )
 
 // String returns a string representation of the ReturnType.
@@ -420,6 +495,8 @@ func (f ReturnType) String() string {
 		return "variant"
 	case TypeNoData:
 		return "noData"
+	case TypeTableData:
+		return "tableData"
 	default:
 		return "unknown"
 	}
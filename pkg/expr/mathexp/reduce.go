@@ -3,13 +3,26 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
+// ReducerFunc reduces the values of a field to a single value.
 type ReducerFunc = func(fv *Float64Field) *float64
 
+// SeriesReducerFunc reduces a series to a single value. Unlike ReducerFunc it has access to the time of each point.
+type SeriesReducerFunc = func(s Series) *float64
+
+// valueReducer turns a ReducerFunc into a SeriesReducerFunc that reduces the values of the series.
+func valueReducer(f ReducerFunc) SeriesReducerFunc {
+	return func(s Series) *float64 {
+		fv := Float64Field(*s.Frame.Fields[seriesTypeValIdx])
+		return f(&fv)
+	}
+}
+
 func Sum(fv *Float64Field) *float64 {
 	var sum float64
 	for i := 0; i < fv.Len(); i++ {
@@ -81,20 +94,165 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
-func GetReduceFunc(rFunc string) (ReducerFunc, error) {
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// sortedValues returns the values of the field in ascending order. It returns false
+// if the field contains a null or NaN value.
+func sortedValues(fv *Float64Field) ([]float64, bool) {
+	values := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		values = append(values, *v)
+	}
+	sort.Float64s(values)
+	return values, true
+}
+
+func Median(fv *Float64Field) *float64 {
+	return Percentile(50)(fv)
+}
+
+// Percentile returns a ReducerFunc that calculates the p-th percentile of the values,
+// using linear interpolation between the closest ranks.
+func Percentile(p float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		f := math.NaN()
+		values, ok := sortedValues(fv)
+		if !ok || len(values) == 0 {
+			return &f
+		}
+		rank := p / 100 * float64(len(values)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		f = values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+		return &f
+	}
+}
+
+// Variance calculates the population variance of the values.
+func Variance(fv *Float64Field) *float64 {
+	f := math.NaN()
+	if fv.Len() == 0 {
+		return &f
+	}
+	mean := Avg(fv)
+	if math.IsNaN(*mean) {
+		return &f
+	}
+	var sum float64
+	for i := 0; i < fv.Len(); i++ {
+		d := *fv.GetValue(i) - *mean
+		sum += d * d
+	}
+	f = sum / float64(fv.Len())
+	return &f
+}
+
+// StdDev calculates the population standard deviation of the values.
+func StdDev(fv *Float64Field) *float64 {
+	f := math.Sqrt(*Variance(fv))
+	return &f
+}
+
+// Range calculates the difference between the maximum and the minimum values.
+func Range(fv *Float64Field) *float64 {
+	f := *Max(fv) - *Min(fv)
+	return &f
+}
+
+// Increase calculates the increase of a monotonically increasing counter between the first and the last value.
+// A decrease of the value is considered a counter reset, and the value after the reset is counted as an increase.
+func Increase(fv *Float64Field) *float64 {
+	f := math.NaN()
+	if fv.Len() < 2 {
+		return &f
+	}
+	var increase float64
+	var prev float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return &f
+		}
+		if i > 0 {
+			if *v >= prev {
+				increase += *v - prev
+			} else {
+				increase += *v
+			}
+		}
+		prev = *v
+	}
+	return &increase
+}
+
+// Rate calculates the per-second rate of increase of a monotonically increasing counter
+// between the first and the last point of the series. See Increase for how counter resets are handled.
+func Rate(s Series) *float64 {
+	f := math.NaN()
+	if s.Len() < 2 {
+		return &f
+	}
+	seconds := s.GetTime(s.Len() - 1).Sub(s.GetTime(0)).Seconds()
+	if seconds <= 0 {
+		return &f
+	}
+	fv := Float64Field(*s.Frame.Fields[seriesTypeValIdx])
+	f = *Increase(&fv) / seconds
+	return &f
+}
+
+// ReduceParams holds the optional parameters of reduction functions.
+type ReduceParams struct {
+	// Percentile is the percentile, in the range (0, 100], calculated by the "percentile" reducer.
+	Percentile float64
+}
+
+// GetReduceFunc returns the reduction function for the given name. It returns an error
+// if the function is not known or if the parameters it requires are not valid.
+func GetReduceFunc(rFunc string, params ReduceParams) (SeriesReducerFunc, error) {
 	switch strings.ToLower(rFunc) {
 	case "sum":
-		return Sum, nil
+		return valueReducer(Sum), nil
 	case "mean":
-		return Avg, nil
+		return valueReducer(Avg), nil
 	case "min":
-		return Min, nil
+		return valueReducer(Min), nil
 	case "max":
-		return Max, nil
+		return valueReducer(Max), nil
 	case "count":
-		return Count, nil
+		return valueReducer(Count), nil
 	case "last":
-		return Last, nil
+		return valueReducer(Last), nil
+	case "first":
+		return valueReducer(First), nil
+	case "median":
+		return valueReducer(Median), nil
+	case "percentile":
+		if params.Percentile <= 0 || params.Percentile > 100 || math.IsNaN(params.Percentile) {
+			return nil, fmt.Errorf("reduction percentile requires a percentile in the range (0, 100], got %v", params.Percentile)
+		}
+		return valueReducer(Percentile(params.Percentile)), nil
+	case "stddev":
+		return valueReducer(StdDev), nil
+	case "variance":
+		return valueReducer(Variance), nil
+	case "range":
+		return valueReducer(Range), nil
+	case "increase":
+		return valueReducer(Increase), nil
+	case "rate":
+		return Rate, nil
 	default:
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
@@ -102,13 +260,18 @@ func GetReduceFunc(rFunc string) (ReducerFunc, error) {
 
 // GetSupportedReduceFuncs returns collection of supported function names
 func GetSupportedReduceFuncs() []string {
-	return []string{"sum", "mean", "min", "max", "count", "last"}
+	return []string{"sum", "mean", "min", "max", "count", "last", "first", "median", "percentile", "stddev", "variance", "range", "increase", "rate"}
 }
 
 // Reduce turns the Series into a Number based on the given reduction function
 // if ReduceMapper is defined it applies it to the provided series and performs reduction of the resulting series.
 // Otherwise, the reduction operation is done against the original series.
 func (s Series) Reduce(refID, rFunc string, mapper ReduceMapper) (Number, error) {
+	return s.ReduceWithParams(refID, rFunc, ReduceParams{}, mapper)
+}
+
+// ReduceWithParams is like Reduce but accepts the parameters required by some reduction functions, such as percentile.
+func (s Series) ReduceWithParams(refID, rFunc string, params ReduceParams, mapper ReduceMapper) (Number, error) {
 	var l data.Labels
 	if s.GetLabels() != nil {
 		l = s.GetLabels().Copy()
@@ -119,13 +282,11 @@ func (s Series) Reduce(refID, rFunc string, mapper ReduceMapper) (Number, error)
 	if mapper != nil {
 		series = mapSeries(s, mapper)
 	}
-	fVec := series.Frame.Fields[seriesTypeValIdx]
-	floatField := Float64Field(*fVec)
-	reduceFunc, err := GetReduceFunc(rFunc)
+	reduceFunc, err := GetReduceFunc(rFunc, params)
 	if err != nil {
 		return number, fmt.Errorf("invalid expression '%s': %w", refID, err)
 	}
-	f = reduceFunc(&floatField)
+	f = reduceFunc(series)
 	if f != nil && mapper != nil {
 		f = mapper.MapOutput(f)
 	}
@@ -278,6 +278,189 @@ var seriesNonNumbers = Vars{
 	},
 }
 
+var counterSeries = Vars{
+	"A": Results{
+		[]Value{
+			makeSeries("temp", nil,
+				tp{time.Unix(0, 0), float64Pointer(4)},
+				tp{time.Unix(10, 0), float64Pointer(10)},
+				tp{time.Unix(20, 0), float64Pointer(2)},
+				tp{time.Unix(30, 0), float64Pointer(8)}),
+		},
+	},
+}
+
+func TestSeriesReduceStatistical(t *testing.T) {
+	var tests = []struct {
+		name    string
+		red     string
+		params  ReduceParams
+		mapper  ReduceMapper
+		vars    Vars
+		errIs   require.ErrorAssertionFunc
+		results Results
+	}{
+		{
+			name:    "first series",
+			red:     "first",
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(4))}},
+		},
+		{
+			name:    "first empty series",
+			red:     "first",
+			vars:    seriesEmpty,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:    "median series with even number of points",
+			red:     "median",
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(6))}},
+		},
+		{
+			name:    "median series with a nil value",
+			red:     "median",
+			vars:    seriesWithNil,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:    "percentile series",
+			red:     "percentile",
+			params:  ReduceParams{Percentile: 75},
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(8.5))}},
+		},
+		{
+			name:    "percentile 100 is max",
+			red:     "percentile",
+			params:  ReduceParams{Percentile: 100},
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(10))}},
+		},
+		{
+			name:  "percentile without parameter will error",
+			red:   "percentile",
+			vars:  counterSeries,
+			errIs: require.Error,
+		},
+		{
+			name:   "percentile out of range will error",
+			red:    "percentile",
+			vars:   counterSeries,
+			params: ReduceParams{Percentile: 101},
+			errIs:  require.Error,
+		},
+		{
+			name:    "variance series",
+			red:     "variance",
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(10))}},
+		},
+		{
+			name:    "stddev series",
+			red:     "stddev",
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(math.Sqrt(10)))}},
+		},
+		{
+			name:    "stddev series with a nil value",
+			red:     "stddev",
+			vars:    seriesWithNil,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:    "range series",
+			red:     "range",
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(8))}},
+		},
+		{
+			name:    "increase handles counter resets",
+			red:     "increase",
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(14))}},
+		},
+		{
+			name:    "increase of a single point",
+			red:     "increase",
+			vars:    Vars{"A": Results{[]Value{makeSeries("temp", nil, tp{time.Unix(0, 0), float64Pointer(4)})}}},
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:    "rate is per second",
+			red:     "rate",
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(14.0/30))}},
+		},
+		{
+			name:    "rate with a nil value",
+			red:     "rate",
+			vars:    seriesWithNil,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:    "dropNN: median series with a nil value",
+			red:     "median",
+			mapper:  DropNonNumber{},
+			vars:    seriesWithNil,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(2))}},
+		},
+		{
+			name:    "dropNN: rate series that becomes empty after filtering non-number",
+			red:     "rate",
+			mapper:  DropNonNumber{},
+			vars:    seriesNonNumbers,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, nil)}},
+		},
+		{
+			name:    "replaceNN: stddev series with a nil value",
+			red:     "stddev",
+			mapper:  ReplaceNonNumberWithValue{Value: 4},
+			vars:    seriesWithNil,
+			errIs:   require.NoError,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(1))}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Results{}
+			for _, series := range tt.vars["A"].Values {
+				ns, err := series.Value().(*Series).ReduceWithParams("", tt.red, tt.params, tt.mapper)
+				tt.errIs(t, err)
+				if err != nil {
+					return
+				}
+				results.Values = append(results.Values, ns)
+			}
+			opt := cmp.Comparer(func(x, y float64) bool {
+				return (math.IsNaN(x) && math.IsNaN(y)) || math.Abs(x-y) < 1e-9
+			})
+			options := append([]cmp.Option{opt}, data.FrameTestCompareOptions()...)
+			if diff := cmp.Diff(tt.results, results, options...); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestSeriesReduceDropNN(t *testing.T) {
 	var tests = []struct {
 		name        string
@@ -214,3 +214,44 @@ func (s NoData) AsDataFrame() *data.Frame { return s.Frame }
 func (s NoData) New() NoData {
 	return NoData{data.NewFrame("no data")}
 }
+
+// TableData is a tabular data response, such as the table of a SQL data source,
+// that is not a collection of numbers or time series.
+type TableData struct{ Frame *data.Frame }
+
+// Type returns the Value type and allows it to fulfill the Value interface.
+func (s TableData) Type() parse.ReturnType { return parse.TypeTableData }
+
+// Value returns the actual value allows it to fulfill the Value interface.
+func (s TableData) Value() interface{} { return s }
+
+func (s TableData) GetLabels() data.Labels { return nil }
+
+func (s TableData) SetLabels(ls data.Labels) {}
+
+func (s TableData) GetMeta() interface{} {
+	if s.Frame.Meta == nil {
+		return nil
+	}
+	return s.Frame.Meta.Custom
+}
+
+func (s TableData) SetMeta(v interface{}) {
+	m := s.Frame.Meta
+	if m == nil {
+		m = &data.FrameMeta{}
+		s.Frame.SetMeta(m)
+	}
+	m.Custom = v
+}
+
+func (s TableData) AddNotice(notice data.Notice) {
+	m := s.Frame.Meta
+	if m == nil {
+		m = &data.FrameMeta{}
+		s.Frame.SetMeta(m)
+	}
+	m.Notices = append(m.Notices, notice)
+}
+
+func (s TableData) AsDataFrame() *data.Frame { return s.Frame }
@@ -121,6 +121,12 @@ func buildCMDNode(dp *simple.DirectedGraph, rn *rawNode) (*CMDNode, error) {
 		node.Command, err = classic.UnmarshalConditionsCmd(rn.Query, rn.RefID)
 	case TypeThreshold:
 		node.Command, err = UnmarshalThresholdCommand(rn)
+	case TypeSQL:
+		node.Command, err = UnmarshalSQLCommand(rn)
+	case TypeAnomaly:
+		node.Command, err = UnmarshalAnomalyCommand(rn)
+	case TypeRuleState:
+		node.Command, err = UnmarshalRuleStateCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in expression '%v' not implemented", commandType, rn.RefID)
 	}
@@ -148,6 +154,10 @@ type DSNode struct {
 	intervalMS int64
 	maxDP      int64
 	request    Request
+
+	// isInputToSQLExpr is set when the results are the input of a SQL expression,
+	// in which case results that are not time series are returned as table data without conversion.
+	isInputToSQLExpr bool
 }
 
 // NodeType returns the data pipeline node type.
@@ -240,6 +250,11 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 			return mathexp.Results{}, QueryError{RefID: refID, Err: qr.Error}
 		}
 
+		// time series are converted as for other expressions, and the SQL expression reads them as a long table
+		if dn.isInputToSQLExpr && !hasTimeSeriesFrame(qr.Frames) {
+			return framesToTableData(qr.Frames), nil
+		}
+
 		dataSource := dn.datasource.Type
 		if isAllFrameVectors(dataSource, qr.Frames) { // Prometheus Specific Handling
 			vals, err = framesToNumbers(qr.Frames)
@@ -296,6 +311,31 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 	}, nil
 }
 
+// framesToTableData returns the frames as they are, so that they can be used as tables.
+func framesToTableData(frames data.Frames) mathexp.Results {
+	vals := make([]mathexp.Value, 0, len(frames))
+	for _, frame := range frames {
+		if len(frame.Fields) == 0 {
+			continue
+		}
+		vals = append(vals, mathexp.TableData{Frame: frame})
+	}
+	if len(vals) == 0 {
+		return mathexp.Results{Values: mathexp.Values{mathexp.NoData{}.New()}}
+	}
+	return mathexp.Results{Values: vals}
+}
+
+// hasTimeSeriesFrame reports whether any of the frames is a time series.
+func hasTimeSeriesFrame(frames data.Frames) bool {
+	for _, frame := range frames {
+		if frame.TimeSeriesSchema().Type != data.TimeSeriesTypeNot {
+			return true
+		}
+	}
+	return false
+}
+
 func isAllFrameVectors(datasourceType string, frames data.Frames) bool {
 	if datasourceType != "prometheus" {
 		return false
@@ -386,8 +426,11 @@ func extractNumberSet(frame *data.Frame) ([]mathexp.Number, error) {
 				labels = make(data.Labels)
 			}
 			key := stringFieldNames[i] // TODO check for duplicate string column names
-			val, _ := frame.ConcreteAt(stringFieldIdxs[i], rowIdx)
-			labels[key] = val.(string) // TODO check assertion / return error
+			val, ok := frame.ConcreteAt(stringFieldIdxs[i], rowIdx)
+			if !ok {
+				continue // null labels are dropped
+			}
+			labels[key] = val.(string)
 		}
 
 		n := mathexp.NewNumber(frame.Fields[numericField].Name, labels)
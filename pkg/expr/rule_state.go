@@ -0,0 +1,163 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+const (
+	// RuleStateOutputState returns 1 for each alert instance of the rule that is firing and 0 for the others.
+	RuleStateOutputState = "state"
+	// RuleStateOutputValue returns the value of a query or expression of the rule for each alert instance of the rule.
+	RuleStateOutputValue = "value"
+
+	ruleStatesKey = "ruleStates"
+)
+
+var supportedRuleStateOutputs = []string{RuleStateOutputState, RuleStateOutputValue}
+
+// RuleStateCommand is an expression command that returns the current state of the alert instances of another alert rule.
+// It can be evaluated only as part of an alert rule, which provides the states of the alert instances of the referenced rule.
+type RuleStateCommand struct {
+	RuleUID string
+	Output  string
+	// ValueFrom is the RefID of the query or expression of the referenced rule whose value is returned by the value output.
+	ValueFrom string
+	// States are the alert instances of the referenced rule. It is nil if the states were not provided.
+	States []RuleInstanceState
+	refID  string
+}
+
+// RuleInstanceState is the state of an alert instance of the rule that is referenced by a rule state expression.
+type RuleInstanceState struct {
+	Labels data.Labels `json:"labels"`
+	// Firing is true if the alert instance is alerting.
+	Firing bool `json:"firing"`
+	// Values contains the values of the queries and expressions of the rule by RefID.
+	Values map[string]float64 `json:"values,omitempty"`
+}
+
+// RuleStateCommandJSON is the model of a rule state expression in Grafana's frontend query.
+type RuleStateCommandJSON struct {
+	RuleUID   string               `json:"ruleUid"`
+	Output    string               `json:"output"`
+	ValueFrom string               `json:"valueFrom"`
+	States    *[]RuleInstanceState `json:"ruleStates"`
+}
+
+// NewRuleStateCommand creates a new RuleStateCommand.
+func NewRuleStateCommand(refID, ruleUID, output, valueFrom string) (*RuleStateCommand, error) {
+	if ruleUID == "" {
+		return nil, fmt.Errorf("no rule specified in rule state expression for refId %v", refID)
+	}
+	if !isOneOf(output, supportedRuleStateOutputs) {
+		return nil, fmt.Errorf("expected rule state output to be one of %s, got %s", strings.Join(supportedRuleStateOutputs, ", "), output)
+	}
+	if output == RuleStateOutputValue && valueFrom == "" {
+		return nil, fmt.Errorf("the value output of rule state expression for refId %v requires the refId of the value of the rule", refID)
+	}
+	return &RuleStateCommand{
+		RuleUID:   ruleUID,
+		Output:    output,
+		ValueFrom: valueFrom,
+		refID:     refID,
+	}, nil
+}
+
+// UnmarshalRuleStateCommand creates a RuleStateCommand from Grafana's frontend query.
+func UnmarshalRuleStateCommand(rn *rawNode) (*RuleStateCommand, error) {
+	jsonFromM, err := json.Marshal(rn.Query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remarshal rule state expression body: %w", err)
+	}
+	model := RuleStateCommandJSON{Output: RuleStateOutputState}
+	if err := json.Unmarshal(jsonFromM, &model); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal remarshaled rule state expression body: %w", err)
+	}
+
+	cmd, err := NewRuleStateCommand(rn.RefID, model.RuleUID, model.Output, model.ValueFrom)
+	if err != nil {
+		return nil, err
+	}
+	if model.States != nil {
+		cmd.States = *model.States
+		if cmd.States == nil {
+			cmd.States = []RuleInstanceState{}
+		}
+	}
+	return cmd, nil
+}
+
+// GetRuleStateRuleUID returns the UID of the rule that is referenced by the model if it is a rule state expression.
+func GetRuleStateRuleUID(model map[string]interface{}) (string, bool) {
+	if t, _ := model["type"].(string); t != TypeRuleState.String() {
+		return "", false
+	}
+	uid, _ := model["ruleUid"].(string)
+	return uid, uid != ""
+}
+
+// SetRuleStates sets the alert instances of the referenced rule to the model of a rule state expression.
+func SetRuleStates(model map[string]interface{}, states []RuleInstanceState) {
+	if states == nil {
+		states = []RuleInstanceState{}
+	}
+	model[ruleStatesKey] = states
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (rc *RuleStateCommand) NeedsVars() []string {
+	return []string{}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (rc *RuleStateCommand) Execute(_ context.Context, _ time.Time, _ mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	if rc.States == nil {
+		return newRes, fmt.Errorf("the state of rule %s is not available, rule state expressions can be evaluated only by alert rules", rc.RuleUID)
+	}
+
+	if rc.Output == RuleStateOutputState {
+		// a rule without alert instances is not firing
+		if len(rc.States) == 0 {
+			newRes.Values = append(newRes.Values, numberOf(rc.refID, nil, 0))
+			return newRes, nil
+		}
+		for _, s := range rc.States {
+			v := float64(0)
+			if s.Firing {
+				v = 1
+			}
+			newRes.Values = append(newRes.Values, numberOf(rc.refID, s.Labels, v))
+		}
+		return newRes, nil
+	}
+
+	if len(rc.States) == 0 {
+		newRes.Values = append(newRes.Values, mathexp.NoData{}.New())
+		return newRes, nil
+	}
+	for _, s := range rc.States {
+		n := mathexp.NewNumber(rc.refID, s.Labels.Copy())
+		if v, ok := s.Values[rc.ValueFrom]; ok {
+			n.SetValue(&v)
+		}
+		newRes.Values = append(newRes.Values, n)
+	}
+	return newRes, nil
+}
+
+func numberOf(refID string, labels data.Labels, v float64) mathexp.Number {
+	n := mathexp.NewNumber(refID, labels.Copy())
+	n.SetValue(&v)
+	return n
+}
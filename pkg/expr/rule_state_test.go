@@ -0,0 +1,160 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestUnmarshalRuleStateCommand(t *testing.T) {
+	tests := []struct {
+		description   string
+		query         string
+		expected      *RuleStateCommand
+		expectedError string
+	}{
+		{
+			description: "defaults",
+			query:       `{"type": "rule_state", "ruleUid": "network"}`,
+			expected: &RuleStateCommand{
+				RuleUID: "network",
+				Output:  RuleStateOutputState,
+				refID:   "B",
+			},
+		},
+		{
+			description: "value with states",
+			query:       `{"type": "rule_state", "ruleUid": "network", "output": "value", "valueFrom": "A", "ruleStates": [{"labels": {"host": "a"}, "firing": true, "values": {"A": 3}}]}`,
+			expected: &RuleStateCommand{
+				RuleUID:   "network",
+				Output:    RuleStateOutputValue,
+				ValueFrom: "A",
+				States: []RuleInstanceState{
+					{Labels: data.Labels{"host": "a"}, Firing: true, Values: map[string]float64{"A": 3}},
+				},
+				refID: "B",
+			},
+		},
+		{
+			description: "empty states",
+			query:       `{"type": "rule_state", "ruleUid": "network", "ruleStates": []}`,
+			expected: &RuleStateCommand{
+				RuleUID: "network",
+				Output:  RuleStateOutputState,
+				States:  []RuleInstanceState{},
+				refID:   "B",
+			},
+		},
+		{
+			description:   "rule is required",
+			query:         `{"type": "rule_state"}`,
+			expectedError: "no rule specified",
+		},
+		{
+			description:   "unknown output",
+			query:         `{"type": "rule_state", "ruleUid": "network", "output": "labels"}`,
+			expectedError: "expected rule state output to be one of",
+		},
+		{
+			description:   "value output requires refId",
+			query:         `{"type": "rule_state", "ruleUid": "network", "output": "value"}`,
+			expectedError: "requires the refId of the value",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			q := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal([]byte(tc.query), &q))
+			cmd, err := UnmarshalRuleStateCommand(&rawNode{RefID: "B", Query: q})
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cmd)
+		})
+	}
+}
+
+func TestRuleStateCommandExecute(t *testing.T) {
+	states := []RuleInstanceState{
+		{Labels: data.Labels{"host": "a"}, Firing: true, Values: map[string]float64{"A": 3}},
+		{Labels: data.Labels{"host": "b"}, Values: map[string]float64{"A": 1}},
+		{Labels: data.Labels{"host": "c"}},
+	}
+
+	t.Run("state", func(t *testing.T) {
+		cmd, err := NewRuleStateCommand("B", "network", RuleStateOutputState, "")
+		require.NoError(t, err)
+		cmd.States = states
+		res, err := cmd.Execute(context.Background(), time.Now(), nil)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 3)
+		for i, expected := range []float64{1, 0, 0} {
+			n := res.Values[i].(mathexp.Number)
+			require.Equal(t, states[i].Labels, n.GetLabels())
+			require.Equal(t, expected, *n.GetFloat64Value())
+		}
+	})
+
+	t.Run("state of rule without instances is not firing", func(t *testing.T) {
+		cmd, err := NewRuleStateCommand("B", "network", RuleStateOutputState, "")
+		require.NoError(t, err)
+		cmd.States = []RuleInstanceState{}
+		res, err := cmd.Execute(context.Background(), time.Now(), nil)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, float64(0), *res.Values[0].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("value", func(t *testing.T) {
+		cmd, err := NewRuleStateCommand("B", "network", RuleStateOutputValue, "A")
+		require.NoError(t, err)
+		cmd.States = states
+		res, err := cmd.Execute(context.Background(), time.Now(), nil)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 3)
+		require.Equal(t, float64(3), *res.Values[0].(mathexp.Number).GetFloat64Value())
+		require.Equal(t, float64(1), *res.Values[1].(mathexp.Number).GetFloat64Value())
+		require.Nil(t, res.Values[2].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("value of rule without instances is no data", func(t *testing.T) {
+		cmd, err := NewRuleStateCommand("B", "network", RuleStateOutputValue, "A")
+		require.NoError(t, err)
+		cmd.States = []RuleInstanceState{}
+		res, err := cmd.Execute(context.Background(), time.Now(), nil)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		_, ok := res.Values[0].(mathexp.NoData)
+		require.True(t, ok)
+	})
+
+	t.Run("fails if states are not provided", func(t *testing.T) {
+		cmd, err := NewRuleStateCommand("B", "network", RuleStateOutputState, "")
+		require.NoError(t, err)
+		_, err = cmd.Execute(context.Background(), time.Now(), nil)
+		require.ErrorContains(t, err, "can be evaluated only by alert rules")
+	})
+}
+
+func TestSetRuleStates(t *testing.T) {
+	model := map[string]interface{}{"type": "rule_state", "ruleUid": "network"}
+	uid, ok := GetRuleStateRuleUID(model)
+	require.True(t, ok)
+	require.Equal(t, "network", uid)
+
+	SetRuleStates(model, []RuleInstanceState{{Labels: data.Labels{"host": "a"}, Firing: true}})
+	cmd, err := UnmarshalRuleStateCommand(&rawNode{RefID: "B", Query: model})
+	require.NoError(t, err)
+	require.Equal(t, []RuleInstanceState{{Labels: data.Labels{"host": "a"}, Firing: true}}, cmd.States)
+
+	_, ok = GetRuleStateRuleUID(map[string]interface{}{"type": "math", "expression": "$A"})
+	require.False(t, ok)
+}
@@ -0,0 +1,332 @@
+// Package sql runs SQL queries over data frames with an in-memory SQLite database.
+package sql
+
+import (
+	"context"
+	gosql "database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/mattn/go-sqlite3"
+)
+
+const driverName = "sqlite3_expressions"
+
+// sqliteRecursive is the SQLITE_RECURSIVE authorizer action code, which is not exported by the driver.
+const sqliteRecursive = 33
+
+var registerDriver sync.Once
+
+// queryTimeout is the maximum duration of a query, including the loading of its tables, so that a query such
+// as an unbounded recursive common table expression can not run forever. It is a variable for tests.
+var queryTimeout = 10 * time.Second
+
+// rowLimit is the maximum number of rows of the result of a query. It is a variable for tests.
+var rowLimit = 100000
+
+// readOnlyActions are the authorizer actions allowed when the query of the user is run.
+// Everything else, such as writing to tables, ATTACH, or PRAGMA, is denied.
+var readOnlyActions = map[int]bool{
+	sqlite3.SQLITE_SELECT:   true,
+	sqlite3.SQLITE_READ:     true,
+	sqlite3.SQLITE_FUNCTION: true,
+	sqliteRecursive:         true,
+}
+
+// QueryFrames loads each frame as a table with the name of its key in tables, runs the
+// query, and returns its result as a frame with the given name. The query can only read data,
+// it is interrupted if it runs for longer than the query timeout, and it fails if its result
+// has more rows than the row limit.
+func QueryFrames(ctx context.Context, name string, query string, tables map[string]*data.Frame) (*data.Frame, error) {
+	registerDriver.Do(func() {
+		gosql.Register(driverName, &sqlite3.SQLiteDriver{})
+	})
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	db, err := gosql.Open(driverName, ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = db.Close() }()
+
+	// every connection to :memory: is a different database
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	for tableName, frame := range tables {
+		if err := createTable(ctx, conn, tableName, frame); err != nil {
+			return nil, fmt.Errorf("failed to load %s as a table: %w", tableName, err)
+		}
+	}
+
+	err = conn.Raw(func(driverConn interface{}) error {
+		c, ok := driverConn.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("unexpected connection type %T", driverConn)
+		}
+		c.RegisterAuthorizer(func(action int, _, _, _ string) int {
+			if readOnlyActions[action] {
+				return sqlite3.SQLITE_OK
+			}
+			return sqlite3.SQLITE_DENY
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("query did not complete within %s", queryTimeout)
+		}
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	frame, err := frameFromRows(name, rows, rowLimit)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("query did not complete within %s", queryTimeout)
+		}
+		return nil, err
+	}
+	return frame, nil
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// columnType returns the SQLite type of a field. The declared type of a column
+// is used to read the values back with the same type.
+func columnType(f *data.Field) (string, error) {
+	t := f.Type()
+	switch {
+	case t == data.FieldTypeTime || t == data.FieldTypeNullableTime:
+		return "TIMESTAMP", nil
+	case t == data.FieldTypeBool || t == data.FieldTypeNullableBool:
+		return "BOOLEAN", nil
+	case t == data.FieldTypeString || t == data.FieldTypeNullableString:
+		return "TEXT", nil
+	case t == data.FieldTypeFloat32 || t == data.FieldTypeNullableFloat32 ||
+		t == data.FieldTypeFloat64 || t == data.FieldTypeNullableFloat64:
+		return "REAL", nil
+	case t.Numeric():
+		return "INTEGER", nil
+	default:
+		return "", fmt.Errorf("field %s has unsupported type %s", f.Name, t.ItemTypeString())
+	}
+}
+
+func createTable(ctx context.Context, conn *gosql.Conn, name string, frame *data.Frame) error {
+	if len(frame.Fields) == 0 {
+		return errors.New("the table has no columns")
+	}
+	columns := make([]string, 0, len(frame.Fields))
+	placeholders := make([]string, 0, len(frame.Fields))
+	for _, f := range frame.Fields {
+		ct, err := columnType(f)
+		if err != nil {
+			return err
+		}
+		columns = append(columns, quoteIdent(f.Name)+" "+ct)
+		placeholders = append(placeholders, "?")
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdent(name), strings.Join(columns, ", "))); err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s VALUES (%s)", quoteIdent(name), strings.Join(placeholders, ", ")))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	args := make([]interface{}, len(frame.Fields))
+	for row := 0; row < frame.Rows(); row++ {
+		for i, f := range frame.Fields {
+			v, ok := f.ConcreteAt(row)
+			if !ok {
+				v = nil
+			}
+			args[i] = sqlValue(v)
+		}
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// sqlValue converts a value of a field to one of the types supported by database/sql.
+func sqlValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case int8:
+		return int64(t)
+	case int16:
+		return int64(t)
+	case int32:
+		return int64(t)
+	case uint8:
+		return int64(t)
+	case uint16:
+		return int64(t)
+	case uint32:
+		return int64(t)
+	case uint64:
+		return float64(t)
+	case float32:
+		return float64(t)
+	case time.Time:
+		return t.UTC()
+	}
+	return v
+}
+
+// frameFromRows reads the rows into a frame. The type of each field is the type of the values in
+// the column, which the driver reads according to the declared type of the column if there is one,
+// such as TIMESTAMP for times. All fields are nullable. It fails if there are more rows than the limit.
+func frameFromRows(name string, rows *gosql.Rows, limit int) (*data.Frame, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([][]interface{}, len(columnTypes))
+	dest := make([]interface{}, len(columnTypes))
+	for n := 0; rows.Next(); n++ {
+		if n == limit {
+			return nil, fmt.Errorf("query returned more than %d rows", limit)
+		}
+		row := make([]interface{}, len(columnTypes))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		for i, v := range row {
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			values[i] = append(values[i], v)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	frame := data.NewFrame(name)
+	for i, ct := range columnTypes {
+		field, err := fieldFromValues(ct.Name(), values[i])
+		if err != nil {
+			return nil, err
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+	return frame, nil
+}
+
+func fieldFromValues(name string, values []interface{}) (*data.Field, error) {
+	var hasInt, hasFloat, hasString, hasBool, hasTime bool
+	for _, v := range values {
+		switch v.(type) {
+		case nil:
+		case int64:
+			hasInt = true
+		case float64:
+			hasFloat = true
+		case string:
+			hasString = true
+		case bool:
+			hasBool = true
+		case time.Time:
+			hasTime = true
+		default:
+			return nil, fmt.Errorf("column %s has unsupported type %T", name, v)
+		}
+	}
+
+	switch {
+	case hasString || (hasTime && (hasInt || hasFloat || hasBool)):
+		field := data.NewFieldFromFieldType(data.FieldTypeNullableString, len(values))
+		for i, v := range values {
+			switch t := v.(type) {
+			case nil:
+			case string:
+				field.Set(i, &t)
+			case time.Time:
+				s := t.UTC().Format(time.RFC3339Nano)
+				field.Set(i, &s)
+			default:
+				s := fmt.Sprint(t)
+				field.Set(i, &s)
+			}
+		}
+		field.Name = name
+		return field, nil
+	case hasTime:
+		field := data.NewFieldFromFieldType(data.FieldTypeNullableTime, len(values))
+		for i, v := range values {
+			if t, ok := v.(time.Time); ok {
+				field.Set(i, &t)
+			}
+		}
+		field.Name = name
+		return field, nil
+	case hasBool && !hasInt && !hasFloat:
+		field := data.NewFieldFromFieldType(data.FieldTypeNullableBool, len(values))
+		for i, v := range values {
+			if b, ok := v.(bool); ok {
+				field.Set(i, &b)
+			}
+		}
+		field.Name = name
+		return field, nil
+	case hasInt && !hasFloat && !hasBool:
+		field := data.NewFieldFromFieldType(data.FieldTypeNullableInt64, len(values))
+		for i, v := range values {
+			if n, ok := v.(int64); ok {
+				field.Set(i, &n)
+			}
+		}
+		field.Name = name
+		return field, nil
+	default:
+		field := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(values))
+		for i, v := range values {
+			var f float64
+			switch t := v.(type) {
+			case int64:
+				f = float64(t)
+			case float64:
+				f = t
+			case bool:
+				if t {
+					f = 1
+				}
+			default:
+				continue
+			}
+			field.Set(i, &f)
+		}
+		field.Name = name
+		return field, nil
+	}
+}
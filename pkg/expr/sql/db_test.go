@@ -0,0 +1,97 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryFrames(t *testing.T) {
+	t0 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	metrics := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{t0, t0, t0.Add(time.Minute)}),
+		data.NewField("host", nil, []string{"a", "b", "a"}),
+		data.NewField("value", nil, []float64{1, 2, 3}),
+	)
+	inventory := data.NewFrame("",
+		data.NewField("host", nil, []*string{strPtr("a"), strPtr("b")}),
+		data.NewField("team", nil, []*string{strPtr("red"), nil}),
+		data.NewField("cores", nil, []int32{4, 8}),
+	)
+	tables := map[string]*data.Frame{"A": metrics, "B": inventory}
+
+	t.Run("join and group by", func(t *testing.T) {
+		frame, err := QueryFrames(context.Background(), "C", `
+			SELECT A.host, B.team, sum(A.value) / B.cores AS load
+			FROM A JOIN B ON A.host = B.host
+			GROUP BY A.host ORDER BY A.host`, tables)
+		require.NoError(t, err)
+		require.Equal(t, "C", frame.Name)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[0].Type())
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[1].Type())
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[2].Type())
+		require.Equal(t, "red", *frame.At(1, 0).(*string))
+		require.Nil(t, frame.At(1, 1))
+		require.InDelta(t, 1.0, *frame.At(2, 0).(*float64), 1e-9)
+		require.InDelta(t, 0.25, *frame.At(2, 1).(*float64), 1e-9)
+	})
+
+	t.Run("times and integers keep their type", func(t *testing.T) {
+		frame, err := QueryFrames(context.Background(), "C", `SELECT time, count(*) AS n FROM A GROUP BY time ORDER BY time`, tables)
+		require.NoError(t, err)
+		require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		require.Equal(t, data.FieldTypeNullableInt64, frame.Fields[1].Type())
+		require.True(t, t0.Equal(*frame.At(0, 0).(*time.Time)))
+		require.Equal(t, int64(2), *frame.At(1, 0).(*int64))
+	})
+
+	t.Run("query can not write", func(t *testing.T) {
+		for _, q := range []string{
+			`DELETE FROM A`,
+			`ATTACH DATABASE 'file.db' AS f`,
+			`PRAGMA table_info(A)`,
+		} {
+			_, err := QueryFrames(context.Background(), "C", q, tables)
+			require.Errorf(t, err, "query %q", q)
+		}
+	})
+
+	t.Run("query is interrupted after the timeout", func(t *testing.T) {
+		origTimeout := queryTimeout
+		t.Cleanup(func() { queryTimeout = origTimeout })
+		queryTimeout = 100 * time.Millisecond
+
+		start := time.Now()
+		_, err := QueryFrames(context.Background(), "C", `
+			WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c)
+			SELECT count(*) FROM c`, tables)
+		require.ErrorContains(t, err, "did not complete")
+		require.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("query fails if it returns more rows than the limit", func(t *testing.T) {
+		origLimit := rowLimit
+		t.Cleanup(func() { rowLimit = origLimit })
+		rowLimit = 2
+
+		_, err := QueryFrames(context.Background(), "C", `SELECT * FROM A`, tables)
+		require.ErrorContains(t, err, "more than 2 rows")
+
+		frame, err := QueryFrames(context.Background(), "C", `SELECT * FROM B`, tables)
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+	})
+
+	t.Run("unknown table", func(t *testing.T) {
+		_, err := QueryFrames(context.Background(), "C", `SELECT * FROM D`, tables)
+		require.Error(t, err)
+	})
+}
+
+func strPtr(s string) *string {
+	return &s
+}
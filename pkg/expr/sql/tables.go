@@ -0,0 +1,191 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenType int
+
+const (
+	tokenIdent tokenType = iota
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenPunct
+)
+
+type token struct {
+	typ tokenType
+	val string
+}
+
+// isKeyword reports whether the token is the given keyword. Quoted identifiers are never keywords.
+func (t token) isKeyword(keyword string) bool {
+	return t.typ == tokenIdent && strings.EqualFold(t.val, keyword)
+}
+
+func (t token) isIdent() bool {
+	return t.typ == tokenIdent || t.typ == tokenQuotedIdent
+}
+
+func (t token) isPunct(p string) bool {
+	return t.typ == tokenPunct && t.val == p
+}
+
+// clauseKeywords are the keywords that can follow a table in a FROM clause, and so are not an alias of the table.
+var clauseKeywords = map[string]bool{
+	"WHERE": true, "GROUP": true, "ORDER": true, "LIMIT": true, "HAVING": true, "WINDOW": true,
+	"JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "FULL": true, "CROSS": true, "NATURAL": true,
+	"OUTER": true, "ON": true, "USING": true, "UNION": true, "EXCEPT": true, "INTERSECT": true, "AS": true,
+}
+
+// tokenize splits a SQL statement into tokens. Comments are dropped.
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	r := []rune(query)
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '-' && i+1 < len(r) && r[i+1] == '-':
+			for i < len(r) && r[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(r) && r[i+1] == '*':
+			j := i + 2
+			for j+1 < len(r) && !(r[j] == '*' && r[j+1] == '/') {
+				j++
+			}
+			if j+1 >= len(r) {
+				return nil, fmt.Errorf("unterminated comment starting at position %d", i)
+			}
+			i = j + 2
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			var sb strings.Builder
+			j := i + 1
+			for {
+				if j >= len(r) {
+					return nil, fmt.Errorf("unterminated quoted text starting at position %d", i)
+				}
+				if r[j] == closing {
+					// a doubled quote is an escaped quote
+					if closing != ']' && j+1 < len(r) && r[j+1] == closing {
+						sb.WriteRune(closing)
+						j += 2
+						continue
+					}
+					break
+				}
+				sb.WriteRune(r[j])
+				j++
+			}
+			typ := tokenQuotedIdent
+			if c == '\'' {
+				typ = tokenString
+			}
+			tokens = append(tokens, token{typ: typ, val: sb.String()})
+			i = j + 1
+		case c == '_' || unicode.IsLetter(c):
+			j := i
+			for j < len(r) && (r[j] == '_' || r[j] == '$' || unicode.IsLetter(r[j]) || unicode.IsDigit(r[j])) {
+				j++
+			}
+			tokens = append(tokens, token{typ: tokenIdent, val: string(r[i:j])})
+			i = j
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(r) && (unicode.IsDigit(r[j]) || r[j] == '.' || unicode.IsLetter(r[j])) {
+				j++
+			}
+			tokens = append(tokens, token{typ: tokenNumber, val: string(r[i:j])})
+			i = j
+		default:
+			tokens = append(tokens, token{typ: tokenPunct, val: string(c)})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// TablesList returns the names of the tables that are read by the SQL query, in the order they first appear.
+// Tables that are defined by the query itself, with a common table expression, are not returned.
+func TablesList(query string) ([]string, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	defined := map[string]bool{}
+	for i, t := range tokens {
+		if !t.isKeyword("AS") || i+1 >= len(tokens) || !tokens[i+1].isPunct("(") || i == 0 {
+			continue
+		}
+		// name AS (...) or name(column, ...) AS (...)
+		j := i - 1
+		if tokens[j].isPunct(")") {
+			for j >= 0 && !tokens[j].isPunct("(") {
+				j--
+			}
+			j--
+		}
+		if j >= 0 && tokens[j].isIdent() {
+			defined[strings.ToLower(tokens[j].val)] = true
+		}
+	}
+
+	// the names of tables are case-insensitive in SQLite, so a table is returned once with its first spelling
+	var tables []string
+	seen := map[string]bool{}
+	addTable := func(t token) {
+		name := strings.ToLower(t.val)
+		if defined[name] || seen[name] {
+			return
+		}
+		seen[name] = true
+		tables = append(tables, t.val)
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		if !tokens[i].isKeyword("FROM") && !tokens[i].isKeyword("JOIN") {
+			continue
+		}
+		isFrom := tokens[i].isKeyword("FROM")
+		for j := i + 1; j < len(tokens); {
+			t := tokens[j]
+			if !t.isIdent() || (t.typ == tokenIdent && clauseKeywords[strings.ToUpper(t.val)]) {
+				break
+			}
+			// schema.table, the schema is main or temp because other databases can not be attached
+			if j+2 < len(tokens) && tokens[j+1].isPunct(".") && tokens[j+2].isIdent() {
+				j += 2
+				t = tokens[j]
+			}
+			// a function call, for example a table-valued function
+			if j+1 < len(tokens) && tokens[j+1].isPunct("(") {
+				break
+			}
+			addTable(t)
+			j++
+			// skip the alias of the table
+			if j < len(tokens) && tokens[j].isKeyword("AS") {
+				j++
+			}
+			if j < len(tokens) && tokens[j].isIdent() && !(tokens[j].typ == tokenIdent && clauseKeywords[strings.ToUpper(tokens[j].val)]) {
+				j++
+			}
+			// FROM A, B is a list of tables
+			if !isFrom || j >= len(tokens) || !tokens[j].isPunct(",") {
+				break
+			}
+			j++
+		}
+	}
+	return tables, nil
+}
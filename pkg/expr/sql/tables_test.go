@@ -0,0 +1,85 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTablesList(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{
+			name:     "single table",
+			query:    `SELECT * FROM A`,
+			expected: []string{"A"},
+		},
+		{
+			name:     "join with aliases",
+			query:    `SELECT a.host, a.value, b.team FROM A AS a JOIN B b ON a.host = b.host`,
+			expected: []string{"A", "B"},
+		},
+		{
+			name:     "list of tables",
+			query:    `SELECT * FROM A a, "B" WHERE a.host = "B".host`,
+			expected: []string{"A", "B"},
+		},
+		{
+			name:     "subquery",
+			query:    `SELECT host, max(value) FROM (SELECT * FROM A WHERE value > 1) GROUP BY host`,
+			expected: []string{"A"},
+		},
+		{
+			name: "common table expression is not a table",
+			query: `WITH totals(host, total) AS (SELECT host, sum(value) FROM A GROUP BY host)
+				SELECT * FROM totals JOIN B ON totals.host = B.host`,
+			expected: []string{"A", "B"},
+		},
+		{
+			name:     "keywords in strings and comments are ignored",
+			query:    "SELECT 'from C' AS text -- FROM D\n, value FROM /* JOIN E */ A",
+			expected: []string{"A"},
+		},
+		{
+			name:     "table-valued function is not a table",
+			query:    `SELECT value FROM json_each('[1, 2]')`,
+			expected: nil,
+		},
+		{
+			name:     "table is returned once",
+			query:    `SELECT * FROM A UNION SELECT * FROM A`,
+			expected: []string{"A"},
+		},
+		{
+			name:     "names of tables are case-insensitive",
+			query:    `SELECT * FROM A JOIN a ON A.host = a.host`,
+			expected: []string{"A"},
+		},
+		{
+			name:     "table with a schema",
+			query:    `SELECT * FROM main.A JOIN "temp"."B" b ON A.host = b.host`,
+			expected: []string{"A", "B"},
+		},
+		{
+			name: "common table expressions are case-insensitive",
+			query: `WITH Totals AS (SELECT host, sum(value) AS total FROM A GROUP BY host)
+				SELECT * FROM totals`,
+			expected: []string{"A"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, err := TablesList(tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tables)
+		})
+	}
+
+	t.Run("unterminated string", func(t *testing.T) {
+		_, err := TablesList(`SELECT * FROM A WHERE host = 'a`)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,265 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/sql"
+)
+
+// SQLCommand is an expression command that runs a SQL query over the results of other queries
+// and expressions. The results of each query or expression are a table named after its refID.
+type SQLCommand struct {
+	RawSQL      string
+	varsToQuery []string
+	refID       string
+}
+
+// NewSQLCommand creates a new SQLCommand. It will return an error if the tables
+// that are read by rawSQL can not be found.
+func NewSQLCommand(refID, rawSQL string) (*SQLCommand, error) {
+	if rawSQL == "" {
+		return nil, errors.New("sql expression is missing a query")
+	}
+	tables, err := sql.TablesList(rawSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sql expression: %w", err)
+	}
+	if len(tables) == 0 {
+		return nil, errors.New("sql expression must read the results of at least one query or expression")
+	}
+	return &SQLCommand{
+		RawSQL:      rawSQL,
+		varsToQuery: tables,
+		refID:       refID,
+	}, nil
+}
+
+// UnmarshalSQLCommand creates a SQLCommand from Grafana's frontend query.
+func UnmarshalSQLCommand(rn *rawNode) (*SQLCommand, error) {
+	rawExpr, ok := rn.Query["expression"]
+	if !ok {
+		return nil, errors.New("command is missing an expression")
+	}
+	rawSQL, ok := rawExpr.(string)
+	if !ok {
+		return nil, fmt.Errorf("sql expression is expected to be a string, got %T", rawExpr)
+	}
+	return NewSQLCommand(rn.RefID, rawSQL)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (gr *SQLCommand) NeedsVars() []string {
+	return gr.varsToQuery
+}
+
+// matchRefIDs replaces the names of the tables with the refIDs of the nodes they read. The names of
+// tables are case-insensitive in SQL, so FROM a reads the results of the node with refID A.
+func (gr *SQLCommand) matchRefIDs(registry map[string]Node) {
+	for i, name := range gr.varsToQuery {
+		if _, ok := registry[name]; ok {
+			continue
+		}
+		for refID := range registry {
+			if strings.EqualFold(refID, name) {
+				gr.varsToQuery[i] = refID
+				break
+			}
+		}
+	}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (gr *SQLCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars) (mathexp.Results, error) {
+	tables := make(map[string]*data.Frame, len(gr.varsToQuery))
+	for _, refID := range gr.varsToQuery {
+		frame, err := resultsToTable(refID, vars[refID])
+		if err != nil {
+			return mathexp.Results{}, err
+		}
+		if frame == nil {
+			return mathexp.Results{Values: mathexp.Values{mathexp.NoData{}.New()}}, nil
+		}
+		tables[refID] = frame
+	}
+
+	frame, err := sql.QueryFrames(ctx, gr.refID, gr.RawSQL, tables)
+	if err != nil {
+		return mathexp.Results{}, fmt.Errorf("failed to execute sql expression: %w", err)
+	}
+	return tableToResults(frame)
+}
+
+// resultsToTable returns the results of a query or expression as a single table. Table data is returned as it is.
+// Numbers and time series are returned in long format, with a column for the time of time series, a column for
+// each label, and a column named value. A nil frame is returned if there is no data.
+func resultsToTable(refID string, res mathexp.Results) (*data.Frame, error) {
+	if len(res.Values) == 0 {
+		return nil, nil
+	}
+
+	if table, ok := res.Values[0].(mathexp.TableData); ok {
+		if len(res.Values) > 1 {
+			return nil, fmt.Errorf("%s returned %v tables but only a single table can be used in a sql expression", refID, len(res.Values))
+		}
+		return table.Frame, nil
+	}
+
+	labelSet := map[string]struct{}{}
+	isSeries := false
+	for _, v := range res.Values {
+		switch v.(type) {
+		case mathexp.NoData:
+			return nil, nil
+		case mathexp.Series:
+			isSeries = true
+		case mathexp.Number:
+		default:
+			return nil, fmt.Errorf("%s is of type %v, which can not be used in a sql expression", refID, v.Type())
+		}
+		for k := range v.GetLabels() {
+			labelSet[k] = struct{}{}
+		}
+	}
+
+	labelNames := make([]string, 0, len(labelSet))
+	for k := range labelSet {
+		if k == "time" || k == "value" {
+			return nil, fmt.Errorf("%s has a label named %s, which is also the name of a column of the table", refID, k)
+		}
+		labelNames = append(labelNames, k)
+	}
+	sort.Strings(labelNames)
+
+	table := data.NewFrame(refID)
+	var timeField *data.Field
+	if isSeries {
+		timeField = data.NewField("time", nil, []time.Time{})
+		table.Fields = append(table.Fields, timeField)
+	}
+	labelFields := make([]*data.Field, len(labelNames))
+	for i, name := range labelNames {
+		labelFields[i] = data.NewField(name, nil, []*string{})
+		table.Fields = append(table.Fields, labelFields[i])
+	}
+	valueField := data.NewField("value", nil, []*float64{})
+	table.Fields = append(table.Fields, valueField)
+
+	appendRow := func(labels data.Labels, t time.Time, f *float64) {
+		if isSeries {
+			timeField.Append(t)
+		}
+		for i, name := range labelNames {
+			var label *string
+			if l, ok := labels[name]; ok {
+				label = &l
+			}
+			labelFields[i].Append(label)
+		}
+		valueField.Append(f)
+	}
+
+	for _, v := range res.Values {
+		switch val := v.(type) {
+		case mathexp.Series:
+			for i := 0; i < val.Len(); i++ {
+				t, f := val.GetPoint(i)
+				appendRow(val.GetLabels(), t, f)
+			}
+		case mathexp.Number:
+			appendRow(val.GetLabels(), time.Time{}, val.GetFloat64Value())
+		}
+	}
+	return table, nil
+}
+
+// tableToResults returns the result of a sql expression as numbers if it has a single numeric
+// column and otherwise only string columns, and as time series if it has a time column.
+// Otherwise the result is returned as table data.
+func tableToResults(frame *data.Frame) (mathexp.Results, error) {
+	if frame.Rows() == 0 {
+		return mathexp.Results{Values: mathexp.Values{mathexp.NoData{}.New()}}, nil
+	}
+
+	if isNumberTable(frame) {
+		numbers, err := extractNumberSet(frame)
+		if err != nil {
+			return mathexp.Results{}, err
+		}
+		vals := make(mathexp.Values, 0, len(numbers))
+		for _, n := range numbers {
+			vals = append(vals, n)
+		}
+		return mathexp.Results{Values: vals}, nil
+	}
+
+	switch frame.TimeSeriesSchema().Type {
+	case data.TimeSeriesTypeWide:
+		series, err := WideToMany(frame)
+		if err != nil {
+			return mathexp.Results{}, err
+		}
+		vals := make(mathexp.Values, 0, len(series))
+		for _, s := range series {
+			s.SortByTime(false)
+			vals = append(vals, s)
+		}
+		return mathexp.Results{Values: vals}, nil
+	case data.TimeSeriesTypeLong:
+		return longToSeries(frame)
+	}
+	return mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: frame}}}, nil
+}
+
+// longToSeries returns a time series for each numeric column and each combination of the values
+// of the string and boolean columns of a long format frame, which are the labels of the series.
+// The rows do not need to be sorted by time.
+func longToSeries(frame *data.Frame) (mathexp.Results, error) {
+	schema := frame.TimeSeriesSchema()
+	var keys []string
+	series := map[string]mathexp.Series{}
+	for row := 0; row < frame.Rows(); row++ {
+		t, ok := frame.ConcreteAt(schema.TimeIndex, row)
+		if !ok {
+			return mathexp.Results{}, fmt.Errorf("time series with null time stamps are not supported")
+		}
+		labels := data.Labels{}
+		for _, idx := range schema.FactorIndices {
+			if v, ok := frame.ConcreteAt(idx, row); ok {
+				labels[frame.Fields[idx].Name] = fmt.Sprint(v)
+			}
+		}
+		for _, idx := range schema.ValueIndices {
+			name := frame.Fields[idx].Name
+			key := name + labels.String()
+			s, ok := series[key]
+			if !ok {
+				s = mathexp.NewSeries(name, labels.Copy(), 0)
+				series[key] = s
+				keys = append(keys, key)
+			}
+			f, err := frame.Fields[idx].NullableFloatAt(row)
+			if err != nil {
+				return mathexp.Results{}, err
+			}
+			s.AppendPoint(t.(time.Time), f)
+		}
+	}
+
+	vals := make(mathexp.Values, 0, len(keys))
+	for _, key := range keys {
+		s := series[key]
+		s.SortByTime(false)
+		vals = append(vals, s)
+	}
+	return mathexp.Results{Values: vals}, nil
+}
@@ -0,0 +1,300 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datafakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestUnmarshalSQLCommand(t *testing.T) {
+	tests := []struct {
+		description   string
+		query         string
+		expectedVars  []string
+		expectedError string
+	}{
+		{
+			description:  "tables are the needed vars",
+			query:        `{"type": "sql", "expression": "SELECT A.host, A.value / B.cores FROM A JOIN B ON A.host = B.host"}`,
+			expectedVars: []string{"A", "B"},
+		},
+		{
+			description:   "query is required",
+			query:         `{"type": "sql", "expression": ""}`,
+			expectedError: "missing a query",
+		},
+		{
+			description:   "query must read a table",
+			query:         `{"type": "sql", "expression": "SELECT 1"}`,
+			expectedError: "must read the results of at least one query or expression",
+		},
+		{
+			description:   "query must be a string",
+			query:         `{"type": "sql", "expression": 1}`,
+			expectedError: "expected to be a string",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			q := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal([]byte(tc.query), &q))
+			cmd, err := UnmarshalSQLCommand(&rawNode{RefID: "C", Query: q})
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedVars, cmd.NeedsVars())
+		})
+	}
+}
+
+func TestSQLCommandExecute(t *testing.T) {
+	t0 := time.Unix(60, 0).UTC()
+	cpu := mathexp.NewSeries("A", data.Labels{"host": "a"}, 2)
+	cpu.SetPoint(0, t0, fp(1))
+	cpu.SetPoint(1, t0.Add(time.Minute), fp(3))
+	cpuB := mathexp.NewSeries("A", data.Labels{"host": "b"}, 1)
+	cpuB.SetPoint(0, t0, fp(8))
+	inventory := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b"}),
+		data.NewField("team", nil, []string{"red", "blue"}),
+	)
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{cpu, cpuB}},
+		"B": mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: inventory}}},
+	}
+
+	t.Run("result with one numeric column is numbers", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", `
+			SELECT A.host, B.team, avg(A.value) AS cpu
+			FROM A JOIN B ON A.host = B.host
+			GROUP BY A.host, B.team`)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		for i, expected := range []struct {
+			labels data.Labels
+			value  float64
+		}{
+			{data.Labels{"host": "a", "team": "red"}, 2},
+			{data.Labels{"host": "b", "team": "blue"}, 8},
+		} {
+			n, ok := res.Values[i].(mathexp.Number)
+			require.True(t, ok)
+			require.Equal(t, expected.labels, n.GetLabels())
+			require.Equal(t, expected.value, *n.GetFloat64Value())
+		}
+	})
+
+	t.Run("result with a time column is time series", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", `
+			SELECT A.time, B.team, A.value * 2 AS cpu
+			FROM A JOIN B ON A.host = B.host
+			ORDER BY A.time DESC`)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		s, ok := res.Values[0].(mathexp.Series)
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"team": "red"}, s.GetLabels())
+		require.Equal(t, 2, s.Len())
+		require.True(t, t0.Equal(s.GetTime(0)))
+		require.Equal(t, fp(2), s.GetValue(0))
+		require.Equal(t, fp(6), s.GetValue(1))
+	})
+
+	t.Run("other results are table data", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", `SELECT host, team FROM B WHERE team = 'red'`)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		table, ok := res.Values[0].(mathexp.TableData)
+		require.True(t, ok)
+		require.Equal(t, 1, table.Frame.Rows())
+	})
+
+	t.Run("empty result is no data", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", `SELECT host, team FROM B WHERE team = 'green'`)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Equal(t, "noData", resultType(res))
+	})
+
+	t.Run("no data input is no data", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", `SELECT * FROM D`)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"D": mathexp.Results{Values: mathexp.Values{mathexp.NoData{}.New()}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "noData", resultType(res))
+	})
+}
+
+func TestSQLCommandInPipeline(t *testing.T) {
+	inventory := data.NewFrame("inventory",
+		data.NewField("host", nil, []string{"a", "b", "c"}),
+		data.NewField("team", nil, []string{"red", "blue", "red"}),
+	)
+	s := Service{
+		cfg:               setting.NewCfg(),
+		dataService:       &mockEndpoint{Frames: []*data.Frame{inventory}},
+		dataSourceService: &datafakes.FakeDataSourceService{},
+	}
+
+	req := &Request{Queries: []Query{
+		{
+			RefID: "A",
+			DataSource: &datasources.DataSource{
+				OrgId: 1,
+				Uid:   "mysql",
+				Type:  "mysql",
+			},
+			JSON:      json.RawMessage(`{ "rawSql": "SELECT host, team FROM inventory" }`),
+			TimeRange: AbsoluteTimeRange{},
+		},
+		{
+			RefID:      "B",
+			DataSource: DataSourceModel(),
+			JSON:       json.RawMessage(`{ "type": "sql", "expression": "SELECT team, count(*) AS hosts FROM A GROUP BY team ORDER BY team" }`),
+		},
+	}}
+
+	pl, err := s.BuildPipeline(req)
+	require.NoError(t, err)
+	res, err := s.ExecutePipeline(context.Background(), time.Now(), pl)
+	require.NoError(t, err)
+
+	require.NoError(t, res.Responses["B"].Error)
+	frames := res.Responses["B"].Frames
+	require.Len(t, frames, 2)
+	require.Equal(t, data.Labels{"team": "blue"}, frames[0].Fields[0].Labels)
+	v, err := frames[0].FloatAt(0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1.0, v)
+	require.Equal(t, data.Labels{"team": "red"}, frames[1].Fields[0].Labels)
+	v, err = frames[1].FloatAt(0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2.0, v)
+}
+
+func TestSQLCommandInPipelineWithTimeSeries(t *testing.T) {
+	series := func(instance string, values ...float64) *data.Frame {
+		times := make([]time.Time, len(values))
+		for i := range values {
+			times[i] = time.Unix(int64(i*60), 0)
+		}
+		return data.NewFrame("",
+			data.NewField("time", nil, times),
+			data.NewField("value", data.Labels{"instance": instance}, values),
+		)
+	}
+	s := Service{
+		cfg:               setting.NewCfg(),
+		dataService:       &mockEndpoint{Frames: []*data.Frame{series("a", 1, 2), series("b", 3, 4, 5)}},
+		dataSourceService: &datafakes.FakeDataSourceService{},
+	}
+
+	req := &Request{Queries: []Query{
+		{
+			RefID: "A",
+			DataSource: &datasources.DataSource{
+				OrgId: 1,
+				Uid:   "prometheus",
+				Type:  "prometheus",
+			},
+			JSON:      json.RawMessage(`{ "expr": "up" }`),
+			TimeRange: AbsoluteTimeRange{},
+		},
+		{
+			RefID:      "B",
+			DataSource: DataSourceModel(),
+			JSON:       json.RawMessage(`{ "type": "sql", "expression": "SELECT instance, count(*) AS samples, sum(value) AS total FROM A GROUP BY instance ORDER BY instance" }`),
+		},
+	}}
+
+	pl, err := s.BuildPipeline(req)
+	require.NoError(t, err)
+	res, err := s.ExecutePipeline(context.Background(), time.Now(), pl)
+	require.NoError(t, err)
+
+	require.NoError(t, res.Responses["B"].Error)
+	frames := res.Responses["B"].Frames
+	require.Len(t, frames, 1)
+	frame := frames[0]
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, []interface{}{"a", int64(2), 3.0}, derefRow(frame, 0))
+	require.Equal(t, []interface{}{"b", int64(3), 12.0}, derefRow(frame, 1))
+}
+
+func TestSQLCommandTableNamesAreCaseInsensitive(t *testing.T) {
+	inventory := data.NewFrame("inventory",
+		data.NewField("host", nil, []string{"a", "b"}),
+		data.NewField("team", nil, []string{"red", "blue"}),
+	)
+	s := Service{
+		cfg:               setting.NewCfg(),
+		dataService:       &mockEndpoint{Frames: []*data.Frame{inventory}},
+		dataSourceService: &datafakes.FakeDataSourceService{},
+	}
+
+	req := &Request{Queries: []Query{
+		{
+			RefID: "A",
+			DataSource: &datasources.DataSource{
+				OrgId: 1,
+				Uid:   "mysql",
+				Type:  "mysql",
+			},
+			JSON:      json.RawMessage(`{ "rawSql": "SELECT host, team FROM inventory" }`),
+			TimeRange: AbsoluteTimeRange{},
+		},
+		{
+			RefID:      "B",
+			DataSource: DataSourceModel(),
+			JSON:       json.RawMessage(`{ "type": "sql", "expression": "SELECT host, team FROM a WHERE team = 'red'" }`),
+		},
+	}}
+
+	pl, err := s.BuildPipeline(req)
+	require.NoError(t, err)
+	res, err := s.ExecutePipeline(context.Background(), time.Now(), pl)
+	require.NoError(t, err)
+
+	require.NoError(t, res.Responses["B"].Error)
+	frames := res.Responses["B"].Frames
+	require.Len(t, frames, 1)
+	require.Equal(t, 1, frames[0].Rows())
+	require.Equal(t, []interface{}{"a", "red"}, derefRow(frames[0], 0))
+}
+
+// derefRow returns the values of a row of the frame, with the values of nullable fields dereferenced.
+func derefRow(frame *data.Frame, row int) []interface{} {
+	values := make([]interface{}, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		v, _ := field.ConcreteAt(row)
+		values = append(values, v)
+	}
+	return values
+}
+
+func resultType(res mathexp.Results) string {
+	if len(res.Values) != 1 {
+		return "unknown"
+	}
+	return res.Values[0].Type().String()
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
 
@@ -15,6 +18,21 @@ type ThresholdCommand struct {
 	RefID         string
 	ThresholdFunc string
 	Conditions    []float64
+	// NullPolicy is how the comparisons handle values that are null or NaN.
+	NullPolicy mathexp.NullPolicy
+
+	// Unloading is the optional recovery threshold. When it is set, a series whose labels are in LoadedDimensions
+	// keeps meeting the condition until it meets the recovery threshold, even if it no longer meets ThresholdFunc.
+	Unloading *ThresholdUnloading
+	// LoadedDimensions are the labels of the alert instances that met the condition at the previous evaluation.
+	// A series is loaded if its labels are contained in any of them.
+	LoadedDimensions []data.Labels
+}
+
+// ThresholdUnloading is the recovery threshold of a ThresholdCommand.
+type ThresholdUnloading struct {
+	ThresholdFunc string
+	Conditions    []float64
 }
 
 const (
@@ -34,11 +52,13 @@ func NewThresholdCommand(refID, referenceVar, thresholdFunc string, conditions [
 		ReferenceVar:  referenceVar,
 		ThresholdFunc: thresholdFunc,
 		Conditions:    conditions,
+		NullPolicy:    mathexp.NullPolicyPropagate,
 	}, nil
 }
 
 type ThresholdConditionJSON struct {
-	Evaluator ConditionEvalJSON `json:"evaluator"`
+	Evaluator       ConditionEvalJSON  `json:"evaluator"`
+	UnloadEvaluator *ConditionEvalJSON `json:"unloadEvaluator,omitempty"`
 }
 
 type ConditionEvalJSON struct {
@@ -80,7 +100,100 @@ func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
 	}
 	firstCondition := conditions[0]
 
-	return NewThresholdCommand(rn.RefID, referenceVar, firstCondition.Evaluator.Type, firstCondition.Evaluator.Params)
+	cmd, err := NewThresholdCommand(rn.RefID, referenceVar, firstCondition.Evaluator.Type, firstCondition.Evaluator.Params)
+	if err != nil {
+		return nil, err
+	}
+	cmd.NullPolicy, err = unmarshalNullPolicy(rn)
+	if err != nil {
+		return nil, err
+	}
+	if firstCondition.UnloadEvaluator == nil {
+		return cmd, nil
+	}
+
+	cmd.Unloading = &ThresholdUnloading{
+		ThresholdFunc: firstCondition.UnloadEvaluator.Type,
+		Conditions:    firstCondition.UnloadEvaluator.Params,
+	}
+	if err := validateUnloading(cmd.ThresholdFunc, cmd.Conditions, cmd.Unloading); err != nil {
+		return nil, fmt.Errorf("invalid recovery threshold for refId %v: %w", rn.RefID, err)
+	}
+
+	if rawLoaded, ok := rawQuery[loadedDimensionsKey]; ok && rawLoaded != nil {
+		jsonFromM, err := json.Marshal(rawLoaded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remarshal loaded dimensions: %w", err)
+		}
+		if err = json.Unmarshal(jsonFromM, &cmd.LoadedDimensions); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal loaded dimensions for refId %v: %w", rn.RefID, err)
+		}
+	}
+	return cmd, nil
+}
+
+const loadedDimensionsKey = "loadedDimensions"
+
+// oppositeThresholdFuncs maps each threshold function to the only function accepted as its recovery threshold.
+var oppositeThresholdFuncs = map[string]string{
+	ThresholdIsAbove:        ThresholdIsBelow,
+	ThresholdIsBelow:        ThresholdIsAbove,
+	ThresholdIsWithinRange:  ThresholdIsOutsideRange,
+	ThresholdIsOutsideRange: ThresholdIsWithinRange,
+}
+
+// validateUnloading checks that the recovery threshold is the opposite of the threshold function, and that
+// there is no value that meets both, so a firing series can not flap between the two.
+func validateUnloading(thresholdFunc string, conditions []float64, unloading *ThresholdUnloading) error {
+	expected := oppositeThresholdFuncs[thresholdFunc]
+	if unloading.ThresholdFunc != expected {
+		return fmt.Errorf("expected the recovery threshold of function %s to be %s, got %s", thresholdFunc, expected, unloading.ThresholdFunc)
+	}
+	params := 1
+	if thresholdFunc == ThresholdIsWithinRange || thresholdFunc == ThresholdIsOutsideRange {
+		params = 2
+	}
+	if len(conditions) < params || len(unloading.Conditions) < params {
+		return fmt.Errorf("function %s requires %d parameters", thresholdFunc, params)
+	}
+	load, unload := conditions, unloading.Conditions
+	var ok bool
+	switch thresholdFunc {
+	case ThresholdIsAbove:
+		ok = unload[0] <= load[0]
+	case ThresholdIsBelow:
+		ok = unload[0] >= load[0]
+	case ThresholdIsWithinRange:
+		ok = unload[0] <= load[0] && unload[1] >= load[1]
+	case ThresholdIsOutsideRange:
+		ok = unload[0] >= load[0] && unload[1] <= load[1]
+	}
+	if !ok {
+		return fmt.Errorf("the recovery threshold %s %v overlaps the threshold %s %v", unloading.ThresholdFunc, unload[:params], thresholdFunc, load[:params])
+	}
+	return nil
+}
+
+// IsHysteresisExpression returns true if the model is a threshold expression that has a recovery threshold.
+func IsHysteresisExpression(model map[string]interface{}) bool {
+	if t, _ := model["type"].(string); t != TypeThreshold.String() {
+		return false
+	}
+	conditions, ok := model["conditions"].([]interface{})
+	if !ok || len(conditions) == 0 {
+		return false
+	}
+	condition, ok := conditions[0].(map[string]interface{})
+	return ok && condition["unloadEvaluator"] != nil
+}
+
+// SetLoadedDimensions sets the labels of the alert instances that met the condition at the previous
+// evaluation to the model of a threshold expression that has a recovery threshold.
+func SetLoadedDimensions(model map[string]interface{}, loaded []data.Labels) {
+	if loaded == nil {
+		loaded = []data.Labels{}
+	}
+	model[loadedDimensionsKey] = loaded
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -90,15 +203,72 @@ func (tc *ThresholdCommand) NeedsVars() []string {
 }
 
 func (tc *ThresholdCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars) (mathexp.Results, error) {
-	mathExpression, err := createMathExpression(tc.ReferenceVar, tc.ThresholdFunc, tc.Conditions)
+	loading, err := executeThreshold(ctx, now, vars, tc.ReferenceVar, tc.ThresholdFunc, tc.Conditions, tc.NullPolicy)
+	if err != nil || tc.Unloading == nil || len(tc.LoadedDimensions) == 0 {
+		return loading, err
+	}
+
+	unloading, err := executeThreshold(ctx, now, vars, tc.ReferenceVar, tc.Unloading.ThresholdFunc, tc.Unloading.Conditions, tc.NullPolicy)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	if len(unloading.Values) != len(loading.Values) {
+		return mathexp.Results{}, fmt.Errorf("failed to evaluate recovery threshold: expected %d results, got %d", len(loading.Values), len(unloading.Values))
+	}
+
+	// both results are computed from the same variable, so they have the same order.
+	for i, val := range loading.Values {
+		if !tc.isLoaded(val.GetLabels()) {
+			continue
+		}
+		// a loaded series keeps meeting the condition until it meets the recovery threshold.
+		switch v := val.(type) {
+		case mathexp.Number:
+			v.SetValue(notCondition(unloading.Values[i].(mathexp.Number).GetFloat64Value()))
+		case mathexp.Series:
+			u := unloading.Values[i].(mathexp.Series)
+			for p := 0; p < v.Len(); p++ {
+				t, _ := v.GetPoint(p)
+				v.SetPoint(p, t, notCondition(u.GetValue(p)))
+			}
+		}
+	}
+	return loading, nil
+}
+
+// isLoaded returns true if the labels of a series are contained in any of the loaded dimensions.
+func (tc *ThresholdCommand) isLoaded(labels data.Labels) bool {
+	for _, loaded := range tc.LoadedDimensions {
+		if loaded.Contains(labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// notCondition negates the result of a threshold condition. Null and NaN are returned as is.
+func notCondition(f *float64) *float64 {
+	if f == nil || math.IsNaN(*f) {
+		return f
+	}
+	r := float64(0)
+	if *f == 0 {
+		r = 1
+	}
+	return &r
+}
+
+func executeThreshold(ctx context.Context, now time.Time, vars mathexp.Vars, referenceVar, thresholdFunc string, conditions []float64, nullPolicy mathexp.NullPolicy) (mathexp.Results, error) {
+	mathExpression, err := createMathExpression(referenceVar, thresholdFunc, conditions)
 	if err != nil {
 		return mathexp.Results{}, err
 	}
 
-	mathCommand, err := NewMathCommand(tc.ReferenceVar, mathExpression)
+	mathCommand, err := NewMathCommand(referenceVar, mathExpression)
 	if err != nil {
 		return mathexp.Results{}, err
 	}
+	mathCommand.NullPolicy = nullPolicy
 
 	return mathCommand.Execute(ctx, now, vars)
 }
@@ -1,10 +1,16 @@
 package expr
 
 import (
+	"context"
 	"encoding/json"
+	"math"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
 
 func TestNewThresholdCommand(t *testing.T) {
@@ -71,6 +77,63 @@ func TestUnmarshalThresholdCommand(t *testing.T) {
 			shouldError:   true,
 			expectedError: "expected threshold function to be one of",
 		},
+		{
+			description: "unmarshal with recovery threshold",
+			query: `{
+				"expression" : "A",
+				"type": "threshold",
+				"conditions": [{
+					"evaluator": {
+						"type": "gt",
+						"params": [90]
+					},
+					"unloadEvaluator": {
+						"type": "lt",
+						"params": [80]
+					}
+				}],
+				"loadedDimensions": [{"host": "a"}]
+			}`,
+			shouldError: false,
+		},
+		{
+			description: "unmarshal with recovery threshold of the wrong function",
+			query: `{
+				"expression" : "A",
+				"type": "threshold",
+				"conditions": [{
+					"evaluator": {
+						"type": "gt",
+						"params": [90]
+					},
+					"unloadEvaluator": {
+						"type": "gt",
+						"params": [80]
+					}
+				}]
+			}`,
+			shouldError:   true,
+			expectedError: "expected the recovery threshold of function gt to be lt",
+		},
+		{
+			description: "unmarshal with recovery threshold that overlaps the threshold",
+			query: `{
+				"expression" : "A",
+				"type": "threshold",
+				"conditions": [{
+					"evaluator": {
+						"type": "within_range",
+						"params": [20, 80]
+					},
+					"unloadEvaluator": {
+						"type": "outside_range",
+						"params": [30, 70]
+					}
+				}]
+			}`,
+			shouldError:   true,
+			expectedError: "overlaps the threshold",
+		},
 		{
 			description: "unmarshal with bad expression",
 			query: `{
@@ -81,6 +144,22 @@ func TestUnmarshalThresholdCommand(t *testing.T) {
 			shouldError:   true,
 			expectedError: "expected threshold variable to be a string",
 		},
+		{
+			description: "unmarshal with unknown null policy",
+			query: `{
+				"expression" : "A",
+				"type": "threshold",
+				"nullPolicy": "ignore",
+				"conditions": [{
+					"evaluator": {
+						"type": "gt",
+						"params": [20]
+					}
+				}]
+			}`,
+			shouldError:   true,
+			expectedError: "expected null policy to be one of",
+		},
 	}
 
 	for _, tc := range cases {
@@ -107,6 +186,110 @@ func TestUnmarshalThresholdCommand(t *testing.T) {
 	}
 }
 
+func TestThresholdCommandHysteresis(t *testing.T) {
+	vars := mathexp.Vars{
+		"A": mathexp.Results{
+			Values: []mathexp.Value{
+				newNumberWithLabels(data.Labels{"host": "a"}, 85),
+				newNumberWithLabels(data.Labels{"host": "b"}, 85),
+				newNumberWithLabels(data.Labels{"host": "c"}, 75),
+				newNumberWithLabels(data.Labels{"host": "d"}, 95),
+			},
+		},
+	}
+	cmd, err := NewThresholdCommand("B", "A", ThresholdIsAbove, []float64{90})
+	require.NoError(t, err)
+	cmd.Unloading = &ThresholdUnloading{ThresholdFunc: ThresholdIsBelow, Conditions: []float64{80}}
+
+	t.Run("without loaded dimensions only the threshold is used", func(t *testing.T) {
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Equal(t, []float64{0, 0, 0, 1}, numberValues(t, res))
+	})
+
+	t.Run("loaded series keep firing until they meet the recovery threshold", func(t *testing.T) {
+		cmd.LoadedDimensions = []data.Labels{
+			{"host": "a", "alertname": "test"},
+			{"host": "c", "alertname": "test"},
+		}
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Equal(t, []float64{1, 0, 0, 1}, numberValues(t, res))
+	})
+}
+
+func TestThresholdCommandNullPolicy(t *testing.T) {
+	nan := math.NaN()
+	vars := mathexp.Vars{
+		"A": mathexp.Results{
+			Values: []mathexp.Value{
+				newNumberWithLabels(data.Labels{"host": "a"}, 5),
+				mathexp.NewNumber("", data.Labels{"host": "b"}),
+				newNumberWithLabels(data.Labels{"host": "c"}, nan),
+			},
+		},
+	}
+	var model map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"expression" : "A",
+		"type": "threshold",
+		"nullPolicy": "treatAsFalse",
+		"conditions": [{
+			"evaluator": { "type": "lt", "params": [10] }
+		}]
+	}`), &model))
+	cmd, err := UnmarshalThresholdCommand(&rawNode{RefID: "B", Query: model})
+	require.NoError(t, err)
+	require.Equal(t, mathexp.NullPolicyTreatAsFalse, cmd.NullPolicy)
+
+	res, err := cmd.Execute(context.Background(), time.Now(), vars)
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 0, 0}, numberValues(t, res))
+
+	cmd.NullPolicy = mathexp.NullPolicyTreatAsZero
+	res, err = cmd.Execute(context.Background(), time.Now(), vars)
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 1, 1}, numberValues(t, res))
+}
+
+func newNumberWithLabels(labels data.Labels, f float64) mathexp.Number {
+	n := mathexp.NewNumber("", labels)
+	n.SetValue(&f)
+	return n
+}
+
+func numberValues(t *testing.T, res mathexp.Results) []float64 {
+	t.Helper()
+	values := make([]float64, 0, len(res.Values))
+	for _, v := range res.Values {
+		n, ok := v.(mathexp.Number)
+		require.True(t, ok)
+		require.NotNil(t, n.GetFloat64Value())
+		values = append(values, *n.GetFloat64Value())
+	}
+	return values
+}
+
+func TestSetLoadedDimensions(t *testing.T) {
+	var model map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"expression" : "A",
+		"type": "threshold",
+		"conditions": [{
+			"evaluator": { "type": "gt", "params": [90] },
+			"unloadEvaluator": { "type": "lt", "params": [80] }
+		}]
+	}`), &model))
+	require.True(t, IsHysteresisExpression(model))
+
+	SetLoadedDimensions(model, []data.Labels{{"host": "a"}})
+	cmd, err := UnmarshalThresholdCommand(&rawNode{RefID: "B", Query: model})
+	require.NoError(t, err)
+	require.Equal(t, []data.Labels{{"host": "a"}}, cmd.LoadedDimensions)
+
+	require.False(t, IsHysteresisExpression(map[string]interface{}{"type": "math", "expression": "$A"}))
+}
+
 func TestThresholdCommandVars(t *testing.T) {
 	cmd, err := NewThresholdCommand("B", "A", "is_above", []float64{})
 	require.Nil(t, err)
@@ -0,0 +1,149 @@
+package expr
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+const (
+	// traceMaxFrames is the maximum number of frames of each node in a trace.
+	traceMaxFrames = 10
+	// traceMaxRows is the maximum number of rows of each frame in a trace.
+	traceMaxRows = 20
+)
+
+// Trace is the explanation of the execution of a data pipeline. It has the dependency graph
+// of the pipeline, and the inputs, results, and execution time of each node in execution order.
+type Trace struct {
+	mu    sync.Mutex
+	Nodes []NodeTrace `json:"nodes"`
+	Edges []TraceEdge `json:"edges"`
+}
+
+// TraceEdge is an edge of the dependency graph of a data pipeline. The node From is an input of the node To.
+type TraceEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// NodeTrace is the execution of a single node of a data pipeline.
+type NodeTrace struct {
+	RefID       string   `json:"refId"`
+	NodeType    string   `json:"nodeType"`
+	CommandType string   `json:"commandType,omitempty"`
+	Datasource  string   `json:"datasource,omitempty"`
+	Inputs      []string `json:"inputs"`
+	// DurationMs is the execution time of the node in milliseconds.
+	DurationMs float64 `json:"durationMs"`
+	// SeriesCount is the number of values (series, numbers, or tables) that the node returned.
+	SeriesCount int `json:"seriesCount"`
+	// Labels are the label sets of the values that the node returned.
+	Labels []string `json:"labels"`
+	// Frames are the results of the node, limited to the first rows of the first frames.
+	Frames    []*data.Frame `json:"frames"`
+	Truncated bool          `json:"truncated"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// TracedResponse is the response of a query in debug mode, which has the trace of
+// the execution of the expressions next to the results.
+type TracedResponse struct {
+	Results backend.Responses `json:"results"`
+	Trace   *Trace            `json:"trace"`
+}
+
+type traceKey struct{}
+
+// WithTrace returns a context that collects the trace of the data pipelines executed with it.
+// The trace is complete once the execution returns, even when it fails, and has the nodes
+// executed until the failure.
+func WithTrace(ctx context.Context) (context.Context, *Trace) {
+	t := &Trace{Nodes: []NodeTrace{}, Edges: []TraceEdge{}}
+	return context.WithValue(ctx, traceKey{}, t), t
+}
+
+// traceFromContext returns the trace of the context, or nil if the context does not collect a trace.
+func traceFromContext(ctx context.Context) *Trace {
+	t, _ := ctx.Value(traceKey{}).(*Trace)
+	return t
+}
+
+// addNode adds the execution of the node to the trace. It is safe to call on a nil trace.
+func (t *Trace) addNode(node Node, res mathexp.Results, err error, duration time.Duration) {
+	if t == nil {
+		return
+	}
+
+	nt := NodeTrace{
+		RefID:      node.RefID(),
+		NodeType:   node.NodeType().String(),
+		Inputs:     []string{},
+		DurationMs: float64(duration.Nanoseconds()) / float64(time.Millisecond),
+		Labels:     []string{},
+		Frames:     []*data.Frame{},
+	}
+	switch n := node.(type) {
+	case *CMDNode:
+		nt.CommandType = n.CMDType.String()
+		nt.Inputs = append(nt.Inputs, n.Command.NeedsVars()...)
+	case *DSNode:
+		if n.datasource != nil {
+			nt.Datasource = n.datasource.Uid
+		}
+	}
+	if err != nil {
+		nt.Error = err.Error()
+	} else {
+		nt.SeriesCount = len(res.Values)
+		for _, v := range res.Values {
+			if labels := v.GetLabels(); labels != nil {
+				nt.Labels = append(nt.Labels, labels.String())
+			}
+		}
+		nt.Frames, nt.Truncated = truncateFrames(res.Values.AsDataFrames(node.RefID()))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Nodes = append(t.Nodes, nt)
+	for _, input := range nt.Inputs {
+		t.Edges = append(t.Edges, TraceEdge{From: input, To: nt.RefID})
+	}
+}
+
+// truncateFrames returns copies of the first traceMaxFrames frames with their first traceMaxRows rows,
+// and whether any frame or row was left out.
+func truncateFrames(frames data.Frames) ([]*data.Frame, bool) {
+	truncated := len(frames) > traceMaxFrames
+	if truncated {
+		frames = frames[:traceMaxFrames]
+	}
+
+	res := make([]*data.Frame, 0, len(frames))
+	for _, frame := range frames {
+		rows, err := frame.RowLen()
+		if err != nil {
+			// fields of different lengths can not be copied by row
+			truncated = true
+			res = append(res, frame.EmptyCopy())
+			continue
+		}
+		if rows <= traceMaxRows {
+			res = append(res, frame)
+			continue
+		}
+		truncated = true
+		copied := frame.EmptyCopy()
+		for i := 0; i < traceMaxRows; i++ {
+			copied.AppendRow(frame.RowCopy(i)...)
+		}
+		res = append(res, copied)
+	}
+	return res, truncated
+}
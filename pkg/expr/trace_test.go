@@ -0,0 +1,117 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datafakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestPipelineTrace(t *testing.T) {
+	times := make([]time.Time, 30)
+	values := make([]*float64, 30)
+	for i := range times {
+		times[i] = time.Unix(int64(i)*60, 0)
+		values[i] = fp(float64(i))
+	}
+	s := Service{
+		cfg: setting.NewCfg(),
+		dataService: &mockEndpoint{Frames: []*data.Frame{
+			data.NewFrame("",
+				data.NewField("time", nil, times),
+				data.NewField("value", data.Labels{"host": "a"}, values),
+			),
+		}},
+		dataSourceService: &datafakes.FakeDataSourceService{},
+	}
+
+	queries := []Query{
+		{
+			RefID: "A",
+			DataSource: &datasources.DataSource{
+				OrgId: 1,
+				Uid:   "test",
+				Type:  "test",
+			},
+			JSON:      json.RawMessage(`{ "datasource": { "uid": "1" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+			TimeRange: AbsoluteTimeRange{},
+		},
+		{
+			RefID:      "B",
+			DataSource: DataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "reduce", "expression": "$A", "reducer": "last" }`),
+		},
+		{
+			RefID:      "C",
+			DataSource: DataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$B * $A" }`),
+		},
+	}
+
+	execute := func(t *testing.T, ctx context.Context, queries []Query) error {
+		pl, err := s.BuildPipeline(&Request{Queries: queries})
+		require.NoError(t, err)
+		_, err = s.ExecutePipeline(ctx, time.Now(), pl)
+		return err
+	}
+
+	t.Run("collects the execution of each node", func(t *testing.T) {
+		ctx, trace := WithTrace(context.Background())
+		require.NoError(t, execute(t, ctx, queries))
+
+		require.Len(t, trace.Nodes, 3)
+		ds, reduce, math := trace.Nodes[0], trace.Nodes[1], trace.Nodes[2]
+
+		require.Equal(t, "A", ds.RefID)
+		require.Equal(t, "Datasource", ds.NodeType)
+		require.Equal(t, "test", ds.Datasource)
+		require.Empty(t, ds.Inputs)
+		require.Equal(t, 1, ds.SeriesCount)
+		require.Equal(t, []string{"host=a"}, ds.Labels)
+		require.True(t, ds.Truncated)
+		require.Len(t, ds.Frames, 1)
+		require.Equal(t, traceMaxRows, ds.Frames[0].Rows())
+
+		require.Equal(t, "B", reduce.RefID)
+		require.Equal(t, "Expression", reduce.NodeType)
+		require.Equal(t, "reduce", reduce.CommandType)
+		require.Equal(t, []string{"A"}, reduce.Inputs)
+		require.False(t, reduce.Truncated)
+		require.GreaterOrEqual(t, reduce.DurationMs, 0.0)
+
+		require.Equal(t, "C", math.RefID)
+		require.ElementsMatch(t, []string{"A", "B"}, math.Inputs)
+		require.ElementsMatch(t, []TraceEdge{{From: "A", To: "B"}, {From: "A", To: "C"}, {From: "B", To: "C"}}, trace.Edges)
+
+		_, err := json.Marshal(TracedResponse{Trace: trace})
+		require.NoError(t, err)
+	})
+
+	t.Run("has the nodes executed until the failure", func(t *testing.T) {
+		ctx, trace := WithTrace(context.Background())
+		err := execute(t, ctx, append(queries[:2:2], Query{
+			RefID:      "C",
+			DataSource: DataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "resample", "expression": "$B", "window": "1m", "downsampler": "mean", "upsampler": "fillna" }`),
+			TimeRange:  AbsoluteTimeRange{},
+		}))
+		require.Error(t, err)
+
+		require.Len(t, trace.Nodes, 3)
+		require.Empty(t, trace.Nodes[1].Error)
+		require.NotEmpty(t, trace.Nodes[2].Error)
+		require.Zero(t, trace.Nodes[2].SeriesCount)
+	})
+
+	t.Run("is not collected without a trace in the context", func(t *testing.T) {
+		require.Nil(t, traceFromContext(context.Background()))
+		require.NoError(t, execute(t, context.Background(), queries))
+	})
+}
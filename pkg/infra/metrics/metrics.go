@@ -108,6 +108,15 @@ var (
 
 	// MPublicDashboardDatasourceQuerySuccess is a metric counter for successful queries labelled by datasource
 	MPublicDashboardDatasourceQuerySuccess *prometheus.CounterVec
+
+	// MQueryDataCoalesced is a metric counter for data source queries that joined identical in-flight queries labelled by datasource
+	MQueryDataCoalesced *prometheus.CounterVec
+
+	// MQueryLimitRejections is a metric counter for data source requests rejected by the query limits labelled by limit
+	MQueryLimitRejections *prometheus.CounterVec
+
+	// MQueryConcurrentRequests is a metric gauge for concurrent data source requests labelled by datasource
+	MQueryConcurrentRequests *prometheus.GaugeVec
 )
 
 // Timers
@@ -426,6 +435,24 @@ func init() {
 		Namespace: ExporterName,
 	}, []string{"datasource", "status"}, map[string][]string{"status": pubdash.QueryResultStatuses})
 
+	MQueryDataCoalesced = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "query_data_coalesced_total",
+		Help:      "counter for data source queries that were served by an identical in-flight query labelled by datasource type",
+		Namespace: ExporterName,
+	}, []string{"datasource"})
+
+	MQueryLimitRejections = metricutil.NewCounterVecStartingAtZero(prometheus.CounterOpts{
+		Name:      "query_limit_rejections_total",
+		Help:      "counter for data source requests rejected by the query limits labelled by limit",
+		Namespace: ExporterName,
+	}, []string{"limit"}, map[string][]string{"limit": {"queries_per_request", "time_range", "user_rate", "org_concurrency", "datasource_concurrency"}})
+
+	MQueryConcurrentRequests = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "query_concurrent_requests",
+		Help:      "number of concurrent data source requests labelled by datasource type",
+		Namespace: ExporterName,
+	}, []string{"datasource"})
+
 	MStatTotalDashboards = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name:      "stat_totals_dashboard",
 		Help:      "total amount of dashboards",
@@ -680,5 +707,8 @@ func initMetricVars() {
 		MStatTotalPublicDashboards,
 		MPublicDashboardRequestCount,
 		MPublicDashboardDatasourceQuerySuccess,
+		MQueryDataCoalesced,
+		MQueryLimitRejections,
+		MQueryConcurrentRequests,
 	)
 }
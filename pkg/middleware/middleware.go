@@ -22,7 +22,9 @@ var (
 )
 
 func HandleNoCacheHeader(ctx *models.ReqContext) {
-	ctx.SkipCache = ctx.Req.Header.Get("X-Grafana-NoCache") == "true"
+	if ctx.Req.Header.Get("X-Grafana-NoCache") == "true" {
+		ctx.SkipCache = true
+	}
 }
 
 func AddDefaultResponseHeaders(cfg *setting.Cfg) web.Handler {
@@ -85,6 +85,22 @@ func TestMiddleWareSecurityHeaders(t *testing.T) {
 	})
 }
 
+func TestMiddlewareSkipCache(t *testing.T) {
+	middlewareScenario(t, "middleware should not skip the cache by default", func(t *testing.T, sc *scenarioContext) {
+		sc.fakeReq("GET", "/").exec()
+		assert.False(t, sc.context.SkipCache)
+	})
+
+	for _, header := range []string{contexthandler.HeaderCacheSkip, "X-Grafana-NoCache"} {
+		middlewareScenario(t, fmt.Sprintf("middleware should skip the cache if the request has the %s header", header), func(t *testing.T, sc *scenarioContext) {
+			sc.fakeReq("GET", "/")
+			sc.req.Header.Set(header, "true")
+			sc.exec()
+			assert.True(t, sc.context.SkipCache)
+		})
+	}
+}
+
 func TestMiddleWareContentSecurityPolicyHeaders(t *testing.T) {
 	policy := `script-src 'self' 'strict-dynamic' 'nonce-[^']+';connect-src 'self' ws://localhost:3000/ wss://localhost:3000/;`
 
@@ -839,6 +855,7 @@ func middlewareScenario(t *testing.T, desc string, fn scenarioFunc, cbs ...func(
 		sc.contextHandler = ctxHdlr
 		sc.m.Use(ctxHdlr.Middleware)
 		sc.m.Use(OrgRedirect(sc.cfg, sc.userService))
+		sc.m.Use(HandleNoCacheHeader)
 
 		sc.userAuthTokenService = ctxHdlr.AuthTokenService.(*authtest.FakeUserAuthTokenService)
 		sc.jwtAuthService = ctxHdlr.JWTAuthService.(*models.FakeJWTService)
@@ -46,6 +46,10 @@ const (
 
 const ServiceName = "ContextHandler"
 
+// HeaderCacheSkip is the request header that makes the request skip the caches, for example of the responses of
+// data source queries, when it is set to true.
+const HeaderCacheSkip = "X-Cache-Skip"
+
 func ProvideService(cfg *setting.Cfg, tokenService auth.UserTokenService, jwtService models.JWTService,
 	remoteCache *remotecache.RemoteCache, renderService rendering.Service, sqlStore db.DB,
 	tracer tracing.Tracer, authProxy *authproxy.AuthProxy, loginService login.Service,
@@ -119,7 +123,7 @@ func (h *ContextHandler) Middleware(next http.Handler) http.Handler {
 			SignedInUser:   &user.SignedInUser{},
 			IsSignedIn:     false,
 			AllowAnonymous: false,
-			SkipCache:      false,
+			SkipCache:      r.Header.Get(HeaderCacheSkip) == "true",
 			Logger:         log.New("context"),
 		}
 
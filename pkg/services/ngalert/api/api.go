@@ -45,6 +45,8 @@ type Alertmanager interface {
 	DeleteSilence(silenceID string) error
 	GetSilence(silenceID string) (apimodels.GettableSilence, error)
 	ListSilences(filter []string) (apimodels.GettableSilences, error)
+	ExpireSilences(filter []string) ([]string, error)
+	ExtendSilences(filter []string, d time.Duration) ([]string, error)
 
 	// Alerts
 	GetAlerts(active, silenced, inhibited bool, filter []string, receiver string) (apimodels.GettableAlerts, error)
@@ -53,6 +55,9 @@ type Alertmanager interface {
 	// Receivers
 	GetReceivers(ctx context.Context) []apimodels.Receiver
 	TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigBodyParams) (*notifier.TestReceiversResult, error)
+
+	// Routes
+	TestRoutes(ctx context.Context, c apimodels.TestRoutesConfigBodyParams) (*apimodels.TestRoutesResult, error)
 }
 
 type AlertingStore interface {
@@ -75,11 +80,13 @@ type API struct {
 	DataProxy            *datasourceproxy.DataSourceProxyService
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
 	StateManager         *state.Manager
+	StateHistory         state.HistoryReader
 	AccessControl        accesscontrol.AccessControl
 	Policies             *provisioning.NotificationPolicyService
 	ContactPointService  *provisioning.ContactPointService
 	Templates            *provisioning.TemplateService
 	MuteTimings          *provisioning.MuteTimingService
+	Silences             *provisioning.SilenceService
 	AlertRules           *provisioning.AlertRuleService
 	AlertsRouter         *sender.AlertsRouter
 	EvaluatorFactory     eval.EvaluatorFactory
@@ -100,7 +107,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 	api.RegisterAlertmanagerApiEndpoints(NewForkingAM(
 		api.DatasourceCache,
 		NewLotexAM(proxy, logger),
-		&AlertmanagerSrv{crypto: api.MultiOrgAlertmanager.Crypto, log: logger, ac: api.AccessControl, mam: api.MultiOrgAlertmanager},
+		&AlertmanagerSrv{crypto: api.MultiOrgAlertmanager.Crypto, log: logger, ac: api.AccessControl, mam: api.MultiOrgAlertmanager, silences: api.Silences},
 	), m)
 	// Register endpoints for proxying to Prometheus-compatible backends.
 	api.RegisterPrometheusApiEndpoints(NewForkingProm(
@@ -134,6 +141,9 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			cfg:             &api.Cfg.UnifiedAlerting,
 			backtesting:     backtesting.NewEngine(api.AppUrl, api.EvaluatorFactory),
 			featureManager:  api.FeatureManager,
+			ruleStore:       api.RuleStore,
+			stateManager:    api.StateManager,
+			policies:        api.Policies,
 		}), m)
 	api.RegisterConfigurationApiEndpoints(NewConfiguration(
 		&ConfigSrv{
@@ -144,12 +154,20 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		},
 	), m)
 
+	api.RegisterHistoryApiEndpoints(NewStateHistoryApi(&HistorySrv{
+		logger: logger,
+		hist:   api.StateHistory,
+		store:  api.RuleStore,
+		ac:     api.AccessControl,
+	}), m)
+
 	api.RegisterProvisioningApiEndpoints(NewProvisioningApi(&ProvisioningSrv{
 		log:                 logger,
 		policies:            api.Policies,
 		contactPointService: api.ContactPointService,
 		templates:           api.Templates,
 		muteTimings:         api.MuteTimings,
+		silences:            api.Silences,
 		alertRules:          api.AlertRules,
 	}), m)
 }
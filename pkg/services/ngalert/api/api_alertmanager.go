@@ -27,10 +27,11 @@ const (
 )
 
 type AlertmanagerSrv struct {
-	log    log.Logger
-	ac     accesscontrol.AccessControl
-	mam    *notifier.MultiOrgAlertmanager
-	crypto notifier.Crypto
+	log      log.Logger
+	ac       accesscontrol.AccessControl
+	mam      *notifier.MultiOrgAlertmanager
+	crypto   notifier.Crypto
+	silences SilenceService
 }
 
 type UnknownReceiverError struct {
@@ -105,6 +106,65 @@ func (srv AlertmanagerSrv) RouteDeleteAlertingConfig(c *models.ReqContext) respo
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "configuration deleted; the default is applied"})
 }
 
+func (srv AlertmanagerSrv) RouteCreateSilenceFromTemplate(c *models.ReqContext, name string) response.Response {
+	template, err := srv.silences.GetSilenceTemplate(c.Req.Context(), name, c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the silence template")
+	}
+	if template == nil {
+		return ErrResp(http.StatusNotFound, fmt.Errorf("silence template %s not found", name), "")
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgID)
+	if errResp != nil {
+		return errResp
+	}
+
+	postableSilence := template.Silence(time.Now(), c.SignedInUser.Login)
+	silenceID, err := am.CreateSilence(&postableSilence)
+	if err != nil {
+		if errors.Is(err, notifier.ErrCreateSilenceBadPayload) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to create silence")
+	}
+	return response.JSON(http.StatusAccepted, apimodels.PostSilencesOKBody{
+		SilenceID: silenceID,
+	})
+}
+
+func (srv AlertmanagerSrv) RoutePostSilencesExpire(c *models.ReqContext, body apimodels.PostableBulkSilences) response.Response {
+	am, errResp := srv.AlertmanagerFor(c.OrgID)
+	if errResp != nil {
+		return errResp
+	}
+
+	ids, err := am.ExpireSilences(body.Filter)
+	if err != nil {
+		if errors.Is(err, notifier.ErrBulkSilencesBadPayload) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to expire silences")
+	}
+	return response.JSON(http.StatusOK, apimodels.BulkSilencesResult{SilenceIDs: ids})
+}
+
+func (srv AlertmanagerSrv) RoutePostSilencesExtend(c *models.ReqContext, body apimodels.PostableBulkSilences) response.Response {
+	am, errResp := srv.AlertmanagerFor(c.OrgID)
+	if errResp != nil {
+		return errResp
+	}
+
+	ids, err := am.ExtendSilences(body.Filter, time.Duration(body.Duration))
+	if err != nil {
+		if errors.Is(err, notifier.ErrBulkSilencesBadPayload) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to extend silences")
+	}
+	return response.JSON(http.StatusOK, apimodels.BulkSilencesResult{SilenceIDs: ids})
+}
+
 func (srv AlertmanagerSrv) RouteDeleteSilence(c *models.ReqContext, silenceID string) response.Response {
 	am, errResp := srv.AlertmanagerFor(c.OrgID)
 	if errResp != nil {
@@ -296,6 +356,27 @@ func (srv AlertmanagerSrv) RoutePostTestReceivers(c *models.ReqContext, body api
 	return response.JSON(statusForTestReceivers(result.Receivers), newTestReceiversResult(result))
 }
 
+func (srv AlertmanagerSrv) RoutePostTestRoutes(c *models.ReqContext, body apimodels.TestRoutesConfigBodyParams) response.Response {
+	if len(body.Labels) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("at least one label set is required"), "")
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgID)
+	if errResp != nil {
+		return errResp
+	}
+
+	result, err := am.TestRoutes(c.Req.Context(), body)
+	if err != nil {
+		if errors.Is(err, notifier.ErrAlertmanagerNotReady) {
+			return ErrResp(http.StatusConflict, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to test the routes")
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
 // contextWithTimeoutFromRequest returns a context with a deadline set from the
 // Request-Timeout header in the HTTP request. If the header is absent then the
 // context will use the default timeout. The timeout in the Request-Timeout
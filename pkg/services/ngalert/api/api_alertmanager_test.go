@@ -10,7 +10,9 @@ import (
 
 	"github.com/go-openapi/strfmt"
 	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/api/response"
@@ -290,6 +292,95 @@ func TestAlertmanagerConfig(t *testing.T) {
 	})
 }
 
+func TestRoutePostTestRoutes(t *testing.T) {
+	sut := createSut(t, nil)
+
+	t.Run("assert 400 Bad Request when there are no label sets", func(t *testing.T) {
+		response := sut.RoutePostTestRoutes(createRequestCtxInOrg(1), apimodels.TestRoutesConfigBodyParams{})
+
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("assert 404 Not Found when the org does not exist", func(t *testing.T) {
+		body := apimodels.TestRoutesConfigBodyParams{Labels: []model.LabelSet{{"alertname": "test"}}}
+
+		response := sut.RoutePostTestRoutes(createRequestCtxInOrg(12), body)
+
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("assert 200 with the route of the label set", func(t *testing.T) {
+		body := apimodels.TestRoutesConfigBodyParams{Labels: []model.LabelSet{{"alertname": "test"}}}
+
+		response := sut.RoutePostTestRoutes(createRequestCtxInOrg(1), body)
+
+		require.Equal(t, http.StatusOK, response.Status())
+		var result apimodels.TestRoutesResult
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result.Alerts, 1)
+		require.Len(t, result.Alerts[0].Routes, 1)
+		require.Equal(t, "grafana-default-email", result.Alerts[0].Routes[0].Receiver)
+	})
+}
+
+func TestRouteCreateSilenceFromTemplate(t *testing.T) {
+	sut := createSut(t, nil)
+	silenceStore := notifier.NewFakeConfigStore(t, nil)
+	sut.silences = provisioning.NewSilenceService(&silenceStore, provisioning.NewFakeProvisioningStore(), &provisioning.NopTransactionManager{}, log.NewNopLogger())
+	_, err := sut.silences.CreateSilenceTemplate(context.Background(), apimodels.SilenceTemplate{
+		Name:     "maintenance",
+		Matchers: apimodels.ObjectMatchers{{Type: labels.MatchEqual, Name: "team", Value: "a"}},
+		Duration: model.Duration(time.Hour),
+	}, 1)
+	require.NoError(t, err)
+
+	t.Run("assert 404 Not Found when the template does not exist", func(t *testing.T) {
+		response := sut.RouteCreateSilenceFromTemplate(createRequestCtxInOrg(1), "does not exist")
+
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("assert 202 Accepted with the silence of the template", func(t *testing.T) {
+		rc := createRequestCtxInOrg(1)
+		rc.SignedInUser.Login = "editor"
+
+		response := sut.RouteCreateSilenceFromTemplate(rc, "maintenance")
+
+		require.Equal(t, http.StatusAccepted, response.Status())
+		var body apimodels.PostSilencesOKBody
+		require.NoError(t, json.Unmarshal(response.Body(), &body))
+		am, err := sut.mam.AlertmanagerFor(1)
+		require.NoError(t, err)
+		silence, err := am.GetSilence(body.SilenceID)
+		require.NoError(t, err)
+		require.Equal(t, "editor", *silence.CreatedBy)
+		require.Equal(t, "Created from the silence template maintenance", *silence.Comment)
+		require.WithinDuration(t, time.Time(*silence.StartsAt).Add(time.Hour), time.Time(*silence.EndsAt), time.Second)
+	})
+}
+
+func TestRoutePostSilencesExpireAndExtend(t *testing.T) {
+	sut := createSut(t, nil)
+
+	t.Run("assert 400 Bad Request when the filter is empty", func(t *testing.T) {
+		response := sut.RoutePostSilencesExpire(createRequestCtxInOrg(1), apimodels.PostableBulkSilences{})
+		require.Equal(t, http.StatusBadRequest, response.Status())
+
+		response = sut.RoutePostSilencesExtend(createRequestCtxInOrg(1), apimodels.PostableBulkSilences{Filter: []string{"team=a"}})
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("assert 200 with the IDs of the matching silences", func(t *testing.T) {
+		response := sut.RoutePostSilencesExtend(createRequestCtxInOrg(1), apimodels.PostableBulkSilences{
+			Filter:   []string{"team=a"},
+			Duration: model.Duration(time.Hour),
+		})
+
+		require.Equal(t, http.StatusOK, response.Status())
+		require.JSONEq(t, `{"silenceIDs":[]}`, string(response.Body()))
+	})
+}
+
 func TestSilenceCreate(t *testing.T) {
 	makeSilence := func(comment string, createdBy string,
 		startsAt, endsAt strfmt.DateTime, matchers amv2.Matchers) amv2.Silence {
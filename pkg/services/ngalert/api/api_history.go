@@ -0,0 +1,118 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// labelQueryPrefix is the prefix of the query parameters that filter the alert instances by label.
+const labelQueryPrefix = "labels_"
+
+type HistorySrv struct {
+	logger log.Logger
+	// hist is nil if the state history is disabled.
+	hist  state.HistoryReader
+	store RuleStore
+	ac    accesscontrol.AccessControl
+}
+
+func (srv *HistorySrv) RouteQueryStateHistory(c *models.ReqContext) response.Response {
+	if srv.hist == nil {
+		return ErrResp(http.StatusNotFound, errors.New("state history is not enabled"), "")
+	}
+
+	query, err := parseHistoryQuery(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	q := ngmodels.GetAlertRulesGroupByRuleUIDQuery{UID: query.RuleUID, OrgID: c.OrgID}
+	if err := srv.store.GetAlertRulesGroupByRuleUID(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rule")
+	}
+	var rule *ngmodels.AlertRule
+	for _, r := range q.Result {
+		if r.UID == query.RuleUID {
+			rule = r
+			break
+		}
+	}
+	if rule == nil {
+		return ErrResp(http.StatusNotFound, fmt.Errorf("alert rule %s not found", query.RuleUID), "")
+	}
+
+	namespaces, err := srv.store.GetUserVisibleNamespaces(c.Req.Context(), c.OrgID, c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+	if _, ok := namespaces[rule.NamespaceUID]; !ok {
+		return ErrResp(http.StatusNotFound, fmt.Errorf("alert rule %s not found", query.RuleUID), "")
+	}
+
+	hasAccess := func(evaluator accesscontrol.Evaluator) bool {
+		return accesscontrol.HasAccess(srv.ac, c)(accesscontrol.ReqViewer, evaluator)
+	}
+	if !authorizeDatasourceAccessForRule(rule, hasAccess) {
+		return ErrResp(http.StatusUnauthorized, fmt.Errorf("%w to access the history of the rule because the user does not have read permissions for one or many datasources the rule uses", ErrAuthorization), "")
+	}
+
+	frames, err := srv.hist.QueryStates(c.Req.Context(), query)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to query state history")
+	}
+	return response.JSON(http.StatusOK, apimodels.StateHistory(frames))
+}
+
+func parseHistoryQuery(c *models.ReqContext) (ngmodels.HistoryQuery, error) {
+	query := ngmodels.HistoryQuery{
+		OrgID:   c.OrgID,
+		RuleUID: c.Query("ruleUID"),
+		Labels:  make(map[string]string),
+	}
+	if query.RuleUID == "" {
+		return query, errors.New("ruleUID is required")
+	}
+
+	params := c.Req.URL.Query()
+	for _, p := range []struct {
+		name string
+		to   *time.Time
+	}{{"from", &query.From}, {"to", &query.To}} {
+		if v := params.Get(p.name); v != "" {
+			ms, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return query, fmt.Errorf("%s must be a time in milliseconds since the epoch: %w", p.name, err)
+			}
+			*p.to = time.UnixMilli(ms)
+		}
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return query, errors.New("to must not be before from")
+	}
+	if v := params.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			return query, fmt.Errorf("limit must be a positive number, got %s", v)
+		}
+		query.Limit = limit
+	}
+
+	for k, v := range params {
+		if name := strings.TrimPrefix(k, labelQueryPrefix); name != k && name != "" && len(v) > 0 {
+			query.Labels[name] = v[0]
+		}
+	}
+	return query, nil
+}
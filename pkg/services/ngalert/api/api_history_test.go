@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	acMock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+type fakeHistoryReader struct {
+	queries []models.HistoryQuery
+}
+
+func (f *fakeHistoryReader) QueryStates(_ context.Context, query models.HistoryQuery) (data.Frames, error) {
+	f.queries = append(f.queries, query)
+	return data.Frames{data.NewFrame("")}, nil
+}
+
+func TestRouteQueryStateHistory(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+	rule := models.AlertRuleGen(withOrgID(orgID), withNamespace(folder))()
+	ruleStore.PutRule(context.Background(), rule)
+
+	query := func(srv *HistorySrv, params url.Values) (int, *fakeHistoryReader) {
+		hist := &fakeHistoryReader{}
+		if srv.hist != nil {
+			srv.hist = hist
+		}
+		c := createRequestContext(orgID, "", nil)
+		c.Req.URL.RawQuery = params.Encode()
+		return srv.RouteQueryStateHistory(c).Status(), hist
+	}
+	newSrv := func() *HistorySrv {
+		return &HistorySrv{
+			logger: log.NewNopLogger(),
+			hist:   &fakeHistoryReader{},
+			store:  ruleStore,
+			ac:     acMock.New().WithPermissions(createPermissionsForRules([]*models.AlertRule{rule})),
+		}
+	}
+
+	t.Run("queries the history of the rule", func(t *testing.T) {
+		status, hist := query(newSrv(), url.Values{
+			"ruleUID":     {rule.UID},
+			"from":        {"1000"},
+			"to":          {"2000"},
+			"limit":       {"10"},
+			"labels_host": {"a"},
+		})
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, []models.HistoryQuery{{
+			OrgID:   orgID,
+			RuleUID: rule.UID,
+			Labels:  map[string]string{"host": "a"},
+			From:    time.UnixMilli(1000),
+			To:      time.UnixMilli(2000),
+			Limit:   10,
+		}}, hist.queries)
+	})
+
+	t.Run("returns 400 if the parameters are invalid", func(t *testing.T) {
+		for _, params := range []url.Values{
+			{},
+			{"ruleUID": {rule.UID}, "from": {"yesterday"}},
+			{"ruleUID": {rule.UID}, "from": {"2000"}, "to": {"1000"}},
+			{"ruleUID": {rule.UID}, "limit": {"-1"}},
+		} {
+			status, hist := query(newSrv(), params)
+			require.Equal(t, http.StatusBadRequest, status, params)
+			require.Empty(t, hist.queries)
+		}
+	})
+
+	t.Run("returns 404 if the rule does not exist", func(t *testing.T) {
+		status, hist := query(newSrv(), url.Values{"ruleUID": {"unknown"}})
+		require.Equal(t, http.StatusNotFound, status)
+		require.Empty(t, hist.queries)
+	})
+
+	t.Run("returns 401 if the user does not have access to the data sources of the rule", func(t *testing.T) {
+		srv := newSrv()
+		srv.ac = acMock.New()
+		status, hist := query(srv, url.Values{"ruleUID": {rule.UID}})
+		require.Equal(t, http.StatusUnauthorized, status)
+		require.Empty(t, hist.queries)
+	})
+
+	t.Run("returns 404 if the state history is disabled", func(t *testing.T) {
+		srv := newSrv()
+		srv.hist = nil
+		status, _ := query(srv, url.Values{"ruleUID": {rule.UID}})
+		require.Equal(t, http.StatusNotFound, status)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"sort"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -12,6 +13,7 @@ import (
 	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -23,6 +25,7 @@ type ProvisioningSrv struct {
 	contactPointService ContactPointService
 	templates           TemplateService
 	muteTimings         MuteTimingService
+	silences            SilenceService
 	alertRules          AlertRuleService
 }
 
@@ -52,6 +55,19 @@ type MuteTimingService interface {
 	DeleteMuteTiming(ctx context.Context, name string, orgID int64) error
 }
 
+type SilenceService interface {
+	GetSilenceTemplates(ctx context.Context, orgID int64) ([]definitions.SilenceTemplate, error)
+	GetSilenceTemplate(ctx context.Context, name string, orgID int64) (*definitions.SilenceTemplate, error)
+	CreateSilenceTemplate(ctx context.Context, t definitions.SilenceTemplate, orgID int64) (*definitions.SilenceTemplate, error)
+	UpdateSilenceTemplate(ctx context.Context, t definitions.SilenceTemplate, orgID int64) (*definitions.SilenceTemplate, error)
+	DeleteSilenceTemplate(ctx context.Context, name string, orgID int64) error
+	GetRecurringSilences(ctx context.Context, orgID int64) ([]definitions.RecurringSilence, error)
+	GetRecurringSilence(ctx context.Context, name string, orgID int64) (*definitions.RecurringSilence, error)
+	CreateRecurringSilence(ctx context.Context, s definitions.RecurringSilence, orgID int64) (*definitions.RecurringSilence, error)
+	UpdateRecurringSilence(ctx context.Context, s definitions.RecurringSilence, orgID int64) (*definitions.RecurringSilence, error)
+	DeleteRecurringSilence(ctx context.Context, name string, orgID int64) error
+}
+
 type AlertRuleService interface {
 	GetAlertRules(ctx context.Context, orgID int64) ([]*alerting_models.AlertRule, error)
 	GetAlertRule(ctx context.Context, orgID int64, ruleUID string) (alerting_models.AlertRule, alerting_models.Provenance, error)
@@ -59,6 +75,7 @@ type AlertRuleService interface {
 	UpdateAlertRule(ctx context.Context, rule alerting_models.AlertRule, provenance alerting_models.Provenance) (alerting_models.AlertRule, error)
 	DeleteAlertRule(ctx context.Context, orgID int64, ruleUID string, provenance alerting_models.Provenance) error
 	GetRuleGroup(ctx context.Context, orgID int64, folder, group string) (alerting_models.AlertRuleGroup, error)
+	GetAlertGroupsWithFolderTitle(ctx context.Context, orgID int64, folderUIDs []string, group string) ([]alerting_models.AlertRuleGroupWithFolderTitle, error)
 	ReplaceRuleGroup(ctx context.Context, orgID int64, group alerting_models.AlertRuleGroup, userID int64, provenance alerting_models.Provenance) error
 }
 
@@ -74,6 +91,20 @@ func (srv *ProvisioningSrv) RouteGetPolicyTree(c *models.ReqContext) response.Re
 	return response.JSON(http.StatusOK, policies)
 }
 
+func (srv *ProvisioningSrv) RouteGetPolicyTreeExport(c *models.ReqContext) response.Response {
+	policies, err := srv.policies.GetPolicyTree(c.Req.Context(), c.OrgID)
+	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	return exportResponse(c, definitions.AlertingFileExport{
+		Policies: []definitions.NotificationPolicyExport{definitions.NewNotificationPolicyExport(c.OrgID, policies)},
+	})
+}
+
 func (srv *ProvisioningSrv) RoutePutPolicyTree(c *models.ReqContext, tree definitions.Route) response.Response {
 	err := srv.policies.UpdatePolicyTree(c.Req.Context(), c.OrgID, tree, alerting_models.ProvenanceAPI)
 	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
@@ -109,6 +140,25 @@ func (srv *ProvisioningSrv) RouteGetContactPoints(c *models.ReqContext) response
 	return response.JSON(http.StatusOK, cps)
 }
 
+func (srv *ProvisioningSrv) RouteGetContactPointsExport(c *models.ReqContext) response.Response {
+	q := provisioning.ContactPointQuery{
+		OrgID:   c.OrgID,
+		Decrypt: c.QueryBool("decrypt"),
+	}
+	if q.Decrypt && c.OrgRole != org.RoleAdmin {
+		return ErrResp(http.StatusForbidden, errors.New("only organization admins can export the secure settings of contact points"), "")
+	}
+	cps, err := srv.contactPointService.GetContactPoints(c.Req.Context(), q)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	exports, err := definitions.NewContactPointExports(c.OrgID, cps)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return exportResponse(c, definitions.AlertingFileExport{ContactPoints: exports})
+}
+
 func (srv *ProvisioningSrv) RoutePostContactPoint(c *models.ReqContext, cp definitions.EmbeddedContactPoint) response.Response {
 	// TODO: provenance is hardcoded for now, change it later to make it more flexible
 	contactPoint, err := srv.contactPointService.CreateContactPoint(c.Req.Context(), c.OrgID, cp, alerting_models.ProvenanceAPI)
@@ -156,6 +206,23 @@ func (srv *ProvisioningSrv) RouteGetTemplates(c *models.ReqContext) response.Res
 	return response.JSON(http.StatusOK, result)
 }
 
+func (srv *ProvisioningSrv) RouteGetTemplatesExport(c *models.ReqContext) response.Response {
+	templates, err := srv.templates.GetTemplates(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]definitions.MessageTemplateExport, 0, len(templates))
+	for _, name := range names {
+		result = append(result, definitions.NewMessageTemplateExport(c.OrgID, definitions.MessageTemplate{Name: name, Template: templates[name]}))
+	}
+	return exportResponse(c, definitions.AlertingFileExport{Templates: result})
+}
+
 func (srv *ProvisioningSrv) RouteGetTemplate(c *models.ReqContext, name string) response.Response {
 	templates, err := srv.templates.GetTemplates(c.Req.Context(), c.OrgID)
 	if err != nil {
@@ -212,6 +279,18 @@ func (srv *ProvisioningSrv) RouteGetMuteTimings(c *models.ReqContext) response.R
 	return response.JSON(http.StatusOK, timings)
 }
 
+func (srv *ProvisioningSrv) RouteGetMuteTimingsExport(c *models.ReqContext) response.Response {
+	timings, err := srv.muteTimings.GetMuteTimings(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	result := make([]definitions.MuteTimeIntervalExport, 0, len(timings))
+	for _, timing := range timings {
+		result = append(result, definitions.NewMuteTimeIntervalExport(c.OrgID, timing))
+	}
+	return exportResponse(c, definitions.AlertingFileExport{MuteTimes: result})
+}
+
 func (srv *ProvisioningSrv) RoutePostMuteTiming(c *models.ReqContext, mt definitions.MuteTimeInterval) response.Response {
 	mt.Provenance = alerting_models.ProvenanceAPI
 	created, err := srv.muteTimings.CreateMuteTiming(c.Req.Context(), mt, c.OrgID)
@@ -248,6 +327,128 @@ func (srv *ProvisioningSrv) RouteDeleteMuteTiming(c *models.ReqContext, name str
 	return response.JSON(http.StatusNoContent, nil)
 }
 
+func (srv *ProvisioningSrv) RouteGetSilenceTemplates(c *models.ReqContext) response.Response {
+	templates, err := srv.silences.GetSilenceTemplates(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, templates)
+}
+
+func (srv *ProvisioningSrv) RouteGetSilenceTemplate(c *models.ReqContext, name string) response.Response {
+	template, err := srv.silences.GetSilenceTemplate(c.Req.Context(), name, c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	if template == nil {
+		return response.Empty(http.StatusNotFound)
+	}
+	return response.JSON(http.StatusOK, template)
+}
+
+func (srv *ProvisioningSrv) RoutePostSilenceTemplate(c *models.ReqContext, t definitions.SilenceTemplate) response.Response {
+	t.Provenance = alerting_models.ProvenanceAPI
+	created, err := srv.silences.CreateSilenceTemplate(c.Req.Context(), t, c.OrgID)
+	if err != nil {
+		if errors.Is(err, provisioning.ErrValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusCreated, created)
+}
+
+func (srv *ProvisioningSrv) RoutePutSilenceTemplate(c *models.ReqContext, t definitions.SilenceTemplate, name string) response.Response {
+	t.Name = name
+	t.Provenance = alerting_models.ProvenanceAPI
+	updated, err := srv.silences.UpdateSilenceTemplate(c.Req.Context(), t, c.OrgID)
+	if err != nil {
+		if errors.Is(err, provisioning.ErrValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	if updated == nil {
+		return response.Empty(http.StatusNotFound)
+	}
+	return response.JSON(http.StatusAccepted, updated)
+}
+
+func (srv *ProvisioningSrv) RouteDeleteSilenceTemplate(c *models.ReqContext, name string) response.Response {
+	err := srv.silences.DeleteSilenceTemplate(c.Req.Context(), name, c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusNoContent, nil)
+}
+
+func (srv *ProvisioningSrv) RouteGetRecurringSilences(c *models.ReqContext) response.Response {
+	silences, err := srv.silences.GetRecurringSilences(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, silences)
+}
+
+func (srv *ProvisioningSrv) RouteGetRecurringSilencesExport(c *models.ReqContext) response.Response {
+	silences, err := srv.silences.GetRecurringSilences(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	result := make([]definitions.RecurringSilenceExport, 0, len(silences))
+	for _, s := range silences {
+		result = append(result, definitions.NewRecurringSilenceExport(c.OrgID, s))
+	}
+	return exportResponse(c, definitions.AlertingFileExport{RecurringSilences: result})
+}
+
+func (srv *ProvisioningSrv) RouteGetRecurringSilence(c *models.ReqContext, name string) response.Response {
+	silence, err := srv.silences.GetRecurringSilence(c.Req.Context(), name, c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	if silence == nil {
+		return response.Empty(http.StatusNotFound)
+	}
+	return response.JSON(http.StatusOK, silence)
+}
+
+func (srv *ProvisioningSrv) RoutePostRecurringSilence(c *models.ReqContext, s definitions.RecurringSilence) response.Response {
+	s.Provenance = alerting_models.ProvenanceAPI
+	created, err := srv.silences.CreateRecurringSilence(c.Req.Context(), s, c.OrgID)
+	if err != nil {
+		if errors.Is(err, provisioning.ErrValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusCreated, created)
+}
+
+func (srv *ProvisioningSrv) RoutePutRecurringSilence(c *models.ReqContext, s definitions.RecurringSilence, name string) response.Response {
+	s.Name = name
+	s.Provenance = alerting_models.ProvenanceAPI
+	updated, err := srv.silences.UpdateRecurringSilence(c.Req.Context(), s, c.OrgID)
+	if err != nil {
+		if errors.Is(err, provisioning.ErrValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	if updated == nil {
+		return response.Empty(http.StatusNotFound)
+	}
+	return response.JSON(http.StatusAccepted, updated)
+}
+
+func (srv *ProvisioningSrv) RouteDeleteRecurringSilence(c *models.ReqContext, name string) response.Response {
+	err := srv.silences.DeleteRecurringSilence(c.Req.Context(), name, c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusNoContent, nil)
+}
+
 func (srv *ProvisioningSrv) RouteGetAlertRules(c *models.ReqContext) response.Response {
 	rules, err := srv.alertRules.GetAlertRules(c.Req.Context(), c.OrgID)
 	if err != nil {
@@ -256,6 +457,19 @@ func (srv *ProvisioningSrv) RouteGetAlertRules(c *models.ReqContext) response.Re
 	return response.JSON(http.StatusOK, definitions.NewAlertRules(rules))
 }
 
+func (srv *ProvisioningSrv) RouteGetAlertRulesExport(c *models.ReqContext) response.Response {
+	folderUID := c.Query("folderUid")
+	group := c.Query("group")
+	if group != "" && folderUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("folderUid must be specified when group is specified"), "")
+	}
+	var folderUIDs []string
+	if folderUID != "" {
+		folderUIDs = []string{folderUID}
+	}
+	return srv.exportAlertRules(c, folderUIDs, group)
+}
+
 func (srv *ProvisioningSrv) RouteRouteGetAlertRule(c *models.ReqContext, UID string) response.Response {
 	rule, provenace, err := srv.alertRules.GetAlertRule(c.Req.Context(), c.OrgID, UID)
 	if err != nil {
@@ -334,6 +548,34 @@ func (srv *ProvisioningSrv) RouteGetAlertRuleGroup(c *models.ReqContext, folder
 	return response.JSON(http.StatusOK, definitions.NewAlertRuleGroupFromModel(g))
 }
 
+func (srv *ProvisioningSrv) RouteGetAlertRuleGroupExport(c *models.ReqContext, folder string, group string) response.Response {
+	return srv.exportAlertRules(c, []string{folder}, group)
+}
+
+// exportAlertRules exports the rule groups of the given folders in the provisioning file format.
+// If no folder is given, all groups of the organization are exported.
+func (srv *ProvisioningSrv) exportAlertRules(c *models.ReqContext, folderUIDs []string, group string) response.Response {
+	groups, err := srv.alertRules.GetAlertGroupsWithFolderTitle(c.Req.Context(), c.OrgID, folderUIDs, group)
+	if err != nil {
+		if errors.Is(err, provisioning.ErrNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	if group != "" && len(groups) == 0 {
+		return ErrResp(http.StatusNotFound, store.ErrAlertRuleGroupNotFound, "")
+	}
+	result := make([]definitions.AlertRuleGroupExport, 0, len(groups))
+	for _, g := range groups {
+		export, err := definitions.NewAlertRuleGroupExport(g)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		result = append(result, export)
+	}
+	return exportResponse(c, definitions.AlertingFileExport{Groups: result})
+}
+
 func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *models.ReqContext, ag definitions.AlertRuleGroup, folderUID string, group string) response.Response {
 	ag.FolderUID = folderUID
 	ag.Title = group
@@ -5,14 +5,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
 	"time"
 
 	prometheus "github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/alertmanager/timeinterval"
 	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 
+	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -21,6 +26,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	secrets_fakes "github.com/grafana/grafana/pkg/services/secrets/fakes"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -227,6 +233,63 @@ func TestProvisioningApi(t *testing.T) {
 		})
 	})
 
+	t.Run("silence templates", func(t *testing.T) {
+		t.Run("are invalid, POST returns 400", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+
+			response := sut.RoutePostSilenceTemplate(&rc, definitions.SilenceTemplate{Name: "maintenance"})
+
+			require.Equal(t, 400, response.Status())
+			require.Contains(t, string(response.Body()), "invalid")
+		})
+
+		t.Run("are missing, PUT returns 404", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+
+			response := sut.RoutePutSilenceTemplate(&rc, createTestSilenceTemplate(), "does not exist")
+
+			require.Equal(t, 404, response.Status())
+		})
+
+		t.Run("successful POST returns 201 and GET returns the template", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+
+			response := sut.RoutePostSilenceTemplate(&rc, createTestSilenceTemplate())
+			require.Equal(t, 201, response.Status())
+
+			response = sut.RouteGetSilenceTemplate(&rc, "maintenance")
+			require.Equal(t, 200, response.Status())
+			response = sut.RouteGetSilenceTemplate(&rc, "does not exist")
+			require.Equal(t, 404, response.Status())
+		})
+	})
+
+	t.Run("recurring silences", func(t *testing.T) {
+		t.Run("are invalid, POST returns 400", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+			s := createTestRecurringSilence()
+			s.Schedule = "nightly"
+
+			response := sut.RoutePostRecurringSilence(&rc, s)
+
+			require.Equal(t, 400, response.Status())
+			require.Contains(t, string(response.Body()), "invalid")
+		})
+
+		t.Run("are missing, PUT returns 404", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+
+			response := sut.RoutePutRecurringSilence(&rc, createTestRecurringSilence(), "does not exist")
+
+			require.Equal(t, 404, response.Status())
+		})
+	})
+
 	t.Run("alert rules", func(t *testing.T) {
 		t.Run("are invalid", func(t *testing.T) {
 			t.Run("POST returns 400 on wrong body params", func(t *testing.T) {
@@ -370,6 +433,246 @@ func TestProvisioningApi(t *testing.T) {
 			})
 		})
 	})
+
+	t.Run("exports", func(t *testing.T) {
+		t.Run("alert rules", func(t *testing.T) {
+			env := createTestEnv(t)
+			sut := createProvisioningSrvSutFromEnv(t, &env)
+			createTestFolder(t, env.store, 1, "folder-uid", "Folder Title")
+			rule := createTestAlertRule("rule", 1)
+			rule.Labels = map[string]string{"team": "alerting"}
+			insertRule(t, sut, rule)
+
+			t.Run("GET returns the org in YAML by default", func(t *testing.T) {
+				rc := createTestRequestCtx()
+
+				response := sut.RouteGetAlertRulesExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, "application/yaml", responseHeader(t, response, "Content-Type"))
+				var export definitions.AlertingFileExport
+				require.NoError(t, yaml.Unmarshal(response.Body(), &export))
+				require.Equal(t, int64(1), export.APIVersion)
+				require.Len(t, export.Groups, 1)
+				require.Equal(t, "Folder Title", export.Groups[0].Folder)
+				require.Equal(t, "my-cool-group", export.Groups[0].Name)
+				require.Len(t, export.Groups[0].Rules, 1)
+				require.Equal(t, "rule", export.Groups[0].Rules[0].Title)
+				require.Equal(t, map[string]string{"team": "alerting"}, export.Groups[0].Rules[0].Labels)
+			})
+
+			t.Run("GET returns JSON if requested", func(t *testing.T) {
+				rc := createTestRequestCtx()
+				rc.Req.Form = url.Values{"format": {"json"}, "folderUid": {"folder-uid"}}
+
+				response := sut.RouteGetAlertRulesExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, "application/json", responseHeader(t, response, "Content-Type"))
+				var export definitions.AlertingFileExport
+				require.NoError(t, json.Unmarshal(response.Body(), &export))
+				require.Len(t, export.Groups, 1)
+			})
+
+			t.Run("GET returns HCL if requested", func(t *testing.T) {
+				rc := createTestRequestCtx()
+				rc.Req.Form = url.Values{"format": {"hcl"}}
+
+				response := sut.RouteGetAlertRuleGroupExport(&rc, "folder-uid", "my-cool-group")
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, "text/hcl", responseHeader(t, response, "Content-Type"))
+				require.Contains(t, string(response.Body()), `resource "grafana_rule_group" "rule_group_folder_uid_my_cool_group" {`)
+			})
+
+			t.Run("GET sets attachment header if download is requested", func(t *testing.T) {
+				rc := createTestRequestCtx()
+				rc.Req.Form = url.Values{"download": {"true"}, "format": {"json"}}
+
+				response := sut.RouteGetAlertRulesExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, "attachment;filename=export.json", responseHeader(t, response, "Content-Disposition"))
+			})
+
+			t.Run("GET returns 400 if format is unknown", func(t *testing.T) {
+				rc := createTestRequestCtx()
+				rc.Req.Form = url.Values{"format": {"xml"}}
+
+				response := sut.RouteGetAlertRulesExport(&rc)
+
+				require.Equal(t, 400, response.Status())
+			})
+
+			t.Run("GET returns 400 if group is requested without folder", func(t *testing.T) {
+				rc := createTestRequestCtx()
+				rc.Req.Form = url.Values{"group": {"my-cool-group"}}
+
+				response := sut.RouteGetAlertRulesExport(&rc)
+
+				require.Equal(t, 400, response.Status())
+			})
+
+			t.Run("GET returns 404 if group does not exist", func(t *testing.T) {
+				rc := createTestRequestCtx()
+
+				response := sut.RouteGetAlertRuleGroupExport(&rc, "folder-uid", "does not exist")
+
+				require.Equal(t, 404, response.Status())
+			})
+		})
+
+		t.Run("alert rules without recording rules", func(t *testing.T) {
+			env := createTestEnv(t)
+			sut := createProvisioningSrvSutFromEnv(t, &env)
+			createTestFolder(t, env.store, 1, "folder-uid", "Folder Title")
+			insertRule(t, sut, createTestAlertRule("rule", 1))
+			recording := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(time.Minute), models.WithRecord("my_metric", models.LiveRecordTarget))()
+			recording.NamespaceUID = "folder-uid"
+			recording.RuleGroup = "my-cool-group"
+			_, err := env.store.InsertAlertRules(context.Background(), []models.AlertRule{*recording})
+			require.NoError(t, err)
+
+			rc := createTestRequestCtx()
+
+			response := sut.RouteGetAlertRuleGroupExport(&rc, "folder-uid", "my-cool-group")
+
+			require.Equal(t, 200, response.Status())
+			var export definitions.AlertingFileExport
+			require.NoError(t, yaml.Unmarshal(response.Body(), &export))
+			require.Len(t, export.Groups, 1)
+			require.Len(t, export.Groups[0].Rules, 1)
+			require.Equal(t, "rule", export.Groups[0].Rules[0].Title)
+		})
+
+		t.Run("contact points", func(t *testing.T) {
+			t.Run("GET returns contact points grouped by name", func(t *testing.T) {
+				env := createTestEnv(t)
+				env.prov.(*provisioning.MockProvisioningStore).EXPECT().
+					GetProvenances(mock.Anything, mock.Anything, mock.Anything).
+					Return(map[string]models.Provenance{}, nil)
+				sut := createProvisioningSrvSutFromEnv(t, &env)
+				rc := createTestRequestCtx()
+
+				response := sut.RouteGetContactPointsExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				var export definitions.AlertingFileExport
+				require.NoError(t, yaml.Unmarshal(response.Body(), &export))
+				require.Len(t, export.ContactPoints, 1)
+				require.Equal(t, "email receiver", export.ContactPoints[0].Name)
+				require.Len(t, export.ContactPoints[0].Receivers, 1)
+				require.Equal(t, "email-uid", export.ContactPoints[0].Receivers[0].UID)
+			})
+
+			t.Run("GET returns 403 if decrypt is requested by a non-admin", func(t *testing.T) {
+				env := createTestEnv(t)
+				sut := createProvisioningSrvSutFromEnv(t, &env)
+				rc := createTestRequestCtx()
+				rc.OrgRole = org.RoleEditor
+				rc.Req.Form = url.Values{"decrypt": {"true"}}
+
+				response := sut.RouteGetContactPointsExport(&rc)
+
+				require.Equal(t, 403, response.Status())
+			})
+
+			t.Run("GET returns 200 if decrypt is requested by an admin", func(t *testing.T) {
+				env := createTestEnv(t)
+				sut := createProvisioningSrvSutFromEnv(t, &env)
+				rc := createTestRequestCtx()
+				rc.OrgRole = org.RoleAdmin
+				rc.Req.Form = url.Values{"decrypt": {"true"}}
+
+				response := sut.RouteGetContactPointsExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+			})
+
+			t.Run("GET returns 400 if HCL is requested", func(t *testing.T) {
+				env := createTestEnv(t)
+				env.prov.(*provisioning.MockProvisioningStore).EXPECT().
+					GetProvenances(mock.Anything, mock.Anything, mock.Anything).
+					Return(map[string]models.Provenance{}, nil)
+				sut := createProvisioningSrvSutFromEnv(t, &env)
+				rc := createTestRequestCtx()
+				rc.Req.Form = url.Values{"format": {"hcl"}}
+
+				response := sut.RouteGetContactPointsExport(&rc)
+
+				require.Equal(t, 400, response.Status())
+			})
+		})
+
+		t.Run("policies", func(t *testing.T) {
+			t.Run("GET returns the policy tree", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				response := sut.RouteGetPolicyTreeExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, "apiVersion: 1\npolicies:\n    - orgId: 1\n      receiver: some-receiver\n      continue: false\n", string(response.Body()))
+			})
+
+			t.Run("GET returns 404 if org has no AM config", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+				rc.SignedInUser.OrgID = 2
+
+				response := sut.RouteGetPolicyTreeExport(&rc)
+
+				require.Equal(t, 404, response.Status())
+			})
+		})
+
+		t.Run("mute timings", func(t *testing.T) {
+			t.Run("GET returns mute timings", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				response := sut.RouteGetMuteTimingsExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				var export definitions.AlertingFileExport
+				require.NoError(t, yaml.Unmarshal(response.Body(), &export))
+				require.Len(t, export.MuteTimes, 1)
+				require.Equal(t, "interval", export.MuteTimes[0].Name)
+			})
+		})
+
+		t.Run("recurring silences", func(t *testing.T) {
+			t.Run("GET returns recurring silences", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+				require.Equal(t, 201, sut.RoutePostRecurringSilence(&rc, createTestRecurringSilence()).Status())
+
+				response := sut.RouteGetRecurringSilencesExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				var export definitions.AlertingFileExport
+				require.NoError(t, yaml.Unmarshal(response.Body(), &export))
+				require.Len(t, export.RecurringSilences, 1)
+				require.Equal(t, int64(1), export.RecurringSilences[0].OrgID)
+				require.Equal(t, "nightly-batch", export.RecurringSilences[0].Name)
+				require.Equal(t, "0 2 * * *", export.RecurringSilences[0].Schedule)
+				require.Empty(t, export.RecurringSilences[0].Provenance)
+			})
+		})
+
+		t.Run("templates", func(t *testing.T) {
+			t.Run("GET returns templates", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+				rc.Req.Form = url.Values{"format": {"json"}}
+
+				response := sut.RouteGetTemplatesExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.JSONEq(t, `{"apiVersion":1,"templates":[{"orgId":1,"name":"a","template":"template"}]}`, string(response.Body()))
+			})
+		})
+	})
 }
 
 // testEnvironment binds together common dependencies for testing alerting APIs.
@@ -406,6 +709,7 @@ func createTestEnv(t *testing.T) testEnvironment {
 	prov := &provisioning.MockProvisioningStore{}
 	prov.EXPECT().SaveSucceeds()
 	prov.EXPECT().GetReturns(models.ProvenanceNone)
+	prov.EXPECT().GetProvenances(mock.Anything, mock.Anything, mock.Anything).Return(map[string]models.Provenance{}, nil)
 
 	return testEnvironment{
 		secrets: secrets,
@@ -434,10 +738,34 @@ func createProvisioningSrvSutFromEnv(t *testing.T, env *testEnvironment) Provisi
 		contactPointService: provisioning.NewContactPointService(env.configs, env.secrets, env.prov, env.xact, env.log),
 		templates:           provisioning.NewTemplateService(env.configs, env.prov, env.xact, env.log),
 		muteTimings:         provisioning.NewMuteTimingService(env.configs, env.prov, env.xact, env.log),
+		silences:            provisioning.NewSilenceService(env.store, env.prov, env.xact, env.log),
 		alertRules:          provisioning.NewAlertRuleService(env.store, env.prov, env.quotas, env.xact, 60, 10, env.log),
 	}
 }
 
+func createTestFolder(t *testing.T, store store.DBstore, orgID int64, uid, title string) {
+	t.Helper()
+
+	folder := gfcore.NewDashboardFolder(title)
+	folder.Uid = uid
+	folder.OrgId = orgID
+	folder.Created = time.Now()
+	folder.Updated = time.Now()
+	err := store.SQLStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+		_, err := sess.Insert(folder)
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func responseHeader(t *testing.T, resp response.Response, name string) string {
+	t.Helper()
+
+	normal, ok := resp.(*response.NormalResponse)
+	require.True(t, ok)
+	return normal.Header().Get(name)
+}
+
 func createTestRequestCtx() gfcore.ReqContext {
 	return gfcore.ReqContext{
 		Context: &web.Context{
@@ -523,6 +851,23 @@ func createInvalidContactPoint() definitions.EmbeddedContactPoint {
 	}
 }
 
+func createTestSilenceTemplate() definitions.SilenceTemplate {
+	return definitions.SilenceTemplate{
+		Name:     "maintenance",
+		Matchers: definitions.ObjectMatchers{{Type: labels.MatchEqual, Name: "team", Value: "a"}},
+		Duration: model.Duration(2 * time.Hour),
+	}
+}
+
+func createTestRecurringSilence() definitions.RecurringSilence {
+	return definitions.RecurringSilence{
+		Name:     "nightly-batch",
+		Matchers: definitions.ObjectMatchers{{Type: labels.MatchEqual, Name: "job", Value: "batch"}},
+		Schedule: "0 2 * * *",
+		Duration: model.Duration(2 * time.Hour),
+	}
+}
+
 func createInvalidMuteTiming() definitions.MuteTimeInterval {
 	return definitions.MuteTimeInterval{
 		MuteTimeInterval: prometheus.MuteTimeInterval{
@@ -320,12 +320,44 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		RuleGroup:    ruleGroupConfig.Name,
 	}
 
-	return srv.updateAlertRulesInGroup(c, groupKey, rules)
+	return srv.updateAlertRulesInGroup(c, groupKey, rules, pauseUnspecifiedRules(ruleGroupConfig))
+}
+
+// pauseUnspecifiedRules returns the UIDs of the rules of the group that do not specify whether they are paused,
+// for example because the client does not know the field. These rules keep their stored pause state.
+func pauseUnspecifiedRules(ruleGroupConfig apimodels.PostableRuleGroupConfig) map[string]struct{} {
+	result := make(map[string]struct{})
+	if ruleGroupConfig.IsPaused != nil {
+		return result
+	}
+	for _, r := range ruleGroupConfig.Rules {
+		if r.GrafanaManagedAlert != nil && r.GrafanaManagedAlert.UID != "" && r.GrafanaManagedAlert.IsPaused == nil {
+			result[r.GrafanaManagedAlert.UID] = struct{}{}
+		}
+	}
+	return result
+}
+
+// keepPausedState keeps the stored pause state of the updated rules with the given UIDs.
+// Updates that do not change anything else are dropped.
+func keepPausedState(changes *store.GroupDelta, uids map[string]struct{}) {
+	updates := make([]store.RuleDelta, 0, len(changes.Update))
+	for _, update := range changes.Update {
+		if _, ok := uids[update.New.UID]; ok && update.New.IsPaused != update.Existing.IsPaused {
+			update.New.IsPaused = update.Existing.IsPaused
+			update.Diff = update.Existing.Diff(update.New, store.AlertRuleFieldsToIgnoreInDiff[:]...)
+			if len(update.Diff) == 0 {
+				continue
+			}
+		}
+		updates = append(updates, update)
+	}
+	changes.Update = updates
 }
 
 // updateAlertRulesInGroup calculates changes (rules to add,update,delete), verifies that the user is authorized to do the calculated changes and updates database.
 // All operations are performed in a single transaction
-func (srv RulerSrv) updateAlertRulesInGroup(c *models.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRule) response.Response {
+func (srv RulerSrv) updateAlertRulesInGroup(c *models.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRule, pauseUnspecified map[string]struct{}) response.Response {
 	var finalChanges *store.GroupDelta
 	hasAccess := accesscontrol.HasAccess(srv.ac, c)
 	err := srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
@@ -334,6 +366,7 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *models.ReqContext, groupKey ngmod
 		if err != nil {
 			return err
 		}
+		keepPausedState(groupChanges, pauseUnspecified)
 
 		if groupChanges.IsEmpty() {
 			finalChanges = groupChanges
@@ -426,7 +459,7 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *models.ReqContext, groupKey ngmod
 		srv.scheduleService.UpdateAlertRule(ngmodels.AlertRuleKey{
 			OrgID: c.SignedInUser.OrgID,
 			UID:   rule.Existing.UID,
-		}, rule.Existing.Version+1)
+		}, rule.Existing.Version+1, rule.New.IsPaused)
 	}
 
 	if len(finalChanges.Delete) > 0 {
@@ -457,6 +490,7 @@ func toGettableRuleGroupConfig(groupName string, rules ngmodels.RulesGroup, name
 	return apimodels.GettableRuleGroupConfig{
 		Name:     groupName,
 		Interval: model.Duration(interval),
+		IsPaused: rules.IsPaused(),
 		Rules:    ruleNodes,
 	}
 }
@@ -483,6 +517,9 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64, provena
 			NoDataState:     apimodels.NoDataState(r.NoDataState),
 			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
 			Provenance:      provenance,
+			Record:          r.Record,
+			IsPaused:        r.IsPaused,
+			Enrichments:     r.Enrichments,
 		},
 	}
 	forDuration := model.Duration(r.For)
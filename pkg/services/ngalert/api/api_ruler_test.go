@@ -577,6 +577,50 @@ func TestRouteGetRulesGroupConfig(t *testing.T) {
 	})
 }
 
+func TestKeepPausedState(t *testing.T) {
+	paused := true
+	config := apimodels.PostableRuleGroupConfig{
+		Rules: []apimodels.PostableExtendedRuleNode{
+			{GrafanaManagedAlert: &apimodels.PostableGrafanaRule{UID: "unspecified"}},
+			{GrafanaManagedAlert: &apimodels.PostableGrafanaRule{UID: "paused", IsPaused: &paused}},
+			{GrafanaManagedAlert: &apimodels.PostableGrafanaRule{}},
+		},
+	}
+
+	t.Run("rules that do not specify the pause state keep it", func(t *testing.T) {
+		unspecified := pauseUnspecifiedRules(config)
+		require.Equal(t, map[string]struct{}{"unspecified": {}}, unspecified)
+
+		existing := models.AlertRuleGen(models.WithIsPaused(true))()
+		existing.UID = "unspecified"
+		submitted := models.CopyRule(existing)
+		submitted.IsPaused = false
+		submitted.Title = "new title"
+		existingUnchanged := models.AlertRuleGen(models.WithIsPaused(true))()
+		existingUnchanged.UID = "unspecified"
+		submittedUnchanged := models.CopyRule(existingUnchanged)
+		submittedUnchanged.IsPaused = false
+		changes := &store.GroupDelta{Update: []store.RuleDelta{
+			{Existing: existing, New: submitted, Diff: existing.Diff(submitted)},
+			{Existing: existingUnchanged, New: submittedUnchanged, Diff: existingUnchanged.Diff(submittedUnchanged)},
+		}}
+
+		keepPausedState(changes, unspecified)
+
+		require.Len(t, changes.Update, 1)
+		require.True(t, changes.Update[0].New.IsPaused)
+		require.Equal(t, "new title", changes.Update[0].New.Title)
+		require.Empty(t, changes.Update[0].Diff.GetDiffsForField("IsPaused"))
+		require.NotEmpty(t, changes.Update[0].Diff.GetDiffsForField("Title"))
+	})
+
+	t.Run("the pause state of the group applies to all rules", func(t *testing.T) {
+		config := config
+		config.IsPaused = &paused
+		require.Empty(t, pauseUnspecifiedRules(config))
+	})
+}
+
 func TestVerifyProvisionedRulesNotAffected(t *testing.T) {
 	orgID := rand.Int63()
 	group := models.GenerateGroupKey(orgID)
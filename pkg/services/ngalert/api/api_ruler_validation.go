@@ -76,6 +76,17 @@ func validateRuleNode(
 		}
 	}
 
+	record := ruleNode.GrafanaManagedAlert.Record
+	if record != nil {
+		if err = validateRecord(record, ruleNode.GrafanaManagedAlert.Data, cfg); err != nil {
+			return nil, err
+		}
+		// the condition of a recording rule is the query or expression it records
+		if ruleNode.GrafanaManagedAlert.Condition == "" {
+			ruleNode.GrafanaManagedAlert.Condition = record.From
+		}
+	}
+
 	if len(ruleNode.GrafanaManagedAlert.Data) != 0 {
 		cond := ngmodels.Condition{
 			Condition: ruleNode.GrafanaManagedAlert.Condition,
@@ -86,6 +97,18 @@ func validateRuleNode(
 		}
 	}
 
+	for i := range ruleNode.GrafanaManagedAlert.Enrichments {
+		if err = ruleNode.GrafanaManagedAlert.Enrichments[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	// a rule that does not specify it is not paused, unless it exists, in which case it keeps its stored state
+	isPaused := false
+	if ruleNode.GrafanaManagedAlert.IsPaused != nil {
+		isPaused = *ruleNode.GrafanaManagedAlert.IsPaused
+	}
+
 	newAlertRule := ngmodels.AlertRule{
 		OrgID:           orgId,
 		Title:           ruleNode.GrafanaManagedAlert.Title,
@@ -97,12 +120,21 @@ func validateRuleNode(
 		RuleGroup:       groupName,
 		NoDataState:     noDataState,
 		ExecErrState:    errorState,
+		Record:          record,
+		IsPaused:        isPaused,
+		Enrichments:     ruleNode.GrafanaManagedAlert.Enrichments,
 	}
 
 	newAlertRule.For, err = validateForInterval(ruleNode)
 	if err != nil {
 		return nil, err
 	}
+	if record != nil {
+		if newAlertRule.For > 0 {
+			return nil, fmt.Errorf("%w: field `for` must be 0 for recording rules", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		newAlertRule.For = 0
+	}
 
 	if ruleNode.ApiRuleNode != nil {
 		newAlertRule.Annotations = ruleNode.ApiRuleNode.Annotations
@@ -132,6 +164,17 @@ func validateInterval(cfg *setting.UnifiedAlertingSettings, interval time.Durati
 	return intervalSeconds, nil
 }
 
+// validateRecord validates the record of a recording rule and checks that its target is configured.
+func validateRecord(record *ngmodels.Record, data []ngmodels.AlertQuery, cfg *setting.UnifiedAlertingSettings) error {
+	if err := record.Validate(data); err != nil {
+		return err
+	}
+	if record.Target == ngmodels.RemoteWriteRecordTarget && cfg.RecordingRules.RemoteWriteURL == "" {
+		return fmt.Errorf("%w: record target %s is not configured. Set remote_write_url in the [unified_alerting.recording_rules] section", ngmodels.ErrAlertRuleFailedValidation, record.Target)
+	}
+	return nil
+}
+
 // validateForInterval validates ApiRuleNode.For and converts it to time.Duration. If the field is not specified returns 0 if GrafanaManagedAlert.UID is empty and -1 if it is not.
 func validateForInterval(ruleNode *apimodels.PostableExtendedRuleNode) (time.Duration, error) {
 	if ruleNode.ApiRuleNode == nil || ruleNode.ApiRuleNode.For == nil {
@@ -190,7 +233,14 @@ func validateRuleGroup(
 			uids[rule.UID] = idx
 		}
 		rule.RuleGroupIndex = idx + 1
+		// the pause status of the group takes precedence over the status of its rules
+		if ruleGroupConfig.IsPaused != nil {
+			rule.IsPaused = *ruleGroupConfig.IsPaused
+		}
 		result = append(result, rule)
 	}
+	if err := ngmodels.RulesGroup(result).ValidateDependencies(); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
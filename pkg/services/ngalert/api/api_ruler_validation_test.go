@@ -132,6 +132,109 @@ func TestValidateRuleGroup(t *testing.T) {
 	})
 }
 
+func TestValidateRuleGroup_IsPaused(t *testing.T) {
+	orgId := rand.Int63()
+	folder := randFolder()
+	cfg := config(t)
+
+	paused, running := true, false
+	pausedRule := validRule()
+	pausedRule.GrafanaManagedAlert.IsPaused = &paused
+	runningRule := validRule()
+
+	validate := func(g apimodels.PostableRuleGroupConfig) []*models.AlertRule {
+		alerts, err := validateRuleGroup(&g, orgId, folder, func(condition models.Condition) error {
+			return nil
+		}, cfg)
+		require.NoError(t, err)
+		return alerts
+	}
+
+	t.Run("should use the status of the rules if the group does not specify it", func(t *testing.T) {
+		alerts := validate(validGroup(cfg, pausedRule, runningRule))
+		require.True(t, alerts[0].IsPaused)
+		require.False(t, alerts[1].IsPaused)
+	})
+	t.Run("should pause all rules if the group is paused", func(t *testing.T) {
+		g := validGroup(cfg, pausedRule, runningRule)
+		g.IsPaused = &paused
+		for _, alert := range validate(g) {
+			require.True(t, alert.IsPaused)
+		}
+	})
+	t.Run("should resume all rules if the group is not paused", func(t *testing.T) {
+		g := validGroup(cfg, pausedRule, runningRule)
+		g.IsPaused = &running
+		for _, alert := range validate(g) {
+			require.False(t, alert.IsPaused)
+		}
+	})
+}
+
+func TestValidateRuleNode_Enrichments(t *testing.T) {
+	orgId := rand.Int63()
+	folder := randFolder()
+	cfg := config(t)
+
+	validate := func(enrichments ...models.Enrichment) (*models.AlertRule, error) {
+		r := validRule()
+		r.GrafanaManagedAlert.Enrichments = enrichments
+		return validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval, orgId, folder, func(condition models.Condition) error {
+			return nil
+		}, cfg)
+	}
+
+	t.Run("should keep the enrichments of the rule", func(t *testing.T) {
+		enrichment := models.Enrichment{
+			Type:        models.TeamEnrichmentType,
+			Keys:        []string{"team"},
+			Annotations: map[string]string{"team_email": "email"},
+		}
+		alert, err := validate(enrichment)
+		require.NoError(t, err)
+		require.Equal(t, []models.Enrichment{enrichment}, alert.Enrichments)
+	})
+	t.Run("should fail if an enrichment is invalid", func(t *testing.T) {
+		_, err := validate(models.Enrichment{Type: models.TeamEnrichmentType, Keys: []string{"team"}})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+}
+
+func TestValidateRuleGroup_Dependencies(t *testing.T) {
+	orgId := rand.Int63()
+	folder := randFolder()
+	cfg := config(t)
+
+	dependsOn := func(rule apimodels.PostableExtendedRuleNode, uid string) apimodels.PostableExtendedRuleNode {
+		rule.GrafanaManagedAlert.Data = append(rule.GrafanaManagedAlert.Data, models.CreateRuleStateExpression("B", uid))
+		return rule
+	}
+	validate := func(g apimodels.PostableRuleGroupConfig) error {
+		_, err := validateRuleGroup(&g, orgId, folder, func(condition models.Condition) error {
+			return nil
+		}, cfg)
+		return err
+	}
+
+	network := validRule()
+	database := dependsOn(validRule(), network.GrafanaManagedAlert.UID)
+
+	t.Run("should accept rules that depend on rules of the group", func(t *testing.T) {
+		require.NoError(t, validate(validGroup(cfg, database, network)))
+	})
+	t.Run("should reject rules that depend on rules of other groups", func(t *testing.T) {
+		err := validate(validGroup(cfg, database))
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+	t.Run("should reject rules that depend on each other in a cycle", func(t *testing.T) {
+		cyclic := dependsOn(validRule(), database.GrafanaManagedAlert.UID)
+		cyclic.GrafanaManagedAlert.UID = network.GrafanaManagedAlert.UID
+		err := validate(validGroup(cfg, database, cyclic))
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "cycle")
+	})
+}
+
 func TestValidateRuleGroupFailures(t *testing.T) {
 	orgId := rand.Int63()
 	folder := randFolder()
@@ -438,6 +541,33 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 				return &r
 			},
 		},
+		{
+			name: "fail if record is not valid",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Record = &models.Record{Metric: "not-a-metric", From: "A", Target: models.LiveRecordTarget}
+				*r.ApiRuleNode.For = 0
+				return &r
+			},
+		},
+		{
+			name: "fail if record target is not configured",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Record = &models.Record{Metric: "up", From: "A", Target: models.RemoteWriteRecordTarget}
+				*r.ApiRuleNode.For = 0
+				return &r
+			},
+		},
+		{
+			name: "fail if recording rule has for",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Record = &models.Record{Metric: "up", From: "A", Target: models.LiveRecordTarget}
+				*r.ApiRuleNode.For = model.Duration(time.Minute)
+				return &r
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -465,6 +595,31 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 	}
 }
 
+func TestValidateRuleNode_Record(t *testing.T) {
+	orgId := rand.Int63()
+	folder := randFolder()
+	cfg := config(t)
+	cfg.RecordingRules.RemoteWriteURL = "http://localhost:9090/api/v1/write"
+
+	for _, target := range []models.RecordTarget{models.RemoteWriteRecordTarget, models.LiveRecordTarget} {
+		t.Run(string(target), func(t *testing.T) {
+			r := validRule()
+			r.GrafanaManagedAlert.Condition = ""
+			r.ApiRuleNode.For = nil
+			r.GrafanaManagedAlert.Record = &models.Record{Metric: "job:up:sum", From: "A", Target: target}
+
+			alert, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval, orgId, folder, func(condition models.Condition) error {
+				return nil
+			}, cfg)
+			require.NoError(t, err)
+			require.True(t, alert.IsRecordingRule())
+			require.Equal(t, *r.GrafanaManagedAlert.Record, *alert.Record)
+			require.Equal(t, "A", alert.Condition)
+			require.Equal(t, time.Duration(0), alert.For)
+		})
+	}
+}
+
 func TestValidateRuleNode_UID(t *testing.T) {
 	orgId := rand.Int63()
 	folder := randFolder()
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -20,6 +21,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -33,6 +35,9 @@ type TestingApiSrv struct {
 	cfg             *setting.UnifiedAlertingSettings
 	backtesting     *backtesting.Engine
 	featureManager  featuremgmt.FeatureToggles
+	ruleStore       RuleStore
+	stateManager    *state.Manager
+	policies        NotificationPolicyService
 }
 
 func (srv TestingApiSrv) RouteTestGrafanaRuleConfig(c *models.ReqContext, body apimodels.TestRulePayload) response.Response {
@@ -50,7 +55,7 @@ func (srv TestingApiSrv) RouteTestGrafanaRuleConfig(c *models.ReqContext, body a
 		Condition: body.GrafanaManagedCondition.Condition,
 		Data:      body.GrafanaManagedCondition.Data,
 	}
-	ctx := eval.Context(c.Req.Context(), c.SignedInUser)
+	ctx := srv.evalContext(c)
 
 	conditionEval, err := srv.evaluator.Create(ctx, evalCond)
 	if err != nil {
@@ -62,15 +67,25 @@ func (srv TestingApiSrv) RouteTestGrafanaRuleConfig(c *models.ReqContext, body a
 		now = timeNow()
 	}
 
-	evalResults, err := conditionEval.Evaluate(c.Req.Context(), now)
+	evalCtx := c.Req.Context()
+	var trace *expr.Trace
+	if body.GrafanaManagedCondition.Debug {
+		evalCtx, trace = expr.WithTrace(evalCtx)
+	}
+
+	evalResults, err := conditionEval.Evaluate(evalCtx, now)
 	if err != nil {
 		return ErrResp(500, err, "Failed to evaluate the rule")
 	}
 
 	frame := evalResults.AsDataFrame()
-	return response.JSONStreaming(http.StatusOK, util.DynMap{
+	res := util.DynMap{
 		"instances": []*data.Frame{&frame},
-	})
+	}
+	if trace != nil {
+		res["trace"] = trace
+	}
+	return response.JSONStreaming(http.StatusOK, res)
 }
 
 func (srv TestingApiSrv) RouteTestRuleConfig(c *models.ReqContext, body apimodels.TestRulePayload, datasourceUID string) response.Response {
@@ -125,7 +140,7 @@ func (srv TestingApiSrv) RouteEvalQueries(c *models.ReqContext, cmd apimodels.Ev
 	if len(cmd.Data) > 0 {
 		cond.Condition = cmd.Data[0].RefID
 	}
-	evaluator, err := srv.evaluator.Create(eval.Context(c.Req.Context(), c.SignedInUser), cond)
+	evaluator, err := srv.evaluator.Create(srv.evalContext(c), cond)
 
 	if err != nil {
 		return ErrResp(http.StatusBadRequest, err, "Failed to build evaluator for queries and expressions")
@@ -136,12 +151,24 @@ func (srv TestingApiSrv) RouteEvalQueries(c *models.ReqContext, cmd apimodels.Ev
 		now = timeNow()
 	}
 
-	evalResults, err := evaluator.EvaluateRaw(c.Req.Context(), now)
+	evalCtx := c.Req.Context()
+	var trace *expr.Trace
+	if cmd.Debug {
+		evalCtx, trace = expr.WithTrace(evalCtx)
+	}
+
+	evalResults, err := evaluator.EvaluateRaw(evalCtx, now)
 
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "Failed to evaluate queries and expressions")
 	}
 
+	if trace != nil {
+		return response.JSONStreaming(http.StatusOK, expr.TracedResponse{
+			Results: evalResults.Responses,
+			Trace:   trace,
+		})
+	}
 	return response.JSONStreaming(http.StatusOK, evalResults)
 }
 
@@ -212,3 +239,145 @@ func (srv TestingApiSrv) BacktestAlertRule(c *models.ReqContext, cmd apimodels.B
 	}
 	return response.JSON(http.StatusOK, body)
 }
+
+func (srv TestingApiSrv) BacktestRuleGroup(c *models.ReqContext, cmd apimodels.BacktestGroupConfig) response.Response {
+	if !srv.featureManager.IsEnabled(featuremgmt.FlagAlertingBacktesting) {
+		return ErrResp(http.StatusNotFound, nil, "Backtesting API is not enabled")
+	}
+
+	if cmd.From.After(cmd.To) {
+		return ErrResp(400, nil, "From cannot be greater than To")
+	}
+
+	namespace, err := srv.ruleStore.GetNamespaceByTitle(c.Req.Context(), cmd.Namespace, c.SignedInUser.OrgID, c.SignedInUser, false)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	// the conditions are validated when the rules are evaluated
+	rules, err := validateRuleGroup(&cmd.Group, c.SignedInUser.OrgID, namespace, func(ngmodels.Condition) error {
+		return nil
+	}, srv.cfg)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	hasAccess := func(evaluator accesscontrol.Evaluator) bool {
+		return accesscontrol.HasAccess(srv.accessControl, c)(accesscontrol.ReqSignedIn, evaluator)
+	}
+	for _, rule := range rules {
+		if !authorizeDatasourceAccessForRule(rule, hasAccess) {
+			return errorToResponse(fmt.Errorf("%w to query one or many data sources used by the rule '%s'", ErrAuthorization, rule.Title))
+		}
+		if rule.UID == "" {
+			// prefix backtesting- is to distinguish between executions of regular rule and backtesting in logs
+			rule.UID = "backtesting-" + util.GenerateShortUID()
+		}
+	}
+
+	route := cmd.Route
+	if route == nil {
+		if !hasAccess(accesscontrol.EvalPermission(accesscontrol.ActionAlertingNotificationsRead)) {
+			return errorToResponse(fmt.Errorf("%w to read the notification policy tree", ErrAuthorization))
+		}
+		tree, err := srv.policies.GetPolicyTree(c.Req.Context(), c.SignedInUser.OrgID)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "Failed to get the notification policy tree")
+		}
+		route = &tree
+	}
+
+	folderTitle := namespace.Title
+	if srv.cfg.ReservedLabels.IsReservedLabelDisabled(ngmodels.FolderTitleLabel) {
+		folderTitle = ""
+	}
+
+	result, err := srv.backtesting.TestGroup(c.Req.Context(), c.SignedInUser, rules, folderTitle, route, cmd.From, cmd.To)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(400, err, "Failed to evaluate")
+		}
+		return ErrResp(500, err, "Failed to evaluate")
+	}
+
+	body := apimodels.BacktestGroupResult{
+		Rules:         make([]apimodels.BacktestRuleResult, 0, len(result.States)),
+		Notifications: make([]apimodels.BacktestNotification, 0, len(result.Notifications)),
+		ContactPoints: make([]apimodels.BacktestContactPoint, 0, len(result.ContactPoints)),
+	}
+	for _, rule := range rules {
+		frame, ok := result.States[rule.UID]
+		if !ok {
+			continue
+		}
+		body.Rules = append(body.Rules, apimodels.BacktestRuleResult{
+			UID:    rule.UID,
+			Title:  rule.Title,
+			States: frame,
+		})
+	}
+	for _, n := range result.Notifications {
+		body.Notifications = append(body.Notifications, apimodels.BacktestNotification{
+			Time:         n.Time,
+			ContactPoint: n.ContactPoint,
+			GroupKey:     n.GroupKey,
+			GroupLabels:  n.GroupLabels,
+			Firing:       n.Firing,
+			Resolved:     n.Resolved,
+		})
+	}
+	for _, cp := range result.ContactPoints {
+		body.ContactPoints = append(body.ContactPoints, apimodels.BacktestContactPoint{
+			Name:          cp.ContactPoint,
+			Notifications: cp.Notifications,
+			Groups:        cp.Groups,
+		})
+	}
+	return response.JSON(http.StatusOK, body)
+}
+
+// evalContext creates the evaluation context of the request. If the rule store and the state manager are available,
+// rule state expressions read the current state of the rules that the user can access.
+func (srv TestingApiSrv) evalContext(c *models.ReqContext) eval.EvaluationContext {
+	ctx := eval.Context(c.Req.Context(), c.SignedInUser)
+	if srv.ruleStore != nil && srv.stateManager != nil {
+		ctx.RuleStateReader = ruleStateFromStore{
+			c:             c,
+			store:         srv.ruleStore,
+			manager:       srv.stateManager,
+			accessControl: srv.accessControl,
+		}
+	}
+	return ctx
+}
+
+// ruleStateFromStore provides the state of the alert rules to the rule state expressions of the rules that are tested.
+type ruleStateFromStore struct {
+	c             *models.ReqContext
+	store         RuleStore
+	manager       *state.Manager
+	accessControl accesscontrol.AccessControl
+}
+
+func (r ruleStateFromStore) ReadRuleState(ruleUID string) ([]expr.RuleInstanceState, error) {
+	q := ngmodels.GetAlertRulesGroupByRuleUIDQuery{UID: ruleUID, OrgID: r.c.OrgID}
+	if err := r.store.GetAlertRulesGroupByRuleUID(r.c.Req.Context(), &q); err != nil {
+		return nil, fmt.Errorf("failed to get alert rule %s: %w", ruleUID, err)
+	}
+	var rule *ngmodels.AlertRule
+	for _, rr := range q.Result {
+		if rr.UID == ruleUID {
+			rule = rr
+			break
+		}
+	}
+	if rule == nil {
+		return nil, fmt.Errorf("alert rule %s not found", ruleUID)
+	}
+	if !authorizeDatasourceAccessForRule(rule, func(evaluator accesscontrol.Evaluator) bool {
+		return accesscontrol.HasAccess(r.accessControl, r.c)(accesscontrol.ReqViewer, evaluator)
+	}) {
+		return nil, fmt.Errorf("%w to read the state of rule %s because the user does not have read permissions for one or many datasources the rule uses", ErrAuthorization, ruleUID)
+	}
+	return r.manager.GetRuleInstanceStates(rule), nil
+}
@@ -49,7 +49,7 @@ func TestAuthorize(t *testing.T) {
 		}
 		paths[p] = methods
 	}
-	require.Len(t, paths, 41)
+	require.Len(t, paths, 58)
 
 	ac := acmock.New()
 	api := &API{AccessControl: ac}
@@ -448,6 +448,32 @@ func TestCheckDatasourcePermissionsForRule(t *testing.T) {
 		require.False(t, eval)
 		require.Equal(t, 1, executed)
 	})
+
+	t.Run("should check data sources of SQL enrichments", func(t *testing.T) {
+		enriched := models.CopyRule(rule)
+		enriched.Enrichments = []models.Enrichment{
+			{Type: models.TableEnrichmentType},
+			{Type: models.SQLEnrichmentType, DatasourceUID: "lookup"},
+		}
+		lookupScope := datasources.ScopeProvider.GetResourceScopeUID("lookup")
+
+		permissions := map[string][]string{
+			datasources.ActionQuery: scopes,
+		}
+		eval := authorizeDatasourceAccessForRule(enriched, func(evaluator ac.Evaluator) bool {
+			return evaluator.Evaluate(permissions)
+		})
+		require.False(t, eval)
+
+		permissions[datasources.ActionQuery] = append(scopes, lookupScope)
+		executed := 0
+		eval = authorizeDatasourceAccessForRule(enriched, func(evaluator ac.Evaluator) bool {
+			executed++
+			return evaluator.Evaluate(permissions)
+		})
+		require.True(t, eval)
+		require.Equal(t, expectedExecutions+1, executed)
+	})
 }
 
 func Test_authorizeAccessToRuleGroup(t *testing.T) {
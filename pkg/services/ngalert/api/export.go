@@ -0,0 +1,238 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const exportFileName = "export"
+
+// exportFileAPIVersion is the version of the provisioning file format that is produced by the export.
+const exportFileAPIVersion = 1
+
+var (
+	errHCLNotSupported = errors.New("format 'hcl' is supported only for alert rules")
+
+	hclIdentifierRegex      = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+	hclInvalidResourceChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+)
+
+type exportFormat string
+
+const (
+	exportFormatYAML exportFormat = "yaml"
+	exportFormatJSON exportFormat = "json"
+	exportFormatHCL  exportFormat = "hcl"
+)
+
+// parseExportFormat returns the format requested by the query parameter "format". It defaults to YAML.
+func parseExportFormat(c *models.ReqContext) (exportFormat, error) {
+	switch f := exportFormat(strings.ToLower(c.Query("format"))); f {
+	case "":
+		return exportFormatYAML, nil
+	case exportFormatYAML, exportFormatJSON, exportFormatHCL:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported export format '%s', expected one of: yaml, json, hcl", f)
+	}
+}
+
+// exportResponse serializes the export in the format requested by the query parameter "format".
+// If the query parameter "download" is true, the response instructs the browser to save it as a file.
+func exportResponse(c *models.ReqContext, body definitions.AlertingFileExport) response.Response {
+	format, err := parseExportFormat(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	body.APIVersion = exportFileAPIVersion
+
+	var data []byte
+	var contentType string
+	switch format {
+	case exportFormatJSON:
+		contentType = "application/json"
+		data, err = json.MarshalIndent(body, "", "  ")
+	case exportFormatHCL:
+		if len(body.ContactPoints) > 0 || len(body.Policies) > 0 || len(body.MuteTimes) > 0 || len(body.Templates) > 0 {
+			return ErrResp(http.StatusBadRequest, errHCLNotSupported, "")
+		}
+		contentType = "text/hcl"
+		data = encodeAlertRuleGroupsHCL(body.Groups)
+	default:
+		contentType = "application/yaml"
+		data, err = yaml.Marshal(body)
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to serialize export")
+	}
+
+	resp := response.Respond(http.StatusOK, data).SetHeader("Content-Type", contentType)
+	if c.QueryBool("download") {
+		resp.SetHeader("Content-Disposition", fmt.Sprintf(`attachment;filename=%s.%s`, exportFileName, format))
+	}
+	return resp
+}
+
+// encodeAlertRuleGroupsHCL writes the rule groups as resources of the Grafana Terraform provider.
+func encodeAlertRuleGroupsHCL(groups []definitions.AlertRuleGroupExport) []byte {
+	w := &hclWriter{}
+	for i, group := range groups {
+		if i > 0 {
+			w.newLine()
+		}
+		name := "rule_group_" + strings.Trim(hclInvalidResourceChars.ReplaceAllString(strings.ToLower(group.FolderUID+"_"+group.Name), "_"), "_")
+		w.block(fmt.Sprintf("resource %q %q", "grafana_rule_group", name), func() {
+			w.attribute("org_id", strconv.FormatInt(group.OrgID, 10))
+			w.attribute("name", hclString(group.Name))
+			w.attribute("folder_uid", hclString(group.FolderUID))
+			w.attribute("interval_seconds", strconv.FormatInt(int64(time.Duration(group.Interval).Seconds()), 10))
+			for _, rule := range group.Rules {
+				w.newLine()
+				w.rule(rule)
+			}
+		})
+	}
+	return w.buf.Bytes()
+}
+
+type hclWriter struct {
+	buf    bytes.Buffer
+	indent int
+}
+
+func (w *hclWriter) newLine() {
+	w.buf.WriteString("\n")
+}
+
+func (w *hclWriter) line(s string) {
+	w.buf.WriteString(strings.Repeat("  ", w.indent))
+	w.buf.WriteString(s)
+	w.newLine()
+}
+
+func (w *hclWriter) attribute(name string, value string) {
+	w.line(name + " = " + value)
+}
+
+func (w *hclWriter) block(header string, body func()) {
+	w.line(header + " {")
+	w.indent++
+	body()
+	w.indent--
+	w.line("}")
+}
+
+func (w *hclWriter) rule(rule definitions.AlertRuleExport) {
+	w.block("rule", func() {
+		w.attribute("uid", hclString(rule.UID))
+		w.attribute("name", hclString(rule.Title))
+		w.attribute("condition", hclString(rule.Condition))
+		w.attribute("for", hclString(rule.For.String()))
+		w.attribute("no_data_state", hclString(string(rule.NoDataState)))
+		w.attribute("exec_err_state", hclString(string(rule.ExecErrState)))
+		w.attribute("is_paused", strconv.FormatBool(rule.IsPaused))
+		if len(rule.Annotations) > 0 {
+			w.attribute("annotations", w.value(stringMapToInterface(rule.Annotations)))
+		}
+		if len(rule.Labels) > 0 {
+			w.attribute("labels", w.value(stringMapToInterface(rule.Labels)))
+		}
+		for _, query := range rule.Data {
+			w.newLine()
+			w.block("data", func() {
+				w.attribute("ref_id", hclString(query.RefID))
+				w.attribute("query_type", hclString(query.QueryType))
+				w.attribute("datasource_uid", hclString(query.DatasourceUID))
+				w.block("relative_time_range", func() {
+					w.attribute("from", strconv.FormatInt(int64(time.Duration(query.RelativeTimeRange.From).Seconds()), 10))
+					w.attribute("to", strconv.FormatInt(int64(time.Duration(query.RelativeTimeRange.To).Seconds()), 10))
+				})
+				w.attribute("model", "jsonencode("+w.value(query.Model)+")")
+			})
+		}
+	})
+}
+
+// value returns the HCL expression of a value that was decoded from JSON.
+// Nested objects and lists are indented relative to the current line.
+func (w *hclWriter) value(v interface{}) string {
+	indent := strings.Repeat("  ", w.indent)
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case string:
+		return hclString(val)
+	case []interface{}:
+		if len(val) == 0 {
+			return "[]"
+		}
+		w.indent++
+		items := make([]string, 0, len(val))
+		for _, item := range val {
+			items = append(items, indent+"  "+w.value(item)+",")
+		}
+		w.indent--
+		return "[\n" + strings.Join(items, "\n") + "\n" + indent + "]"
+	case map[string]interface{}:
+		if len(val) == 0 {
+			return "{}"
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		w.indent++
+		items := make([]string, 0, len(val))
+		for _, k := range keys {
+			key := k
+			if !hclIdentifierRegex.MatchString(k) {
+				key = hclString(k)
+			}
+			items = append(items, indent+"  "+key+" = "+w.value(val[k]))
+		}
+		w.indent--
+		return "{\n" + strings.Join(items, "\n") + "\n" + indent + "}"
+	default:
+		return hclString(fmt.Sprintf("%v", val))
+	}
+}
+
+// hclString returns the quoted HCL string literal of s. Template sequences are escaped
+// so that the string is not interpreted by Terraform.
+func hclString(s string) string {
+	buf := bytes.Buffer{}
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	quoted := strings.TrimSuffix(buf.String(), "\n")
+	quoted = strings.ReplaceAll(quoted, "${", "$${")
+	quoted = strings.ReplaceAll(quoted, "%{", "%%{")
+	return quoted
+}
+
+func stringMapToInterface(m map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestEncodeAlertRuleGroupsHCL(t *testing.T) {
+	groups := []definitions.AlertRuleGroupExport{
+		{
+			OrgID:     1,
+			Name:      "My Group",
+			Folder:    "My Folder",
+			FolderUID: "folder-uid",
+			Interval:  model.Duration(time.Minute),
+			Rules: []definitions.AlertRuleExport{
+				{
+					UID:       "rule-uid",
+					Title:     "Rule with ${template}",
+					Condition: "B",
+					Data: []definitions.AlertQueryExport{
+						{
+							RefID:     "A",
+							QueryType: "",
+							RelativeTimeRange: models.RelativeTimeRange{
+								From: models.Duration(10 * time.Minute),
+								To:   models.Duration(0),
+							},
+							DatasourceUID: "datasource-uid",
+							Model: map[string]interface{}{
+								"expr":          "up",
+								"intervalMs":    float64(1000),
+								"hide":          false,
+								"datasource":    map[string]interface{}{"uid": "datasource-uid"},
+								"tags":          []interface{}{"a", "b"},
+								"invalid key":   nil,
+								"maxDataPoints": 43200.5,
+							},
+						},
+					},
+					NoDataState:  models.NoData,
+					ExecErrState: models.AlertingErrState,
+					For:          model.Duration(5 * time.Minute),
+					Annotations:  map[string]string{"summary": "%{ not a directive"},
+					Labels:       map[string]string{"team": "alerting"},
+					IsPaused:     true,
+				},
+			},
+		},
+	}
+
+	expected := `resource "grafana_rule_group" "rule_group_folder_uid_my_group" {
+  org_id = 1
+  name = "My Group"
+  folder_uid = "folder-uid"
+  interval_seconds = 60
+
+  rule {
+    uid = "rule-uid"
+    name = "Rule with $${template}"
+    condition = "B"
+    for = "5m"
+    no_data_state = "NoData"
+    exec_err_state = "Alerting"
+    is_paused = true
+    annotations = {
+      summary = "%%{ not a directive"
+    }
+    labels = {
+      team = "alerting"
+    }
+
+    data {
+      ref_id = "A"
+      query_type = ""
+      datasource_uid = "datasource-uid"
+      relative_time_range {
+        from = 600
+        to = 0
+      }
+      model = jsonencode({
+        datasource = {
+          uid = "datasource-uid"
+        }
+        expr = "up"
+        hide = false
+        intervalMs = 1000
+        "invalid key" = null
+        maxDataPoints = 43200.5
+        tags = [
+          "a",
+          "b",
+        ]
+      })
+    }
+  }
+}
+`
+	require.Equal(t, expected, string(encodeAlertRuleGroupsHCL(groups)))
+}
@@ -143,6 +143,18 @@ func (f *AlertmanagerApiHandler) handleRouteCreateGrafanaSilence(ctx *models.Req
 	return f.GrafanaSvc.RouteCreateSilence(ctx, body)
 }
 
+func (f *AlertmanagerApiHandler) handleRouteCreateGrafanaSilenceFromTemplate(ctx *models.ReqContext, name string) response.Response {
+	return f.GrafanaSvc.RouteCreateSilenceFromTemplate(ctx, name)
+}
+
+func (f *AlertmanagerApiHandler) handleRoutePostGrafanaSilencesExpire(ctx *models.ReqContext, body apimodels.PostableBulkSilences) response.Response {
+	return f.GrafanaSvc.RoutePostSilencesExpire(ctx, body)
+}
+
+func (f *AlertmanagerApiHandler) handleRoutePostGrafanaSilencesExtend(ctx *models.ReqContext, body apimodels.PostableBulkSilences) response.Response {
+	return f.GrafanaSvc.RoutePostSilencesExtend(ctx, body)
+}
+
 func (f *AlertmanagerApiHandler) handleRouteGetGrafanaAMStatus(ctx *models.ReqContext) response.Response {
 	return f.GrafanaSvc.RouteGetAMStatus(ctx)
 }
@@ -181,3 +193,7 @@ func (f *AlertmanagerApiHandler) handleRouteGetGrafanaReceivers(ctx *models.ReqC
 func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaReceivers(ctx *models.ReqContext, conf apimodels.TestReceiversConfigBodyParams) response.Response {
 	return f.GrafanaSvc.RoutePostTestReceivers(ctx, conf)
 }
+
+func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaRoutes(ctx *models.ReqContext, conf apimodels.TestRoutesConfigBodyParams) response.Response {
+	return f.GrafanaSvc.RoutePostTestRoutes(ctx, conf)
+}
@@ -20,6 +20,7 @@ import (
 
 type AlertmanagerApi interface {
 	RouteCreateGrafanaSilence(*models.ReqContext) response.Response
+	RouteCreateGrafanaSilenceFromTemplate(*models.ReqContext) response.Response
 	RouteCreateSilence(*models.ReqContext) response.Response
 	RouteDeleteAlertingConfig(*models.ReqContext) response.Response
 	RouteDeleteGrafanaAlertingConfig(*models.ReqContext) response.Response
@@ -41,7 +42,10 @@ type AlertmanagerApi interface {
 	RoutePostAMAlerts(*models.ReqContext) response.Response
 	RoutePostAlertingConfig(*models.ReqContext) response.Response
 	RoutePostGrafanaAlertingConfig(*models.ReqContext) response.Response
+	RoutePostGrafanaSilencesExpire(*models.ReqContext) response.Response
+	RoutePostGrafanaSilencesExtend(*models.ReqContext) response.Response
 	RoutePostTestGrafanaReceivers(*models.ReqContext) response.Response
+	RoutePostTestGrafanaRoutes(*models.ReqContext) response.Response
 }
 
 func (f *AlertmanagerApiHandler) RouteCreateGrafanaSilence(ctx *models.ReqContext) response.Response {
@@ -52,6 +56,11 @@ func (f *AlertmanagerApiHandler) RouteCreateGrafanaSilence(ctx *models.ReqContex
 	}
 	return f.handleRouteCreateGrafanaSilence(ctx, conf)
 }
+func (f *AlertmanagerApiHandler) RouteCreateGrafanaSilenceFromTemplate(ctx *models.ReqContext) response.Response {
+	// Parse Path Parameters
+	nameParam := web.Params(ctx.Req)[":Name"]
+	return f.handleRouteCreateGrafanaSilenceFromTemplate(ctx, nameParam)
+}
 func (f *AlertmanagerApiHandler) RouteCreateSilence(ctx *models.ReqContext) response.Response {
 	// Parse Path Parameters
 	datasourceUIDParam := web.Params(ctx.Req)[":DatasourceUID"]
@@ -163,6 +172,22 @@ func (f *AlertmanagerApiHandler) RoutePostGrafanaAlertingConfig(ctx *models.ReqC
 	}
 	return f.handleRoutePostGrafanaAlertingConfig(ctx, conf)
 }
+func (f *AlertmanagerApiHandler) RoutePostGrafanaSilencesExpire(ctx *models.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.PostableBulkSilences{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostGrafanaSilencesExpire(ctx, conf)
+}
+func (f *AlertmanagerApiHandler) RoutePostGrafanaSilencesExtend(ctx *models.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.PostableBulkSilences{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostGrafanaSilencesExtend(ctx, conf)
+}
 func (f *AlertmanagerApiHandler) RoutePostTestGrafanaReceivers(ctx *models.ReqContext) response.Response {
 	// Parse Request Body
 	conf := apimodels.TestReceiversConfigBodyParams{}
@@ -171,6 +196,14 @@ func (f *AlertmanagerApiHandler) RoutePostTestGrafanaReceivers(ctx *models.ReqCo
 	}
 	return f.handleRoutePostTestGrafanaReceivers(ctx, conf)
 }
+func (f *AlertmanagerApiHandler) RoutePostTestGrafanaRoutes(ctx *models.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.TestRoutesConfigBodyParams{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostTestGrafanaRoutes(ctx, conf)
+}
 
 func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
@@ -184,6 +217,16 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silences/template/{Name}"),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/api/v2/silences/template/{Name}"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v2/silences/template/{Name}",
+				srv.RouteCreateGrafanaSilenceFromTemplate,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/alertmanager/{DatasourceUID}/api/v2/silences"),
 			api.authorize(http.MethodPost, "/api/alertmanager/{DatasourceUID}/api/v2/silences"),
@@ -394,6 +437,26 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silences/expire"),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/api/v2/silences/expire"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v2/silences/expire",
+				srv.RoutePostGrafanaSilencesExpire,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silences/extend"),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/api/v2/silences/extend"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v2/silences/extend",
+				srv.RoutePostGrafanaSilencesExtend,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/alertmanager/grafana/config/api/v1/receivers/test"),
 			api.authorize(http.MethodPost, "/api/alertmanager/grafana/config/api/v1/receivers/test"),
@@ -404,5 +467,15 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/config/api/v1/routes/test"),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/config/api/v1/routes/test"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/config/api/v1/routes/test",
+				srv.RoutePostTestGrafanaRoutes,
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
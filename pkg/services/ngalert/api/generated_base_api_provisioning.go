@@ -22,24 +22,41 @@ type ProvisioningApi interface {
 	RouteDeleteAlertRule(*models.ReqContext) response.Response
 	RouteDeleteContactpoints(*models.ReqContext) response.Response
 	RouteDeleteMuteTiming(*models.ReqContext) response.Response
+	RouteDeleteRecurringSilence(*models.ReqContext) response.Response
+	RouteDeleteSilenceTemplate(*models.ReqContext) response.Response
 	RouteDeleteTemplate(*models.ReqContext) response.Response
 	RouteGetAlertRule(*models.ReqContext) response.Response
 	RouteGetAlertRuleGroup(*models.ReqContext) response.Response
+	RouteGetAlertRuleGroupExport(*models.ReqContext) response.Response
 	RouteGetAlertRules(*models.ReqContext) response.Response
+	RouteGetAlertRulesExport(*models.ReqContext) response.Response
 	RouteGetContactpoints(*models.ReqContext) response.Response
+	RouteGetContactpointsExport(*models.ReqContext) response.Response
 	RouteGetMuteTiming(*models.ReqContext) response.Response
 	RouteGetMuteTimings(*models.ReqContext) response.Response
+	RouteGetMuteTimingsExport(*models.ReqContext) response.Response
 	RouteGetPolicyTree(*models.ReqContext) response.Response
+	RouteGetPolicyTreeExport(*models.ReqContext) response.Response
+	RouteGetRecurringSilence(*models.ReqContext) response.Response
+	RouteGetRecurringSilences(*models.ReqContext) response.Response
+	RouteGetRecurringSilencesExport(*models.ReqContext) response.Response
+	RouteGetSilenceTemplate(*models.ReqContext) response.Response
+	RouteGetSilenceTemplates(*models.ReqContext) response.Response
 	RouteGetTemplate(*models.ReqContext) response.Response
 	RouteGetTemplates(*models.ReqContext) response.Response
+	RouteGetTemplatesExport(*models.ReqContext) response.Response
 	RoutePostAlertRule(*models.ReqContext) response.Response
 	RoutePostContactpoints(*models.ReqContext) response.Response
 	RoutePostMuteTiming(*models.ReqContext) response.Response
+	RoutePostRecurringSilence(*models.ReqContext) response.Response
+	RoutePostSilenceTemplate(*models.ReqContext) response.Response
 	RoutePutAlertRule(*models.ReqContext) response.Response
 	RoutePutAlertRuleGroup(*models.ReqContext) response.Response
 	RoutePutContactpoint(*models.ReqContext) response.Response
 	RoutePutMuteTiming(*models.ReqContext) response.Response
 	RoutePutPolicyTree(*models.ReqContext) response.Response
+	RoutePutRecurringSilence(*models.ReqContext) response.Response
+	RoutePutSilenceTemplate(*models.ReqContext) response.Response
 	RoutePutTemplate(*models.ReqContext) response.Response
 	RouteResetPolicyTree(*models.ReqContext) response.Response
 }
@@ -59,6 +76,16 @@ func (f *ProvisioningApiHandler) RouteDeleteMuteTiming(ctx *models.ReqContext) r
 	nameParam := web.Params(ctx.Req)[":name"]
 	return f.handleRouteDeleteMuteTiming(ctx, nameParam)
 }
+func (f *ProvisioningApiHandler) RouteDeleteRecurringSilence(ctx *models.ReqContext) response.Response {
+	// Parse Path Parameters
+	nameParam := web.Params(ctx.Req)[":name"]
+	return f.handleRouteDeleteRecurringSilence(ctx, nameParam)
+}
+func (f *ProvisioningApiHandler) RouteDeleteSilenceTemplate(ctx *models.ReqContext) response.Response {
+	// Parse Path Parameters
+	nameParam := web.Params(ctx.Req)[":name"]
+	return f.handleRouteDeleteSilenceTemplate(ctx, nameParam)
+}
 func (f *ProvisioningApiHandler) RouteDeleteTemplate(ctx *models.ReqContext) response.Response {
 	// Parse Path Parameters
 	nameParam := web.Params(ctx.Req)[":name"]
@@ -75,12 +102,24 @@ func (f *ProvisioningApiHandler) RouteGetAlertRuleGroup(ctx *models.ReqContext)
 	groupParam := web.Params(ctx.Req)[":Group"]
 	return f.handleRouteGetAlertRuleGroup(ctx, folderUIDParam, groupParam)
 }
+func (f *ProvisioningApiHandler) RouteGetAlertRuleGroupExport(ctx *models.ReqContext) response.Response {
+	// Parse Path Parameters
+	folderUIDParam := web.Params(ctx.Req)[":FolderUID"]
+	groupParam := web.Params(ctx.Req)[":Group"]
+	return f.handleRouteGetAlertRuleGroupExport(ctx, folderUIDParam, groupParam)
+}
 func (f *ProvisioningApiHandler) RouteGetAlertRules(ctx *models.ReqContext) response.Response {
 	return f.handleRouteGetAlertRules(ctx)
 }
+func (f *ProvisioningApiHandler) RouteGetAlertRulesExport(ctx *models.ReqContext) response.Response {
+	return f.handleRouteGetAlertRulesExport(ctx)
+}
 func (f *ProvisioningApiHandler) RouteGetContactpoints(ctx *models.ReqContext) response.Response {
 	return f.handleRouteGetContactpoints(ctx)
 }
+func (f *ProvisioningApiHandler) RouteGetContactpointsExport(ctx *models.ReqContext) response.Response {
+	return f.handleRouteGetContactpointsExport(ctx)
+}
 func (f *ProvisioningApiHandler) RouteGetMuteTiming(ctx *models.ReqContext) response.Response {
 	// Parse Path Parameters
 	nameParam := web.Params(ctx.Req)[":name"]
@@ -89,9 +128,34 @@ func (f *ProvisioningApiHandler) RouteGetMuteTiming(ctx *models.ReqContext) resp
 func (f *ProvisioningApiHandler) RouteGetMuteTimings(ctx *models.ReqContext) response.Response {
 	return f.handleRouteGetMuteTimings(ctx)
 }
+func (f *ProvisioningApiHandler) RouteGetMuteTimingsExport(ctx *models.ReqContext) response.Response {
+	return f.handleRouteGetMuteTimingsExport(ctx)
+}
 func (f *ProvisioningApiHandler) RouteGetPolicyTree(ctx *models.ReqContext) response.Response {
 	return f.handleRouteGetPolicyTree(ctx)
 }
+func (f *ProvisioningApiHandler) RouteGetPolicyTreeExport(ctx *models.ReqContext) response.Response {
+	return f.handleRouteGetPolicyTreeExport(ctx)
+}
+func (f *ProvisioningApiHandler) RouteGetRecurringSilence(ctx *models.ReqContext) response.Response {
+	// Parse Path Parameters
+	nameParam := web.Params(ctx.Req)[":name"]
+	return f.handleRouteGetRecurringSilence(ctx, nameParam)
+}
+func (f *ProvisioningApiHandler) RouteGetRecurringSilences(ctx *models.ReqContext) response.Response {
+	return f.handleRouteGetRecurringSilences(ctx)
+}
+func (f *ProvisioningApiHandler) RouteGetRecurringSilencesExport(ctx *models.ReqContext) response.Response {
+	return f.handleRouteGetRecurringSilencesExport(ctx)
+}
+func (f *ProvisioningApiHandler) RouteGetSilenceTemplate(ctx *models.ReqContext) response.Response {
+	// Parse Path Parameters
+	nameParam := web.Params(ctx.Req)[":name"]
+	return f.handleRouteGetSilenceTemplate(ctx, nameParam)
+}
+func (f *ProvisioningApiHandler) RouteGetSilenceTemplates(ctx *models.ReqContext) response.Response {
+	return f.handleRouteGetSilenceTemplates(ctx)
+}
 func (f *ProvisioningApiHandler) RouteGetTemplate(ctx *models.ReqContext) response.Response {
 	// Parse Path Parameters
 	nameParam := web.Params(ctx.Req)[":name"]
@@ -100,6 +164,9 @@ func (f *ProvisioningApiHandler) RouteGetTemplate(ctx *models.ReqContext) respon
 func (f *ProvisioningApiHandler) RouteGetTemplates(ctx *models.ReqContext) response.Response {
 	return f.handleRouteGetTemplates(ctx)
 }
+func (f *ProvisioningApiHandler) RouteGetTemplatesExport(ctx *models.ReqContext) response.Response {
+	return f.handleRouteGetTemplatesExport(ctx)
+}
 func (f *ProvisioningApiHandler) RoutePostAlertRule(ctx *models.ReqContext) response.Response {
 	// Parse Request Body
 	conf := apimodels.ProvisionedAlertRule{}
@@ -124,6 +191,22 @@ func (f *ProvisioningApiHandler) RoutePostMuteTiming(ctx *models.ReqContext) res
 	}
 	return f.handleRoutePostMuteTiming(ctx, conf)
 }
+func (f *ProvisioningApiHandler) RoutePostRecurringSilence(ctx *models.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.RecurringSilence{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostRecurringSilence(ctx, conf)
+}
+func (f *ProvisioningApiHandler) RoutePostSilenceTemplate(ctx *models.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.SilenceTemplate{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostSilenceTemplate(ctx, conf)
+}
 func (f *ProvisioningApiHandler) RoutePutAlertRule(ctx *models.ReqContext) response.Response {
 	// Parse Path Parameters
 	uIDParam := web.Params(ctx.Req)[":UID"]
@@ -173,6 +256,26 @@ func (f *ProvisioningApiHandler) RoutePutPolicyTree(ctx *models.ReqContext) resp
 	}
 	return f.handleRoutePutPolicyTree(ctx, conf)
 }
+func (f *ProvisioningApiHandler) RoutePutRecurringSilence(ctx *models.ReqContext) response.Response {
+	// Parse Path Parameters
+	nameParam := web.Params(ctx.Req)[":name"]
+	// Parse Request Body
+	conf := apimodels.RecurringSilence{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePutRecurringSilence(ctx, conf, nameParam)
+}
+func (f *ProvisioningApiHandler) RoutePutSilenceTemplate(ctx *models.ReqContext) response.Response {
+	// Parse Path Parameters
+	nameParam := web.Params(ctx.Req)[":name"]
+	// Parse Request Body
+	conf := apimodels.SilenceTemplate{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePutSilenceTemplate(ctx, conf, nameParam)
+}
 func (f *ProvisioningApiHandler) RoutePutTemplate(ctx *models.ReqContext) response.Response {
 	// Parse Path Parameters
 	nameParam := web.Params(ctx.Req)[":name"]
@@ -219,6 +322,26 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Delete(
+			toMacaronPath("/api/v1/provisioning/recurring-silences/{name}"),
+			api.authorize(http.MethodDelete, "/api/v1/provisioning/recurring-silences/{name}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/provisioning/recurring-silences/{name}",
+				srv.RouteDeleteRecurringSilence,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/v1/provisioning/silence-templates/{name}"),
+			api.authorize(http.MethodDelete, "/api/v1/provisioning/silence-templates/{name}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/provisioning/silence-templates/{name}",
+				srv.RouteDeleteSilenceTemplate,
+				m,
+			),
+		)
 		group.Delete(
 			toMacaronPath("/api/v1/provisioning/templates/{name}"),
 			api.authorize(http.MethodDelete, "/api/v1/provisioning/templates/{name}"),
@@ -249,6 +372,16 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/export"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/export",
+				srv.RouteGetAlertRuleGroupExport,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/provisioning/alert-rules"),
 			api.authorize(http.MethodGet, "/api/v1/provisioning/alert-rules"),
@@ -259,6 +392,16 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/alert-rules/export"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/alert-rules/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/alert-rules/export",
+				srv.RouteGetAlertRulesExport,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/provisioning/contact-points"),
 			api.authorize(http.MethodGet, "/api/v1/provisioning/contact-points"),
@@ -269,6 +412,16 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/contact-points/export"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/contact-points/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/contact-points/export",
+				srv.RouteGetContactpointsExport,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/provisioning/mute-timings/{name}"),
 			api.authorize(http.MethodGet, "/api/v1/provisioning/mute-timings/{name}"),
@@ -289,6 +442,16 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/mute-timings/export"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/mute-timings/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/mute-timings/export",
+				srv.RouteGetMuteTimingsExport,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/provisioning/policies"),
 			api.authorize(http.MethodGet, "/api/v1/provisioning/policies"),
@@ -299,6 +462,66 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/policies/export"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/policies/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/policies/export",
+				srv.RouteGetPolicyTreeExport,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/recurring-silences/{name}"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/recurring-silences/{name}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/recurring-silences/{name}",
+				srv.RouteGetRecurringSilence,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/recurring-silences"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/recurring-silences"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/recurring-silences",
+				srv.RouteGetRecurringSilences,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/recurring-silences/export"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/recurring-silences/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/recurring-silences/export",
+				srv.RouteGetRecurringSilencesExport,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/silence-templates/{name}"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/silence-templates/{name}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/silence-templates/{name}",
+				srv.RouteGetSilenceTemplate,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/silence-templates"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/silence-templates"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/silence-templates",
+				srv.RouteGetSilenceTemplates,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/provisioning/templates/{name}"),
 			api.authorize(http.MethodGet, "/api/v1/provisioning/templates/{name}"),
@@ -319,6 +542,16 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/templates/export"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/templates/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/templates/export",
+				srv.RouteGetTemplatesExport,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/provisioning/alert-rules"),
 			api.authorize(http.MethodPost, "/api/v1/provisioning/alert-rules"),
@@ -349,6 +582,26 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/recurring-silences"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/recurring-silences"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/recurring-silences",
+				srv.RoutePostRecurringSilence,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/silence-templates"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/silence-templates"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/silence-templates",
+				srv.RoutePostSilenceTemplate,
+				m,
+			),
+		)
 		group.Put(
 			toMacaronPath("/api/v1/provisioning/alert-rules/{UID}"),
 			api.authorize(http.MethodPut, "/api/v1/provisioning/alert-rules/{UID}"),
@@ -399,6 +652,26 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/recurring-silences/{name}"),
+			api.authorize(http.MethodPut, "/api/v1/provisioning/recurring-silences/{name}"),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/recurring-silences/{name}",
+				srv.RoutePutRecurringSilence,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/silence-templates/{name}"),
+			api.authorize(http.MethodPut, "/api/v1/provisioning/silence-templates/{name}"),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/silence-templates/{name}",
+				srv.RoutePutSilenceTemplate,
+				m,
+			),
+		)
 		group.Put(
 			toMacaronPath("/api/v1/provisioning/templates/{name}"),
 			api.authorize(http.MethodPut, "/api/v1/provisioning/templates/{name}"),
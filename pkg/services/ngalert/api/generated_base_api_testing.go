@@ -20,6 +20,7 @@ import (
 
 type TestingApi interface {
 	BacktestConfig(*models.ReqContext) response.Response
+	BacktestGroupConfig(*models.ReqContext) response.Response
 	RouteEvalQueries(*models.ReqContext) response.Response
 	RouteTestRuleConfig(*models.ReqContext) response.Response
 	RouteTestRuleGrafanaConfig(*models.ReqContext) response.Response
@@ -33,6 +34,14 @@ func (f *TestingApiHandler) BacktestConfig(ctx *models.ReqContext) response.Resp
 	}
 	return f.handleBacktestingConfig(ctx, conf)
 }
+func (f *TestingApiHandler) BacktestGroupConfig(ctx *models.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.BacktestGroupConfig{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleBacktestingGroupConfig(ctx, conf)
+}
 func (f *TestingApiHandler) RouteEvalQueries(ctx *models.ReqContext) response.Response {
 	// Parse Request Body
 	conf := apimodels.EvalQueriesPayload{}
@@ -72,6 +81,16 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/rule/backtest/group"),
+			api.authorize(http.MethodPost, "/api/v1/rule/backtest/group"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rule/backtest/group",
+				srv.BacktestGroupConfig,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/eval"),
 			api.authorize(http.MethodPost, "/api/v1/eval"),
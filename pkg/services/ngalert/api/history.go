@@ -0,0 +1,21 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// HistoryApiHandler always forwards requests to grafana backend
+type HistoryApiHandler struct {
+	grafana *HistorySrv
+}
+
+func NewStateHistoryApi(grafana *HistorySrv) *HistoryApiHandler {
+	return &HistoryApiHandler{
+		grafana: grafana,
+	}
+}
+
+func (f *HistoryApiHandler) handleRouteGetStateHistory(c *models.ReqContext) response.Response {
+	return f.grafana.RouteQueryStateHistory(c)
+}
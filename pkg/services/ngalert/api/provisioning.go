@@ -20,6 +20,10 @@ func (f *ProvisioningApiHandler) handleRouteGetPolicyTree(ctx *models.ReqContext
 	return f.svc.RouteGetPolicyTree(ctx)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetPolicyTreeExport(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetPolicyTreeExport(ctx)
+}
+
 func (f *ProvisioningApiHandler) handleRoutePutPolicyTree(ctx *models.ReqContext, route apimodels.Route) response.Response {
 	return f.svc.RoutePutPolicyTree(ctx, route)
 }
@@ -28,6 +32,10 @@ func (f *ProvisioningApiHandler) handleRouteGetContactpoints(ctx *models.ReqCont
 	return f.svc.RouteGetContactPoints(ctx)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetContactpointsExport(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetContactPointsExport(ctx)
+}
+
 func (f *ProvisioningApiHandler) handleRoutePostContactpoints(ctx *models.ReqContext, cp apimodels.EmbeddedContactPoint) response.Response {
 	return f.svc.RoutePostContactPoint(ctx, cp)
 }
@@ -44,6 +52,10 @@ func (f *ProvisioningApiHandler) handleRouteGetTemplates(ctx *models.ReqContext)
 	return f.svc.RouteGetTemplates(ctx)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetTemplatesExport(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetTemplatesExport(ctx)
+}
+
 func (f *ProvisioningApiHandler) handleRouteGetTemplate(ctx *models.ReqContext, name string) response.Response {
 	return f.svc.RouteGetTemplate(ctx, name)
 }
@@ -64,6 +76,10 @@ func (f *ProvisioningApiHandler) handleRouteGetMuteTimings(ctx *models.ReqContex
 	return f.svc.RouteGetMuteTimings(ctx)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetMuteTimingsExport(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetMuteTimingsExport(ctx)
+}
+
 func (f *ProvisioningApiHandler) handleRoutePostMuteTiming(ctx *models.ReqContext, mt apimodels.MuteTimeInterval) response.Response {
 	return f.svc.RoutePostMuteTiming(ctx, mt)
 }
@@ -76,10 +92,58 @@ func (f *ProvisioningApiHandler) handleRouteDeleteMuteTiming(ctx *models.ReqCont
 	return f.svc.RouteDeleteMuteTiming(ctx, name)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetSilenceTemplates(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetSilenceTemplates(ctx)
+}
+
+func (f *ProvisioningApiHandler) handleRouteGetSilenceTemplate(ctx *models.ReqContext, name string) response.Response {
+	return f.svc.RouteGetSilenceTemplate(ctx, name)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePostSilenceTemplate(ctx *models.ReqContext, t apimodels.SilenceTemplate) response.Response {
+	return f.svc.RoutePostSilenceTemplate(ctx, t)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePutSilenceTemplate(ctx *models.ReqContext, t apimodels.SilenceTemplate, name string) response.Response {
+	return f.svc.RoutePutSilenceTemplate(ctx, t, name)
+}
+
+func (f *ProvisioningApiHandler) handleRouteDeleteSilenceTemplate(ctx *models.ReqContext, name string) response.Response {
+	return f.svc.RouteDeleteSilenceTemplate(ctx, name)
+}
+
+func (f *ProvisioningApiHandler) handleRouteGetRecurringSilences(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetRecurringSilences(ctx)
+}
+
+func (f *ProvisioningApiHandler) handleRouteGetRecurringSilencesExport(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetRecurringSilencesExport(ctx)
+}
+
+func (f *ProvisioningApiHandler) handleRouteGetRecurringSilence(ctx *models.ReqContext, name string) response.Response {
+	return f.svc.RouteGetRecurringSilence(ctx, name)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePostRecurringSilence(ctx *models.ReqContext, s apimodels.RecurringSilence) response.Response {
+	return f.svc.RoutePostRecurringSilence(ctx, s)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePutRecurringSilence(ctx *models.ReqContext, s apimodels.RecurringSilence, name string) response.Response {
+	return f.svc.RoutePutRecurringSilence(ctx, s, name)
+}
+
+func (f *ProvisioningApiHandler) handleRouteDeleteRecurringSilence(ctx *models.ReqContext, name string) response.Response {
+	return f.svc.RouteDeleteRecurringSilence(ctx, name)
+}
+
 func (f *ProvisioningApiHandler) handleRouteGetAlertRules(ctx *models.ReqContext) response.Response {
 	return f.svc.RouteGetAlertRules(ctx)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetAlertRulesExport(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetAlertRulesExport(ctx)
+}
+
 func (f *ProvisioningApiHandler) handleRouteGetAlertRule(ctx *models.ReqContext, UID string) response.Response {
 	return f.svc.RouteRouteGetAlertRule(ctx, UID)
 }
@@ -104,6 +168,10 @@ func (f *ProvisioningApiHandler) handleRouteGetAlertRuleGroup(ctx *models.ReqCon
 	return f.svc.RouteGetAlertRuleGroup(ctx, folder, group)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetAlertRuleGroupExport(ctx *models.ReqContext, folder, group string) response.Response {
+	return f.svc.RouteGetAlertRuleGroupExport(ctx, folder, group)
+}
+
 func (f *ProvisioningApiHandler) handleRoutePutAlertRuleGroup(ctx *models.ReqContext, ag apimodels.AlertRuleGroup, folder, group string) response.Response {
 	return f.svc.RoutePutAlertRuleGroup(ctx, ag, folder, group)
 }
@@ -154,6 +154,17 @@ import (
 //       408: Failure
 //       409: AlertManagerNotReady
 
+// swagger:route POST /api/alertmanager/grafana/config/api/v1/routes/test alertmanager RoutePostTestGrafanaRoutes
+//
+// Test how the notification policies route alerts with the given label sets, without creating any alert.
+//
+//     Responses:
+//       200: TestRoutesResult
+//       400: ValidationError
+//       403: PermissionDenied
+//       404: NotFound
+//       409: AlertManagerNotReady
+
 // swagger:route GET /api/alertmanager/grafana/api/v2/silences alertmanager RouteGetGrafanaSilences
 //
 // get silences
@@ -179,6 +190,31 @@ import (
 //       201: postSilencesOKBody
 //       400: ValidationError
 
+// swagger:route POST /api/alertmanager/grafana/api/v2/silences/template/{Name} alertmanager RouteCreateGrafanaSilenceFromTemplate
+//
+// create silence from a silence template, starting now
+//
+//     Responses:
+//       202: postSilencesOKBody
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route POST /api/alertmanager/grafana/api/v2/silences/expire alertmanager RoutePostGrafanaSilencesExpire
+//
+// expire all silences that match the filter
+//
+//     Responses:
+//       200: BulkSilencesResult
+//       400: ValidationError
+
+// swagger:route POST /api/alertmanager/grafana/api/v2/silences/extend alertmanager RoutePostGrafanaSilencesExtend
+//
+// extend the end time of all silences that match the filter
+//
+//     Responses:
+//       200: BulkSilencesResult
+//       400: ValidationError
+
 // swagger:route POST /api/alertmanager/{DatasourceUID}/api/v2/silences alertmanager RouteCreateSilence
 //
 // create silence
@@ -272,12 +308,99 @@ type TestReceiverConfigResult struct {
 	Error  string `json:"error,omitempty"`
 }
 
+// swagger:parameters RoutePostTestGrafanaRoutes
+type TestRoutesConfigParams struct {
+	// in:body
+	Body TestRoutesConfigBodyParams
+}
+
+type TestRoutesConfigBodyParams struct {
+	// Labels are the label sets of the alerts to route.
+	Labels []model.LabelSet `json:"labels"`
+	// AlertmanagerConfig is a draft of the configuration to test. If it is not set, the current configuration is used.
+	AlertmanagerConfig *PostableApiAlertingConfig `json:"alertmanager_config,omitempty"`
+}
+
+// swagger:model
+type TestRoutesResult struct {
+	Alerts   []TestRoutesAlertResult `json:"alerts"`
+	TestedAt time.Time               `json:"tested_at"`
+}
+
+// swagger:model
+type TestRoutesAlertResult struct {
+	Labels model.LabelSet    `json:"labels"`
+	Routes []TestRouteResult `json:"routes"`
+	// SilencedBy contains the IDs of the active silences that match the labels.
+	SilencedBy []string `json:"silenced_by"`
+	// InhibitedBy contains the inhibition rules that inhibit the alert because of the alerts that are firing.
+	InhibitedBy []TestInhibitionResult `json:"inhibited_by"`
+}
+
+// swagger:model
+type TestRouteResult struct {
+	Receiver string `json:"receiver"`
+	// Path is the list of routes from the root of the tree to the route that matched.
+	Path           []TestRouteStep `json:"path"`
+	GroupBy        []string        `json:"group_by"`
+	GroupLabels    model.LabelSet  `json:"group_labels"`
+	GroupWait      model.Duration  `json:"group_wait"`
+	GroupInterval  model.Duration  `json:"group_interval"`
+	RepeatInterval model.Duration  `json:"repeat_interval"`
+	// MuteTimeIntervals are the names of the mute timings of the route, and Muted is true if any of them is active.
+	MuteTimeIntervals []string `json:"mute_time_intervals,omitempty"`
+	Muted             bool     `json:"muted"`
+}
+
+// swagger:model
+type TestRouteStep struct {
+	Receiver       string         `json:"receiver"`
+	ObjectMatchers ObjectMatchers `json:"object_matchers,omitempty"`
+	Continue       bool           `json:"continue"`
+}
+
+// swagger:model
+type TestInhibitionResult struct {
+	SourceMatchers ObjectMatchers `json:"source_matchers,omitempty"`
+	TargetMatchers ObjectMatchers `json:"target_matchers,omitempty"`
+	Equal          []string       `json:"equal,omitempty"`
+	// SourceLabels are the labels of the firing alert that inhibits the alert.
+	SourceLabels model.LabelSet `json:"source_labels"`
+}
+
 // swagger:parameters RouteCreateSilence RouteCreateGrafanaSilence
 type CreateSilenceParams struct {
 	// in:body
 	Silence PostableSilence
 }
 
+// swagger:parameters RouteCreateGrafanaSilenceFromTemplate
+type CreateSilenceFromTemplateParams struct {
+	// in:path
+	Name string
+}
+
+// swagger:parameters RoutePostGrafanaSilencesExpire RoutePostGrafanaSilencesExtend
+type BulkSilencesParams struct {
+	// in:body
+	Body PostableBulkSilences
+}
+
+// swagger:model
+type PostableBulkSilences struct {
+	// Filter selects the silences that are not expired by their matchers, like the filter of the list of silences.
+	Filter []string `json:"filter"`
+	// Duration is added to the end time of the silences when they are extended.
+	Duration model.Duration `json:"duration,omitempty"`
+}
+
+// swagger:model
+type BulkSilencesResult struct {
+	// SilenceIDs are the IDs of the silences that were expired or extended. The silences keep their IDs when they
+	// are extended.
+	SilenceIDs []string `json:"silenceIDs"`
+}
+
 // swagger:parameters RouteGetSilence RouteDeleteSilence RouteGetGrafanaSilence RouteDeleteGrafanaSilence
 type GetDeleteSilenceParams struct {
 	// in:path
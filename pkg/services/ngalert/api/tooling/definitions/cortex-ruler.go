@@ -168,8 +168,11 @@ type NamespaceConfigResponse map[string][]GettableRuleGroupConfig
 
 // swagger:model
 type PostableRuleGroupConfig struct {
-	Name     string                     `yaml:"name" json:"name"`
-	Interval model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Name     string         `yaml:"name" json:"name"`
+	Interval model.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	// IsPaused pauses or resumes all rules of the group. If it is not specified, each rule is paused according to its own isPaused.
+	// Only supported by Grafana managed rules.
+	IsPaused *bool                      `yaml:"isPaused,omitempty" json:"isPaused,omitempty"`
 	Rules    []PostableExtendedRuleNode `yaml:"rules" json:"rules"`
 }
 
@@ -209,15 +212,21 @@ func (c *PostableRuleGroupConfig) validate() error {
 	if hasGrafRules && hasLotexRules {
 		return fmt.Errorf("cannot mix Grafana & Prometheus style rules")
 	}
+
+	if hasLotexRules && c.IsPaused != nil {
+		return fmt.Errorf("isPaused is supported only by Grafana managed rules")
+	}
 	return nil
 }
 
 // swagger:model
 type GettableRuleGroupConfig struct {
-	Name          string                     `yaml:"name" json:"name"`
-	Interval      model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
-	SourceTenants []string                   `yaml:"source_tenants,omitempty" json:"source_tenants,omitempty"`
-	Rules         []GettableExtendedRuleNode `yaml:"rules" json:"rules"`
+	Name          string         `yaml:"name" json:"name"`
+	Interval      model.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	SourceTenants []string       `yaml:"source_tenants,omitempty" json:"source_tenants,omitempty"`
+	// IsPaused is true if all rules of the group are paused.
+	IsPaused bool                       `yaml:"isPaused,omitempty" json:"isPaused,omitempty"`
+	Rules    []GettableExtendedRuleNode `yaml:"rules" json:"rules"`
 }
 
 func (c *GettableRuleGroupConfig) UnmarshalJSON(b []byte) error {
@@ -374,6 +383,14 @@ type PostableGrafanaRule struct {
 	UID          string              `json:"uid" yaml:"uid"`
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	// Record makes the rule a recording rule that writes the series of a query or expression as a metric
+	// instead of alerting.
+	Record *models.Record `json:"record,omitempty" yaml:"record,omitempty"`
+	// IsPaused stops the evaluation of the rule. The alerts of a paused rule are resolved.
+	IsPaused *bool `json:"isPaused" yaml:"isPaused"`
+	// Enrichments add labels and annotations from lookup sources to the alert instances of the rule
+	// after each evaluation.
+	Enrichments []models.Enrichment `json:"enrichments,omitempty" yaml:"enrichments,omitempty"`
 }
 
 // swagger:model
@@ -393,4 +410,7 @@ type GettableGrafanaRule struct {
 	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
 	Provenance      models.Provenance   `json:"provenance,omitempty" yaml:"provenance,omitempty"`
+	Record          *models.Record      `json:"record,omitempty" yaml:"record,omitempty"`
+	IsPaused        bool                `json:"isPaused" yaml:"isPaused"`
+	Enrichments     []models.Enrichment `json:"enrichments,omitempty" yaml:"enrichments,omitempty"`
 }
@@ -13,6 +13,8 @@ type EvalAlertConditionCommand struct {
 	Condition string              `json:"condition"`
 	Data      []models.AlertQuery `json:"data"` // TODO yuri. Create API model for AlertQuery
 	Now       time.Time           `json:"now"`
+	// Debug adds the trace of the queries and expressions to the response.
+	Debug bool `json:"debug,omitempty"`
 }
 
 func (cmd *EvalAlertConditionCommand) UnmarshalJSON(b []byte) error {
@@ -0,0 +1,39 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// swagger:route GET /api/v1/rules/history history RouteGetStateHistory
+//
+// Query the history of the evaluations of a Grafana-managed alert rule. It returns a frame for each alert instance,
+// with the time, state, previous state, duration in milliseconds and error of each evaluation, and a field for each value.
+// Only the alert instances that have all the labels given as labels_<name>=<value> parameters are returned.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistory
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:parameters RouteGetStateHistory
+type StateHistoryParams struct {
+	// The UID of the alert rule.
+	// in:query
+	// required: true
+	RuleUID string `json:"ruleUID"`
+	// The start of the time range, in milliseconds since the epoch.
+	// in:query
+	From int64 `json:"from"`
+	// The end of the time range, in milliseconds since the epoch.
+	// in:query
+	To int64 `json:"to"`
+	// The maximum number of evaluations to return. The latest evaluations are returned.
+	// in:query
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type StateHistory data.Frames
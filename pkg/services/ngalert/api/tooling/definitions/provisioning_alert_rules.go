@@ -111,9 +111,18 @@ type ProvisionedAlertRule struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// readonly: true
 	Provenance models.Provenance `json:"provenance,omitempty"`
+	// example: false
+	IsPaused bool `json:"isPaused"`
+	// Enrichments add labels and annotations from lookup sources to the alert instances of the rule.
+	Enrichments []models.Enrichment `json:"enrichments,omitempty"`
 }
 
 func (a *ProvisionedAlertRule) UpstreamModel() (models.AlertRule, error) {
+	for i := range a.Enrichments {
+		if err := a.Enrichments[i].Validate(); err != nil {
+			return models.AlertRule{}, err
+		}
+	}
 	return models.AlertRule{
 		ID:           a.ID,
 		UID:          a.UID,
@@ -129,6 +138,8 @@ func (a *ProvisionedAlertRule) UpstreamModel() (models.AlertRule, error) {
 		For:          time.Duration(a.For),
 		Annotations:  a.Annotations,
 		Labels:       a.Labels,
+		IsPaused:     a.IsPaused,
+		Enrichments:  a.Enrichments,
 	}, nil
 }
 
@@ -149,6 +160,8 @@ func NewAlertRule(rule models.AlertRule, provenance models.Provenance) Provision
 		Annotations:  rule.Annotations,
 		Labels:       rule.Labels,
 		Provenance:   provenance,
+		IsPaused:     rule.IsPaused,
+		Enrichments:  rule.Enrichments,
 	}
 }
 
@@ -204,10 +217,12 @@ type AlertRuleGroupMetadata struct {
 
 // swagger:model
 type AlertRuleGroup struct {
-	Title     string                 `json:"title"`
-	FolderUID string                 `json:"folderUid"`
-	Interval  int64                  `json:"interval"`
-	Rules     []ProvisionedAlertRule `json:"rules"`
+	Title     string `json:"title"`
+	FolderUID string `json:"folderUid"`
+	Interval  int64  `json:"interval"`
+	// IsPaused pauses all rules of the group. It is true in responses if all rules of the group are paused.
+	IsPaused bool                   `json:"isPaused,omitempty"`
+	Rules    []ProvisionedAlertRule `json:"rules"`
 }
 
 func (a *AlertRuleGroup) ToModel() (models.AlertRuleGroup, error) {
@@ -221,6 +236,9 @@ func (a *AlertRuleGroup) ToModel() (models.AlertRuleGroup, error) {
 		if err != nil {
 			return models.AlertRuleGroup{}, err
 		}
+		if a.IsPaused {
+			converted.IsPaused = true
+		}
 		ruleGroup.Rules = append(ruleGroup.Rules, converted)
 	}
 	return ruleGroup, nil
@@ -228,13 +246,16 @@ func (a *AlertRuleGroup) ToModel() (models.AlertRuleGroup, error) {
 
 func NewAlertRuleGroupFromModel(d models.AlertRuleGroup) AlertRuleGroup {
 	rules := make([]ProvisionedAlertRule, 0, len(d.Rules))
+	isPaused := len(d.Rules) > 0
 	for i := range d.Rules {
 		rules = append(rules, NewAlertRule(d.Rules[i], d.Provenance))
+		isPaused = isPaused && d.Rules[i].IsPaused
 	}
 	return AlertRuleGroup{
 		Title:     d.Title,
 		FolderUID: d.FolderUID,
 		Interval:  d.Interval,
+		IsPaused:  isPaused,
 		Rules:     rules,
 	}
 }
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 func TestToModel(t *testing.T) {
@@ -32,4 +34,46 @@ func TestToModel(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, tm.Rules, 1)
 	})
+	t.Run("if the group is paused all rules should be paused", func(t *testing.T) {
+		ruleGroup := AlertRuleGroup{
+			Title:     "123",
+			FolderUID: "123",
+			Interval:  10,
+			IsPaused:  true,
+			Rules: []ProvisionedAlertRule{
+				{
+					UID: "1",
+				},
+				{
+					UID:      "2",
+					IsPaused: true,
+				},
+			},
+		}
+		tm, err := ruleGroup.ToModel()
+		require.NoError(t, err)
+		for _, rule := range tm.Rules {
+			require.True(t, rule.IsPaused)
+		}
+	})
+}
+
+func TestNewAlertRuleGroupFromModel(t *testing.T) {
+	t.Run("the group should be paused if all rules are paused", func(t *testing.T) {
+		group := NewAlertRuleGroupFromModel(models.AlertRuleGroup{
+			Rules: []models.AlertRule{{UID: "1", IsPaused: true}, {UID: "2", IsPaused: true}},
+		})
+		require.True(t, group.IsPaused)
+	})
+	t.Run("the group should not be paused if some rules are not paused", func(t *testing.T) {
+		group := NewAlertRuleGroupFromModel(models.AlertRuleGroup{
+			Rules: []models.AlertRule{{UID: "1", IsPaused: true}, {UID: "2"}},
+		})
+		require.False(t, group.IsPaused)
+		require.True(t, group.Rules[0].IsPaused)
+		require.False(t, group.Rules[1].IsPaused)
+	})
+	t.Run("the group should not be paused if it has no rules", func(t *testing.T) {
+		require.False(t, NewAlertRuleGroupFromModel(models.AlertRuleGroup{}).IsPaused)
+	})
 }
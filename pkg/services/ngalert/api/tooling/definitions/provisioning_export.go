@@ -0,0 +1,333 @@
+package definitions
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// swagger:route GET /api/v1/provisioning/alert-rules/export provisioning stable RouteGetAlertRulesExport
+//
+// Export all alert rules, the rules of a folder or a single rule group in provisioning file format.
+//
+//     Produces:
+//     - application/json
+//     - application/yaml
+//     - text/hcl
+//
+//     Responses:
+//       200: AlertingFileExport
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route GET /api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/export provisioning stable RouteGetAlertRuleGroupExport
+//
+// Export a rule group in provisioning file format.
+//
+//     Produces:
+//     - application/json
+//     - application/yaml
+//     - text/hcl
+//
+//     Responses:
+//       200: AlertingFileExport
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route GET /api/v1/provisioning/contact-points/export provisioning stable RouteGetContactpointsExport
+//
+// Export all contact points in provisioning file format. Secure settings are redacted unless decrypt is set.
+//
+//     Produces:
+//     - application/json
+//     - application/yaml
+//
+//     Responses:
+//       200: AlertingFileExport
+//       400: ValidationError
+//       403: PermissionDenied
+
+// swagger:route GET /api/v1/provisioning/policies/export provisioning stable RouteGetPolicyTreeExport
+//
+// Export the notification policy tree in provisioning file format.
+//
+//     Produces:
+//     - application/json
+//     - application/yaml
+//
+//     Responses:
+//       200: AlertingFileExport
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route GET /api/v1/provisioning/mute-timings/export provisioning stable RouteGetMuteTimingsExport
+//
+// Export all mute timings in provisioning file format.
+//
+//     Produces:
+//     - application/json
+//     - application/yaml
+//
+//     Responses:
+//       200: AlertingFileExport
+//       400: ValidationError
+
+// swagger:route GET /api/v1/provisioning/templates/export provisioning stable RouteGetTemplatesExport
+//
+// Export all message templates in provisioning file format.
+//
+//     Produces:
+//     - application/json
+//     - application/yaml
+//
+//     Responses:
+//       200: AlertingFileExport
+//       400: ValidationError
+
+// swagger:parameters RouteGetAlertRulesExport RouteGetAlertRuleGroupExport RouteGetContactpointsExport RouteGetPolicyTreeExport RouteGetMuteTimingsExport RouteGetTemplatesExport RouteGetRecurringSilencesExport
+type ExportQueryParams struct {
+	// Format of the exported file. HCL is supported only for alert rules.
+	// in:query
+	// required:false
+	// default:yaml
+	// enum: yaml,json,hcl
+	Format string `json:"format"`
+	// Whether to initiate a download of the file.
+	// in:query
+	// required:false
+	// default:false
+	Download bool `json:"download"`
+}
+
+// swagger:parameters RouteGetAlertRulesExport
+type AlertRulesExportParams struct {
+	// UID of the folder whose rules are exported. If not set, rules of all folders are exported.
+	// in:query
+	// required:false
+	FolderUID string `json:"folderUid"`
+	// Name of the rule group to export. Requires folderUid.
+	// in:query
+	// required:false
+	Group string `json:"group"`
+}
+
+// swagger:parameters RouteGetContactpointsExport
+type ContactPointsExportParams struct {
+	// Whether to export the values of secure settings instead of redacting them. Requires the Admin role of the organization.
+	// in:query
+	// required:false
+	// default:false
+	Decrypt bool `json:"decrypt"`
+}
+
+// AlertingFileExport is the provisioning file structure that is used by the file provisioner of alerting resources.
+// swagger:model
+type AlertingFileExport struct {
+	APIVersion        int64                      `json:"apiVersion" yaml:"apiVersion"`
+	Groups            []AlertRuleGroupExport     `json:"groups,omitempty" yaml:"groups,omitempty"`
+	ContactPoints     []ContactPointExport       `json:"contactPoints,omitempty" yaml:"contactPoints,omitempty"`
+	Policies          []NotificationPolicyExport `json:"policies,omitempty" yaml:"policies,omitempty"`
+	MuteTimes         []MuteTimeIntervalExport   `json:"muteTimes,omitempty" yaml:"muteTimes,omitempty"`
+	Templates         []MessageTemplateExport    `json:"templates,omitempty" yaml:"templates,omitempty"`
+	RecurringSilences []RecurringSilenceExport   `json:"recurringSilences,omitempty" yaml:"recurringSilences,omitempty"`
+}
+
+// AlertRuleGroupExport is the provisioned file export of AlertRuleGroupV1.
+type AlertRuleGroupExport struct {
+	OrgID int64  `json:"orgId" yaml:"orgId"`
+	Name  string `json:"name" yaml:"name"`
+	// Folder is the title of the folder the group belongs to.
+	Folder string `json:"folder" yaml:"folder"`
+	// FolderUID is not part of the provisioning file format. It is used to reference the folder in HCL.
+	FolderUID string            `json:"-" yaml:"-"`
+	Interval  model.Duration    `json:"interval" yaml:"interval"`
+	Rules     []AlertRuleExport `json:"rules" yaml:"rules"`
+}
+
+// AlertRuleExport is the provisioned file export of models.AlertRule.
+type AlertRuleExport struct {
+	UID          string                     `json:"uid" yaml:"uid"`
+	Title        string                     `json:"title" yaml:"title"`
+	Condition    string                     `json:"condition" yaml:"condition"`
+	Data         []AlertQueryExport         `json:"data" yaml:"data"`
+	DashboardUID string                     `json:"dashboardUid,omitempty" yaml:"dashboardUid,omitempty"`
+	PanelID      int64                      `json:"panelId,omitempty" yaml:"panelId,omitempty"`
+	NoDataState  models.NoDataState         `json:"noDataState" yaml:"noDataState"`
+	ExecErrState models.ExecutionErrorState `json:"execErrState" yaml:"execErrState"`
+	For          model.Duration             `json:"for" yaml:"for"`
+	Annotations  map[string]string          `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Labels       map[string]string          `json:"labels,omitempty" yaml:"labels,omitempty"`
+	IsPaused     bool                       `json:"isPaused" yaml:"isPaused"`
+	Enrichments  []models.Enrichment        `json:"enrichments,omitempty" yaml:"enrichments,omitempty"`
+}
+
+// AlertQueryExport is the provisioned file export of models.AlertQuery.
+type AlertQueryExport struct {
+	RefID             string                   `json:"refId" yaml:"refId"`
+	QueryType         string                   `json:"queryType,omitempty" yaml:"queryType,omitempty"`
+	RelativeTimeRange models.RelativeTimeRange `json:"relativeTimeRange" yaml:"relativeTimeRange"`
+	DatasourceUID     string                   `json:"datasourceUid" yaml:"datasourceUid"`
+	Model             map[string]interface{}   `json:"model" yaml:"model"`
+}
+
+// ContactPointExport is the provisioned file export of all receivers of a contact point.
+type ContactPointExport struct {
+	OrgID     int64            `json:"orgId" yaml:"orgId"`
+	Name      string           `json:"name" yaml:"name"`
+	Receivers []ReceiverExport `json:"receivers" yaml:"receivers"`
+}
+
+// ReceiverExport is the provisioned file export of EmbeddedContactPoint.
+type ReceiverExport struct {
+	UID                   string                 `json:"uid" yaml:"uid"`
+	Type                  string                 `json:"type" yaml:"type"`
+	Settings              map[string]interface{} `json:"settings" yaml:"settings"`
+	DisableResolveMessage bool                   `json:"disableResolveMessage" yaml:"disableResolveMessage"`
+}
+
+// NotificationPolicyExport is the provisioned file export of the notification policy tree of an organization.
+type NotificationPolicyExport struct {
+	OrgID int64 `json:"orgId" yaml:"orgId"`
+	Route `yaml:",inline"`
+}
+
+// MuteTimeIntervalExport is the provisioned file export of MuteTimeInterval.
+type MuteTimeIntervalExport struct {
+	OrgID                   int64 `json:"orgId" yaml:"orgId"`
+	config.MuteTimeInterval `yaml:",inline"`
+}
+
+// MessageTemplateExport is the provisioned file export of MessageTemplate.
+type MessageTemplateExport struct {
+	OrgID    int64  `json:"orgId" yaml:"orgId"`
+	Name     string `json:"name" yaml:"name"`
+	Template string `json:"template" yaml:"template"`
+}
+
+// NewAlertRuleGroupExport creates an export of the rule group in the format of the file provisioning.
+// Recording rules are left out of the export because they cannot be provisioned from files.
+func NewAlertRuleGroupExport(d models.AlertRuleGroupWithFolderTitle) (AlertRuleGroupExport, error) {
+	rules := make([]AlertRuleExport, 0, len(d.Rules))
+	for i := range d.Rules {
+		if d.Rules[i].IsRecordingRule() {
+			continue
+		}
+		alert, err := NewAlertRuleExport(d.Rules[i])
+		if err != nil {
+			return AlertRuleGroupExport{}, err
+		}
+		rules = append(rules, alert)
+	}
+	return AlertRuleGroupExport{
+		OrgID:     d.OrgID,
+		Name:      d.Title,
+		Folder:    d.FolderTitle,
+		FolderUID: d.FolderUID,
+		Interval:  model.Duration(time.Duration(d.Interval) * time.Second),
+		Rules:     rules,
+	}, nil
+}
+
+// NewAlertRuleExport creates an export of the alert rule in the format of the file provisioning.
+// Recording rules cannot be provisioned from files and therefore cannot be exported.
+func NewAlertRuleExport(rule models.AlertRule) (AlertRuleExport, error) {
+	if rule.Record != nil {
+		return AlertRuleExport{}, fmt.Errorf("rule '%s' is a recording rule, which cannot be exported", rule.UID)
+	}
+	data := make([]AlertQueryExport, 0, len(rule.Data))
+	for _, query := range rule.Data {
+		var m map[string]interface{}
+		if err := json.Unmarshal(query.Model, &m); err != nil {
+			return AlertRuleExport{}, fmt.Errorf("failed to parse model of query '%s' of rule '%s': %w", query.RefID, rule.UID, err)
+		}
+		data = append(data, AlertQueryExport{
+			RefID:             query.RefID,
+			QueryType:         query.QueryType,
+			RelativeTimeRange: query.RelativeTimeRange,
+			DatasourceUID:     query.DatasourceUID,
+			Model:             m,
+		})
+	}
+	result := AlertRuleExport{
+		UID:          rule.UID,
+		Title:        rule.Title,
+		Condition:    rule.Condition,
+		Data:         data,
+		NoDataState:  rule.NoDataState,
+		ExecErrState: rule.ExecErrState,
+		For:          model.Duration(rule.For),
+		Annotations:  rule.Annotations,
+		Labels:       rule.Labels,
+		IsPaused:     rule.IsPaused,
+		Enrichments:  rule.Enrichments,
+	}
+	if rule.DashboardUID != nil {
+		result.DashboardUID = *rule.DashboardUID
+	}
+	if rule.PanelID != nil {
+		result.PanelID = *rule.PanelID
+	}
+	return result, nil
+}
+
+// NewContactPointExports creates exports of the contact points in the format of the file provisioning.
+// Receivers are grouped by the name of the contact point in the order they appear.
+func NewContactPointExports(orgID int64, contactPoints []EmbeddedContactPoint) ([]ContactPointExport, error) {
+	result := make([]ContactPointExport, 0)
+	idx := make(map[string]int)
+	for _, cp := range contactPoints {
+		settings := map[string]interface{}{}
+		if cp.Settings != nil {
+			m, err := cp.Settings.Map()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read settings of contact point '%s': %w", cp.UID, err)
+			}
+			settings = m
+		}
+		receiver := ReceiverExport{
+			UID:                   cp.UID,
+			Type:                  cp.Type,
+			Settings:              settings,
+			DisableResolveMessage: cp.DisableResolveMessage,
+		}
+		i, ok := idx[cp.Name]
+		if !ok {
+			i = len(result)
+			idx[cp.Name] = i
+			result = append(result, ContactPointExport{OrgID: orgID, Name: cp.Name})
+		}
+		result[i].Receivers = append(result[i].Receivers, receiver)
+	}
+	return result, nil
+}
+
+// NewNotificationPolicyExport creates an export of the notification policy tree in the format of the file provisioning.
+func NewNotificationPolicyExport(orgID int64, tree Route) NotificationPolicyExport {
+	tree.Provenance = ""
+	return NotificationPolicyExport{
+		OrgID: orgID,
+		Route: tree,
+	}
+}
+
+// NewMuteTimeIntervalExport creates an export of the mute timing in the format of the file provisioning.
+func NewMuteTimeIntervalExport(orgID int64, mt MuteTimeInterval) MuteTimeIntervalExport {
+	return MuteTimeIntervalExport{
+		OrgID:            orgID,
+		MuteTimeInterval: mt.MuteTimeInterval,
+	}
+}
+
+// NewMessageTemplateExport creates an export of the template in the format of the file provisioning.
+func NewMessageTemplateExport(orgID int64, tmpl MessageTemplate) MessageTemplateExport {
+	return MessageTemplateExport{
+		OrgID:    orgID,
+		Name:     tmpl.Name,
+		Template: tmpl.Template,
+	}
+}
@@ -0,0 +1,167 @@
+package definitions
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestNewAlertRuleGroupExport(t *testing.T) {
+	t.Run("should convert group and rules", func(t *testing.T) {
+		enrichment := models.Enrichment{Type: models.TeamEnrichmentType, Keys: []string{"team"}, Annotations: map[string]string{"team_email": "email"}}
+		rule := models.AlertRuleGen(models.WithFor(5*time.Minute), models.WithIsPaused(true), models.WithEnrichments(enrichment))()
+		dashboardUID, panelID := "dashboard-uid", int64(42)
+		rule.DashboardUID = &dashboardUID
+		rule.PanelID = &panelID
+		rule.Data = []models.AlertQuery{
+			{
+				RefID:         "A",
+				DatasourceUID: "datasource-uid",
+				Model:         json.RawMessage(`{"expr":"up","intervalMs":1000}`),
+				RelativeTimeRange: models.RelativeTimeRange{
+					From: models.Duration(10 * time.Minute),
+				},
+			},
+		}
+		group := models.AlertRuleGroupWithFolderTitle{
+			AlertRuleGroup: models.AlertRuleGroup{
+				Title:     "my-group",
+				FolderUID: "folder-uid",
+				Interval:  120,
+				Rules:     []models.AlertRule{*rule},
+			},
+			OrgID:       3,
+			FolderTitle: "My Folder",
+		}
+
+		export, err := NewAlertRuleGroupExport(group)
+
+		require.NoError(t, err)
+		require.Equal(t, int64(3), export.OrgID)
+		require.Equal(t, "my-group", export.Name)
+		require.Equal(t, "My Folder", export.Folder)
+		require.Equal(t, "folder-uid", export.FolderUID)
+		require.Equal(t, model.Duration(2*time.Minute), export.Interval)
+		require.Len(t, export.Rules, 1)
+
+		exported := export.Rules[0]
+		require.Equal(t, rule.UID, exported.UID)
+		require.Equal(t, rule.Title, exported.Title)
+		require.Equal(t, rule.Condition, exported.Condition)
+		require.Equal(t, model.Duration(5*time.Minute), exported.For)
+		require.Equal(t, rule.NoDataState, exported.NoDataState)
+		require.Equal(t, rule.ExecErrState, exported.ExecErrState)
+		require.Equal(t, rule.Labels, exported.Labels)
+		require.Equal(t, rule.Annotations, exported.Annotations)
+		require.Equal(t, dashboardUID, exported.DashboardUID)
+		require.Equal(t, panelID, exported.PanelID)
+		require.True(t, exported.IsPaused)
+		require.Equal(t, []models.Enrichment{enrichment}, exported.Enrichments)
+		require.Equal(t, []AlertQueryExport{
+			{
+				RefID:         "A",
+				DatasourceUID: "datasource-uid",
+				Model:         map[string]interface{}{"expr": "up", "intervalMs": float64(1000)},
+				RelativeTimeRange: models.RelativeTimeRange{
+					From: models.Duration(10 * time.Minute),
+				},
+			},
+		}, exported.Data)
+	})
+
+	t.Run("should skip recording rules", func(t *testing.T) {
+		recording := models.AlertRuleGen(models.WithRecord("my_metric", models.LiveRecordTarget))()
+		alerting := models.AlertRuleGen()()
+		alerting.Data = []models.AlertQuery{{RefID: "A", Model: json.RawMessage(`{}`)}}
+		group := models.AlertRuleGroupWithFolderTitle{
+			AlertRuleGroup: models.AlertRuleGroup{
+				Rules: []models.AlertRule{*recording, *alerting},
+			},
+		}
+
+		export, err := NewAlertRuleGroupExport(group)
+
+		require.NoError(t, err)
+		require.Len(t, export.Rules, 1)
+		require.Equal(t, alerting.UID, export.Rules[0].UID)
+	})
+
+	t.Run("should fail to export a recording rule", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithRecord("my_metric", models.LiveRecordTarget))()
+
+		_, err := NewAlertRuleExport(*rule)
+
+		require.ErrorContains(t, err, "recording rule")
+	})
+}
+
+func TestNewContactPointExports(t *testing.T) {
+	cps := []EmbeddedContactPoint{
+		{
+			UID:      "uid-1",
+			Name:     "first",
+			Type:     "email",
+			Settings: simplejson.NewFromAny(map[string]interface{}{"addresses": "test@example.com"}),
+		},
+		{
+			UID:                   "uid-2",
+			Name:                  "second",
+			Type:                  "slack",
+			Settings:              simplejson.NewFromAny(map[string]interface{}{"url": RedactedValue}),
+			DisableResolveMessage: true,
+		},
+		{
+			UID:  "uid-3",
+			Name: "first",
+			Type: "webhook",
+		},
+	}
+
+	exports, err := NewContactPointExports(2, cps)
+
+	require.NoError(t, err)
+	require.Equal(t, []ContactPointExport{
+		{
+			OrgID: 2,
+			Name:  "first",
+			Receivers: []ReceiverExport{
+				{UID: "uid-1", Type: "email", Settings: map[string]interface{}{"addresses": "test@example.com"}},
+				{UID: "uid-3", Type: "webhook", Settings: map[string]interface{}{}},
+			},
+		},
+		{
+			OrgID: 2,
+			Name:  "second",
+			Receivers: []ReceiverExport{
+				{UID: "uid-2", Type: "slack", Settings: map[string]interface{}{"url": RedactedValue}, DisableResolveMessage: true},
+			},
+		},
+	}, exports)
+}
+
+func TestNewNotificationPolicyExport(t *testing.T) {
+	tree := Route{
+		Receiver:   "receiver",
+		Provenance: models.ProvenanceAPI,
+		Routes: []*Route{
+			{Receiver: "child"},
+		},
+	}
+
+	export := NewNotificationPolicyExport(4, tree)
+
+	require.Equal(t, int64(4), export.OrgID)
+	require.Equal(t, "receiver", export.Receiver)
+	require.Equal(t, models.ProvenanceNone, export.Provenance)
+	require.Len(t, export.Routes, 1)
+
+	data, err := json.Marshal(export)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"orgId":4,"receiver":"receiver","routes":[{"receiver":"child"}]}`, string(data))
+}
@@ -0,0 +1,293 @@
+package definitions
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// swagger:route GET /api/v1/provisioning/silence-templates provisioning stable RouteGetSilenceTemplates
+//
+// Get all the silence templates.
+//
+//     Responses:
+//       200: SilenceTemplates
+
+// swagger:route GET /api/v1/provisioning/silence-templates/{name} provisioning stable RouteGetSilenceTemplate
+//
+// Get a silence template.
+//
+//     Responses:
+//       200: SilenceTemplate
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/silence-templates provisioning stable RoutePostSilenceTemplate
+//
+// Create a new silence template.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: SilenceTemplate
+//       400: ValidationError
+
+// swagger:route PUT /api/v1/provisioning/silence-templates/{name} provisioning stable RoutePutSilenceTemplate
+//
+// Replace an existing silence template.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: SilenceTemplate
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route DELETE /api/v1/provisioning/silence-templates/{name} provisioning stable RouteDeleteSilenceTemplate
+//
+// Delete a silence template.
+//
+//     Responses:
+//       204: description: The silence template was deleted successfully.
+
+// swagger:route GET /api/v1/provisioning/recurring-silences provisioning stable RouteGetRecurringSilences
+//
+// Get all the recurring silences.
+//
+//     Responses:
+//       200: RecurringSilences
+
+// swagger:route GET /api/v1/provisioning/recurring-silences/export provisioning stable RouteGetRecurringSilencesExport
+//
+// Export all recurring silences in provisioning file format.
+//
+//     Produces:
+//     - application/json
+//     - application/yaml
+//
+//     Responses:
+//       200: AlertingFileExport
+//       400: ValidationError
+
+// swagger:route GET /api/v1/provisioning/recurring-silences/{name} provisioning stable RouteGetRecurringSilence
+//
+// Get a recurring silence.
+//
+//     Responses:
+//       200: RecurringSilence
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/recurring-silences provisioning stable RoutePostRecurringSilence
+//
+// Create a new recurring silence.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: RecurringSilence
+//       400: ValidationError
+
+// swagger:route PUT /api/v1/provisioning/recurring-silences/{name} provisioning stable RoutePutRecurringSilence
+//
+// Replace an existing recurring silence.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: RecurringSilence
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route DELETE /api/v1/provisioning/recurring-silences/{name} provisioning stable RouteDeleteRecurringSilence
+//
+// Delete a recurring silence. The silence that it created for the current window, if any, is expired shortly after.
+//
+//     Responses:
+//       204: description: The recurring silence was deleted successfully.
+
+// swagger:parameters RouteGetSilenceTemplate RoutePutSilenceTemplate RouteDeleteSilenceTemplate
+type SilenceTemplateNameParam struct {
+	// Silence template name
+	// in:path
+	Name string `json:"name"`
+}
+
+// swagger:parameters RoutePostSilenceTemplate RoutePutSilenceTemplate
+type SilenceTemplatePayload struct {
+	// in:body
+	Body SilenceTemplate
+}
+
+// swagger:parameters RouteGetRecurringSilence RoutePutRecurringSilence RouteDeleteRecurringSilence
+type RecurringSilenceNameParam struct {
+	// Recurring silence name
+	// in:path
+	Name string `json:"name"`
+}
+
+// swagger:parameters RoutePostRecurringSilence RoutePutRecurringSilence
+type RecurringSilencePayload struct {
+	// in:body
+	Body RecurringSilence
+}
+
+// swagger:model
+type SilenceTemplates []SilenceTemplate
+
+// SilenceTemplate is a reusable preset of the matchers and the duration of a silence.
+// swagger:model
+type SilenceTemplate struct {
+	Name     string         `json:"name" yaml:"name"`
+	Matchers ObjectMatchers `json:"matchers,omitempty" yaml:"matchers,omitempty"`
+	// Duration is the default duration of the silences that are created from the template.
+	Duration   model.Duration    `json:"duration" yaml:"duration"`
+	Comment    string            `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Provenance models.Provenance `json:"provenance,omitempty" yaml:"-"`
+}
+
+func (t *SilenceTemplate) ResourceType() string {
+	return "silenceTemplate"
+}
+
+func (t *SilenceTemplate) ResourceID() string {
+	return t.Name
+}
+
+func (t *SilenceTemplate) Validate() error {
+	if t.Name == "" {
+		return errors.New("name must not be empty")
+	}
+	if len(t.Matchers) == 0 {
+		return errors.New("at least one matcher is required")
+	}
+	if t.Duration <= 0 {
+		return errors.New("duration must be positive")
+	}
+	return nil
+}
+
+// Silence returns a silence with the matchers of the template that starts at the given time and lasts the duration
+// of the template.
+func (t *SilenceTemplate) Silence(startsAt time.Time, createdBy string) PostableSilence {
+	comment := t.Comment
+	if comment == "" {
+		comment = fmt.Sprintf("Created from the silence template %s", t.Name)
+	}
+	return newPostableSilence(t.Matchers, startsAt, startsAt.Add(time.Duration(t.Duration)), createdBy, comment)
+}
+
+// swagger:model
+type RecurringSilences []RecurringSilence
+
+// RecurringSilence is a silence that is created automatically for each window of a schedule.
+// swagger:model
+type RecurringSilence struct {
+	Name     string         `json:"name" yaml:"name"`
+	Matchers ObjectMatchers `json:"matchers,omitempty" yaml:"matchers,omitempty"`
+	// Schedule is a cron expression with five fields that defines when the windows start. The time zone can be set
+	// with the CRON_TZ=<zone> prefix, by default the time zone of the server is used.
+	Schedule string `json:"schedule" yaml:"schedule"`
+	// Duration is the length of each window.
+	Duration   model.Duration    `json:"duration" yaml:"duration"`
+	Comment    string            `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Provenance models.Provenance `json:"provenance,omitempty" yaml:"-"`
+}
+
+func (s *RecurringSilence) ResourceType() string {
+	return "recurringSilence"
+}
+
+func (s *RecurringSilence) ResourceID() string {
+	return s.Name
+}
+
+func (s *RecurringSilence) Validate() error {
+	if s.Name == "" {
+		return errors.New("name must not be empty")
+	}
+	if len(s.Matchers) == 0 {
+		return errors.New("at least one matcher is required")
+	}
+	if s.Duration <= 0 {
+		return errors.New("duration must be positive")
+	}
+	if _, err := cron.ParseStandard(s.Schedule); err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+	return nil
+}
+
+// Window returns the window of the schedule that contains the given time, and false if there is none. If the windows
+// overlap, the window that started first is returned.
+func (s *RecurringSilence) Window(now time.Time) (time.Time, time.Time, bool) {
+	schedule, err := cron.ParseStandard(s.Schedule)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	duration := time.Duration(s.Duration)
+	startsAt := schedule.Next(now.Add(-duration))
+	if startsAt.IsZero() || startsAt.After(now) {
+		return time.Time{}, time.Time{}, false
+	}
+	return startsAt, startsAt.Add(duration), true
+}
+
+// Silence returns the silence of the window that starts at the given time.
+func (s *RecurringSilence) Silence(startsAt time.Time, createdBy string) PostableSilence {
+	comment := s.Comment
+	if comment == "" {
+		comment = fmt.Sprintf("Created by the recurring silence %s", s.Name)
+	}
+	return newPostableSilence(s.Matchers, startsAt, startsAt.Add(time.Duration(s.Duration)), createdBy, comment)
+}
+
+// RecurringSilenceExport is the provisioned file export of RecurringSilence.
+type RecurringSilenceExport struct {
+	OrgID            int64 `json:"orgId" yaml:"orgId"`
+	RecurringSilence `yaml:",inline"`
+}
+
+// NewRecurringSilenceExport creates an export of the recurring silence in the format of the file provisioning.
+func NewRecurringSilenceExport(orgID int64, s RecurringSilence) RecurringSilenceExport {
+	s.Provenance = ""
+	return RecurringSilenceExport{
+		OrgID:            orgID,
+		RecurringSilence: s,
+	}
+}
+
+func newPostableSilence(matchers ObjectMatchers, startsAt, endsAt time.Time, createdBy, comment string) PostableSilence {
+	start, end := strfmt.DateTime(startsAt), strfmt.DateTime(endsAt)
+	silence := PostableSilence{
+		Silence: amv2.Silence{
+			Comment:   &comment,
+			CreatedBy: &createdBy,
+			StartsAt:  &start,
+			EndsAt:    &end,
+			Matchers:  make(amv2.Matchers, 0, len(matchers)),
+		},
+	}
+	for _, m := range matchers {
+		name, value := m.Name, m.Value
+		isEqual := m.Type == labels.MatchEqual || m.Type == labels.MatchRegexp
+		isRegex := m.Type == labels.MatchRegexp || m.Type == labels.MatchNotRegexp
+		silence.Matchers = append(silence.Matchers, &amv2.Matcher{
+			Name:    &name,
+			Value:   &value,
+			IsEqual: &isEqual,
+			IsRegex: &isRegex,
+		})
+	}
+	return silence
+}
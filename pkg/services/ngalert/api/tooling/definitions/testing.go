@@ -68,6 +68,25 @@ import (
 //     Responses:
 //       200: BacktestResult
 
+// swagger:route Post /api/v1/rule/backtest/group testing BacktestGroupConfig
+//
+// Test rule group with the notification policy tree
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestGroupResult
+
+// swagger:parameters BacktestGroupConfig
+type BacktestGroupRequest struct {
+	// in:body
+	Body BacktestGroupConfig
+}
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -98,6 +117,8 @@ type EvalQueriesRequest struct {
 type EvalQueriesPayload struct {
 	Data []models.AlertQuery `json:"data"`
 	Now  time.Time           `json:"now"`
+	// Debug adds the trace of the queries and expressions to the response.
+	Debug bool `json:"debug,omitempty"`
 }
 
 func (p *TestRulePayload) UnmarshalJSON(b []byte) error {
@@ -195,3 +216,48 @@ type BacktestConfig struct {
 
 // swagger:model
 type BacktestResult data.Frame
+
+// swagger:model
+type BacktestGroupConfig struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	// Namespace is the title of the folder of the rule group.
+	Namespace string                  `json:"namespace"`
+	Group     PostableRuleGroupConfig `json:"group"`
+	// Route is the notification policy tree that the alerts are routed with.
+	// If it is not specified, the current notification policy tree of the organization is used.
+	Route *Route `json:"route,omitempty"`
+}
+
+// swagger:model
+type BacktestGroupResult struct {
+	Rules         []BacktestRuleResult   `json:"rules"`
+	Notifications []BacktestNotification `json:"notifications"`
+	ContactPoints []BacktestContactPoint `json:"contact_points"`
+}
+
+type BacktestRuleResult struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+	// States contains the states of the alert instances of the rule at each evaluation,
+	// in the same format as the result of backtesting a single rule.
+	States *data.Frame `json:"states"`
+}
+
+// BacktestNotification is a notification that would be sent to a contact point.
+type BacktestNotification struct {
+	Time         time.Time         `json:"time"`
+	ContactPoint string            `json:"contact_point"`
+	GroupKey     string            `json:"group_key"`
+	GroupLabels  map[string]string `json:"group_labels"`
+	Firing       int               `json:"firing"`
+	Resolved     int               `json:"resolved"`
+}
+
+type BacktestContactPoint struct {
+	Name          string `json:"name"`
+	Notifications int    `json:"notifications"`
+	// Groups is the number of different groups of alerts that the contact point would be notified about.
+	Groups int `json:"groups"`
+}
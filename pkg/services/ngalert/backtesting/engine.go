@@ -11,10 +11,15 @@ import (
 	"github.com/benbjohnson/clock"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/hashicorp/go-multierror"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/common/model"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/user"
 )
@@ -34,6 +39,7 @@ type backtestingEvaluator interface {
 
 type stateManager interface {
 	ProcessEvalResults(ctx context.Context, evaluatedAt time.Time, alertRule *models.AlertRule, results eval.Results, extraLabels data.Labels) []state.StateTransition
+	GetRuleInstanceStates(alertRule *models.AlertRule) []expr.RuleInstanceState
 }
 
 type Engine struct {
@@ -54,15 +60,12 @@ func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models
 	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
 	logger := logger.FromContext(ctx)
 
-	if !from.Before(to) {
-		return nil, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
-	}
-	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
-		return nil, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
+	length, err := evaluationsCount(from, to, rule.IntervalSeconds)
+	if err != nil {
+		return nil, err
 	}
-	length := int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds)
 
-	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, user, rule.GetEvalCondition())
+	evaluator, err := backtestingEvaluatorFactory(eval.Context(ruleCtx, user), e.evalFactory, rule.GetEvalCondition())
 	if err != nil {
 		return nil, multierror.Append(ErrInvalidInputData, err)
 	}
@@ -73,45 +76,311 @@ func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models
 
 	start := time.Now()
 
-	tsField := data.NewField("Time", nil, make([]time.Time, length))
-	valueFields := make(map[string]*data.Field)
-
+	frame := newStatesFrame(from, length, rule.IntervalSeconds)
 	err = evaluator.Eval(ruleCtx, from, to, time.Duration(rule.IntervalSeconds)*time.Second, func(currentTime time.Time, results eval.Results) error {
-		idx := int(currentTime.Sub(from).Seconds()) / int(rule.IntervalSeconds)
 		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil)
-		tsField.Set(idx, currentTime)
-		for _, s := range states {
-			field, ok := valueFields[s.CacheID]
-			if !ok {
-				field = data.NewField("", s.Labels, make([]*string, length))
-				valueFields[s.CacheID] = field
+		frame.add(currentTime, states)
+		return nil
+	})
+	result := frame.frame()
+
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Rule testing finished successfully", "duration", time.Since(start))
+	return result, nil
+}
+
+// GroupResult is the result of testing a rule group.
+type GroupResult struct {
+	// States contains the states of the alert instances of each rule, by the UID of the rule,
+	// in the same format as the result of testing a single rule.
+	States map[string]*data.Frame
+	// Notifications contains the notifications that would be sent, in the order they would be sent.
+	Notifications []Notification
+	ContactPoints []ContactPointSummary
+}
+
+// TestGroup evaluates the rules of a group over the time range in the order of their dependencies, and replays
+// the alerts through the notification policy tree to find out which contact points would be notified, how often,
+// and how the alerts would be grouped. Recording rules are not evaluated because they do not create alerts.
+func (e *Engine) TestGroup(ctx context.Context, user *user.SignedInUser, rules []*models.AlertRule, folderTitle string, route *definitions.Route, from, to time.Time) (*GroupResult, error) {
+	logger := logger.FromContext(ctx)
+
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("%w: rule group has no rules", ErrInvalidInputData)
+	}
+	if route == nil {
+		return nil, fmt.Errorf("%w: notification policy tree is not specified", ErrInvalidInputData)
+	}
+	// validation also parses the labels that the alerts are grouped by
+	if err := route.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: invalid notification policy tree: %s", ErrInvalidInputData, err)
+	}
+	intervalSeconds := rules[0].IntervalSeconds
+	length, err := evaluationsCount(from, to, intervalSeconds)
+	if err != nil {
+		return nil, err
+	}
+	interval := time.Duration(intervalSeconds) * time.Second
+
+	ordered, err := dependencyOrder(rules)
+	if err != nil {
+		return nil, multierror.Append(ErrInvalidInputData, err)
+	}
+
+	stateManager := e.createStateManager()
+	reader := ruleStateFromBacktesting{manager: stateManager, rules: make(map[string]*models.AlertRule, len(rules))}
+	for _, rule := range rules {
+		reader.rules[rule.UID] = rule
+	}
+
+	logger.Info("Start testing rule group", "from", from, "to", to, "interval", intervalSeconds, "evaluations", length, "rules", len(rules))
+	start := time.Now()
+
+	// The rules that do not depend on other rules are evaluated over the whole time range at once. The other rules
+	// are evaluated at each evaluation after the rules they depend on. The states of the rules they depend on are read
+	// when their evaluator is created, therefore it is created again at each evaluation.
+	dependents := make(map[string]eval.EvaluationContext, len(ordered))
+	precomputed := make(map[string]map[time.Time]eval.Results, len(ordered))
+	for _, rule := range ordered {
+		if rule.IsRecordingRule() {
+			continue
+		}
+		ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
+		evalCtx := eval.Context(ruleCtx, user)
+		evalCtx.RuleStateReader = reader
+		evaluator, err := backtestingEvaluatorFactory(evalCtx, e.evalFactory, rule.GetEvalCondition())
+		if err != nil {
+			return nil, multierror.Append(ErrInvalidInputData, fmt.Errorf("rule '%s': %w", rule.Title, err))
+		}
+		if len(rule.GetDependencies()) > 0 {
+			dependents[rule.UID] = evalCtx
+			continue
+		}
+		resultsByTime := make(map[time.Time]eval.Results, length)
+		err = evaluator.Eval(ruleCtx, from, to, interval, func(now time.Time, results eval.Results) error {
+			resultsByTime[now] = results
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate rule '%s': %w", rule.Title, err)
+		}
+		precomputed[rule.UID] = resultsByTime
+	}
+
+	frames := make(map[string]*statesFrame, len(ordered))
+	alerts := make(map[string]map[string]model.LabelSet, len(ordered))
+	for _, rule := range ordered {
+		frames[rule.UID] = newStatesFrame(from, length, intervalSeconds)
+		alerts[rule.UID] = make(map[string]model.LabelSet)
+	}
+	simulator := newNotificationSimulator(dispatch.NewRoute(route.AsAMRoute(), nil))
+
+	for now := from; now.Before(to); now = now.Add(interval) {
+		for _, rule := range ordered {
+			if rule.IsRecordingRule() {
+				continue
 			}
-			if s.State.State != eval.NoData { // set nil if NoData
-				value := s.State.State.String()
-				if s.StateReason != "" {
-					value += " (" + s.StateReason + ")"
+			ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
+			results, ok := precomputed[rule.UID][now]
+			if evalCtx, dependent := dependents[rule.UID]; dependent {
+				evaluator, err := backtestingEvaluatorFactory(evalCtx, e.evalFactory, rule.GetEvalCondition())
+				if err != nil {
+					return nil, fmt.Errorf("failed to evaluate rule '%s': %w", rule.Title, err)
+				}
+				err = evaluator.Eval(ruleCtx, now, now.Add(interval), interval, func(_ time.Time, r eval.Results) error {
+					results, ok = r, true
+					return nil
+				})
+				if err != nil {
+					return nil, fmt.Errorf("failed to evaluate rule '%s': %w", rule.Title, err)
 				}
-				field.Set(idx, &value)
+			}
+			if !ok {
 				continue
 			}
+			states := stateManager.ProcessEvalResults(ruleCtx, now, rule, results, ruleExtraLabels(rule, folderTitle))
+			frames[rule.UID].add(now, states)
+			for _, s := range states {
+				if labels, firing := alertLabels(s.State); firing {
+					alerts[rule.UID][s.CacheID] = labels
+				} else {
+					delete(alerts[rule.UID], s.CacheID)
+				}
+			}
 		}
-		return nil
-	})
-	fields := make([]*data.Field, 0, len(valueFields)+1)
-	fields = append(fields, tsField)
-	for _, f := range valueFields {
-		fields = append(fields, f)
+
+		firing := make(map[model.Fingerprint]model.LabelSet)
+		for _, ruleAlerts := range alerts {
+			for _, labels := range ruleAlerts {
+				firing[labels.Fingerprint()] = labels
+			}
+		}
+		simulator.setAlerts(now, firing)
 	}
-	result := data.NewFrame("Backtesting results", fields...)
+	simulator.advance(to)
 
-	if err != nil {
+	result := &GroupResult{
+		States:        make(map[string]*data.Frame, len(frames)),
+		Notifications: simulator.notifications,
+		ContactPoints: simulator.summary(),
+	}
+	for uid, frame := range frames {
+		if reader.rules[uid].IsRecordingRule() {
+			continue
+		}
+		result.States[uid] = frame.frame()
+	}
+	logger.Info("Rule group testing finished successfully", "duration", time.Since(start), "notifications", len(result.Notifications))
+	return result, nil
+}
+
+// evaluationsCount returns the number of evaluations in the time range, or an error if the time range is not valid.
+func evaluationsCount(from, to time.Time, intervalSeconds int64) (int, error) {
+	if !from.Before(to) {
+		return 0, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
+	}
+	if to.Sub(from).Seconds() < float64(intervalSeconds) {
+		return 0, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), intervalSeconds)
+	}
+	return int(to.Sub(from).Seconds()) / int(intervalSeconds), nil
+}
+
+// statesFrame collects the states of the alert instances of a rule at each evaluation into a data frame
+// with a field per alert instance.
+type statesFrame struct {
+	from            time.Time
+	intervalSeconds int64
+	length          int
+	tsField         *data.Field
+	valueFields     map[string]*data.Field
+}
+
+func newStatesFrame(from time.Time, length int, intervalSeconds int64) *statesFrame {
+	return &statesFrame{
+		from:            from,
+		intervalSeconds: intervalSeconds,
+		length:          length,
+		tsField:         data.NewField("Time", nil, make([]time.Time, length)),
+		valueFields:     make(map[string]*data.Field),
+	}
+}
+
+func (f *statesFrame) add(currentTime time.Time, states []state.StateTransition) {
+	idx := int(currentTime.Sub(f.from).Seconds()) / int(f.intervalSeconds)
+	if idx >= f.length {
+		return
+	}
+	f.tsField.Set(idx, currentTime)
+	for _, s := range states {
+		field, ok := f.valueFields[s.CacheID]
+		if !ok {
+			field = data.NewField("", s.Labels, make([]*string, f.length))
+			f.valueFields[s.CacheID] = field
+		}
+		if s.State.State != eval.NoData { // set nil if NoData
+			value := s.State.State.String()
+			if s.StateReason != "" {
+				value += " (" + s.StateReason + ")"
+			}
+			field.Set(idx, &value)
+			continue
+		}
+	}
+}
+
+func (f *statesFrame) frame() *data.Frame {
+	fields := make([]*data.Field, 0, len(f.valueFields)+1)
+	fields = append(fields, f.tsField)
+	for _, field := range f.valueFields {
+		fields = append(fields, field)
+	}
+	return data.NewFrame("Backtesting results", fields...)
+}
+
+// ruleStateFromBacktesting reads the states of the rules of the tested group from the state manager of the backtesting.
+type ruleStateFromBacktesting struct {
+	manager stateManager
+	rules   map[string]*models.AlertRule
+}
+
+func (r ruleStateFromBacktesting) ReadRuleState(ruleUID string) ([]expr.RuleInstanceState, error) {
+	rule, ok := r.rules[ruleUID]
+	if !ok {
+		return nil, fmt.Errorf("rule %s is not in the tested rule group", ruleUID)
+	}
+	return r.manager.GetRuleInstanceStates(rule), nil
+}
+
+// dependencyOrder returns the rules ordered so that each rule comes after the rules it depends on.
+func dependencyOrder(rules []*models.AlertRule) ([]*models.AlertRule, error) {
+	if err := models.RulesGroup(rules).ValidateDependencies(); err != nil {
 		return nil, err
 	}
-	logger.Info("Rule testing finished successfully", "duration", time.Since(start))
+	byUID := make(map[string]*models.AlertRule, len(rules))
+	for _, rule := range rules {
+		byUID[rule.UID] = rule
+	}
+	result := make([]*models.AlertRule, 0, len(rules))
+	visited := make(map[string]struct{}, len(rules))
+	var visit func(rule *models.AlertRule)
+	visit = func(rule *models.AlertRule) {
+		if _, ok := visited[rule.UID]; ok {
+			return
+		}
+		visited[rule.UID] = struct{}{}
+		for _, uid := range rule.GetDependencies() {
+			visit(byUID[uid])
+		}
+		result = append(result, rule)
+	}
+	for _, rule := range rules {
+		visit(rule)
+	}
 	return result, nil
 }
 
-func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, user *user.SignedInUser, condition models.Condition) (backtestingEvaluator, error) {
+// ruleExtraLabels returns the labels that the scheduler adds to the alert instances of the rule.
+func ruleExtraLabels(rule *models.AlertRule, folderTitle string) data.Labels {
+	labels := data.Labels{
+		models.NamespaceUIDLabel: rule.NamespaceUID,
+		model.AlertNameLabel:     rule.Title,
+		models.RuleUIDLabel:      rule.UID,
+	}
+	if folderTitle != "" {
+		labels[models.FolderTitleLabel] = folderTitle
+	}
+	return labels
+}
+
+// alertLabels returns the labels of the alert that is sent to the Alertmanager for the state,
+// or false if the state is not firing.
+func alertLabels(s *state.State) (model.LabelSet, bool) {
+	var alertName string
+	switch s.State {
+	case eval.Alerting:
+	case eval.NoData:
+		alertName = schedule.NoDataAlertName
+	case eval.Error:
+		alertName = schedule.ErrorAlertName
+	default:
+		return nil, false
+	}
+	labels := make(model.LabelSet, len(s.Labels)+1)
+	for k, v := range s.Labels {
+		labels[model.LabelName(k)] = model.LabelValue(v)
+	}
+	if alertName != "" {
+		if name, ok := labels[model.AlertNameLabel]; ok {
+			labels[schedule.Rulename] = name
+		}
+		labels[model.AlertNameLabel] = model.LabelValue(alertName)
+	}
+	return labels, true
+}
+
+func newBacktestingEvaluator(evalCtx eval.EvaluationContext, evalFactory eval.EvaluatorFactory, condition models.Condition) (backtestingEvaluator, error) {
 	for _, q := range condition.Data {
 		if q.DatasourceUID == "__data__" || q.QueryType == "__data__" {
 			if len(condition.Data) != 1 {
@@ -137,9 +406,7 @@ func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFact
 		}
 	}
 
-	evaluator, err := evalFactory.Create(eval.EvaluationContext{Ctx: ctx,
-		User: user,
-	}, condition)
+	evaluator, err := evalFactory.Create(evalCtx, condition)
 
 	if err != nil {
 		return nil, err
@@ -10,13 +10,18 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prommodel "github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -144,7 +149,7 @@ func TestNewBacktestingEvaluator(t *testing.T) {
 
 		for _, testCase := range testCases {
 			t.Run(testCase.name, func(t *testing.T) {
-				e, err := newBacktestingEvaluator(context.Background(), evalFactory, nil, testCase.condition)
+				e, err := newBacktestingEvaluator(eval.Context(context.Background(), nil), evalFactory, testCase.condition)
 				if testCase.error {
 					require.Error(t, err)
 					return
@@ -174,7 +179,7 @@ func TestEvaluatorTest(t *testing.T) {
 	}
 	manager := &fakeStateManager{}
 
-	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user *user.SignedInUser, condition models.Condition) (backtestingEvaluator, error) {
+	backtestingEvaluatorFactory = func(evalCtx eval.EvaluationContext, evalFactory eval.EvaluatorFactory, condition models.Condition) (backtestingEvaluator, error) {
 		return evaluator, nil
 	}
 
@@ -355,6 +360,10 @@ func (f *fakeStateManager) ProcessEvalResults(_ context.Context, evaluatedAt tim
 	return f.stateCallback(evaluatedAt)
 }
 
+func (f *fakeStateManager) GetRuleInstanceStates(_ *models.AlertRule) []expr.RuleInstanceState {
+	return nil
+}
+
 type fakeBacktestingEvaluator struct {
 	evalCallback func(now time.Time) (eval.Results, error)
 }
@@ -374,3 +383,88 @@ func (f *fakeBacktestingEvaluator) Eval(_ context.Context, from, to time.Time, i
 	}
 	return nil
 }
+
+func TestEngineTestGroup(t *testing.T) {
+	interval := time.Minute
+	upstream := models.AlertRuleGen(models.WithInterval(interval), models.WithFor(0), models.WithTitle("upstream"))()
+	upstream.Labels = nil
+	upstream.Condition = "upstream"
+	dependent := models.AlertRuleGen(models.WithInterval(interval), models.WithFor(0), models.WithTitle("dependent"), models.WithDependencies(upstream.UID))()
+	dependent.Labels = nil
+	dependent.Condition = "dependent"
+	// the dependent rule fires when the upstream rule fires in the same evaluation
+	dependent.Data = []models.AlertQuery{{
+		RefID:         "dependent",
+		DatasourceUID: expr.DatasourceUID,
+		Model:         json.RawMessage(fmt.Sprintf(`{"type": "rule_state", "ruleUid": %q, "output": "state"}`, upstream.UID)),
+	}}
+
+	from := time.Unix(0, 0)
+	to := from.Add(10 * interval)
+	// the upstream rule fires from the second to the fifth minute
+	isFiring := func(now time.Time) bool {
+		return !now.Before(from.Add(2*interval)) && now.Before(from.Add(6*interval))
+	}
+
+	backtestingEvaluatorFactory = func(evalCtx eval.EvaluationContext, evalFactory eval.EvaluatorFactory, condition models.Condition) (backtestingEvaluator, error) {
+		if condition.Condition == upstream.Condition {
+			return &fakeBacktestingEvaluator{evalCallback: func(now time.Time) (eval.Results, error) {
+				s := eval.Normal
+				if isFiring(now) {
+					s = eval.Alerting
+				}
+				return eval.Results{{Instance: data.Labels{"host": "a"}, State: s, EvaluatedAt: now}}, nil
+			}}, nil
+		}
+		// the dependent rule is created by the evaluator factory, which provides the states of the upstream rule
+		return newBacktestingEvaluator(evalCtx, evalFactory, condition)
+	}
+	t.Cleanup(func() {
+		backtestingEvaluatorFactory = newBacktestingEvaluator
+	})
+
+	groupWait := prommodel.Duration(0)
+	route := &definitions.Route{
+		Receiver:   "default",
+		GroupByStr: []string{"alertname"},
+		GroupWait:  &groupWait,
+	}
+	evalFactory := eval.NewEvaluatorFactory(setting.UnifiedAlertingSettings{}, nil, expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, nil, nil), &plugins.FakePluginStore{})
+	engine := NewEngine(nil, evalFactory)
+	signedInUser := &user.SignedInUser{OrgID: upstream.OrgID}
+
+	// the dependent rule is listed first to check that the rules are evaluated in the order of their dependencies
+	result, err := engine.TestGroup(context.Background(), signedInUser, []*models.AlertRule{dependent, upstream}, "folder", route, from, to)
+	require.NoError(t, err)
+
+	require.Len(t, result.States, 2)
+	for _, uid := range []string{upstream.UID, dependent.UID} {
+		frame := result.States[uid]
+		require.NotNil(t, frame)
+		require.Len(t, frame.Fields, 2)
+		for i := 0; i < frame.Fields[1].Len(); i++ {
+			expected := eval.Normal.String()
+			if isFiring(from.Add(time.Duration(i) * interval)) {
+				expected = eval.Alerting.String()
+			}
+			v := frame.Fields[1].At(i).(*string)
+			require.NotNil(t, v)
+			require.Equalf(t, expected, *v, "rule %s at evaluation %d", uid, i)
+		}
+	}
+
+	require.Len(t, result.Notifications, 4)
+	for _, n := range result.Notifications {
+		require.Equal(t, "default", n.ContactPoint)
+	}
+	firstUpstream := result.Notifications[0]
+	require.Equal(t, from.Add(2*interval), firstUpstream.Time)
+	require.Equal(t, 1, firstUpstream.Firing)
+	require.Contains(t, []string{"upstream", "dependent"}, firstUpstream.GroupLabels["alertname"])
+	require.Equal(t, []ContactPointSummary{{ContactPoint: "default", Notifications: 4, Groups: 2}}, result.ContactPoints)
+
+	t.Run("should fail if the policy tree is not specified", func(t *testing.T) {
+		_, err := engine.TestGroup(context.Background(), nil, []*models.AlertRule{upstream}, "folder", nil, from, to)
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+}
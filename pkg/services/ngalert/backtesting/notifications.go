@@ -0,0 +1,240 @@
+package backtesting
+
+import (
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/common/model"
+)
+
+// Notification is a notification that the Alertmanager would send to a contact point.
+type Notification struct {
+	Time         time.Time
+	ContactPoint string
+	// GroupKey identifies the notification policy and the values of the labels that the alerts are grouped by.
+	GroupKey    string
+	GroupLabels data.Labels
+	Firing      int
+	Resolved    int
+}
+
+// ContactPointSummary summarizes the notifications sent to a contact point.
+type ContactPointSummary struct {
+	ContactPoint  string
+	Notifications int
+	// Groups is the number of different groups of alerts that the contact point was notified about.
+	Groups int
+}
+
+// notificationSimulator replays the alerts through the notification policy tree and simulates the aggregation groups
+// and the notification log of the Alertmanager to find out when each contact point would be notified.
+// Mute timings, silences and inhibition rules are not taken into account.
+type notificationSimulator struct {
+	route *dispatch.Route
+	// alerts contains the keys of the groups of each firing alert.
+	alerts        map[model.Fingerprint][]string
+	groups        map[string]*aggregationGroup
+	log           map[string]*notificationLogEntry
+	notifications []Notification
+}
+
+type aggregationGroup struct {
+	key      string
+	route    *dispatch.Route
+	labels   model.LabelSet
+	firing   map[model.Fingerprint]struct{}
+	resolved map[model.Fingerprint]struct{}
+	// next is the time of the next flush of the group.
+	next time.Time
+}
+
+type notificationLogEntry struct {
+	timestamp time.Time
+	firing    map[model.Fingerprint]struct{}
+	resolved  map[model.Fingerprint]struct{}
+}
+
+func newNotificationSimulator(route *dispatch.Route) *notificationSimulator {
+	return &notificationSimulator{
+		route:  route,
+		alerts: make(map[model.Fingerprint][]string),
+		groups: make(map[string]*aggregationGroup),
+		log:    make(map[string]*notificationLogEntry),
+	}
+}
+
+// setAlerts sets the alerts that are firing at the time. The alerts that are not firing anymore are resolved.
+// The groups that are due before or at the time are flushed first.
+func (s *notificationSimulator) setAlerts(now time.Time, firing map[model.Fingerprint]model.LabelSet) {
+	s.advance(now)
+
+	for fp, groupKeys := range s.alerts {
+		if _, ok := firing[fp]; ok {
+			continue
+		}
+		for _, key := range groupKeys {
+			if g, ok := s.groups[key]; ok {
+				delete(g.firing, fp)
+				g.resolved[fp] = struct{}{}
+			}
+		}
+		delete(s.alerts, fp)
+	}
+
+	for fp, labels := range firing {
+		if _, ok := s.alerts[fp]; ok {
+			continue
+		}
+		routes := s.route.Match(labels)
+		groupKeys := make([]string, 0, len(routes))
+		for _, route := range routes {
+			groupLabels := getGroupLabels(labels, route)
+			key := route.Key() + ":" + groupLabels.String()
+			g, ok := s.groups[key]
+			if !ok {
+				g = &aggregationGroup{
+					key:      key,
+					route:    route,
+					labels:   groupLabels,
+					firing:   make(map[model.Fingerprint]struct{}),
+					resolved: make(map[model.Fingerprint]struct{}),
+					next:     now.Add(route.RouteOpts.GroupWait),
+				}
+				s.groups[key] = g
+			}
+			delete(g.resolved, fp)
+			g.firing[fp] = struct{}{}
+			groupKeys = append(groupKeys, key)
+		}
+		s.alerts[fp] = groupKeys
+	}
+}
+
+// advance flushes the groups that are due before or at the time, in the order of their flushes.
+func (s *notificationSimulator) advance(now time.Time) {
+	for {
+		var next *aggregationGroup
+		for _, g := range s.groups {
+			if g.next.After(now) {
+				continue
+			}
+			if next == nil || g.next.Before(next.next) || g.next.Equal(next.next) && g.key < next.key {
+				next = g
+			}
+		}
+		if next == nil {
+			return
+		}
+		s.flush(next)
+	}
+}
+
+// flush sends the notification of the group if it is needed, following the rules of the Alertmanager.
+func (s *notificationSimulator) flush(g *aggregationGroup) {
+	at := g.next
+	entry := s.log[g.key]
+	if needsNotification(entry, g.firing, g.resolved, at, g.route.RouteOpts.RepeatInterval) {
+		s.notifications = append(s.notifications, Notification{
+			Time:         at,
+			ContactPoint: g.route.RouteOpts.Receiver,
+			GroupKey:     g.key,
+			GroupLabels:  toDataLabels(g.labels),
+			Firing:       len(g.firing),
+			Resolved:     len(g.resolved),
+		})
+		s.log[g.key] = &notificationLogEntry{
+			timestamp: at,
+			firing:    copyFingerprints(g.firing),
+			resolved:  copyFingerprints(g.resolved),
+		}
+	}
+	g.resolved = make(map[model.Fingerprint]struct{})
+	if len(g.firing) == 0 {
+		delete(s.groups, g.key)
+		return
+	}
+	g.next = at.Add(g.route.RouteOpts.GroupInterval)
+}
+
+// summary returns the number of notifications and groups of each contact point, sorted by contact point.
+func (s *notificationSimulator) summary() []ContactPointSummary {
+	byContactPoint := make(map[string]*ContactPointSummary)
+	groups := make(map[string]map[string]struct{})
+	for _, n := range s.notifications {
+		summary, ok := byContactPoint[n.ContactPoint]
+		if !ok {
+			summary = &ContactPointSummary{ContactPoint: n.ContactPoint}
+			byContactPoint[n.ContactPoint] = summary
+			groups[n.ContactPoint] = make(map[string]struct{})
+		}
+		summary.Notifications++
+		groups[n.ContactPoint][n.GroupKey] = struct{}{}
+	}
+	result := make([]ContactPointSummary, 0, len(byContactPoint))
+	for name, summary := range byContactPoint {
+		summary.Groups = len(groups[name])
+		result = append(result, *summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ContactPoint < result[j].ContactPoint
+	})
+	return result
+}
+
+// needsNotification is the equivalent of the deduplication of notifications of the Alertmanager, for contact points
+// that send resolved notifications.
+func needsNotification(entry *notificationLogEntry, firing, resolved map[model.Fingerprint]struct{}, now time.Time, repeat time.Duration) bool {
+	// If the group was not notified before, notify unless it has only resolved alerts.
+	if entry == nil {
+		return len(firing) > 0
+	}
+	if !isSubset(firing, entry.firing) {
+		return true
+	}
+	// Notify that all alerts are resolved, unless the receiver was not notified about them.
+	if len(firing) == 0 {
+		return len(entry.firing) > 0
+	}
+	if !isSubset(resolved, entry.resolved) {
+		return true
+	}
+	// Nothing changed, only notify if the repeat interval has passed.
+	return entry.timestamp.Before(now.Add(-repeat))
+}
+
+func getGroupLabels(labels model.LabelSet, route *dispatch.Route) model.LabelSet {
+	groupLabels := model.LabelSet{}
+	for ln, lv := range labels {
+		if _, ok := route.RouteOpts.GroupBy[ln]; ok || route.RouteOpts.GroupByAll {
+			groupLabels[ln] = lv
+		}
+	}
+	return groupLabels
+}
+
+func isSubset(subset, set map[model.Fingerprint]struct{}) bool {
+	for fp := range subset {
+		if _, ok := set[fp]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func copyFingerprints(fps map[model.Fingerprint]struct{}) map[model.Fingerprint]struct{} {
+	result := make(map[model.Fingerprint]struct{}, len(fps))
+	for fp := range fps {
+		result[fp] = struct{}{}
+	}
+	return result
+}
+
+func toDataLabels(labels model.LabelSet) data.Labels {
+	result := make(data.Labels, len(labels))
+	for ln, lv := range labels {
+		result[string(ln)] = string(lv)
+	}
+	return result
+}
@@ -0,0 +1,83 @@
+package backtesting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestNotificationSimulator(t *testing.T) {
+	duration := func(d time.Duration) *model.Duration {
+		md := model.Duration(d)
+		return &md
+	}
+	matcher, err := labels.NewMatcher(labels.MatchEqual, "team", "a")
+	require.NoError(t, err)
+	route := &definitions.Route{
+		Receiver:       "default",
+		GroupByStr:     []string{"alertname"},
+		GroupWait:      duration(30 * time.Second),
+		GroupInterval:  duration(5 * time.Minute),
+		RepeatInterval: duration(time.Hour),
+		Routes: []*definitions.Route{
+			{
+				Receiver:       "team-a",
+				ObjectMatchers: definitions.ObjectMatchers{matcher},
+			},
+		},
+	}
+	require.NoError(t, route.Validate())
+
+	x := model.LabelSet{"alertname": "high-cpu", "team": "a", "instance": "x"}
+	y := model.LabelSet{"alertname": "high-cpu", "team": "a", "instance": "y"}
+	z := model.LabelSet{"alertname": "disk-full", "team": "b"}
+	alerts := func(sets ...model.LabelSet) map[model.Fingerprint]model.LabelSet {
+		result := make(map[model.Fingerprint]model.LabelSet, len(sets))
+		for _, s := range sets {
+			result[s.Fingerprint()] = s
+		}
+		return result
+	}
+
+	start := time.Unix(0, 0)
+	at := func(d time.Duration) time.Time {
+		return start.Add(d)
+	}
+
+	simulator := newNotificationSimulator(dispatch.NewRoute(route.AsAMRoute(), nil))
+	simulator.setAlerts(at(0), alerts(x, z))
+	// z is resolved before the group wait, so its contact point is never notified
+	simulator.setAlerts(at(10*time.Second), alerts(x))
+	simulator.setAlerts(at(time.Minute), alerts(x, y))
+	simulator.setAlerts(at(80*time.Minute), alerts())
+	simulator.advance(at(2 * time.Hour))
+
+	expected := []Notification{
+		// first flush of the group after the group wait
+		{Time: at(30 * time.Second), ContactPoint: "team-a", Firing: 1},
+		// y is notified at the next group interval
+		{Time: at(5*time.Minute + 30*time.Second), ContactPoint: "team-a", Firing: 2},
+		// nothing changed, the notification is repeated at the first group interval after the repeat interval
+		{Time: at(70*time.Minute + 30*time.Second), ContactPoint: "team-a", Firing: 2},
+		// all alerts are resolved
+		{Time: at(80*time.Minute + 30*time.Second), ContactPoint: "team-a", Resolved: 2},
+	}
+	require.Len(t, simulator.notifications, len(expected))
+	for i, n := range simulator.notifications {
+		require.Equal(t, expected[i].Time, n.Time, "notification %d", i)
+		require.Equal(t, expected[i].ContactPoint, n.ContactPoint, "notification %d", i)
+		require.Equal(t, expected[i].Firing, n.Firing, "notification %d", i)
+		require.Equal(t, expected[i].Resolved, n.Resolved, "notification %d", i)
+		require.Equal(t, data.Labels{"alertname": "high-cpu"}, n.GroupLabels)
+	}
+	require.Empty(t, simulator.groups)
+
+	require.Equal(t, []ContactPointSummary{{ContactPoint: "team-a", Notifications: 4, Groups: 1}}, simulator.summary())
+}
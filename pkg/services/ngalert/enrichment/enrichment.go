@@ -0,0 +1,317 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// sqlQueryTimeRange is the time range of the queries of SQL lookups. Lookup tables usually do not depend on time
+// but the time range is required by the data sources and can be used by the query with the time macros.
+const sqlQueryTimeRange = 10 * time.Minute
+
+// TeamStore is the subset of team.Service that is used by team lookups.
+type TeamStore interface {
+	SearchTeams(ctx context.Context, query *models.SearchTeamsQuery) error
+}
+
+// UserStore is the subset of user.Service that is used by user lookups.
+type UserStore interface {
+	GetSignedInUser(ctx context.Context, query *user.GetSignedInUserQuery) (*user.SignedInUser, error)
+}
+
+// Enricher adds the labels and annotations of the enrichments of a rule to the results of its evaluation.
+type Enricher struct {
+	evaluatorFactory eval.EvaluatorFactory
+	teams            TeamStore
+	users            UserStore
+	log              log.Logger
+}
+
+func NewEnricher(evaluatorFactory eval.EvaluatorFactory, teams TeamStore, users UserStore, log log.Logger) *Enricher {
+	return &Enricher{
+		evaluatorFactory: evaluatorFactory,
+		teams:            teams,
+		users:            users,
+		log:              log,
+	}
+}
+
+// Enrich runs the enrichments of the rule in order and returns the enriched results. The labels are added to the
+// instance of each result unless the instance already has them, and the annotations are added to the annotations
+// of the result. A result is not enriched if it does not have all keys of an enrichment or if no row matches them.
+// Lookups that fail are logged and skipped, so that a failing source does not prevent the alerts of the rule.
+func (e *Enricher) Enrich(ctx eval.EvaluationContext, rule *ngmodels.AlertRule, results eval.Results) eval.Results {
+	if len(rule.Enrichments) == 0 {
+		return results
+	}
+	logger := e.log.FromContext(ctx.Ctx).New("rule_uid", rule.UID, "org_id", rule.OrgID)
+	enriched := make(eval.Results, 0, len(results))
+	for _, result := range results {
+		result.Instance = result.Instance.Copy()
+		if result.Instance == nil {
+			result.Instance = data.Labels{}
+		}
+		if result.Annotations != nil {
+			result.Annotations = result.Annotations.Copy()
+		}
+		enriched = append(enriched, result)
+	}
+
+	for i, enrichment := range rule.Enrichments {
+		lookup, err := e.newLookup(ctx, rule.OrgID, enrichment)
+		if err != nil {
+			logger.Warn("Failed to prepare the lookup of an enrichment, skipping it", "enrichment", i, "type", enrichment.Type, "error", err)
+			continue
+		}
+		for j := range enriched {
+			values, ok := keyValues(enrichment.Keys, enriched[j].Instance)
+			if !ok {
+				continue
+			}
+			row, err := lookup(values)
+			if err != nil {
+				logger.Warn("Failed to look up the row of an enrichment, skipping it", "enrichment", i, "type", enrichment.Type, "instance", enriched[j].Instance, "error", err)
+				continue
+			}
+			if row == nil {
+				continue
+			}
+			apply(&enriched[j], enrichment, row)
+		}
+	}
+	return enriched
+}
+
+// lookup returns the row whose key fields are equal to the given values, or nil if there is none.
+type lookup func(values []string) (map[string]string, error)
+
+func (e *Enricher) newLookup(ctx eval.EvaluationContext, orgID int64, enrichment ngmodels.Enrichment) (lookup, error) {
+	switch enrichment.Type {
+	case ngmodels.TableEnrichmentType:
+		rows, err := enrichment.TableRows()
+		if err != nil {
+			return nil, err
+		}
+		return newTableLookup(enrichment.Keys, rows), nil
+	case ngmodels.SQLEnrichmentType:
+		rows, err := e.querySQL(ctx, enrichment)
+		if err != nil {
+			return nil, err
+		}
+		return newTableLookup(enrichment.Keys, rows), nil
+	case ngmodels.TeamEnrichmentType:
+		if e.teams == nil {
+			return nil, errors.New("team lookups are not supported")
+		}
+		return cached(func(values []string) (map[string]string, error) {
+			return e.lookupTeam(ctx.Ctx, orgID, values[0])
+		}), nil
+	case ngmodels.UserEnrichmentType:
+		if e.users == nil {
+			return nil, errors.New("user lookups are not supported")
+		}
+		return cached(func(values []string) (map[string]string, error) {
+			return e.lookupUser(ctx.Ctx, orgID, values[0])
+		}), nil
+	}
+	return nil, fmt.Errorf("unknown enrichment type %q", enrichment.Type)
+}
+
+func newTableLookup(keys []string, rows []map[string]string) lookup {
+	index := make(map[string]map[string]string, len(rows))
+	for _, row := range rows {
+		values, ok := keyValues(keys, row)
+		if !ok {
+			continue
+		}
+		// the first row of a key wins
+		if _, exists := index[indexKey(values)]; !exists {
+			index[indexKey(values)] = row
+		}
+	}
+	return func(values []string) (map[string]string, error) {
+		return index[indexKey(values)], nil
+	}
+}
+
+// cached returns a lookup that calls the given lookup once per key.
+func cached(l lookup) lookup {
+	cache := map[string]map[string]string{}
+	return func(values []string) (map[string]string, error) {
+		key := indexKey(values)
+		if row, ok := cache[key]; ok {
+			return row, nil
+		}
+		row, err := l(values)
+		if err != nil {
+			return nil, err
+		}
+		cache[key] = row
+		return row, nil
+	}
+}
+
+// querySQL executes the query of a SQL lookup and returns the rows of the frames of the response.
+func (e *Enricher) querySQL(ctx eval.EvaluationContext, enrichment ngmodels.Enrichment) ([]map[string]string, error) {
+	const refID = "A"
+	model, err := json.Marshal(map[string]interface{}{
+		"refId":  refID,
+		"rawSql": enrichment.Query,
+		"format": "table",
+	})
+	if err != nil {
+		return nil, err
+	}
+	condition := ngmodels.Condition{
+		Condition: refID,
+		Data: []ngmodels.AlertQuery{{
+			RefID:             refID,
+			DatasourceUID:     enrichment.DatasourceUID,
+			Model:             model,
+			RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(sqlQueryTimeRange)},
+		}},
+	}
+	evaluator, err := e.evaluatorFactory.Create(ctx, condition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the query: %w", err)
+	}
+	resp, err := evaluator.EvaluateRaw(ctx.Ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute the query: %w", err)
+	}
+	res, ok := resp.Responses[refID]
+	if !ok {
+		return nil, errors.New("the query returned no response")
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to execute the query: %w", res.Error)
+	}
+	return rowsFromFrames(res.Frames), nil
+}
+
+// rowsFromFrames converts the rows of the frames to maps of field names to values.
+func rowsFromFrames(frames data.Frames) []map[string]string {
+	var rows []map[string]string
+	for _, frame := range frames {
+		length, err := frame.RowLen()
+		if err != nil {
+			continue
+		}
+		for i := 0; i < length; i++ {
+			row := make(map[string]string, len(frame.Fields))
+			for _, field := range frame.Fields {
+				v, ok := field.ConcreteAt(i)
+				if !ok {
+					continue
+				}
+				if t, isTime := v.(time.Time); isTime {
+					row[field.Name] = t.Format(time.RFC3339)
+					continue
+				}
+				row[field.Name] = fmt.Sprint(v)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func (e *Enricher) lookupTeam(ctx context.Context, orgID int64, name string) (map[string]string, error) {
+	query := &models.SearchTeamsQuery{
+		OrgId: orgID,
+		Name:  name,
+		Limit: 1,
+		Page:  1,
+		SignedInUser: &user.SignedInUser{
+			UserID:           -1,
+			IsServiceAccount: true,
+			Login:            "grafana_scheduler",
+			OrgID:            orgID,
+			OrgRole:          org.RoleAdmin,
+			Permissions: map[int64]map[string][]string{
+				orgID: {accesscontrol.ActionTeamsRead: {accesscontrol.ScopeTeamsAll}},
+			},
+		},
+	}
+	if err := e.teams.SearchTeams(ctx, query); err != nil {
+		return nil, err
+	}
+	if len(query.Result.Teams) == 0 {
+		return nil, nil
+	}
+	team := query.Result.Teams[0]
+	return map[string]string{
+		"name":  team.Name,
+		"email": team.Email,
+	}, nil
+}
+
+// lookupUser returns the user with the login only if it is a member of the organization of the rule,
+// so that the rules of an organization cannot read the users of other organizations.
+func (e *Enricher) lookupUser(ctx context.Context, orgID int64, login string) (map[string]string, error) {
+	u, err := e.users.GetSignedInUser(ctx, &user.GetSignedInUserQuery{Login: login, OrgID: orgID})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if u.OrgID != orgID {
+		return nil, nil
+	}
+	return map[string]string{
+		"login": u.Login,
+		"name":  u.Name,
+		"email": u.Email,
+	}, nil
+}
+
+// apply adds the labels and annotations of the enrichment to the result. Fields that are missing or empty in the row are skipped.
+func apply(result *eval.Result, enrichment ngmodels.Enrichment, row map[string]string) {
+	for name, field := range enrichment.Labels {
+		if v := row[field]; v != "" {
+			if _, exists := result.Instance[name]; !exists {
+				result.Instance[name] = v
+			}
+		}
+	}
+	for name, field := range enrichment.Annotations {
+		if v := row[field]; v != "" {
+			if result.Annotations == nil {
+				result.Annotations = data.Labels{}
+			}
+			result.Annotations[name] = v
+		}
+	}
+}
+
+// keyValues returns the values of the keys in the given labels, and false if any of them is missing.
+func keyValues(keys []string, labels map[string]string) ([]string, bool) {
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		v, ok := labels[key]
+		if !ok {
+			return nil, false
+		}
+		values = append(values, v)
+	}
+	return values, true
+}
+
+func indexKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
@@ -0,0 +1,204 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+)
+
+type fakeTeamStore struct {
+	teams   []*models.TeamDTO
+	queries []string
+}
+
+func (f *fakeTeamStore) SearchTeams(_ context.Context, query *models.SearchTeamsQuery) error {
+	f.queries = append(f.queries, query.Name)
+	query.Result = models.SearchTeamQueryResult{Teams: []*models.TeamDTO{}}
+	for _, t := range f.teams {
+		if t.OrgId == query.OrgId && t.Name == query.Name {
+			query.Result.Teams = append(query.Result.Teams, t)
+		}
+	}
+	return nil
+}
+
+func TestEnrich(t *testing.T) {
+	ctx := eval.Context(context.Background(), &user.SignedInUser{OrgID: 1})
+	results := eval.Results{
+		{Instance: data.Labels{"service": "api"}, State: eval.Alerting},
+		{Instance: data.Labels{"service": "web"}, State: eval.Normal},
+		{Instance: data.Labels{"job": "batch"}, State: eval.Alerting},
+	}
+
+	t.Run("returns the results unchanged if the rule has no enrichments", func(t *testing.T) {
+		enricher := NewEnricher(nil, nil, nil, log.NewNopLogger())
+		require.Equal(t, results, enricher.Enrich(ctx, &ngmodels.AlertRule{}, results))
+	})
+
+	t.Run("adds labels and annotations from a table", func(t *testing.T) {
+		enricher := NewEnricher(nil, nil, nil, log.NewNopLogger())
+		rule := &ngmodels.AlertRule{Enrichments: []ngmodels.Enrichment{{
+			Type:        ngmodels.TableEnrichmentType,
+			Keys:        []string{"service"},
+			Labels:      map[string]string{"owner": "team"},
+			Annotations: map[string]string{"runbook_url": "runbook"},
+			CSV:         "service,team,runbook\napi,backend,https://runbooks/api",
+		}}}
+
+		enriched := enricher.Enrich(ctx, rule, results)
+
+		require.Equal(t, data.Labels{"service": "api", "owner": "backend"}, enriched[0].Instance)
+		require.Equal(t, data.Labels{"runbook_url": "https://runbooks/api"}, enriched[0].Annotations)
+		require.Equal(t, results[1], enriched[1])
+		require.Equal(t, results[2], enriched[2])
+		// the original results are not modified
+		require.Equal(t, data.Labels{"service": "api"}, results[0].Instance)
+	})
+
+	t.Run("does not override the labels of the instance", func(t *testing.T) {
+		enricher := NewEnricher(nil, nil, nil, log.NewNopLogger())
+		rule := &ngmodels.AlertRule{Enrichments: []ngmodels.Enrichment{{
+			Type:   ngmodels.TableEnrichmentType,
+			Keys:   []string{"service"},
+			Labels: map[string]string{"service": "name"},
+			Rows:   []map[string]string{{"service": "api", "name": "API"}},
+		}}}
+
+		enriched := enricher.Enrich(ctx, rule, results)
+
+		require.Equal(t, data.Labels{"service": "api"}, enriched[0].Instance)
+	})
+
+	t.Run("uses the labels of the previous enrichments as keys", func(t *testing.T) {
+		teams := &fakeTeamStore{teams: []*models.TeamDTO{
+			{OrgId: 1, Name: "backend", Email: "backend@example.com"},
+			{OrgId: 2, Name: "backend", Email: "other-org@example.com"},
+		}}
+		enricher := NewEnricher(nil, teams, nil, log.NewNopLogger())
+		rule := &ngmodels.AlertRule{OrgID: 1, Enrichments: []ngmodels.Enrichment{
+			{
+				Type:   ngmodels.TableEnrichmentType,
+				Keys:   []string{"service"},
+				Labels: map[string]string{"owner": "team"},
+				Rows:   []map[string]string{{"service": "api", "team": "backend"}, {"service": "web", "team": "frontend"}},
+			},
+			{
+				Type:        ngmodels.TeamEnrichmentType,
+				Keys:        []string{"owner"},
+				Annotations: map[string]string{"owner_email": "email"},
+			},
+		}}
+
+		enriched := enricher.Enrich(ctx, rule, results)
+
+		require.Equal(t, data.Labels{"owner_email": "backend@example.com"}, enriched[0].Annotations)
+		require.Equal(t, data.Labels{"service": "web", "owner": "frontend"}, enriched[1].Instance)
+		require.Nil(t, enriched[1].Annotations)
+		require.Equal(t, []string{"backend", "frontend"}, teams.queries)
+	})
+
+	t.Run("adds annotations from a user", func(t *testing.T) {
+		var queries []*user.GetSignedInUserQuery
+		users := &usertest.FakeUserService{GetSignedInUserFn: func(_ context.Context, query *user.GetSignedInUserQuery) (*user.SignedInUser, error) {
+			queries = append(queries, query)
+			return &user.SignedInUser{Login: "jdoe", Name: "Jane Doe", Email: "jdoe@example.com", OrgID: query.OrgID}, nil
+		}}
+		enricher := NewEnricher(nil, nil, users, log.NewNopLogger())
+		rule := &ngmodels.AlertRule{OrgID: 1, Enrichments: []ngmodels.Enrichment{{
+			Type:        ngmodels.UserEnrichmentType,
+			Keys:        []string{"job"},
+			Annotations: map[string]string{"assignee": "name"},
+		}}}
+
+		enriched := enricher.Enrich(ctx, rule, results)
+
+		require.Nil(t, enriched[0].Annotations)
+		require.Equal(t, data.Labels{"assignee": "Jane Doe"}, enriched[2].Annotations)
+		require.Len(t, queries, 1)
+		require.Equal(t, int64(1), queries[0].OrgID)
+	})
+
+	t.Run("does not add annotations from a user of another organization", func(t *testing.T) {
+		// the user exists but is not a member of the organization of the rule
+		users := &usertest.FakeUserService{ExpectedSignedInUser: &user.SignedInUser{Login: "jdoe", Name: "Jane Doe", OrgID: -1}}
+		enricher := NewEnricher(nil, nil, users, log.NewNopLogger())
+		rule := &ngmodels.AlertRule{OrgID: 1, Enrichments: []ngmodels.Enrichment{{
+			Type:        ngmodels.UserEnrichmentType,
+			Keys:        []string{"job"},
+			Annotations: map[string]string{"assignee": "name"},
+		}}}
+
+		enriched := enricher.Enrich(ctx, rule, results)
+
+		for _, result := range enriched {
+			require.Nil(t, result.Annotations)
+		}
+	})
+
+	t.Run("adds labels from the rows of a SQL query", func(t *testing.T) {
+		evaluator := &eval_mocks.ConditionEvaluatorMock{}
+		evaluator.EXPECT().EvaluateRaw(mock.Anything, mock.Anything).Return(&backend.QueryDataResponse{
+			Responses: backend.Responses{"A": backend.DataResponse{Frames: data.Frames{
+				data.NewFrame("",
+					data.NewField("service", nil, []string{"api", "web"}),
+					data.NewField("tier", nil, []int64{1, 2}),
+				),
+			}}},
+		}, nil)
+		enricher := NewEnricher(eval_mocks.NewEvaluatorFactory(evaluator), nil, nil, log.NewNopLogger())
+		rule := &ngmodels.AlertRule{Enrichments: []ngmodels.Enrichment{{
+			Type:          ngmodels.SQLEnrichmentType,
+			Keys:          []string{"service"},
+			Labels:        map[string]string{"tier": "tier"},
+			DatasourceUID: "mysql",
+			Query:         "SELECT service, tier FROM services",
+		}}}
+
+		enriched := enricher.Enrich(ctx, rule, results)
+
+		require.Equal(t, data.Labels{"service": "api", "tier": "1"}, enriched[0].Instance)
+		require.Equal(t, data.Labels{"service": "web", "tier": "2"}, enriched[1].Instance)
+		evaluator.AssertNumberOfCalls(t, "EvaluateRaw", 1)
+	})
+
+	t.Run("skips enrichments whose lookup fails", func(t *testing.T) {
+		enricher := NewEnricher(eval_mocks.NewFailingEvaluatorFactory(errors.New("datasource not found")), nil, nil, log.NewNopLogger())
+		rule := &ngmodels.AlertRule{Enrichments: []ngmodels.Enrichment{
+			{
+				Type:          ngmodels.SQLEnrichmentType,
+				Keys:          []string{"service"},
+				Labels:        map[string]string{"tier": "tier"},
+				DatasourceUID: "mysql",
+				Query:         "SELECT service, tier FROM services",
+			},
+			{
+				Type:   ngmodels.TeamEnrichmentType,
+				Keys:   []string{"service"},
+				Labels: map[string]string{"owner_email": "email"},
+			},
+			{
+				Type:   ngmodels.TableEnrichmentType,
+				Keys:   []string{"service"},
+				Labels: map[string]string{"owner": "team"},
+				Rows:   []map[string]string{{"service": "api", "team": "backend"}},
+			},
+		}}
+
+		enriched := enricher.Enrich(ctx, rule, results)
+
+		require.Equal(t, data.Labels{"service": "api", "owner": "backend"}, enriched[0].Instance)
+	})
+}
@@ -3,13 +3,30 @@ package eval
 import (
 	"context"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
+// AlertingResultsReader provides the labels of the alert instances whose condition was met at the previous evaluation.
+type AlertingResultsReader interface {
+	Read() []data.Labels
+}
+
+// RuleStateReader provides the current state of the alert instances of the rules that a rule depends on.
+type RuleStateReader interface {
+	ReadRuleState(ruleUID string) ([]expr.RuleInstanceState, error)
+}
+
 // EvaluationContext represents the context in which a condition is evaluated.
 type EvaluationContext struct {
 	Ctx  context.Context
 	User *user.SignedInUser
+	// AlertingResultsReader is used by threshold expressions with a recovery threshold. It can be nil.
+	AlertingResultsReader AlertingResultsReader
+	// RuleStateReader is used by rule state expressions. It can be nil.
+	RuleStateReader RuleStateReader
 }
 
 func Context(ctx context.Context, user *user.SignedInUser) EvaluationContext {
@@ -18,3 +35,13 @@ func Context(ctx context.Context, user *user.SignedInUser) EvaluationContext {
 		User: user,
 	}
 }
+
+// NewContextWithPreviousResults creates an EvaluationContext that provides the results of the previous evaluation
+// to the expressions that need them.
+func NewContextWithPreviousResults(ctx context.Context, user *user.SignedInUser, reader AlertingResultsReader) EvaluationContext {
+	return EvaluationContext{
+		Ctx:                   ctx,
+		User:                  user,
+		AlertingResultsReader: reader,
+	}
+}
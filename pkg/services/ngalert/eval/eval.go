@@ -4,6 +4,7 @@ package eval
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"runtime/debug"
@@ -170,6 +171,10 @@ type Result struct {
 	// as EvalMatches (from "classic condition"), and in the future from operations
 	// like SSE "math".
 	EvaluationString string
+
+	// Annotations are added to the annotations of the rule after the evaluation, for example by enrichments.
+	// They take precedence over the annotations of the rule.
+	Annotations data.Labels
 }
 
 func NewResultFromError(err error, evaluatedAt time.Time, duration time.Duration) Result {
@@ -251,6 +256,18 @@ func getExprRequest(ctx EvaluationContext, data []models.AlertQuery, dsCacheServ
 		if err != nil {
 			return nil, fmt.Errorf("failed to get query model from '%s': %w", q.RefID, err)
 		}
+		if ctx.AlertingResultsReader != nil && expr.IsDataSource(q.DatasourceUID) {
+			model, err = setLoadedDimensions(model, ctx.AlertingResultsReader)
+			if err != nil {
+				return nil, fmt.Errorf("failed to set the previous results to '%s': %w", q.RefID, err)
+			}
+		}
+		if ctx.RuleStateReader != nil && expr.IsDataSource(q.DatasourceUID) {
+			model, err = setRuleStates(model, ctx.RuleStateReader)
+			if err != nil {
+				return nil, fmt.Errorf("failed to set the rule state to '%s': %w", q.RefID, err)
+			}
+		}
 		interval, err := q.GetIntervalDuration()
 		if err != nil {
 			return nil, fmt.Errorf("failed to retrieve intervalMs from '%s': %w", q.RefID, err)
@@ -287,6 +304,39 @@ func getExprRequest(ctx EvaluationContext, data []models.AlertQuery, dsCacheServ
 	return req, nil
 }
 
+// setLoadedDimensions adds the labels of the instances whose condition was met at the previous evaluation
+// to the model of a threshold expression that has a recovery threshold. Other models are returned unchanged.
+func setLoadedDimensions(model []byte, reader AlertingResultsReader) ([]byte, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(model, &m); err != nil {
+		return nil, err
+	}
+	if !expr.IsHysteresisExpression(m) {
+		return model, nil
+	}
+	expr.SetLoadedDimensions(m, reader.Read())
+	return json.Marshal(m)
+}
+
+// setRuleStates adds the current state of the alert instances of the referenced rule to the model of
+// a rule state expression. Other models are returned unchanged.
+func setRuleStates(model []byte, reader RuleStateReader) ([]byte, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(model, &m); err != nil {
+		return nil, err
+	}
+	uid, ok := expr.GetRuleStateRuleUID(m)
+	if !ok {
+		return model, nil
+	}
+	states, err := reader.ReadRuleState(uid)
+	if err != nil {
+		return nil, err
+	}
+	expr.SetRuleStates(m, states)
+	return json.Marshal(m)
+}
+
 type NumberValueCapture struct {
 	Var    string // RefID
 	Labels data.Labels
@@ -544,3 +544,46 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+type fakeAlertingResultsReader []data.Labels
+
+func (f fakeAlertingResultsReader) Read() []data.Labels {
+	return f
+}
+
+func TestSetLoadedDimensions(t *testing.T) {
+	reader := fakeAlertingResultsReader{{"host": "a"}, {"host": "b"}}
+
+	t.Run("adds the alerting results to a threshold with a recovery threshold", func(t *testing.T) {
+		model := []byte(`{"refId": "B", "type": "threshold", "expression": "A", "conditions": [{"evaluator": {"type": "gt", "params": [90]}, "unloadEvaluator": {"type": "lt", "params": [80]}}]}`)
+
+		result, err := setLoadedDimensions(model, reader)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"refId": "B", "type": "threshold", "expression": "A", "conditions": [{"evaluator": {"type": "gt", "params": [90]}, "unloadEvaluator": {"type": "lt", "params": [80]}}], "loadedDimensions": [{"host": "a"}, {"host": "b"}]}`, string(result))
+	})
+
+	t.Run("adds empty alerting results if no instance is alerting", func(t *testing.T) {
+		model := []byte(`{"type": "threshold", "expression": "A", "conditions": [{"evaluator": {"type": "gt", "params": [90]}, "unloadEvaluator": {"type": "lt", "params": [80]}}]}`)
+
+		result, err := setLoadedDimensions(model, fakeAlertingResultsReader(nil))
+		require.NoError(t, err)
+		require.Contains(t, string(result), `"loadedDimensions":[]`)
+	})
+
+	t.Run("returns other models unchanged", func(t *testing.T) {
+		for _, model := range []string{
+			`{"type": "threshold", "expression": "A", "conditions": [{"evaluator": {"type": "gt", "params": [90]}}]}`,
+			`{"type": "math", "expression": "$A > 90"}`,
+			`{"expr": "up"}`,
+		} {
+			result, err := setLoadedDimensions([]byte(model), reader)
+			require.NoError(t, err)
+			require.Equal(t, model, string(result))
+		}
+	})
+
+	t.Run("fails if the model is not valid JSON", func(t *testing.T) {
+		_, err := setLoadedDimensions([]byte(`{`), reader)
+		require.Error(t, err)
+	})
+}
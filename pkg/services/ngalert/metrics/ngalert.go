@@ -54,6 +54,7 @@ type Scheduler struct {
 	SchedulePeriodicDuration            prometheus.Histogram
 	SchedulableAlertRules               prometheus.Gauge
 	SchedulableAlertRulesHash           prometheus.Gauge
+	OwnedAlertRules                     prometheus.Gauge
 	UpdateSchedulableAlertRulesDuration prometheus.Histogram
 	Ticker                              *ticker.Metrics
 	EvaluationMissed                    *prometheus.CounterVec
@@ -183,6 +184,14 @@ func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 				Help:      "The number of alert rules that could be considered for evaluation at the next tick.",
 			},
 		),
+		OwnedAlertRules: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_owned_alert_rules",
+				Help:      "The number of alert rules that are evaluated by this instance when the rule groups are sharded between the instances of the cluster.",
+			},
+		),
 		SchedulableAlertRulesHash: promauto.With(r).NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: Namespace,
@@ -119,6 +119,7 @@ const (
 const (
 	StateReasonMissingSeries = "MissingSeries"
 	StateReasonError         = "Error"
+	StateReasonPaused        = "Paused"
 )
 
 var (
@@ -143,6 +144,13 @@ type AlertRuleGroup struct {
 	Rules      []AlertRule
 }
 
+// AlertRuleGroupWithFolderTitle extends AlertRuleGroup with the organization and the title of the folder it belongs to.
+type AlertRuleGroupWithFolderTitle struct {
+	AlertRuleGroup
+	OrgID       int64
+	FolderTitle string
+}
+
 // AlertRule is the model for alert rules in unified alerting.
 type AlertRule struct {
 	ID              int64 `xorm:"pk autoincr 'id'"`
@@ -166,6 +174,12 @@ type AlertRule struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// Record is nil unless the rule is a recording rule.
+	Record *Record `xorm:"json record"`
+	// IsPaused is true if the rule is not evaluated by the scheduler.
+	IsPaused bool `xorm:"is_paused"`
+	// Enrichments add labels and annotations from lookup sources to the alert instances of the rule.
+	Enrichments []Enrichment `xorm:"json enrichments"`
 }
 
 // GetDashboardUID returns the DashboardUID or "".
@@ -207,6 +221,11 @@ func (alertRule *AlertRule) GetLabels(opts ...LabelOption) map[string]string {
 	return labels
 }
 
+// IsRecordingRule returns true if the rule writes its results as a metric instead of alerting.
+func (alertRule *AlertRule) IsRecordingRule() bool {
+	return alertRule.Record != nil
+}
+
 func (alertRule *AlertRule) GetEvalCondition() Condition {
 	return Condition{
 		Condition: alertRule.Condition,
@@ -345,6 +364,12 @@ type AlertRuleVersion struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// Record is nil unless the rule is a recording rule.
+	Record *Record `xorm:"json record"`
+	// IsPaused is true if the rule is not evaluated by the scheduler.
+	IsPaused bool `xorm:"is_paused"`
+	// Enrichments add labels and annotations from lookup sources to the alert instances of the rule.
+	Enrichments []Enrichment `xorm:"json enrichments"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -438,7 +463,7 @@ func (c Condition) IsValid() bool {
 
 // PatchPartialAlertRule patches `ruleToPatch` by `existingRule` following the rule that if a field of `ruleToPatch` is empty or has the default value, it is populated by the value of the corresponding field from `existingRule`.
 // There are several exceptions:
-// 1. Following fields are not patched and therefore will be ignored: AlertRule.ID, AlertRule.OrgID, AlertRule.Updated, AlertRule.Version, AlertRule.UID, AlertRule.DashboardUID, AlertRule.PanelID, AlertRule.Annotations and AlertRule.Labels
+// 1. Following fields are not patched and therefore will be ignored: AlertRule.ID, AlertRule.OrgID, AlertRule.Updated, AlertRule.Version, AlertRule.UID, AlertRule.DashboardUID, AlertRule.PanelID, AlertRule.Annotations, AlertRule.Labels, AlertRule.Record, AlertRule.IsPaused and AlertRule.Enrichments
 // 2. There are fields that are patched together:
 //   - AlertRule.Condition and AlertRule.Data
 //
@@ -481,6 +506,16 @@ func ValidateRuleGroupInterval(intervalSeconds, baseIntervalSeconds int64) error
 
 type RulesGroup []*AlertRule
 
+// IsPaused returns true if the group has rules and all of them are paused.
+func (g RulesGroup) IsPaused() bool {
+	for _, rule := range g {
+		if !rule.IsPaused {
+			return false
+		}
+	}
+	return len(g) > 0
+}
+
 func (g RulesGroup) SortByGroupIndex() {
 	sort.Slice(g, func(i, j int) bool {
 		if g[i].RuleGroupIndex == g[j].RuleGroupIndex {
@@ -664,6 +664,17 @@ func TestSortByGroupIndex(t *testing.T) {
 	})
 }
 
+func TestRulesGroupIsPaused(t *testing.T) {
+	require.False(t, RulesGroup{}.IsPaused())
+	require.False(t, RulesGroup(GenerateAlertRules(3, AlertRuleGen(WithIsPaused(false)))).IsPaused())
+
+	rules := GenerateAlertRules(3, AlertRuleGen(WithIsPaused(true)))
+	require.True(t, RulesGroup(rules).IsPaused())
+
+	rules = append(rules, AlertRuleGen(WithIsPaused(false))())
+	require.False(t, RulesGroup(rules).IsPaused())
+}
+
 func TestTimeRangeYAML(t *testing.T) {
 	yamlRaw := "from: 600\nto: 0\n"
 	var rtr RelativeTimeRange
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// EnrichmentType is the lookup source of an enrichment.
+type EnrichmentType string
+
+const (
+	// TableEnrichmentType looks up the rows of a static table that is declared in the enrichment.
+	TableEnrichmentType EnrichmentType = "table"
+	// SQLEnrichmentType looks up the rows returned by a query of a SQL data source.
+	SQLEnrichmentType EnrichmentType = "sql"
+	// TeamEnrichmentType looks up the Grafana team whose name is the value of the key label.
+	// The row has the fields name and email.
+	TeamEnrichmentType EnrichmentType = "team"
+	// UserEnrichmentType looks up the member of the organization of the rule whose login is the value of the key label.
+	// The row has the fields login, name and email.
+	UserEnrichmentType EnrichmentType = "user"
+)
+
+// Enrichment adds labels and annotations to the alert instances of a rule. The values are taken from the row
+// of a lookup source whose key fields are equal to the labels of the instance with the same names.
+// Enrichments run after the evaluation of the rule, in the order they are declared, so an enrichment
+// can use the labels added by the previous ones as keys.
+type Enrichment struct {
+	Type EnrichmentType `json:"type" yaml:"type"`
+	// Keys are the names of the instance labels that select the row. Team and user lookups accept a single key.
+	Keys []string `json:"keys" yaml:"keys"`
+	// Labels maps the names of the labels that are added to the instance to the fields of the row.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// Annotations maps the names of the annotations that are added to the instance to the fields of the row.
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	// Rows is the table of a table lookup.
+	Rows []map[string]string `json:"rows,omitempty" yaml:"rows,omitempty"`
+	// CSV is the table of a table lookup in CSV format. The first line is the header with the names of the fields.
+	CSV string `json:"csv,omitempty" yaml:"csv,omitempty"`
+	// DatasourceUID is the data source of a SQL lookup.
+	DatasourceUID string `json:"datasourceUid,omitempty" yaml:"datasourceUid,omitempty"`
+	// Query is the query of a SQL lookup. It is executed once per evaluation and must return the key fields as columns.
+	Query string `json:"query,omitempty" yaml:"query,omitempty"`
+}
+
+// Validate checks that the enrichment has keys, adds at least one label or annotation, and has the settings
+// of its lookup source.
+func (e *Enrichment) Validate() error {
+	if len(e.Keys) == 0 {
+		return fmt.Errorf("%w: enrichment must have at least one key", ErrAlertRuleFailedValidation)
+	}
+	if len(e.Labels) == 0 && len(e.Annotations) == 0 {
+		return fmt.Errorf("%w: enrichment must add at least one label or annotation", ErrAlertRuleFailedValidation)
+	}
+	for name := range e.Labels {
+		if _, ok := InternalLabelNameSet[name]; ok {
+			return fmt.Errorf("%w: enrichment cannot set the reserved label %q", ErrAlertRuleFailedValidation, name)
+		}
+	}
+	switch e.Type {
+	case TableEnrichmentType:
+		if len(e.Rows) > 0 && e.CSV != "" {
+			return fmt.Errorf("%w: table enrichment must have either rows or csv", ErrAlertRuleFailedValidation)
+		}
+		if _, err := e.TableRows(); err != nil {
+			return fmt.Errorf("%w: %s", ErrAlertRuleFailedValidation, err.Error())
+		}
+	case SQLEnrichmentType:
+		if e.DatasourceUID == "" || strings.TrimSpace(e.Query) == "" {
+			return fmt.Errorf("%w: sql enrichment must have a datasource and a query", ErrAlertRuleFailedValidation)
+		}
+	case TeamEnrichmentType, UserEnrichmentType:
+		if len(e.Keys) != 1 {
+			return fmt.Errorf("%w: %s enrichment must have exactly one key", ErrAlertRuleFailedValidation, e.Type)
+		}
+	default:
+		return fmt.Errorf("%w: unknown enrichment type %q, must be one of %s, %s, %s or %s", ErrAlertRuleFailedValidation, e.Type, TableEnrichmentType, SQLEnrichmentType, TeamEnrichmentType, UserEnrichmentType)
+	}
+	return nil
+}
+
+// TableRows returns the rows of a table lookup. The rows declared in CSV format are parsed.
+func (e *Enrichment) TableRows() ([]map[string]string, error) {
+	if e.CSV == "" {
+		return e.Rows, nil
+	}
+	records, err := csv.NewReader(strings.NewReader(e.CSV)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, field := range header {
+			row[strings.TrimSpace(field)] = strings.TrimSpace(record[i])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
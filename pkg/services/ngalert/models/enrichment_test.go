@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnrichmentValidate(t *testing.T) {
+	t.Run("accepts a valid enrichment", func(t *testing.T) {
+		testCases := []Enrichment{
+			{Type: TableEnrichmentType, Keys: []string{"service"}, Labels: map[string]string{"owner": "team"}, Rows: []map[string]string{{"service": "api", "team": "backend"}}},
+			{Type: TableEnrichmentType, Keys: []string{"service"}, Annotations: map[string]string{"runbook_url": "runbook"}, CSV: "service,runbook\napi,https://runbooks/api"},
+			{Type: SQLEnrichmentType, Keys: []string{"service"}, Labels: map[string]string{"owner": "team"}, DatasourceUID: "mysql", Query: "SELECT service, team FROM services"},
+			{Type: TeamEnrichmentType, Keys: []string{"owner"}, Annotations: map[string]string{"owner_email": "email"}},
+			{Type: UserEnrichmentType, Keys: []string{"assignee"}, Annotations: map[string]string{"assignee_name": "name"}},
+		}
+		for _, e := range testCases {
+			require.NoError(t, e.Validate())
+		}
+	})
+
+	t.Run("rejects an invalid enrichment", func(t *testing.T) {
+		testCases := []struct {
+			name       string
+			enrichment Enrichment
+		}{
+			{name: "no keys", enrichment: Enrichment{Type: TeamEnrichmentType, Labels: map[string]string{"owner_email": "email"}}},
+			{name: "nothing added", enrichment: Enrichment{Type: TeamEnrichmentType, Keys: []string{"owner"}}},
+			{name: "reserved label", enrichment: Enrichment{Type: TeamEnrichmentType, Keys: []string{"owner"}, Labels: map[string]string{RuleUIDLabel: "name"}}},
+			{name: "unknown type", enrichment: Enrichment{Type: "ldap", Keys: []string{"owner"}, Labels: map[string]string{"owner_email": "email"}}},
+			{name: "rows and csv", enrichment: Enrichment{Type: TableEnrichmentType, Keys: []string{"service"}, Labels: map[string]string{"owner": "team"}, Rows: []map[string]string{{"service": "api"}}, CSV: "service\napi"}},
+			{name: "invalid csv", enrichment: Enrichment{Type: TableEnrichmentType, Keys: []string{"service"}, Labels: map[string]string{"owner": "team"}, CSV: "service,team\napi"}},
+			{name: "sql without query", enrichment: Enrichment{Type: SQLEnrichmentType, Keys: []string{"service"}, Labels: map[string]string{"owner": "team"}, DatasourceUID: "mysql"}},
+			{name: "team with two keys", enrichment: Enrichment{Type: TeamEnrichmentType, Keys: []string{"owner", "service"}, Labels: map[string]string{"owner_email": "email"}}},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				require.ErrorIs(t, tc.enrichment.Validate(), ErrAlertRuleFailedValidation)
+			})
+		}
+	})
+}
+
+func TestEnrichmentTableRows(t *testing.T) {
+	e := Enrichment{CSV: "service, team\napi, backend\nweb, frontend"}
+	rows, err := e.TableRows()
+	require.NoError(t, err)
+	require.Equal(t, []map[string]string{
+		{"service": "api", "team": "backend"},
+		{"service": "web", "team": "frontend"},
+	}, rows)
+}
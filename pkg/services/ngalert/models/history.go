@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// HistoryQuery represents a query for the history of the evaluations of an alert rule.
+type HistoryQuery struct {
+	OrgID   int64
+	RuleUID string
+	// Labels filters the alert instances to those with all the given labels.
+	Labels map[string]string
+	From   time.Time
+	To     time.Time
+	// Limit is the maximum number of evaluations to return. The latest evaluations are returned.
+	Limit int
+}
@@ -54,6 +54,8 @@ type ListAlertInstancesQuery struct {
 	RuleUID     string
 	State       InstanceStateType
 	StateReason string
+	// EvaluatedAfter selects the instances that were last written after this time. It is ignored if it is zero.
+	EvaluatedAfter time.Time
 
 	Result []*AlertInstance
 }
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// RecordTarget is where a recording rule writes its series.
+type RecordTarget string
+
+const (
+	// RemoteWriteRecordTarget writes the series to the Prometheus remote write endpoint
+	// configured in [unified_alerting.recording_rules].
+	RemoteWriteRecordTarget RecordTarget = "remote_write"
+	// LiveRecordTarget publishes the series to the Grafana Live channel
+	// stream/recording_rules/<metric> of the organization of the rule.
+	LiveRecordTarget RecordTarget = "live"
+)
+
+var metricNameRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// Record is the part of a recording rule that describes which query or expression
+// is recorded and where its series are written.
+type Record struct {
+	// Metric is the name of the metric the series are written to.
+	Metric string `json:"metric"`
+	// From is the RefID of the query or expression whose series are recorded.
+	From string `json:"from"`
+	// Target is where the series are written to.
+	Target RecordTarget `json:"target"`
+}
+
+// Validate checks that the metric name is a valid Prometheus metric name, that the target is known,
+// and that From refers to one of the given queries.
+func (r *Record) Validate(data []AlertQuery) error {
+	if !metricNameRegexp.MatchString(r.Metric) {
+		return fmt.Errorf("%w: invalid metric name %q", ErrAlertRuleFailedValidation, r.Metric)
+	}
+	switch r.Target {
+	case RemoteWriteRecordTarget, LiveRecordTarget:
+	default:
+		return fmt.Errorf("%w: unknown record target %q, must be one of %s or %s", ErrAlertRuleFailedValidation, r.Target, RemoteWriteRecordTarget, LiveRecordTarget)
+	}
+	for _, q := range data {
+		if q.RefID == r.From {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: record refers to an unknown query or expression %q", ErrAlertRuleFailedValidation, r.From)
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordValidate(t *testing.T) {
+	data := []AlertQuery{{RefID: "A"}, {RefID: "B"}}
+
+	t.Run("accepts a valid record", func(t *testing.T) {
+		for _, target := range []RecordTarget{RemoteWriteRecordTarget, LiveRecordTarget} {
+			r := &Record{Metric: "job:http_requests:rate5m", From: "B", Target: target}
+			require.NoError(t, r.Validate(data))
+		}
+	})
+
+	t.Run("rejects an invalid record", func(t *testing.T) {
+		testCases := []struct {
+			name   string
+			record Record
+		}{
+			{name: "empty metric name", record: Record{From: "A", Target: LiveRecordTarget}},
+			{name: "invalid metric name", record: Record{Metric: "http-requests", From: "A", Target: LiveRecordTarget}},
+			{name: "unknown target", record: Record{Metric: "up", From: "A", Target: "kafka"}},
+			{name: "unknown query", record: Record{Metric: "up", From: "C", Target: LiveRecordTarget}},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				require.ErrorIs(t, tc.record.Validate(data), ErrAlertRuleFailedValidation)
+			})
+		}
+	})
+}
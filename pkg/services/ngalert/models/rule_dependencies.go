@@ -0,0 +1,118 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/expr"
+)
+
+// GetDependencies returns the UIDs of the rules whose state is read by the rule state expressions of the rule.
+func (alertRule *AlertRule) GetDependencies() []string {
+	var result []string
+	seen := make(map[string]struct{})
+	for _, q := range alertRule.Data {
+		// skip parsing the models that cannot be rule state expressions
+		if !expr.IsDataSource(q.DatasourceUID) || !bytes.Contains(q.Model, []byte(expr.TypeRuleState.String())) {
+			continue
+		}
+		var model map[string]interface{}
+		if err := json.Unmarshal(q.Model, &model); err != nil {
+			continue
+		}
+		uid, ok := expr.GetRuleStateRuleUID(model)
+		if !ok {
+			continue
+		}
+		if _, ok := seen[uid]; ok {
+			continue
+		}
+		seen[uid] = struct{}{}
+		result = append(result, uid)
+	}
+	return result
+}
+
+// ValidateDependencies checks that the rules of the group depend only on alerting rules of the same group,
+// and that the dependencies do not form a cycle, so that the group can be evaluated in dependency order.
+func (g RulesGroup) ValidateDependencies() error {
+	byUID := make(map[string]*AlertRule, len(g))
+	for _, rule := range g {
+		if rule.UID != "" {
+			byUID[rule.UID] = rule
+		}
+	}
+	for _, rule := range g {
+		for _, uid := range rule.GetDependencies() {
+			dependency, ok := byUID[uid]
+			if !ok {
+				return fmt.Errorf("%w: rule '%s' depends on rule '%s' that is not in the same rule group", ErrAlertRuleFailedValidation, rule.Title, uid)
+			}
+			if dependency.IsRecordingRule() {
+				return fmt.Errorf("%w: rule '%s' depends on recording rule '%s' that has no state", ErrAlertRuleFailedValidation, rule.Title, dependency.Title)
+			}
+		}
+	}
+	return g.ValidateDependencyCycles()
+}
+
+// ValidateDependencyCycles checks that the dependencies between the rules of the group do not form a cycle. The
+// dependencies on rules that are not in the group are ignored.
+func (g RulesGroup) ValidateDependencyCycles() error {
+	byUID := make(map[string]*AlertRule, len(g))
+	for _, rule := range g {
+		if rule.UID != "" {
+			byUID[rule.UID] = rule
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	status := make(map[string]int, len(byUID))
+	var path []*AlertRule
+	var visit func(rule *AlertRule) error
+	visit = func(rule *AlertRule) error {
+		switch status[rule.UID] {
+		case visited:
+			return nil
+		case visiting:
+			var cycle []string
+			for i := len(path) - 1; i >= 0; i-- {
+				cycle = append([]string{path[i].Title}, cycle...)
+				if path[i].UID == rule.UID {
+					break
+				}
+			}
+			cycle = append(cycle, rule.Title)
+			return fmt.Errorf("%w: rules depend on each other in a cycle: %s", ErrAlertRuleFailedValidation, strings.Join(cycle, " -> "))
+		}
+		status[rule.UID] = visiting
+		path = append(path, rule)
+		for _, uid := range rule.GetDependencies() {
+			dependency, ok := byUID[uid]
+			if !ok {
+				continue
+			}
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		status[rule.UID] = visited
+		return nil
+	}
+	for _, rule := range g {
+		if rule.UID == "" {
+			continue
+		}
+		if err := visit(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDependencies(t *testing.T) {
+	rule := AlertRuleGen(WithDependencies("network", "disk", "network"))()
+	require.Equal(t, []string{"network", "disk"}, rule.GetDependencies())
+
+	rule = AlertRuleGen()()
+	require.Empty(t, rule.GetDependencies())
+}
+
+func TestRulesGroupValidateDependencies(t *testing.T) {
+	gen := func(uid string, dependencies ...string) *AlertRule {
+		return AlertRuleGen(func(rule *AlertRule) {
+			rule.UID = uid
+			rule.Title = uid
+		}, WithDependencies(dependencies...))()
+	}
+
+	t.Run("accepts rules that depend on other rules of the group", func(t *testing.T) {
+		group := RulesGroup{gen("database", "network", "disk"), gen("network", "disk"), gen("disk")}
+		require.NoError(t, group.ValidateDependencies())
+	})
+
+	t.Run("accepts new rules without UID", func(t *testing.T) {
+		group := RulesGroup{gen("", "network"), gen("network")}
+		require.NoError(t, group.ValidateDependencies())
+	})
+
+	t.Run("rejects dependency on rule of another group", func(t *testing.T) {
+		group := RulesGroup{gen("database", "network")}
+		err := group.ValidateDependencies()
+		require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "not in the same rule group")
+	})
+
+	t.Run("rejects dependency on recording rule", func(t *testing.T) {
+		recording := gen("network")
+		WithRecord("network_up", LiveRecordTarget)(recording)
+		group := RulesGroup{gen("database", "network"), recording}
+		err := group.ValidateDependencies()
+		require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "recording rule")
+	})
+
+	t.Run("rejects cycles", func(t *testing.T) {
+		group := RulesGroup{gen("database", "network"), gen("network", "disk"), gen("disk", "network")}
+		err := group.ValidateDependencies()
+		require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "network -> disk -> network")
+	})
+
+	t.Run("rejects cycles if the dependencies outside of the group are ignored", func(t *testing.T) {
+		group := RulesGroup{gen("database", "network", "cache"), gen("network", "database")}
+		require.ErrorContains(t, group.ValidateDependencyCycles(), "database -> network -> database")
+		require.NoError(t, RulesGroup{gen("database", "network", "cache"), gen("network")}.ValidateDependencyCycles())
+	})
+
+	t.Run("rejects rule that depends on itself", func(t *testing.T) {
+		group := RulesGroup{gen("database", "database")}
+		err := group.ValidateDependencies()
+		require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "database -> database")
+	})
+}
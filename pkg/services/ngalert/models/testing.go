@@ -164,6 +164,34 @@ func WithFor(duration time.Duration) AlertRuleMutator {
 	}
 }
 
+func WithRecord(metric string, target RecordTarget) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.Record = &Record{Metric: metric, From: rule.Condition, Target: target}
+		rule.For = 0
+	}
+}
+
+// WithDependencies adds a rule state expression for each rule UID, so that the rule depends on these rules.
+func WithDependencies(ruleUIDs ...string) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		for i, uid := range ruleUIDs {
+			rule.Data = append(rule.Data, CreateRuleStateExpression(fmt.Sprintf("STATE%d", i), uid))
+		}
+	}
+}
+
+func WithIsPaused(isPaused bool) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.IsPaused = isPaused
+	}
+}
+
+func WithEnrichments(enrichments ...Enrichment) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.Enrichments = enrichments
+	}
+}
+
 func GenerateAlertLabels(count int, prefix string) data.Labels {
 	labels := make(data.Labels, count)
 	for i := 0; i < count; i++ {
@@ -256,6 +284,7 @@ func CopyRule(r *AlertRule) *AlertRule {
 		NoDataState:     r.NoDataState,
 		ExecErrState:    r.ExecErrState,
 		For:             r.For,
+		IsPaused:        r.IsPaused,
 	}
 
 	if r.DashboardUID != nil {
@@ -293,6 +322,16 @@ func CopyRule(r *AlertRule) *AlertRule {
 		}
 	}
 
+	if r.Record != nil {
+		record := *r.Record
+		result.Record = &record
+	}
+
+	if r.Enrichments != nil {
+		result.Enrichments = make([]Enrichment, len(r.Enrichments))
+		copy(result.Enrichments, r.Enrichments)
+	}
+
 	return &result
 }
 
@@ -337,3 +376,13 @@ func CreateClassicConditionExpression(refID string, inputRefID string, reducer s
 		}`, refID, inputRefID, operation, threshold, reducer, expr.OldDatasourceUID, expr.DatasourceType)),
 	}
 }
+
+// CreateRuleStateExpression creates a rule state expression that reads the state of the alert instances of the rule with the UID.
+func CreateRuleStateExpression(refID string, ruleUID string) AlertQuery {
+	return AlertQuery{
+		RefID:         refID,
+		QueryType:     expr.DatasourceType,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         json.RawMessage(fmt.Sprintf(`{"refId": "%s", "type": "rule_state", "ruleUid": "%s"}`, refID, ruleUID)),
+	}
+}
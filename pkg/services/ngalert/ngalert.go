@@ -23,7 +23,9 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
+	"github.com/grafana/grafana/pkg/services/ngalert/enrichment"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
@@ -35,10 +37,13 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -64,6 +69,9 @@ func ProvideService(
 	accesscontrolService accesscontrol.Service,
 	annotationsRepo annotations.Repository,
 	pluginsStore plugins.Store,
+	grafanaLive *live.GrafanaLive,
+	teamService team.Service,
+	userService user.Service,
 ) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:                  cfg,
@@ -88,6 +96,9 @@ func ProvideService(
 		accesscontrolService: accesscontrolService,
 		annotationsRepo:      annotationsRepo,
 		pluginsStore:         pluginsStore,
+		grafanaLive:          grafanaLive,
+		teamService:          teamService,
+		userService:          userService,
 	}
 
 	if ng.IsDisabled() {
@@ -121,6 +132,7 @@ type AlertNG struct {
 	imageService        image.ImageService
 	schedule            schedule.ScheduleService
 	stateManager        *state.Manager
+	stateHistory        stateHistoryBackend
 	folderService       folder.Service
 	dashboardService    dashboards.DashboardService
 
@@ -134,6 +146,9 @@ type AlertNG struct {
 
 	bus          bus.Bus
 	pluginsStore plugins.Store
+	grafanaLive  *live.GrafanaLive
+	teamService  team.Service
+	userService  user.Service
 }
 
 func (ng *AlertNG) init() error {
@@ -198,10 +213,37 @@ func (ng *AlertNG) init() error {
 		RuleStore:            store,
 		Metrics:              ng.Metrics.GetSchedulerMetrics(),
 		AlertSender:          alertsRouter,
+		RecordingWriter:      ng.newRecordingWriter(),
+		Enricher:             enrichment.NewEnricher(evalFactory, ng.teamService, ng.userService, ng.Log.New("component", "enrichment")),
+	}
+	if ng.Cfg.UnifiedAlerting.HARuleSharding {
+		if membership := ng.MultiOrgAlertmanager.ClusterMembership(); membership != nil {
+			schedCfg.ClusterMembership = membership
+		} else {
+			ng.Log.Warn("Sharding of alert rules is enabled but Grafana does not run in high availability mode, all rules are evaluated by this instance")
+		}
 	}
 
-	historian := historian.NewAnnotationHistorian(ng.annotationsRepo, ng.dashboardService)
-	stateManager := state.NewManager(ng.Metrics.GetStateMetrics(), appUrl, store, ng.imageService, clk, historian)
+	annotationHistorian := historian.NewAnnotationHistorian(ng.annotationsRepo, ng.dashboardService)
+	var stateHistorian state.Historian = annotationHistorian
+	if ng.Cfg.UnifiedAlerting.StateHistory.Enabled {
+		backend, err := configureStateHistoryBackend(ng.Cfg.UnifiedAlerting.StateHistory, ng.SQLStore, clk)
+		if err != nil {
+			return err
+		}
+		// state changes are still recorded as annotations
+		stateHistorian = historian.NewMultipleHistorian(annotationHistorian, backend)
+		ng.stateHistory = backend
+	}
+	stateManager := state.NewManager(ng.Metrics.GetStateMetrics(), appUrl, store, ng.imageService, clk, stateHistorian)
+	if interval := ng.Cfg.UnifiedAlerting.StateSnapshotInterval; interval > 0 {
+		if schedCfg.ClusterMembership != nil {
+			// the instances of the cluster would overwrite the snapshots of each other.
+			ng.Log.Warn("Snapshots of the alert state are not supported together with sharding of alert rules and are disabled")
+		} else {
+			stateManager.EnableSnapshots(state.NewKVSnapshotStore(ng.KVStore), interval)
+		}
+	}
 	scheduler := schedule.NewScheduler(schedCfg, stateManager)
 
 	// if it is required to include folder title to the alerts, we need to subscribe to changes of alert title
@@ -217,6 +259,7 @@ func (ng *AlertNG) init() error {
 	contactPointService := provisioning.NewContactPointService(store, ng.SecretsService, store, store, ng.Log)
 	templateService := provisioning.NewTemplateService(store, store, store, ng.Log)
 	muteTimingService := provisioning.NewMuteTimingService(store, store, store, ng.Log)
+	silenceService := provisioning.NewSilenceService(store, store, store, ng.Log)
 	alertRuleService := provisioning.NewAlertRuleService(store, store, ng.QuotaService, store,
 		int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
 		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()), ng.Log)
@@ -236,11 +279,13 @@ func (ng *AlertNG) init() error {
 		ProvenanceStore:      store,
 		MultiOrgAlertmanager: ng.MultiOrgAlertmanager,
 		StateManager:         ng.stateManager,
+		StateHistory:         ng.stateHistory,
 		AccessControl:        ng.accesscontrol,
 		Policies:             policyService,
 		ContactPointService:  contactPointService,
 		Templates:            templateService,
 		MuteTimings:          muteTimingService,
+		Silences:             silenceService,
 		AlertRules:           alertRuleService,
 		AlertsRouter:         alertsRouter,
 		EvaluatorFactory:     evalFactory,
@@ -288,7 +333,7 @@ func subscribeToFolderChanges(logger log.Logger, bus bus.Bus, dbStore api.RuleSt
 			if len(updated) > 0 {
 				logger.Info("Rules that belong to the folder have been updated successfully. Clearing their status", "folderUID", evt.UID, "updatedRules", len(updated))
 				for _, key := range updated {
-					scheduler.UpdateAlertRule(key.AlertRuleKey, key.Version)
+					scheduler.UpdateAlertRule(key.AlertRuleKey, key.Version, false)
 				}
 			} else {
 				logger.Debug("No alert rules found in the folder. nothing to update", "folderUID", evt.UID, "folder", evt.Title)
@@ -316,6 +361,12 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 		return ng.AlertsRouter.Run(subCtx)
 	})
 
+	if sqlHistorian, ok := ng.stateHistory.(*historian.SQLStateHistorian); ok {
+		children.Go(func() error {
+			return sqlHistorian.Run(subCtx)
+		})
+	}
+
 	if ng.Cfg.UnifiedAlerting.ExecuteAlerts {
 		children.Go(func() error {
 			return ng.schedule.Run(subCtx)
@@ -324,6 +375,36 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	return children.Wait()
 }
 
+// stateHistoryBackend records every evaluation of the alert rules, and queries the history of the evaluations.
+type stateHistoryBackend interface {
+	state.Historian
+	state.HistoryReader
+}
+
+func configureStateHistoryBackend(cfg setting.UnifiedAlertingStateHistorySettings, db db.DB, clk clock.Clock) (stateHistoryBackend, error) {
+	switch cfg.Backend {
+	case "sql":
+		return historian.NewSQLHistorian(db, cfg.Retention, clk), nil
+	case "loki":
+		return historian.NewLokiHistorian(cfg.LokiRemoteURL)
+	default:
+		return nil, fmt.Errorf("unsupported state history backend %q", cfg.Backend)
+	}
+}
+
+// newRecordingWriter returns the writer of the recording rules. The remote write target is only
+// available if its endpoint is configured.
+func (ng *AlertNG) newRecordingWriter() writer.Writer {
+	var remoteWriter, liveWriter writer.Writer
+	if ng.Cfg.UnifiedAlerting.RecordingRules.RemoteWriteURL != "" {
+		remoteWriter = writer.NewRemoteWriter(ng.Cfg.UnifiedAlerting.RecordingRules, ng.Log.New("component", "recording-rules"))
+	}
+	if ng.grafanaLive != nil && ng.grafanaLive.ManagedStreamRunner != nil {
+		liveWriter = writer.NewLiveWriter(ng.grafanaLive.ManagedStreamRunner)
+	}
+	return writer.NewTargetWriter(remoteWriter, liveWriter)
+}
+
 // IsDisabled returns true if the alerting service is disable for this instance.
 func (ng *AlertNG) IsDisabled() bool {
 	if ng.Cfg == nil {
@@ -90,6 +90,7 @@ type ClusterPeer interface {
 type AlertingStore interface {
 	store.AlertingStore
 	store.ImageStore
+	store.SilenceStore
 }
 
 type Alertmanager struct {
@@ -208,6 +209,12 @@ func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 		am.wg.Done()
 	}()
 
+	am.wg.Add(1)
+	go func() {
+		am.runRecurringSilences(ctx)
+		am.wg.Done()
+	}()
+
 	// Initialize in-memory alerts
 	am.alerts, err = mem.NewAlerts(context.Background(), am.marker, memoryAlertsGCInterval, nil, am.logger, m.Registerer)
 	if err != nil {
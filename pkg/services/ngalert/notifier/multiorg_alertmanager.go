@@ -328,6 +328,36 @@ func (moa *MultiOrgAlertmanager) AlertmanagerFor(orgID int64) (*Alertmanager, er
 	return orgAM, nil
 }
 
+// ClusterMembership returns the members of the gossip cluster of the Alertmanagers,
+// or nil if Grafana does not run in high availability mode.
+func (moa *MultiOrgAlertmanager) ClusterMembership() *PeerMembership {
+	p, ok := moa.peer.(*cluster.Peer)
+	if !ok {
+		return nil
+	}
+	return &PeerMembership{peer: p}
+}
+
+// PeerMembership provides the names of the members of the gossip cluster of the Alertmanagers.
+type PeerMembership struct {
+	peer *cluster.Peer
+}
+
+// Self returns the name of this instance.
+func (m *PeerMembership) Self() string {
+	return m.peer.Name()
+}
+
+// Members returns the names of the instances of the cluster, including this instance.
+func (m *PeerMembership) Members() []string {
+	peers := m.peer.Peers()
+	members := make([]string, 0, len(peers))
+	for _, p := range peers {
+		members = append(members, p.Name())
+	}
+	return members
+}
+
 // NilPeer and NilChannel implements the Alertmanager clustering interface.
 type NilPeer struct{}
 
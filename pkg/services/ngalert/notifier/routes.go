@@ -0,0 +1,246 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/inhibit"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/provider"
+	"github.com/prometheus/alertmanager/silence"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// TestRoutes returns how the alerts with the given label sets would be routed by the notification policy tree,
+// either of the current configuration or of the draft configuration in the request. The routes are matched
+// with the same code the dispatcher uses, so that the result is what the Alertmanager would do.
+func (am *Alertmanager) TestRoutes(ctx context.Context, c apimodels.TestRoutesConfigBodyParams) (*apimodels.TestRoutesResult, error) {
+	am.reloadConfigMtx.RLock()
+	if !am.ready() {
+		am.reloadConfigMtx.RUnlock()
+		return nil, ErrAlertmanagerNotReady
+	}
+	route := am.route
+	muteTimes := am.muteTimes
+	inhibitRules := am.config.AlertmanagerConfig.InhibitRules
+	am.reloadConfigMtx.RUnlock()
+
+	if c.AlertmanagerConfig != nil {
+		route = dispatch.NewRoute(c.AlertmanagerConfig.Route.AsAMRoute(), nil)
+		muteTimes = am.buildMuteTimesMap(c.AlertmanagerConfig.MuteTimeIntervals)
+		inhibitRules = c.AlertmanagerConfig.InhibitRules
+	}
+
+	now := time.Now()
+	firing := am.firingAlerts(now)
+	inhibitors := make([]*testInhibitor, 0, len(inhibitRules))
+	for _, cr := range inhibitRules {
+		ti := newTestInhibitor(cr, firing, am.logger)
+		defer ti.stop()
+		inhibitors = append(inhibitors, ti)
+	}
+
+	result := &apimodels.TestRoutesResult{
+		Alerts:   make([]apimodels.TestRoutesAlertResult, 0, len(c.Labels)),
+		TestedAt: now,
+	}
+	for _, labels := range c.Labels {
+		alertResult := apimodels.TestRoutesAlertResult{
+			Labels:      labels,
+			Routes:      []apimodels.TestRouteResult{},
+			SilencedBy:  []string{},
+			InhibitedBy: []apimodels.TestInhibitionResult{},
+		}
+
+		for _, r := range route.Match(labels) {
+			routeResult, err := am.testRoute(ctx, route, r, labels, muteTimes, now)
+			if err != nil {
+				return nil, err
+			}
+			alertResult.Routes = append(alertResult.Routes, routeResult)
+		}
+
+		silences, _, err := am.silences.Query(silence.QState(types.SilenceStateActive), silence.QMatches(labels))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query silences: %w", err)
+		}
+		for _, s := range silences {
+			alertResult.SilencedBy = append(alertResult.SilencedBy, s.Id)
+		}
+
+		for _, ti := range inhibitors {
+			if inhibition, ok := ti.inhibition(labels); ok {
+				alertResult.InhibitedBy = append(alertResult.InhibitedBy, inhibition)
+			}
+		}
+
+		result.Alerts = append(result.Alerts, alertResult)
+	}
+	return result, nil
+}
+
+func (am *Alertmanager) testRoute(ctx context.Context, root, r *dispatch.Route, labels model.LabelSet, muteTimes map[string][]timeinterval.TimeInterval, now time.Time) (apimodels.TestRouteResult, error) {
+	opts := r.RouteOpts
+	result := apimodels.TestRouteResult{
+		Receiver:          opts.Receiver,
+		Path:              []apimodels.TestRouteStep{},
+		GroupBy:           make([]string, 0, len(opts.GroupBy)),
+		GroupLabels:       model.LabelSet{},
+		GroupWait:         model.Duration(opts.GroupWait),
+		GroupInterval:     model.Duration(opts.GroupInterval),
+		RepeatInterval:    model.Duration(opts.RepeatInterval),
+		MuteTimeIntervals: opts.MuteTimeIntervals,
+	}
+
+	for _, step := range routePath(root, r) {
+		result.Path = append(result.Path, apimodels.TestRouteStep{
+			Receiver:       step.RouteOpts.Receiver,
+			ObjectMatchers: apimodels.ObjectMatchers(step.Matchers),
+			Continue:       step.Continue,
+		})
+	}
+
+	if opts.GroupByAll {
+		result.GroupBy = append(result.GroupBy, "...")
+	} else {
+		for ln := range opts.GroupBy {
+			result.GroupBy = append(result.GroupBy, string(ln))
+		}
+		sort.Strings(result.GroupBy)
+	}
+	for ln, lv := range labels {
+		if _, ok := opts.GroupBy[ln]; ok || opts.GroupByAll {
+			result.GroupLabels[ln] = lv
+		}
+	}
+
+	// The mute timings are checked by the same stage as in the notification pipeline, which drops all alerts
+	// if the route is muted.
+	muteCtx := notify.WithNow(notify.WithMuteTimeIntervals(ctx, opts.MuteTimeIntervals), now)
+	_, alerts, err := notify.NewTimeMuteStage(muteTimes).Exec(muteCtx, am.logger, &types.Alert{Alert: model.Alert{Labels: labels}})
+	if err != nil {
+		return result, fmt.Errorf("failed to check the mute timings of the route: %w", err)
+	}
+	result.Muted = len(alerts) == 0
+
+	return result, nil
+}
+
+// firingAlerts returns the alerts of the Alertmanager that are not resolved at the given time.
+func (am *Alertmanager) firingAlerts(now time.Time) []*types.Alert {
+	alerts := am.alerts.GetPending()
+	defer alerts.Close()
+
+	var result []*types.Alert
+	for a := range alerts.Next() {
+		if a.ResolvedAt(now) {
+			continue
+		}
+		result = append(result, a)
+	}
+	return result
+}
+
+// routePath returns the routes from the root of the tree to the route, including both.
+func routePath(root, r *dispatch.Route) []*dispatch.Route {
+	if root == r {
+		return []*dispatch.Route{root}
+	}
+	for _, child := range root.Routes {
+		if path := routePath(child, r); path != nil {
+			return append([]*dispatch.Route{root}, path...)
+		}
+	}
+	return nil
+}
+
+// testInhibitor inhibits alerts with an inhibition rule because of the firing alerts, with the inhibitor of the
+// Alertmanager.
+type testInhibitor struct {
+	rule      *inhibit.InhibitRule
+	inhibitor *inhibit.Inhibitor
+	marker    types.Marker
+	firing    map[string]*types.Alert
+}
+
+// newTestInhibitor returns an inhibitor of the rule that has the firing alerts in its cache. It must be stopped.
+func newTestInhibitor(cr *config.InhibitRule, firing []*types.Alert, logger log.Logger) *testInhibitor {
+	alerts := &testInhibitorAlerts{alerts: firing, synced: make(chan struct{})}
+	ti := &testInhibitor{
+		rule:   inhibit.NewInhibitRule(cr),
+		marker: types.NewMarker(prometheus.NewRegistry()),
+		firing: make(map[string]*types.Alert, len(firing)),
+	}
+	for _, a := range firing {
+		ti.firing[a.Fingerprint().String()] = a
+	}
+	ti.inhibitor = inhibit.NewInhibitor(alerts, []*config.InhibitRule{cr}, ti.marker, logger)
+	go ti.inhibitor.Run()
+	<-alerts.synced
+	return ti
+}
+
+// inhibition returns the inhibition if the rule inhibits an alert with the labels.
+func (ti *testInhibitor) inhibition(labels model.LabelSet) (apimodels.TestInhibitionResult, bool) {
+	if !ti.inhibitor.Mutes(labels) {
+		return apimodels.TestInhibitionResult{}, false
+	}
+	result := apimodels.TestInhibitionResult{
+		SourceMatchers: apimodels.ObjectMatchers(ti.rule.SourceMatchers),
+		TargetMatchers: apimodels.ObjectMatchers(ti.rule.TargetMatchers),
+		Equal:          make([]string, 0, len(ti.rule.Equal)),
+	}
+	for ln := range ti.rule.Equal {
+		result.Equal = append(result.Equal, string(ln))
+	}
+	sort.Strings(result.Equal)
+	// the inhibitor marks the alert as inhibited by the fingerprint of the source alert
+	ids, _ := ti.marker.Inhibited(labels.Fingerprint())
+	for _, id := range ids {
+		if a, ok := ti.firing[id]; ok {
+			result.SourceLabels = a.Labels
+			break
+		}
+	}
+	return result, true
+}
+
+func (ti *testInhibitor) stop() {
+	ti.inhibitor.Stop()
+}
+
+// testInhibitorAlerts provides the firing alerts to the inhibitor of a test. The inhibitor caches the alerts of its
+// subscription in the background, and reads the next alert only once it has cached the previous one, so the alerts
+// are sent through an unbuffered channel and followed by a resolved alert, which the inhibitor ignores, to know when
+// all firing alerts are cached.
+type testInhibitorAlerts struct {
+	provider.Alerts
+	alerts []*types.Alert
+	synced chan struct{}
+}
+
+func (p *testInhibitorAlerts) Subscribe() provider.AlertIterator {
+	ch, done := make(chan *types.Alert), make(chan struct{})
+	resolved := &types.Alert{Alert: model.Alert{EndsAt: time.Unix(0, 0)}}
+	go func() {
+		for _, a := range append(p.alerts, resolved) {
+			select {
+			case ch <- a:
+			case <-done:
+				return
+			}
+		}
+		close(p.synced)
+	}()
+	return provider.NewAlertIterator(ch, done, nil)
+}
@@ -0,0 +1,159 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestTestRoutes(t *testing.T) {
+	const rawConfig = `{
+		"alertmanager_config": {
+			"route": {
+				"receiver": "default",
+				"group_by": ["alertname"],
+				"group_wait": "1h",
+				"routes": [{
+					"receiver": "team-a",
+					"object_matchers": [["team", "=", "a"]],
+					"group_by": ["..."],
+					"group_wait": "1m",
+					"mute_time_intervals": ["always"]
+				}, {
+					"receiver": "team-b",
+					"object_matchers": [["team", "=~", "a|b"]],
+					"repeat_interval": "1h"
+				}]
+			},
+			"mute_time_intervals": [{"name": "always", "time_intervals": [{}]}],
+			"inhibit_rules": [{
+				"source_matchers": ["severity=critical"],
+				"target_matchers": ["severity=warning"],
+				"equal": ["alertname"]
+			}],
+			"receivers": [
+				{"name": "default", "grafana_managed_receiver_configs": [{"name": "default", "type": "email", "settings": {"addresses": "default@localhost"}}]},
+				{"name": "team-a", "grafana_managed_receiver_configs": [{"name": "team-a", "type": "email", "settings": {"addresses": "a@localhost"}}]},
+				{"name": "team-b", "grafana_managed_receiver_configs": [{"name": "team-b", "type": "email", "settings": {"addresses": "b@localhost"}}]}
+			]
+		}
+	}`
+
+	am := setupAMTest(t)
+	ctx := context.Background()
+
+	t.Run("fails when the Alertmanager is not ready", func(t *testing.T) {
+		_, err := am.TestRoutes(ctx, apimodels.TestRoutesConfigBodyParams{Labels: []model.LabelSet{{"alertname": "test"}}})
+		require.ErrorIs(t, err, ErrAlertmanagerNotReady)
+	})
+
+	cfg, err := Load([]byte(rawConfig))
+	require.NoError(t, err)
+	require.NoError(t, am.SaveAndApplyConfig(ctx, cfg))
+
+	require.NoError(t, am.PutAlerts(apimodels.PostableAlerts{PostableAlerts: []models.PostableAlert{{
+		Alert:    models.Alert{Labels: models.LabelSet{"alertname": "high-cpu", "severity": "critical"}},
+		StartsAt: strfmt.DateTime(time.Now().Add(-time.Minute)),
+	}}}))
+
+	comment, createdBy := "test", "test"
+	name, value, isEqual, isRegex := "instance", "x", true, false
+	silenceID, err := am.CreateSilence(&apimodels.PostableSilence{Silence: models.Silence{
+		Comment:   &comment,
+		CreatedBy: &createdBy,
+		StartsAt:  timePtr(strfmt.DateTime(time.Now().Add(-time.Minute))),
+		EndsAt:    timePtr(strfmt.DateTime(time.Now().Add(time.Hour))),
+		Matchers:  models.Matchers{{Name: &name, Value: &value, IsEqual: &isEqual, IsRegex: &isRegex}},
+	}})
+	require.NoError(t, err)
+
+	t.Run("returns the routes, silences and inhibition rules of the current configuration", func(t *testing.T) {
+		result, err := am.TestRoutes(ctx, apimodels.TestRoutesConfigBodyParams{Labels: []model.LabelSet{
+			{"alertname": "high-cpu", "team": "a", "instance": "x", "severity": "warning"},
+			{"alertname": "disk-full", "team": "c"},
+		}})
+		require.NoError(t, err)
+		require.Len(t, result.Alerts, 2)
+
+		matched := result.Alerts[0]
+		require.Len(t, matched.Routes, 1)
+		route := matched.Routes[0]
+		require.Equal(t, "team-a", route.Receiver)
+		require.Len(t, route.Path, 2)
+		require.Equal(t, "default", route.Path[0].Receiver)
+		require.Empty(t, route.Path[0].ObjectMatchers)
+		require.Equal(t, "team-a", route.Path[1].Receiver)
+		require.Equal(t, `team="a"`, route.Path[1].ObjectMatchers[0].String())
+		require.Equal(t, []string{"..."}, route.GroupBy)
+		require.Equal(t, matched.Labels, route.GroupLabels)
+		require.Equal(t, model.Duration(time.Minute), route.GroupWait)
+		require.Equal(t, []string{"always"}, route.MuteTimeIntervals)
+		require.True(t, route.Muted)
+		require.Equal(t, []string{silenceID}, matched.SilencedBy)
+		require.Len(t, matched.InhibitedBy, 1)
+		require.Equal(t, []string{"alertname"}, matched.InhibitedBy[0].Equal)
+		require.Equal(t, model.LabelSet{"alertname": "high-cpu", "severity": "critical"}, matched.InhibitedBy[0].SourceLabels)
+
+		unmatched := result.Alerts[1]
+		require.Len(t, unmatched.Routes, 1)
+		route = unmatched.Routes[0]
+		require.Equal(t, "default", route.Receiver)
+		require.Len(t, route.Path, 1)
+		require.Equal(t, []string{"alertname"}, route.GroupBy)
+		require.Equal(t, model.LabelSet{"alertname": "disk-full"}, route.GroupLabels)
+		require.False(t, route.Muted)
+		require.Empty(t, unmatched.SilencedBy)
+		require.Empty(t, unmatched.InhibitedBy)
+	})
+
+	t.Run("does not inhibit alerts without the equal labels of a firing source alert", func(t *testing.T) {
+		result, err := am.TestRoutes(ctx, apimodels.TestRoutesConfigBodyParams{Labels: []model.LabelSet{
+			{"alertname": "disk-full", "severity": "warning"},
+			{"alertname": "high-cpu", "severity": "critical"},
+		}})
+		require.NoError(t, err)
+		require.Len(t, result.Alerts, 2)
+		require.Empty(t, result.Alerts[0].InhibitedBy)
+		require.Empty(t, result.Alerts[1].InhibitedBy)
+	})
+
+	t.Run("uses the draft configuration if it is provided", func(t *testing.T) {
+		draft, err := Load([]byte(rawConfig))
+		require.NoError(t, err)
+		draft.AlertmanagerConfig.Route.Routes[0].Continue = true
+		draft.AlertmanagerConfig.InhibitRules = nil
+
+		// the body is decoded from JSON to validate the draft like the API does
+		b, err := json.Marshal(apimodels.TestRoutesConfigBodyParams{
+			Labels:             []model.LabelSet{{"alertname": "high-cpu", "team": "a", "severity": "warning"}},
+			AlertmanagerConfig: &draft.AlertmanagerConfig,
+		})
+		require.NoError(t, err)
+		var body apimodels.TestRoutesConfigBodyParams
+		require.NoError(t, json.Unmarshal(b, &body))
+
+		result, err := am.TestRoutes(ctx, body)
+		require.NoError(t, err)
+		require.Len(t, result.Alerts, 1)
+		routes := result.Alerts[0].Routes
+		require.Len(t, routes, 2)
+		require.Equal(t, "team-a", routes[0].Receiver)
+		require.True(t, routes[0].Path[1].Continue)
+		require.Equal(t, "team-b", routes[1].Receiver)
+		require.Equal(t, model.Duration(time.Hour), routes[1].RepeatInterval)
+		require.Empty(t, result.Alerts[0].SilencedBy)
+		require.Empty(t, result.Alerts[0].InhibitedBy)
+	})
+}
+
+func timePtr(t strfmt.DateTime) *strfmt.DateTime {
+	return &t
+}
@@ -1,6 +1,8 @@
 package notifier
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -8,13 +10,27 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	v2 "github.com/prometheus/alertmanager/api/v2"
 	"github.com/prometheus/alertmanager/silence"
+	"github.com/prometheus/alertmanager/silence/silencepb"
+	"github.com/prometheus/alertmanager/types"
 )
 
+const (
+	// recurringSilenceCreatedByPrefix is the prefix of the creator of the silences that are created for the windows
+	// of a recurring silence, followed by the name of the recurring silence.
+	recurringSilenceCreatedByPrefix = "recurring-silence:"
+	// recurringSilenceIDsKey is the key of the IDs of the silences that are created for the recurring silences.
+	recurringSilenceIDsKey = "recurring_silences"
+)
+
+// recurringSilencesInterval is how often the silences of the recurring silences are synchronized.
+var recurringSilencesInterval = time.Minute
+
 var (
 	ErrGetSilencesInternal     = fmt.Errorf("unable to retrieve silence(s) due to an internal error")
 	ErrDeleteSilenceInternal   = fmt.Errorf("unable to delete silence due to an internal error")
 	ErrCreateSilenceBadPayload = fmt.Errorf("unable to create silence")
 	ErrListSilencesBadPayload  = fmt.Errorf("unable to list silences")
+	ErrBulkSilencesBadPayload  = fmt.Errorf("unable to update silences")
 	ErrSilenceNotFound         = silence.ErrNotFound
 )
 
@@ -117,3 +133,231 @@ func (am *Alertmanager) DeleteSilence(silenceID string) error {
 
 	return nil
 }
+
+// ExpireSilences expires the active and pending silences that match the filter and returns their IDs. The filter must
+// not be empty, so that all silences are not expired by mistake.
+func (am *Alertmanager) ExpireSilences(filter []string) ([]string, error) {
+	sils, err := am.bulkSilences(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(sils))
+	for _, sil := range sils {
+		if err := am.silences.Expire(sil.Id); err != nil {
+			if errors.Is(err, silence.ErrNotFound) {
+				continue
+			}
+			return ids, fmt.Errorf("%s: %w", err.Error(), ErrDeleteSilenceInternal)
+		}
+		ids = append(ids, sil.Id)
+	}
+	return ids, nil
+}
+
+// ExtendSilences moves the end of the active and pending silences that match the filter by the duration and returns
+// their IDs. The filter must not be empty, so that all silences are not extended by mistake.
+func (am *Alertmanager) ExtendSilences(filter []string, d time.Duration) ([]string, error) {
+	if d <= 0 {
+		return nil, fmt.Errorf("duration must be positive: %w", ErrBulkSilencesBadPayload)
+	}
+	sils, err := am.bulkSilences(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(sils))
+	for _, sil := range sils {
+		extended := *sil
+		extended.EndsAt = sil.EndsAt.Add(d)
+		// The silence is updated in place because only its end is changed.
+		id, err := am.silences.Set(&extended)
+		if err != nil {
+			if errors.Is(err, silence.ErrNotFound) {
+				continue
+			}
+			return ids, fmt.Errorf("unable to save silence: %s: %w", err.Error(), ErrBulkSilencesBadPayload)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// bulkSilences returns the active and pending silences that match the filter.
+func (am *Alertmanager) bulkSilences(filter []string) ([]*silencepb.Silence, error) {
+	if len(filter) == 0 {
+		return nil, fmt.Errorf("filter must not be empty: %w", ErrBulkSilencesBadPayload)
+	}
+	matchers, err := parseFilter(filter)
+	if err != nil {
+		am.logger.Error("failed to parse matchers", "error", err)
+		return nil, fmt.Errorf("%s: %w", ErrBulkSilencesBadPayload.Error(), err)
+	}
+
+	psils, _, err := am.silences.Query(silence.QState(types.SilenceStateActive, types.SilenceStatePending))
+	if err != nil {
+		am.logger.Error(ErrGetSilencesInternal.Error(), "error", err)
+		return nil, fmt.Errorf("%s: %w", ErrGetSilencesInternal.Error(), err)
+	}
+
+	sils := make([]*silencepb.Silence, 0, len(psils))
+	for _, ps := range psils {
+		if v2.CheckSilenceMatchesFilterLabels(ps, matchers) {
+			sils = append(sils, ps)
+		}
+	}
+	return sils, nil
+}
+
+// runRecurringSilences synchronizes the silences of the recurring silences until the Alertmanager is stopped.
+func (am *Alertmanager) runRecurringSilences(ctx context.Context) {
+	ticker := time.NewTicker(recurringSilencesInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-am.stopc:
+			return
+		case now := <-ticker.C:
+			// The silences are gossiped to the other members of the cluster, so only the first one creates them.
+			// Two members can still create the same silence if they both consider themselves to be the first one
+			// while the cluster settles.
+			if am.peer.Position() != 0 {
+				continue
+			}
+			if err := am.syncRecurringSilences(ctx, now); err != nil {
+				am.logger.Error("failed to synchronize the recurring silences", "error", err)
+			}
+		}
+	}
+}
+
+// syncRecurringSilences creates the silence of each recurring silence whose window contains the given time, unless
+// it was already created for the window. The silences that no longer match their recurring silence, or whose recurring
+// silence was deleted, are expired. A silence that was expired by a user during its window is not created again.
+// The IDs of the created silences are stored, so that the silences created by users are never changed.
+func (am *Alertmanager) syncRecurringSilences(ctx context.Context, now time.Time) error {
+	recurring, err := am.Store.GetRecurringSilences(ctx, am.orgID)
+	if err != nil {
+		return err
+	}
+	createdIDs, err := am.getRecurringSilenceIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The silences that are no longer in the Alertmanager, because they were garbage collected, are not tracked.
+	byName := make(map[string][]*silencepb.Silence, len(createdIDs))
+	for name, ids := range createdIDs {
+		if len(ids) == 0 {
+			// a query without IDs returns all silences
+			continue
+		}
+		sils, _, err := am.silences.Query(silence.QIDs(ids...))
+		if err != nil {
+			return fmt.Errorf("%s: %w", ErrGetSilencesInternal.Error(), err)
+		}
+		byName[name] = sils
+	}
+	tracked := make(map[string][]string, len(recurring))
+
+	for _, rs := range recurring {
+		sils := byName[rs.Name]
+		delete(byName, rs.Name)
+		for _, sil := range sils {
+			tracked[rs.Name] = append(tracked[rs.Name], sil.Id)
+		}
+
+		var desired *silencepb.Silence
+		start, end, ok := rs.Window(now)
+		if ok {
+			ps := rs.Silence(start, recurringSilenceCreatedByPrefix+rs.Name)
+			if desired, err = v2.PostableSilenceToProto(&ps); err != nil {
+				am.logger.Error("failed to convert the recurring silence", "name", rs.Name, "error", err)
+				continue
+			}
+		}
+
+		create := desired != nil
+		for _, sil := range sils {
+			sameSilence := desired != nil && sil.Comment == desired.Comment && matchersEqual(sil.Matchers, desired.Matchers) &&
+				!sil.StartsAt.Before(start) && sil.StartsAt.Before(end)
+			if !sil.EndsAt.After(now) {
+				// The silence of the window ended early, it was expired by a user.
+				if sameSilence {
+					create = false
+				}
+				continue
+			}
+			if sameSilence && sil.EndsAt.Equal(end) {
+				create = false
+				continue
+			}
+			if err := am.silences.Expire(sil.Id); err != nil && !errors.Is(err, silence.ErrNotFound) {
+				am.logger.Error("failed to expire an outdated silence of the recurring silence", "name", rs.Name, "id", sil.Id, "error", err)
+			}
+		}
+
+		if create {
+			id, err := am.silences.Set(desired)
+			if err != nil {
+				am.logger.Error("failed to create the silence of the recurring silence", "name", rs.Name, "error", err)
+				continue
+			}
+			am.logger.Debug("created the silence of the recurring silence", "name", rs.Name, "id", id, "ends_at", end)
+			tracked[rs.Name] = append(tracked[rs.Name], id)
+		}
+	}
+
+	// The silences of the recurring silences that were deleted are expired.
+	for name, sils := range byName {
+		for _, sil := range sils {
+			if !sil.EndsAt.After(now) {
+				continue
+			}
+			if err := am.silences.Expire(sil.Id); err != nil && !errors.Is(err, silence.ErrNotFound) {
+				am.logger.Error("failed to expire the silence of a deleted recurring silence", "name", name, "id", sil.Id, "error", err)
+				tracked[name] = append(tracked[name], sil.Id)
+			}
+		}
+	}
+	return am.saveRecurringSilenceIDs(ctx, tracked)
+}
+
+// getRecurringSilenceIDs returns the IDs of the silences that are created for the recurring silences, by name.
+func (am *Alertmanager) getRecurringSilenceIDs(ctx context.Context) (map[string][]string, error) {
+	content, exists, err := am.fileStore.kv.Get(ctx, recurringSilenceIDsKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the silences of the recurring silences: %w", err)
+	}
+	ids := map[string][]string{}
+	if !exists {
+		return ids, nil
+	}
+	if err := json.Unmarshal([]byte(content), &ids); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the silences of the recurring silences: %w", err)
+	}
+	return ids, nil
+}
+
+func (am *Alertmanager) saveRecurringSilenceIDs(ctx context.Context, ids map[string][]string) error {
+	b, err := json.Marshal(ids)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the silences of the recurring silences: %w", err)
+	}
+	if err := am.fileStore.kv.Set(ctx, recurringSilenceIDsKey, string(b)); err != nil {
+		return fmt.Errorf("failed to save the silences of the recurring silences: %w", err)
+	}
+	return nil
+}
+
+func matchersEqual(a, b []*silencepb.Matcher) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type || a[i].Name != b[i].Name || a[i].Pattern != b[i].Pattern {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestBulkSilences(t *testing.T) {
+	am := setupAMTest(t)
+
+	createSilence := func(team string) string {
+		comment, createdBy := "test", "test"
+		name, isEqual, isRegex := "team", true, false
+		id, err := am.CreateSilence(&apimodels.PostableSilence{Silence: models.Silence{
+			Comment:   &comment,
+			CreatedBy: &createdBy,
+			StartsAt:  timePtr(strfmt.DateTime(time.Now())),
+			EndsAt:    timePtr(strfmt.DateTime(time.Now().Add(time.Hour))),
+			Matchers:  models.Matchers{{Name: &name, Value: &team, IsEqual: &isEqual, IsRegex: &isRegex}},
+		}})
+		require.NoError(t, err)
+		return id
+	}
+	teamA := createSilence("a")
+	teamB := createSilence("b")
+
+	t.Run("fails when the filter is empty", func(t *testing.T) {
+		_, err := am.ExpireSilences(nil)
+		require.ErrorIs(t, err, ErrBulkSilencesBadPayload)
+		_, err = am.ExtendSilences(nil, time.Hour)
+		require.ErrorIs(t, err, ErrBulkSilencesBadPayload)
+	})
+
+	t.Run("extends the silences that match the filter", func(t *testing.T) {
+		before, err := am.GetSilence(teamA)
+		require.NoError(t, err)
+
+		ids, err := am.ExtendSilences([]string{"team=a"}, time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, []string{teamA}, ids)
+
+		after, err := am.GetSilence(teamA)
+		require.NoError(t, err)
+		require.Equal(t, time.Time(*before.EndsAt).Add(time.Hour), time.Time(*after.EndsAt))
+	})
+
+	t.Run("expires the silences that match the filter", func(t *testing.T) {
+		ids, err := am.ExpireSilences([]string{"team=a"})
+		require.NoError(t, err)
+		require.Equal(t, []string{teamA}, ids)
+
+		expired, err := am.GetSilence(teamA)
+		require.NoError(t, err)
+		require.Equal(t, string(types.SilenceStateExpired), *expired.Status.State)
+		active, err := am.GetSilence(teamB)
+		require.NoError(t, err)
+		require.Equal(t, string(types.SilenceStateActive), *active.Status.State)
+
+		ids, err = am.ExpireSilences([]string{"team=a"})
+		require.NoError(t, err)
+		require.Empty(t, ids)
+	})
+}
+
+func TestSyncRecurringSilences(t *testing.T) {
+	am := setupAMTest(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	// The window started half an hour ago and lasts two hours.
+	start := now.Add(-30 * time.Minute).UTC()
+	recurring := apimodels.RecurringSilence{
+		Name:     "nightly-batch",
+		Matchers: apimodels.ObjectMatchers{{Type: labels.MatchEqual, Name: "job", Value: "batch"}},
+		Schedule: fmt.Sprintf("CRON_TZ=UTC %d %d * * *", start.Minute(), start.Hour()),
+		Duration: model.Duration(2 * time.Hour),
+	}
+
+	activeSilences := func() apimodels.GettableSilences {
+		t.Helper()
+		sils, err := am.ListSilences(nil)
+		require.NoError(t, err)
+		result := apimodels.GettableSilences{}
+		for _, s := range sils {
+			if *s.Status.State != string(types.SilenceStateExpired) {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+
+	t.Run("does not create silences outside of the windows", func(t *testing.T) {
+		outside := recurring
+		outside.Duration = model.Duration(10 * time.Minute)
+		require.NoError(t, am.Store.InsertRecurringSilence(ctx, am.orgID, outside))
+
+		require.NoError(t, am.syncRecurringSilences(ctx, time.Now()))
+		require.Empty(t, activeSilences())
+	})
+
+	_, err := am.Store.UpdateRecurringSilence(ctx, am.orgID, recurring)
+	require.NoError(t, err)
+
+	t.Run("creates the silence of the window once", func(t *testing.T) {
+		require.NoError(t, am.syncRecurringSilences(ctx, time.Now()))
+		require.NoError(t, am.syncRecurringSilences(ctx, time.Now()))
+
+		sils := activeSilences()
+		require.Len(t, sils, 1)
+		require.Equal(t, "recurring-silence:nightly-batch", *sils[0].CreatedBy)
+		require.Equal(t, "Created by the recurring silence nightly-batch", *sils[0].Comment)
+		require.Equal(t, start.Truncate(time.Minute).Add(2*time.Hour), time.Time(*sils[0].EndsAt).UTC())
+	})
+
+	t.Run("replaces the silence when the recurring silence changes", func(t *testing.T) {
+		recurring.Comment = "batch jobs restart the services"
+		_, err := am.Store.UpdateRecurringSilence(ctx, am.orgID, recurring)
+		require.NoError(t, err)
+
+		require.NoError(t, am.syncRecurringSilences(ctx, time.Now()))
+
+		sils := activeSilences()
+		require.Len(t, sils, 1)
+		require.Equal(t, recurring.Comment, *sils[0].Comment)
+	})
+
+	t.Run("does not create the silence again when it is expired by a user", func(t *testing.T) {
+		sils := activeSilences()
+		require.Len(t, sils, 1)
+		require.NoError(t, am.DeleteSilence(*sils[0].ID))
+
+		require.NoError(t, am.syncRecurringSilences(ctx, time.Now()))
+		require.Empty(t, activeSilences())
+	})
+
+	t.Run("expires the silence when the recurring silence is deleted", func(t *testing.T) {
+		other := recurring
+		other.Name = "weekly-backup"
+		require.NoError(t, am.Store.InsertRecurringSilence(ctx, am.orgID, other))
+		require.NoError(t, am.syncRecurringSilences(ctx, time.Now()))
+		require.Len(t, activeSilences(), 1)
+
+		_, err := am.Store.DeleteRecurringSilence(ctx, am.orgID, other.Name)
+		require.NoError(t, err)
+		require.NoError(t, am.syncRecurringSilences(ctx, time.Now()))
+		require.Empty(t, activeSilences())
+	})
+
+	t.Run("does not change the silences of users", func(t *testing.T) {
+		// the silence looks like the silence of the recurring silence, but it was not created for it
+		ps := recurring.Silence(time.Now().Add(-time.Minute), "recurring-silence:"+recurring.Name)
+		comment := "created by a user"
+		ps.Comment = &comment
+		id, err := am.CreateSilence(&ps)
+		require.NoError(t, err)
+		_, err = am.Store.DeleteRecurringSilence(ctx, am.orgID, recurring.Name)
+		require.NoError(t, err)
+
+		require.NoError(t, am.syncRecurringSilences(ctx, time.Now()))
+		sils := activeSilences()
+		require.Len(t, sils, 1)
+		require.Equal(t, id, *sils[0].ID)
+	})
+}
@@ -10,12 +10,15 @@ import (
 	"testing"
 
 	"github.com/grafana/grafana/pkg/infra/kvstore"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 )
 
 type FakeConfigStore struct {
-	configs map[int64]*models.AlertConfiguration
+	configs           map[int64]*models.AlertConfiguration
+	silenceTemplates  map[int64][]apimodels.SilenceTemplate
+	recurringSilences map[int64][]apimodels.RecurringSilence
 }
 
 // Saves the image or returns an error.
@@ -97,6 +100,55 @@ func (f *FakeConfigStore) UpdateAlertmanagerConfiguration(_ context.Context, cmd
 	return errors.New("config not found or hash not valid")
 }
 
+func (f *FakeConfigStore) GetSilenceTemplates(_ context.Context, orgID int64) ([]apimodels.SilenceTemplate, error) {
+	return f.silenceTemplates[orgID], nil
+}
+
+func (f *FakeConfigStore) SaveSilenceTemplates(_ context.Context, orgID int64, templates []apimodels.SilenceTemplate) error {
+	if f.silenceTemplates == nil {
+		f.silenceTemplates = map[int64][]apimodels.SilenceTemplate{}
+	}
+	f.silenceTemplates[orgID] = templates
+	return nil
+}
+
+func (f *FakeConfigStore) GetRecurringSilences(_ context.Context, orgID int64) ([]apimodels.RecurringSilence, error) {
+	return f.recurringSilences[orgID], nil
+}
+
+func (f *FakeConfigStore) InsertRecurringSilence(_ context.Context, orgID int64, s apimodels.RecurringSilence) error {
+	if f.recurringSilences == nil {
+		f.recurringSilences = map[int64][]apimodels.RecurringSilence{}
+	}
+	for _, existing := range f.recurringSilences[orgID] {
+		if existing.Name == s.Name {
+			return store.ErrRecurringSilenceExists
+		}
+	}
+	f.recurringSilences[orgID] = append(f.recurringSilences[orgID], s)
+	return nil
+}
+
+func (f *FakeConfigStore) UpdateRecurringSilence(_ context.Context, orgID int64, s apimodels.RecurringSilence) (bool, error) {
+	for i, existing := range f.recurringSilences[orgID] {
+		if existing.Name == s.Name {
+			f.recurringSilences[orgID][i] = s
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *FakeConfigStore) DeleteRecurringSilence(_ context.Context, orgID int64, name string) (bool, error) {
+	for i, existing := range f.recurringSilences[orgID] {
+		if existing.Name == name {
+			f.recurringSilences[orgID] = append(f.recurringSilences[orgID][:i:i], f.recurringSilences[orgID][i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 type FakeOrgStore struct {
 	orgs []int64
 }
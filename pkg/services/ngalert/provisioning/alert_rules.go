@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -106,6 +107,10 @@ func (service *AlertRuleService) CreateAlertRule(ctx context.Context, rule model
 			return err
 		}
 
+		if err = service.checkDependenciesTransactionCtx(ctx, rule.GetGroupKey()); err != nil {
+			return err
+		}
+
 		return service.provenanceStore.SetProvenance(ctx, &rule, rule.OrgID, provenance)
 	})
 	if err != nil {
@@ -140,6 +145,66 @@ func (service *AlertRuleService) GetRuleGroup(ctx context.Context, orgID int64,
 	return res, nil
 }
 
+// GetAlertGroupsWithFolderTitle returns the rule groups of the organization together with the titles of their folders.
+// If folderUIDs is not empty, only groups of those folders are returned. If group is not empty, only groups with this name are returned.
+// Groups are ordered by folder and name, and rules within a group by their index.
+func (service *AlertRuleService) GetAlertGroupsWithFolderTitle(ctx context.Context, orgID int64, folderUIDs []string, group string) ([]models.AlertRuleGroupWithFolderTitle, error) {
+	q := models.ListAlertRulesQuery{
+		OrgID:         orgID,
+		NamespaceUIDs: folderUIDs,
+		RuleGroup:     group,
+	}
+	if err := service.ruleStore.ListAlertRules(ctx, &q); err != nil {
+		return nil, err
+	}
+	if len(q.Result) == 0 {
+		return nil, nil
+	}
+
+	namespaces := make([]string, 0)
+	groups := make(map[models.AlertRuleGroupKey][]models.AlertRule)
+	for _, r := range q.Result {
+		if r == nil {
+			continue
+		}
+		if len(namespaces) == 0 || namespaces[len(namespaces)-1] != r.NamespaceUID {
+			namespaces = append(namespaces, r.NamespaceUID)
+		}
+		key := r.GetGroupKey()
+		groups[key] = append(groups[key], *r)
+	}
+
+	titles, err := service.ruleStore.GetNamespaceTitlesByUID(ctx, orgID, namespaces...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.AlertRuleGroupWithFolderTitle, 0, len(groups))
+	for key, rules := range groups {
+		title, ok := titles[key.NamespaceUID]
+		if !ok {
+			return nil, fmt.Errorf("%w: folder '%s' of rule group '%s'", ErrNotFound, key.NamespaceUID, key.RuleGroup)
+		}
+		result = append(result, models.AlertRuleGroupWithFolderTitle{
+			AlertRuleGroup: models.AlertRuleGroup{
+				Title:     key.RuleGroup,
+				FolderUID: key.NamespaceUID,
+				Interval:  rules[0].IntervalSeconds,
+				Rules:     rules,
+			},
+			OrgID:       orgID,
+			FolderTitle: title,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].FolderTitle != result[j].FolderTitle {
+			return result[i].FolderTitle < result[j].FolderTitle
+		}
+		return result[i].Title < result[j].Title
+	})
+	return result, nil
+}
+
 // UpdateRuleGroup will update the interval for all rules in the group.
 func (service *AlertRuleService) UpdateRuleGroup(ctx context.Context, orgID int64, namespaceUID string, ruleGroup string, intervalSeconds int64) error {
 	if err := models.ValidateRuleGroupInterval(intervalSeconds, service.baseIntervalSeconds); err != nil {
@@ -273,7 +338,7 @@ func (service *AlertRuleService) ReplaceRuleGroup(ctx context.Context, orgID int
 			return err
 		}
 
-		return nil
+		return service.checkDependenciesTransactionCtx(ctx, key)
 	})
 }
 
@@ -305,6 +370,9 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, rule model
 		if err != nil {
 			return err
 		}
+		if err := service.checkDependenciesTransactionCtx(ctx, storedRule.GetGroupKey(), rule.GetGroupKey()); err != nil {
+			return err
+		}
 		return service.provenanceStore.SetProvenance(ctx, &rule, rule.OrgID, provenance)
 	})
 	if err != nil {
@@ -347,6 +415,25 @@ func (service *AlertRuleService) checkLimitsTransactionCtx(ctx context.Context,
 }
 
 // deleteRules deletes a set of target rules and associated data, while checking for database consistency.
+// checkDependenciesTransactionCtx checks that the rules of the groups, as written by the current transaction (as
+// identified by the ctx), depend only on alerting rules of the same group and do not form a dependency cycle.
+func (service *AlertRuleService) checkDependenciesTransactionCtx(ctx context.Context, keys ...models.AlertRuleGroupKey) error {
+	for _, key := range keys {
+		q := models.ListAlertRulesQuery{
+			OrgID:         key.OrgID,
+			NamespaceUIDs: []string{key.NamespaceUID},
+			RuleGroup:     key.RuleGroup,
+		}
+		if err := service.ruleStore.ListAlertRules(ctx, &q); err != nil {
+			return fmt.Errorf("failed to list alert rules: %w", err)
+		}
+		if err := models.RulesGroup(q.Result).ValidateDependencies(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (service *AlertRuleService) deleteRules(ctx context.Context, orgID int64, targets ...*models.AlertRule) error {
 	uids := make([]string, 0, len(targets))
 	for _, tgt := range targets {
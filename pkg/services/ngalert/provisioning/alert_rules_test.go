@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	gfmodels "github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
@@ -309,6 +310,53 @@ func TestAlertRuleService(t *testing.T) {
 		}
 	})
 
+	t.Run("group write with a dependency cycle should be rejected", func(t *testing.T) {
+		var orgID int64 = 1
+		group := createDummyGroup("dependency-cycle", orgID)
+		group.Rules = []models.AlertRule{createTestRule("cycle-database", group.Title, orgID), createTestRule("cycle-network", group.Title, orgID)}
+		require.NoError(t, ruleService.ReplaceRuleGroup(context.Background(), orgID, group, 0, models.ProvenanceAPI))
+		group, err := ruleService.GetRuleGroup(context.Background(), orgID, "my-namespace", group.Title)
+		require.NoError(t, err)
+		require.Len(t, group.Rules, 2)
+
+		models.WithDependencies(group.Rules[1].UID)(&group.Rules[0])
+		models.WithDependencies(group.Rules[0].UID)(&group.Rules[1])
+		err = ruleService.ReplaceRuleGroup(context.Background(), orgID, group, 0, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "cycle")
+
+		stored, err := ruleService.GetRuleGroup(context.Background(), orgID, "my-namespace", group.Title)
+		require.NoError(t, err)
+		for _, rule := range stored.Rules {
+			require.Empty(t, rule.GetDependencies(), "the rules must not be updated")
+		}
+	})
+
+	t.Run("rule update that adds a dependency cycle should be rejected", func(t *testing.T) {
+		var orgID int64 = 1
+		database, err := ruleService.CreateAlertRule(context.Background(), createTestRule("update-database", "dependency-update", orgID), models.ProvenanceNone, 0)
+		require.NoError(t, err)
+		network := createTestRule("update-network", "dependency-update", orgID)
+		models.WithDependencies(database.UID)(&network)
+		network, err = ruleService.CreateAlertRule(context.Background(), network, models.ProvenanceNone, 0)
+		require.NoError(t, err)
+
+		models.WithDependencies(network.UID)(&database)
+		_, err = ruleService.UpdateAlertRule(context.Background(), database, models.ProvenanceNone)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("rule creation that depends on a rule of another group should be rejected", func(t *testing.T) {
+		var orgID int64 = 1
+		other, err := ruleService.CreateAlertRule(context.Background(), createTestRule("other", "dependency-other", orgID), models.ProvenanceNone, 0)
+		require.NoError(t, err)
+		rule := createTestRule("dependent", "dependency-create", orgID)
+		models.WithDependencies(other.UID)(&rule)
+
+		_, err = ruleService.CreateAlertRule(context.Background(), rule, models.ProvenanceNone, 0)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
 	t.Run("quota met causes create to be rejected", func(t *testing.T) {
 		ruleService := createAlertRuleService(t)
 		checker := &MockQuotaChecker{}
@@ -333,6 +381,82 @@ func TestAlertRuleService(t *testing.T) {
 	})
 }
 
+func TestAlertRuleService_GetAlertGroupsWithFolderTitle(t *testing.T) {
+	ruleService := createAlertRuleService(t)
+	var orgID int64 = 10
+	insertFolder(t, ruleService, orgID, "folder-b", "B")
+	insertFolder(t, ruleService, orgID, "folder-a", "A")
+
+	for _, r := range []struct{ title, folder, group string }{
+		{"rule-1", "folder-b", "group-1"},
+		{"rule-2", "folder-a", "group-2"},
+		{"rule-3", "folder-a", "group-1"},
+		{"rule-4", "folder-a", "group-1"},
+	} {
+		rule := createTestRule(r.title, r.group, orgID)
+		rule.NamespaceUID = r.folder
+		_, err := ruleService.CreateAlertRule(context.Background(), rule, models.ProvenanceNone, 0)
+		require.NoError(t, err)
+	}
+
+	t.Run("should return all groups of the org ordered by folder title and name", func(t *testing.T) {
+		groups, err := ruleService.GetAlertGroupsWithFolderTitle(context.Background(), orgID, nil, "")
+		require.NoError(t, err)
+		require.Len(t, groups, 3)
+		require.Equal(t, "A", groups[0].FolderTitle)
+		require.Equal(t, "group-1", groups[0].Title)
+		require.Len(t, groups[0].Rules, 2)
+		require.Equal(t, "rule-3", groups[0].Rules[0].Title)
+		require.Equal(t, "rule-4", groups[0].Rules[1].Title)
+		require.Equal(t, "A", groups[1].FolderTitle)
+		require.Equal(t, "group-2", groups[1].Title)
+		require.Equal(t, "B", groups[2].FolderTitle)
+		require.Equal(t, "folder-b", groups[2].FolderUID)
+		for _, g := range groups {
+			require.Equal(t, orgID, g.OrgID)
+			require.Equal(t, int64(60), g.Interval)
+		}
+	})
+
+	t.Run("should filter by folder and group", func(t *testing.T) {
+		groups, err := ruleService.GetAlertGroupsWithFolderTitle(context.Background(), orgID, []string{"folder-b"}, "group-1")
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		require.Equal(t, "B", groups[0].FolderTitle)
+		require.Equal(t, "rule-1", groups[0].Rules[0].Title)
+	})
+
+	t.Run("should return empty result if nothing matches", func(t *testing.T) {
+		groups, err := ruleService.GetAlertGroupsWithFolderTitle(context.Background(), orgID, []string{"folder-b"}, "group-2")
+		require.NoError(t, err)
+		require.Empty(t, groups)
+	})
+
+	t.Run("should fail if folder does not exist", func(t *testing.T) {
+		rule := createTestRule("rule-5", "group-1", orgID)
+		rule.NamespaceUID = "unknown-folder"
+		_, err := ruleService.CreateAlertRule(context.Background(), rule, models.ProvenanceNone, 0)
+		require.NoError(t, err)
+
+		_, err = ruleService.GetAlertGroupsWithFolderTitle(context.Background(), orgID, []string{"unknown-folder"}, "")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func insertFolder(t *testing.T, service AlertRuleService, orgID int64, uid, title string) {
+	t.Helper()
+	folder := gfmodels.NewDashboardFolder(title)
+	folder.Uid = uid
+	folder.OrgId = orgID
+	folder.Created = time.Now()
+	folder.Updated = time.Now()
+	err := service.ruleStore.(store.DBstore).SQLStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+		_, err := sess.Insert(folder)
+		return err
+	})
+	require.NoError(t, err)
+}
+
 func createAlertRuleService(t *testing.T) AlertRuleService {
 	t.Helper()
 	sqlStore := db.InitTestDB(t)
@@ -40,6 +40,8 @@ type ContactPointQuery struct {
 	// Optionally filter by name.
 	Name  string
 	OrgID int64
+	// Decrypt returns the values of secure settings instead of redacting them.
+	Decrypt bool
 }
 
 func (ecp *ContactPointService) GetContactPoints(ctx context.Context, q ContactPointQuery) ([]apimodels.EmbeddedContactPoint, error) {
@@ -80,6 +82,10 @@ func (ecp *ContactPointService) GetContactPoints(ctx context.Context, q ContactP
 			if decryptedValue == "" {
 				continue
 			}
+			if q.Decrypt {
+				embeddedContactPoint.Settings.Set(k, decryptedValue)
+				continue
+			}
 			embeddedContactPoint.Settings.Set(k, apimodels.RedactedValue)
 		}
 
@@ -134,6 +140,17 @@ func (ecp *ContactPointService) CreateContactPoint(ctx context.Context, orgID in
 	if err := contactPoint.Valid(ecp.encryptionService.GetDecryptedValue); err != nil {
 		return apimodels.EmbeddedContactPoint{}, fmt.Errorf("%w: %s", ErrValidation, err.Error())
 	}
+	// a new contact point has no stored secret that a redacted value could refer to, for example
+	// when an export, which redacts the secrets, is provisioned to another organization or instance.
+	secretKeys, err := contactPoint.SecretKeys()
+	if err != nil {
+		return apimodels.EmbeddedContactPoint{}, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+	for _, secretKey := range secretKeys {
+		if contactPoint.Settings.Get(secretKey).MustString() == apimodels.RedactedValue {
+			return apimodels.EmbeddedContactPoint{}, fmt.Errorf("%w: secure setting '%s' is redacted, set its actual value", ErrValidation, secretKey)
+		}
+	}
 
 	revision, err := getLastConfiguration(ctx, orgID, ecp.amStore)
 	if err != nil {
@@ -28,6 +28,16 @@ type ProvisioningStore interface {
 	DeleteProvenance(ctx context.Context, o models.Provisionable, org int64) error
 }
 
+// SilenceStore is a store of the silence templates and the recurring silences of an organization.
+type SilenceStore interface {
+	GetSilenceTemplates(ctx context.Context, orgID int64) ([]definitions.SilenceTemplate, error)
+	SaveSilenceTemplates(ctx context.Context, orgID int64, templates []definitions.SilenceTemplate) error
+	GetRecurringSilences(ctx context.Context, orgID int64) ([]definitions.RecurringSilence, error)
+	InsertRecurringSilence(ctx context.Context, orgID int64, s definitions.RecurringSilence) error
+	UpdateRecurringSilence(ctx context.Context, orgID int64, s definitions.RecurringSilence) (bool, error)
+	DeleteRecurringSilence(ctx context.Context, orgID int64, name string) (bool, error)
+}
+
 // TransactionManager represents the ability to issue and close transactions through contexts.
 type TransactionManager interface {
 	InTransaction(ctx context.Context, work func(ctx context.Context) error) error
@@ -42,6 +52,7 @@ type RuleStore interface {
 	UpdateAlertRules(ctx context.Context, rule []models.UpdateRule) error
 	DeleteAlertRulesByUID(ctx context.Context, orgID int64, ruleUID ...string) error
 	GetAlertRulesGroupByRuleUID(ctx context.Context, query *models.GetAlertRulesGroupByRuleUIDQuery) error
+	GetNamespaceTitlesByUID(ctx context.Context, orgID int64, uids ...string) (map[string]string, error)
 }
 
 // QuotaChecker represents the ability to evaluate whether quotas are met.
@@ -0,0 +1,232 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+type SilenceService struct {
+	store SilenceStore
+	prov  ProvisioningStore
+	xact  TransactionManager
+	log   log.Logger
+}
+
+func NewSilenceService(store SilenceStore, prov ProvisioningStore, xact TransactionManager, log log.Logger) *SilenceService {
+	return &SilenceService{
+		store: store,
+		prov:  prov,
+		xact:  xact,
+		log:   log,
+	}
+}
+
+// GetSilenceTemplates returns all silence templates within the specified org.
+func (svc *SilenceService) GetSilenceTemplates(ctx context.Context, orgID int64) ([]definitions.SilenceTemplate, error) {
+	templates, err := svc.store.GetSilenceTemplates(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	provenances, err := svc.prov.GetProvenances(ctx, orgID, (&definitions.SilenceTemplate{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]definitions.SilenceTemplate, 0, len(templates))
+	for _, t := range templates {
+		t.Provenance = provenances[t.ResourceID()]
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+// GetSilenceTemplate returns the silence template with the given name within the specified org, or nil if it does not exist.
+func (svc *SilenceService) GetSilenceTemplate(ctx context.Context, name string, orgID int64) (*definitions.SilenceTemplate, error) {
+	templates, err := svc.GetSilenceTemplates(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
+		if t.Name == name {
+			return &t, nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateSilenceTemplate adds a new silence template within the specified org. The created silence template is returned.
+func (svc *SilenceService) CreateSilenceTemplate(ctx context.Context, t definitions.SilenceTemplate, orgID int64) (*definitions.SilenceTemplate, error) {
+	if err := t.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+
+	err := svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		templates, err := svc.store.GetSilenceTemplates(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		for _, existing := range templates {
+			if existing.Name == t.Name {
+				return fmt.Errorf("%w: %s", ErrValidation, "a silence template with this name already exists")
+			}
+		}
+		if err := svc.store.SaveSilenceTemplates(ctx, orgID, append(templates, t)); err != nil {
+			return err
+		}
+		return svc.prov.SetProvenance(ctx, &t, orgID, t.Provenance)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// UpdateSilenceTemplate replaces an existing silence template within the specified org. The replaced silence template is returned. If the silence template does not exist, nil is returned and no action is taken.
+func (svc *SilenceService) UpdateSilenceTemplate(ctx context.Context, t definitions.SilenceTemplate, orgID int64) (*definitions.SilenceTemplate, error) {
+	if err := t.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+
+	updated := false
+	err := svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		templates, err := svc.store.GetSilenceTemplates(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		for i, existing := range templates {
+			if existing.Name == t.Name {
+				templates[i] = t
+				updated = true
+				break
+			}
+		}
+		if !updated {
+			return nil
+		}
+		if err := svc.store.SaveSilenceTemplates(ctx, orgID, templates); err != nil {
+			return err
+		}
+		return svc.prov.SetProvenance(ctx, &t, orgID, t.Provenance)
+	})
+	if err != nil || !updated {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// DeleteSilenceTemplate deletes the silence template with the given name in the given org. If the silence template does not exist, no error is returned.
+func (svc *SilenceService) DeleteSilenceTemplate(ctx context.Context, name string, orgID int64) error {
+	return svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		templates, err := svc.store.GetSilenceTemplates(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		remaining := make([]definitions.SilenceTemplate, 0, len(templates))
+		for _, existing := range templates {
+			if existing.Name != name {
+				remaining = append(remaining, existing)
+			}
+		}
+		if len(remaining) == len(templates) {
+			return nil
+		}
+		if err := svc.store.SaveSilenceTemplates(ctx, orgID, remaining); err != nil {
+			return err
+		}
+		target := definitions.SilenceTemplate{Name: name}
+		return svc.prov.DeleteProvenance(ctx, &target, orgID)
+	})
+}
+
+// GetRecurringSilences returns all recurring silences within the specified org.
+func (svc *SilenceService) GetRecurringSilences(ctx context.Context, orgID int64) ([]definitions.RecurringSilence, error) {
+	silences, err := svc.store.GetRecurringSilences(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	provenances, err := svc.prov.GetProvenances(ctx, orgID, (&definitions.RecurringSilence{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]definitions.RecurringSilence, 0, len(silences))
+	for _, s := range silences {
+		s.Provenance = provenances[s.ResourceID()]
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// GetRecurringSilence returns the recurring silence with the given name within the specified org, or nil if it does not exist.
+func (svc *SilenceService) GetRecurringSilence(ctx context.Context, name string, orgID int64) (*definitions.RecurringSilence, error) {
+	silences, err := svc.GetRecurringSilences(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range silences {
+		if s.Name == name {
+			return &s, nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateRecurringSilence adds a new recurring silence within the specified org. The created recurring silence is returned.
+// The silences of the schedule are created by the Alertmanager of the org.
+func (svc *SilenceService) CreateRecurringSilence(ctx context.Context, s definitions.RecurringSilence, orgID int64) (*definitions.RecurringSilence, error) {
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+
+	err := svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.InsertRecurringSilence(ctx, orgID, s); err != nil {
+			if errors.Is(err, store.ErrRecurringSilenceExists) {
+				return fmt.Errorf("%w: %s", ErrValidation, err.Error())
+			}
+			return err
+		}
+		return svc.prov.SetProvenance(ctx, &s, orgID, s.Provenance)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// UpdateRecurringSilence replaces an existing recurring silence within the specified org. The replaced recurring silence is returned. If the recurring silence does not exist, nil is returned and no action is taken.
+func (svc *SilenceService) UpdateRecurringSilence(ctx context.Context, s definitions.RecurringSilence, orgID int64) (*definitions.RecurringSilence, error) {
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+
+	updated := false
+	err := svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		updated, err = svc.store.UpdateRecurringSilence(ctx, orgID, s)
+		if err != nil || !updated {
+			return err
+		}
+		return svc.prov.SetProvenance(ctx, &s, orgID, s.Provenance)
+	})
+	if err != nil || !updated {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// DeleteRecurringSilence deletes the recurring silence with the given name in the given org. If the recurring silence does not exist, no error is returned.
+func (svc *SilenceService) DeleteRecurringSilence(ctx context.Context, name string, orgID int64) error {
+	return svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		deleted, err := svc.store.DeleteRecurringSilence(ctx, orgID, name)
+		if err != nil || !deleted {
+			return err
+		}
+		target := definitions.RecurringSilence{Name: name}
+		return svc.prov.DeleteProvenance(ctx, &target, orgID)
+	})
+}
@@ -0,0 +1,156 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestSilenceService(t *testing.T) {
+	t.Run("silence templates", func(t *testing.T) {
+		t.Run("rejects templates that fail validation", func(t *testing.T) {
+			sut := createSilenceSvcSut()
+			tmpl := createSilenceTemplate()
+			tmpl.Matchers = nil
+
+			_, err := sut.CreateSilenceTemplate(context.Background(), tmpl, 1)
+
+			require.ErrorIs(t, err, ErrValidation)
+		})
+
+		t.Run("rejects templates with an existing name", func(t *testing.T) {
+			sut := createSilenceSvcSut()
+			_, err := sut.CreateSilenceTemplate(context.Background(), createSilenceTemplate(), 1)
+			require.NoError(t, err)
+
+			_, err = sut.CreateSilenceTemplate(context.Background(), createSilenceTemplate(), 1)
+
+			require.ErrorIs(t, err, ErrValidation)
+		})
+
+		t.Run("returns the created templates with their provenance", func(t *testing.T) {
+			sut := createSilenceSvcSut()
+			tmpl := createSilenceTemplate()
+			tmpl.Provenance = models.ProvenanceAPI
+			_, err := sut.CreateSilenceTemplate(context.Background(), tmpl, 1)
+			require.NoError(t, err)
+
+			result, err := sut.GetSilenceTemplates(context.Background(), 1)
+			require.NoError(t, err)
+			require.Equal(t, []definitions.SilenceTemplate{tmpl}, result)
+
+			other, err := sut.GetSilenceTemplates(context.Background(), 2)
+			require.NoError(t, err)
+			require.Empty(t, other)
+		})
+
+		t.Run("update returns nil when the template does not exist", func(t *testing.T) {
+			sut := createSilenceSvcSut()
+
+			result, err := sut.UpdateSilenceTemplate(context.Background(), createSilenceTemplate(), 1)
+
+			require.NoError(t, err)
+			require.Nil(t, result)
+		})
+
+		t.Run("updates and deletes templates", func(t *testing.T) {
+			sut := createSilenceSvcSut()
+			tmpl := createSilenceTemplate()
+			_, err := sut.CreateSilenceTemplate(context.Background(), tmpl, 1)
+			require.NoError(t, err)
+
+			tmpl.Comment = "updated"
+			_, err = sut.UpdateSilenceTemplate(context.Background(), tmpl, 1)
+			require.NoError(t, err)
+			result, err := sut.GetSilenceTemplate(context.Background(), tmpl.Name, 1)
+			require.NoError(t, err)
+			require.Equal(t, "updated", result.Comment)
+
+			require.NoError(t, sut.DeleteSilenceTemplate(context.Background(), tmpl.Name, 1))
+			result, err = sut.GetSilenceTemplate(context.Background(), tmpl.Name, 1)
+			require.NoError(t, err)
+			require.Nil(t, result)
+		})
+	})
+
+	t.Run("recurring silences", func(t *testing.T) {
+		t.Run("rejects recurring silences with an invalid schedule", func(t *testing.T) {
+			sut := createSilenceSvcSut()
+			s := createRecurringSilence()
+			s.Schedule = "every night"
+
+			_, err := sut.CreateRecurringSilence(context.Background(), s, 1)
+
+			require.ErrorIs(t, err, ErrValidation)
+		})
+
+		t.Run("returns the created recurring silences with their provenance", func(t *testing.T) {
+			sut := createSilenceSvcSut()
+			s := createRecurringSilence()
+			s.Provenance = models.ProvenanceFile
+			_, err := sut.CreateRecurringSilence(context.Background(), s, 1)
+			require.NoError(t, err)
+
+			result, err := sut.GetRecurringSilences(context.Background(), 1)
+			require.NoError(t, err)
+			require.Equal(t, []definitions.RecurringSilence{s}, result)
+		})
+
+		t.Run("updates and deletes recurring silences", func(t *testing.T) {
+			sut := createSilenceSvcSut()
+			s := createRecurringSilence()
+			_, err := sut.CreateRecurringSilence(context.Background(), s, 1)
+			require.NoError(t, err)
+
+			s.Schedule = "0 1 * * *"
+			updated, err := sut.UpdateRecurringSilence(context.Background(), s, 1)
+			require.NoError(t, err)
+			require.NotNil(t, updated)
+			result, err := sut.GetRecurringSilence(context.Background(), s.Name, 1)
+			require.NoError(t, err)
+			require.Equal(t, "0 1 * * *", result.Schedule)
+
+			require.NoError(t, sut.DeleteRecurringSilence(context.Background(), s.Name, 1))
+			result, err = sut.GetRecurringSilence(context.Background(), s.Name, 1)
+			require.NoError(t, err)
+			require.Nil(t, result)
+			provenances, err := sut.prov.GetProvenances(context.Background(), 1, s.ResourceType())
+			require.NoError(t, err)
+			require.Empty(t, provenances)
+		})
+	})
+}
+
+func createSilenceSvcSut() *SilenceService {
+	return &SilenceService{
+		store: newFakeSilenceStore(),
+		prov:  NewFakeProvisioningStore(),
+		xact:  newNopTransactionManager(),
+		log:   log.NewNopLogger(),
+	}
+}
+
+func createSilenceTemplate() definitions.SilenceTemplate {
+	return definitions.SilenceTemplate{
+		Name:     "maintenance",
+		Matchers: definitions.ObjectMatchers{{Type: labels.MatchEqual, Name: "team", Value: "a"}},
+		Duration: model.Duration(2 * time.Hour),
+	}
+}
+
+func createRecurringSilence() definitions.RecurringSilence {
+	return definitions.RecurringSilence{
+		Name:     "nightly-batch",
+		Matchers: definitions.ObjectMatchers{{Type: labels.MatchEqual, Name: "job", Value: "batch"}},
+		Schedule: "0 2 * * *",
+		Duration: model.Duration(2 * time.Hour),
+	}
+}
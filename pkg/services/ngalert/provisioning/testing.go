@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -136,6 +138,63 @@ func (f *fakeProvisioningStore) DeleteProvenance(ctx context.Context, o models.P
 	return nil
 }
 
+type fakeSilenceStore struct {
+	templates map[int64][]definitions.SilenceTemplate
+	recurring map[int64][]definitions.RecurringSilence
+}
+
+func newFakeSilenceStore() *fakeSilenceStore {
+	return &fakeSilenceStore{
+		templates: map[int64][]definitions.SilenceTemplate{},
+		recurring: map[int64][]definitions.RecurringSilence{},
+	}
+}
+
+func (f *fakeSilenceStore) GetSilenceTemplates(ctx context.Context, orgID int64) ([]definitions.SilenceTemplate, error) {
+	return append([]definitions.SilenceTemplate{}, f.templates[orgID]...), nil
+}
+
+func (f *fakeSilenceStore) SaveSilenceTemplates(ctx context.Context, orgID int64, templates []definitions.SilenceTemplate) error {
+	f.templates[orgID] = templates
+	return nil
+}
+
+func (f *fakeSilenceStore) GetRecurringSilences(ctx context.Context, orgID int64) ([]definitions.RecurringSilence, error) {
+	return append([]definitions.RecurringSilence{}, f.recurring[orgID]...), nil
+}
+
+func (f *fakeSilenceStore) InsertRecurringSilence(ctx context.Context, orgID int64, s definitions.RecurringSilence) error {
+	for _, existing := range f.recurring[orgID] {
+		if existing.Name == s.Name {
+			return store.ErrRecurringSilenceExists
+		}
+	}
+	s.Provenance = ""
+	f.recurring[orgID] = append(f.recurring[orgID], s)
+	return nil
+}
+
+func (f *fakeSilenceStore) UpdateRecurringSilence(ctx context.Context, orgID int64, s definitions.RecurringSilence) (bool, error) {
+	for i, existing := range f.recurring[orgID] {
+		if existing.Name == s.Name {
+			s.Provenance = ""
+			f.recurring[orgID][i] = s
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeSilenceStore) DeleteRecurringSilence(ctx context.Context, orgID int64, name string) (bool, error) {
+	for i, existing := range f.recurring[orgID] {
+		if existing.Name == name {
+			f.recurring[orgID] = append(f.recurring[orgID][:i:i], f.recurring[orgID][i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 type NopTransactionManager struct{}
 
 func newNopTransactionManager() *NopTransactionManager {
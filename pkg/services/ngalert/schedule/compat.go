@@ -141,7 +141,8 @@ func FromStateTransitionToPostableAlerts(firingStates []state.StateTransition, s
 		}
 		alert := stateToPostableAlert(alertState.State, appURL)
 		alerts.PostableAlerts = append(alerts.PostableAlerts, *alert)
-		if alertState.StateReason == ngModels.StateReasonMissingSeries { // do not put stale state back to state manager
+		// do not put stale states or states of paused rules back to state manager
+		if alertState.StateReason == ngModels.StateReasonMissingSeries || alertState.StateReason == ngModels.StateReasonPaused {
 			continue
 		}
 		alertState.LastSentAt = ts
@@ -78,16 +78,22 @@ func (r *alertRuleInfoRegistry) keyMap() map[models.AlertRuleKey]struct{} {
 
 type ruleVersion int64
 
+// ruleVersionAndPauseStatus is the message sent to the rule evaluation routine when the rule is updated.
+type ruleVersionAndPauseStatus struct {
+	Version  ruleVersion
+	IsPaused bool
+}
+
 type alertRuleInfo struct {
 	evalCh   chan *evaluation
-	updateCh chan ruleVersion
+	updateCh chan ruleVersionAndPauseStatus
 	ctx      context.Context
 	stop     func(reason error)
 }
 
 func newAlertRuleInfo(parent context.Context) *alertRuleInfo {
 	ctx, stop := util.WithCancelCause(parent)
-	return &alertRuleInfo{evalCh: make(chan *evaluation), updateCh: make(chan ruleVersion), ctx: ctx, stop: stop}
+	return &alertRuleInfo{evalCh: make(chan *evaluation), updateCh: make(chan ruleVersionAndPauseStatus), ctx: ctx, stop: stop}
 }
 
 // eval signals the rule evaluation routine to perform the evaluation of the rule. Does nothing if the loop is stopped.
@@ -114,13 +120,13 @@ func (a *alertRuleInfo) eval(eval *evaluation) (bool, *evaluation) {
 }
 
 // update sends an instruction to the rule evaluation routine to update the scheduled rule to the specified version. The specified version must be later than the current version, otherwise no update will happen.
-func (a *alertRuleInfo) update(lastVersion ruleVersion) bool {
+func (a *alertRuleInfo) update(lastVersion ruleVersionAndPauseStatus) bool {
 	// check if the channel is not empty.
 	msg := lastVersion
 	select {
 	case v := <-a.updateCh:
 		// if it has a version pick the greatest one.
-		if v > msg {
+		if v.Version > msg.Version {
 			msg = v
 		}
 	case <-a.ctx.Done():
@@ -140,6 +146,18 @@ type evaluation struct {
 	scheduledAt time.Time
 	rule        *models.AlertRule
 	folderTitle string
+	// dependencies are closed when the evaluations of the rules that the rule depends on are finished.
+	dependencies []<-chan struct{}
+	// done is closed when the evaluation is finished or skipped. It is nil if no other evaluation depends on it.
+	done chan struct{}
+}
+
+// finish signals the evaluations of the rules that depend on the rule that they can start.
+// It must be called exactly once for every evaluation that is sent to the rule evaluation routine.
+func (e *evaluation) finish() {
+	if e.done != nil {
+		close(e.done)
+	}
 }
 
 type alertRulesRegistry struct {
@@ -27,7 +27,7 @@ func TestSchedule_alertRuleInfo(t *testing.T) {
 			r := newAlertRuleInfo(context.Background())
 			resultCh := make(chan bool)
 			go func() {
-				resultCh <- r.update(ruleVersion(rand.Int63()))
+				resultCh <- r.update(ruleVersionAndPauseStatus{ruleVersion(rand.Int63()), false})
 			}()
 			select {
 			case <-r.updateCh:
@@ -38,8 +38,8 @@ func TestSchedule_alertRuleInfo(t *testing.T) {
 		})
 		t.Run("update should drop any concurrent sending to updateCh", func(t *testing.T) {
 			r := newAlertRuleInfo(context.Background())
-			version1 := ruleVersion(rand.Int31())
-			version2 := version1 + 1
+			version1 := ruleVersionAndPauseStatus{ruleVersion(rand.Int31()), false}
+			version2 := ruleVersionAndPauseStatus{version1.Version + 1, false}
 
 			wg := sync.WaitGroup{}
 			wg.Add(1)
@@ -64,8 +64,8 @@ func TestSchedule_alertRuleInfo(t *testing.T) {
 		})
 		t.Run("update should drop any concurrent sending to updateCh and use greater version", func(t *testing.T) {
 			r := newAlertRuleInfo(context.Background())
-			version1 := ruleVersion(rand.Int31())
-			version2 := version1 + 1
+			version1 := ruleVersionAndPauseStatus{ruleVersion(rand.Int31()), false}
+			version2 := ruleVersionAndPauseStatus{version1.Version + 1, false}
 
 			wg := sync.WaitGroup{}
 			wg.Add(1)
@@ -185,7 +185,7 @@ func TestSchedule_alertRuleInfo(t *testing.T) {
 			r := newAlertRuleInfo(context.Background())
 			r.stop(errRuleDeleted)
 			require.ErrorIs(t, r.ctx.Err(), errRuleDeleted)
-			require.False(t, r.update(ruleVersion(rand.Int63())))
+			require.False(t, r.update(ruleVersionAndPauseStatus{ruleVersion(rand.Int63()), false}))
 		})
 		t.Run("eval should do nothing", func(t *testing.T) {
 			r := newAlertRuleInfo(context.Background())
@@ -237,7 +237,7 @@ func TestSchedule_alertRuleInfo(t *testing.T) {
 					}
 					switch rand.Intn(max) + 1 {
 					case 1:
-						r.update(ruleVersion(rand.Int63()))
+						r.update(ruleVersionAndPauseStatus{ruleVersion(rand.Int63()), false})
 					case 2:
 						r.eval(&evaluation{
 							scheduledAt: time.Now(),
@@ -7,8 +7,10 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	prometheusModel "github.com/prometheus/common/model"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -16,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util/ticker"
@@ -33,7 +36,7 @@ type ScheduleService interface {
 	// an error. The scheduler is terminated when this function returns.
 	Run(context.Context) error
 	// UpdateAlertRule notifies scheduler that a rule has been changed
-	UpdateAlertRule(key ngmodels.AlertRuleKey, lastVersion int64)
+	UpdateAlertRule(key ngmodels.AlertRuleKey, lastVersion int64, isPaused bool)
 	// DeleteAlertRule notifies scheduler that rules have been deleted
 	DeleteAlertRule(keys ...ngmodels.AlertRuleKey)
 }
@@ -88,6 +91,15 @@ type schedule struct {
 	alertsSender    AlertsSender
 	minRuleInterval time.Duration
 
+	// recordingWriter writes the series of the recording rules. It can be nil.
+	recordingWriter writer.Writer
+
+	// sharding assigns the rule groups to the instances of the cluster. If it is nil, this instance evaluates all rules.
+	sharding *ruleSharding
+
+	// enricher adds the labels and annotations of the enrichments of the rules to the results. It can be nil.
+	enricher Enricher
+
 	// schedulableAlertRules contains the alert rules that are considered for
 	// evaluation in the current tick. The evaluation of an alert rule in the
 	// current tick depends on its evaluation interval and when it was
@@ -107,6 +119,16 @@ type SchedulerCfg struct {
 	RuleStore            RulesStore
 	Metrics              *metrics.Scheduler
 	AlertSender          AlertsSender
+	RecordingWriter      writer.Writer
+	// ClusterMembership enables the sharding of the rule groups between the members of the cluster. It can be nil.
+	ClusterMembership ClusterMembership
+	// Enricher adds the labels and annotations of the enrichments of the rules to the results. It can be nil.
+	Enricher Enricher
+}
+
+// Enricher adds labels and annotations to the results of the evaluation of a rule.
+type Enricher interface {
+	Enrich(ctx eval.EvaluationContext, rule *ngmodels.AlertRule, results eval.Results) eval.Results
 }
 
 // NewScheduler returns a new schedule.
@@ -126,6 +148,11 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		minRuleInterval:       cfg.MinRuleInterval,
 		schedulableAlertRules: alertRulesRegistry{rules: make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule)},
 		alertsSender:          cfg.AlertSender,
+		recordingWriter:       cfg.RecordingWriter,
+		enricher:              cfg.Enricher,
+	}
+	if cfg.ClusterMembership != nil {
+		sch.sharding = newRuleSharding(cfg.ClusterMembership)
 	}
 
 	return &sch
@@ -142,12 +169,12 @@ func (sch *schedule) Run(ctx context.Context) error {
 }
 
 // UpdateAlertRule looks for the active rule evaluation and commands it to update the rule
-func (sch *schedule) UpdateAlertRule(key ngmodels.AlertRuleKey, lastVersion int64) {
+func (sch *schedule) UpdateAlertRule(key ngmodels.AlertRuleKey, lastVersion int64, isPaused bool) {
 	ruleInfo, err := sch.registry.get(key)
 	if err != nil {
 		return
 	}
-	ruleInfo.update(ruleVersion(lastVersion))
+	ruleInfo.update(ruleVersionAndPauseStatus{Version: ruleVersion(lastVersion), IsPaused: isPaused})
 }
 
 // DeleteAlertRule stops evaluation of the rule, deletes it from active rules, and cleans up state cache.
@@ -221,10 +248,28 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 	sch.metrics.SchedulableAlertRules.Set(float64(len(alertRules)))
 	sch.metrics.SchedulableAlertRulesHash.Set(float64(hashUIDs(alertRules)))
 
+	if sch.sharding != nil && sch.sharding.update() {
+		sch.log.Info("Members of the cluster changed, rule groups are reassigned", "self", sch.sharding.self, "members", sch.sharding.members)
+	}
+
 	readyToRun := make([]readyToRunItem, 0)
 	missingFolder := make(map[string][]string)
+	owned := 0
+	var remote []*ngmodels.AlertRule
 	for _, item := range alertRules {
 		key := item.GetKey()
+		if sch.sharding != nil && !sch.sharding.owns(item.GetGroupKey()) {
+			remote = append(remote, item)
+			// the rule is evaluated by another instance, which takes over its state
+			if ruleInfo, ok := sch.registry.del(key); ok {
+				ruleInfo.stop(errRuleNotOwned)
+			} else {
+				sch.stateManager.ForgetStateByRuleUID(key)
+			}
+			delete(registeredDefinitions, key)
+			continue
+		}
+		owned++
 		ruleInfo, newRoutine := sch.registry.getOrCreateInfo(ctx, key)
 
 		// enforce minimum evaluation interval
@@ -236,7 +281,14 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		invalidInterval := item.IntervalSeconds%int64(sch.baseInterval.Seconds()) != 0
 
 		if newRoutine && !invalidInterval {
+			// the state of a rule that was evaluated by another instance is loaded from the database
+			// because the state in the cache was loaded when this instance started.
+			warm := sch.sharding != nil && sch.sharding.rebalances > 0
+			rule := item
 			dispatcherGroup.Go(func() error {
+				if warm {
+					sch.stateManager.WarmRule(ruleInfo.ctx, rule)
+				}
 				return sch.ruleRoutine(ruleInfo.ctx, key, ruleInfo.evalCh, ruleInfo.updateCh)
 			})
 		}
@@ -248,6 +300,14 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 			continue
 		}
 
+		// paused rules are not evaluated. The routine of the rule keeps running so that its state is cleared
+		// if the rule is deleted.
+		if item.IsPaused {
+			sch.pauseRule(ctx, item, tick)
+			delete(registeredDefinitions, key)
+			continue
+		}
+
 		itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
 		if item.IntervalSeconds != 0 && tickNum%itemFrequency == 0 {
 			var folderTitle string
@@ -270,10 +330,18 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		delete(registeredDefinitions, key)
 	}
 
+	if sch.sharding != nil {
+		sch.metrics.OwnedAlertRules.Set(float64(owned))
+		// the states of the rules evaluated by other instances are read from the database by the API
+		sch.stateManager.SetRemoteRules(remote)
+	}
+
 	if len(missingFolder) > 0 { // if this happens then there can be problems with fetching folders from the database.
 		sch.log.Warn("Unable to obtain folder titles for some rules", "missingFolderUIDToRuleUID", missingFolder)
 	}
 
+	sch.linkDependencies(readyToRun)
+
 	var step int64 = 0
 	if len(readyToRun) > 0 {
 		step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
@@ -285,7 +353,11 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		time.AfterFunc(time.Duration(int64(i)*step), func() {
 			key := item.rule.GetKey()
 			success, dropped := item.ruleInfo.eval(&item.evaluation)
+			if dropped != nil {
+				dropped.finish()
+			}
 			if !success {
+				item.evaluation.finish()
 				sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", tick)...)
 				return
 			}
@@ -305,7 +377,60 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 	return readyToRun, registeredDefinitions
 }
 
-func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertRuleKey, evalCh <-chan *evaluation, updateCh <-chan ruleVersion) error {
+// pauseRule pauses the states of a paused rule, including the ones restored on startup, and sends the alerts that
+// are resolved by the pause. The states are kept, with the reason Paused, until the rule is resumed or deleted.
+func (sch *schedule) pauseRule(ctx context.Context, rule *ngmodels.AlertRule, pausedAt time.Time) {
+	states := sch.stateManager.PauseStatesByRule(ctx, pausedAt, rule)
+	resolvedAlerts := FromStateTransitionToPostableAlerts(states, sch.stateManager, sch.appURL)
+	if len(resolvedAlerts.PostableAlerts) > 0 {
+		sch.alertsSender.Send(rule.GetKey(), resolvedAlerts)
+	}
+}
+
+// linkDependencies makes the evaluation of each rule wait for the evaluations of the rules of the same group that
+// it depends on, so that the rules of a group are evaluated in dependency order in every tick. The dependencies that
+// are not evaluated in the tick, for example because they are paused, are satisfied, and the rule reads their current
+// state. If the dependencies of a group form a cycle, the rules of the group are evaluated independently.
+func (sch *schedule) linkDependencies(items []readyToRunItem) {
+	groups := make(map[ngmodels.AlertRuleGroupKey][]*readyToRunItem)
+	withDependencies := make(map[ngmodels.AlertRuleGroupKey]struct{})
+	for i := range items {
+		item := &items[i]
+		key := item.rule.GetGroupKey()
+		groups[key] = append(groups[key], item)
+		if len(item.rule.GetDependencies()) > 0 {
+			withDependencies[key] = struct{}{}
+		}
+	}
+
+	for groupKey := range withDependencies {
+		group := groups[groupKey]
+		rules := make(ngmodels.RulesGroup, 0, len(group))
+		byUID := make(map[string]*readyToRunItem, len(group))
+		for _, item := range group {
+			rules = append(rules, item.rule)
+			byUID[item.rule.UID] = item
+		}
+		if err := rules.ValidateDependencyCycles(); err != nil {
+			sch.log.Warn("Rules of the group are evaluated independently because their dependencies form a cycle", "org_id", groupKey.OrgID, "namespace_uid", groupKey.NamespaceUID, "rule_group", groupKey.RuleGroup, "error", err)
+			continue
+		}
+		for _, item := range group {
+			for _, uid := range item.rule.GetDependencies() {
+				dependency, ok := byUID[uid]
+				if !ok {
+					continue
+				}
+				if dependency.done == nil {
+					dependency.done = make(chan struct{})
+				}
+				item.dependencies = append(item.dependencies, dependency.done)
+			}
+		}
+	}
+}
+
+func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertRuleKey, evalCh <-chan *evaluation, updateCh <-chan ruleVersionAndPauseStatus) error {
 	grafanaCtx = ngmodels.WithRuleKey(grafanaCtx, key)
 	logger := sch.log.FromContext(grafanaCtx)
 	logger.Debug("Alert rule routine started")
@@ -341,7 +466,29 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				},
 			},
 		}
-		evalCtx := eval.Context(ctx, schedulerUser)
+		evalCtx := eval.NewContextWithPreviousResults(ctx, schedulerUser, alertingResultsFromRuleState{
+			manager: sch.stateManager,
+			rule:    e.rule,
+		})
+		evalCtx.RuleStateReader = ruleStateFromScheduledRules{
+			manager: sch.stateManager,
+			rules:   &sch.schedulableAlertRules,
+			orgID:   e.rule.OrgID,
+		}
+		if e.rule.IsRecordingRule() {
+			// recording rules do not have a state, their series are written to the target of the rule
+			err := sch.evaluateRecordingRule(ctx, evalCtx, e.rule, e.scheduledAt)
+			dur := sch.clock.Now().Sub(start)
+			evalTotal.Inc()
+			evalDuration.Observe(dur.Seconds())
+			if err != nil {
+				evalTotalFailures.Inc()
+				logger.Error("Failed to evaluate recording rule", "error", err, "duration", dur)
+				return
+			}
+			logger.Debug("Recording rule evaluated", "duration", dur)
+			return
+		}
 		ruleEval, err := sch.evaluatorFactory.Create(evalCtx, e.rule.GetEvalCondition())
 		var results eval.Results
 		var dur time.Duration
@@ -370,6 +517,9 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 			logger.Debug("Skip updating the state because the context has been cancelled")
 			return
 		}
+		if sch.enricher != nil {
+			results = sch.enricher.Enrich(evalCtx, e.rule, results)
+		}
 		processedStates := sch.stateManager.ProcessEvalResults(ctx, e.scheduledAt, e.rule, results, sch.getRuleExtraLabels(e))
 		alerts := FromStateTransitionToPostableAlerts(processedStates, sch.stateManager, sch.appURL)
 		if len(alerts.PostableAlerts) > 0 {
@@ -400,11 +550,16 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 			// and there were two concurrent messages in updateCh and evalCh, and the eval's one got processed first.
 			// therefore, at the time when message from updateCh is processed the current rule will have
 			// at least the same version (or greater) and the state created for the new version of the rule.
-			if currentRuleVersion >= int64(lastVersion) {
-				logger.Info("Skip updating rule because its current version is actual", "version", currentRuleVersion, "newVersion", lastVersion)
+			if currentRuleVersion >= int64(lastVersion.Version) {
+				logger.Info("Skip updating rule because its current version is actual", "version", currentRuleVersion, "newVersion", lastVersion.Version)
+				continue
+			}
+			// the states of a paused rule are not cleared but paused by the scheduler in the next tick.
+			if lastVersion.IsPaused {
+				logger.Info("Keeping the state of the rule because it was paused", "version", currentRuleVersion, "newVersion", lastVersion.Version)
 				continue
 			}
-			logger.Info("Clearing the state of the rule because version has changed", "version", currentRuleVersion, "newVersion", lastVersion)
+			logger.Info("Clearing the state of the rule because version has changed", "version", currentRuleVersion, "newVersion", lastVersion.Version)
 			// clear the state. So the next evaluation will start from the scratch.
 			clearState()
 		// evalCh - used by the scheduler to signal that evaluation is needed.
@@ -414,6 +569,7 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				return nil
 			}
 			if evalRunning {
+				ctx.finish()
 				continue
 			}
 
@@ -421,9 +577,19 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				evalRunning = true
 				defer func() {
 					evalRunning = false
+					ctx.finish()
 					sch.evalApplied(key, ctx.scheduledAt)
 				}()
 
+				// the rules that this rule depends on are evaluated first, so that it reads their current state.
+				for _, dependency := range ctx.dependencies {
+					select {
+					case <-dependency:
+					case <-grafanaCtx.Done():
+						return
+					}
+				}
+
 				err := retryIfError(func(attempt int64) error {
 					newVersion := ctx.rule.Version
 					// fetch latest alert rule version
@@ -446,12 +612,47 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 			if errors.Is(grafanaCtx.Err(), errRuleDeleted) {
 				clearState()
 			}
+			// keep the state in the database for the instance that evaluates the rule now
+			if errors.Is(grafanaCtx.Err(), errRuleNotOwned) {
+				sch.stateManager.ForgetStateByRuleUID(key)
+			}
 			logger.Debug("Stopping alert rule routine")
 			return nil
 		}
 	}
 }
 
+// evaluateRecordingRule evaluates the queries and expressions of the recording rule and writes the series
+// of the recorded query or expression, with the labels of the rule, to the target of the rule.
+func (sch *schedule) evaluateRecordingRule(ctx context.Context, evalCtx eval.EvaluationContext, rule *ngmodels.AlertRule, now time.Time) error {
+	if sch.recordingWriter == nil {
+		return errors.New("recording rules are not supported")
+	}
+	ruleEval, err := sch.evaluatorFactory.Create(evalCtx, ngmodels.Condition{Condition: rule.Record.From, Data: rule.Data})
+	if err != nil {
+		return fmt.Errorf("failed to build rule evaluator: %w", err)
+	}
+	resp, err := ruleEval.EvaluateRaw(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate rule: %w", err)
+	}
+	res, ok := resp.Responses[rule.Record.From]
+	if !ok {
+		return fmt.Errorf("no result for %s", rule.Record.From)
+	}
+	if res.Error != nil {
+		return fmt.Errorf("failed to evaluate %s: %w", rule.Record.From, res.Error)
+	}
+
+	samples := writer.SamplesFromFrames(res.Frames)
+	for _, sample := range samples {
+		for k, v := range rule.Labels {
+			sample.Labels[k] = v
+		}
+	}
+	return sch.recordingWriter.Write(ctx, rule.OrgID, *rule.Record, now, samples)
+}
+
 // evalApplied is only used on tests.
 func (sch *schedule) evalApplied(alertDefKey ngmodels.AlertRuleKey, now time.Time) {
 	if sch.evalAppliedFunc == nil {
@@ -470,6 +671,38 @@ func (sch *schedule) stopApplied(alertDefKey ngmodels.AlertRuleKey) {
 	sch.stopAppliedFunc(alertDefKey)
 }
 
+// alertingResultsFromRuleState reads the labels of the alert instances of a rule that are alerting or pending,
+// which means that their condition was met at the previous evaluation.
+type alertingResultsFromRuleState struct {
+	manager *state.Manager
+	rule    *ngmodels.AlertRule
+}
+
+func (a alertingResultsFromRuleState) Read() []data.Labels {
+	var result []data.Labels
+	for _, s := range a.manager.GetStatesForRuleUID(a.rule.OrgID, a.rule.UID) {
+		if s.State == eval.Alerting || s.State == eval.Pending {
+			result = append(result, s.Labels.Copy())
+		}
+	}
+	return result
+}
+
+// ruleStateFromScheduledRules reads the current state of the alert instances of the scheduled rules.
+type ruleStateFromScheduledRules struct {
+	manager *state.Manager
+	rules   *alertRulesRegistry
+	orgID   int64
+}
+
+func (r ruleStateFromScheduledRules) ReadRuleState(ruleUID string) ([]expr.RuleInstanceState, error) {
+	rule := r.rules.get(ngmodels.AlertRuleKey{OrgID: r.orgID, UID: ruleUID})
+	if rule == nil {
+		return nil, fmt.Errorf("rule %s is not found", ruleUID)
+	}
+	return r.manager.GetRuleInstanceStates(rule), nil
+}
+
 func (sch *schedule) getRuleExtraLabels(evalCtx *evaluation) map[string]string {
 	extraLabels := make(map[string]string, 4)
 
@@ -35,9 +35,9 @@ func (_m *FakeScheduleService) Run(_a0 context.Context) error {
 	return r0
 }
 
-// UpdateAlertRule provides a mock function with given fields: key, lastVersion
-func (_m *FakeScheduleService) UpdateAlertRule(key models.AlertRuleKey, lastVersion int64) {
-	_m.Called(key, lastVersion)
+// UpdateAlertRule provides a mock function with given fields: key, lastVersion, isPaused
+func (_m *FakeScheduleService) UpdateAlertRule(key models.AlertRuleKey, lastVersion int64, isPaused bool) {
+	_m.Called(key, lastVersion, isPaused)
 }
 
 // evalApplied provides a mock function with given fields: _a0, _a1
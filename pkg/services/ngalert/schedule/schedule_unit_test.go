@@ -27,6 +27,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -173,9 +174,10 @@ func TestProcessTicks(t *testing.T) {
 		assertEvalRun(t, evalAppliedCh, tick, alertRule2.GetKey())
 	})
 
+	// create alert rule with one base interval
+	alertRule3 := models.AlertRuleGen(models.WithOrgID(mainOrgID), models.WithInterval(cfg.BaseInterval), models.WithTitle("rule-3"))()
+
 	t.Run("on 7th tick a new alert rule should be evaluated", func(t *testing.T) {
-		// create alert rule with one base interval
-		alertRule3 := models.AlertRuleGen(models.WithOrgID(mainOrgID), models.WithInterval(cfg.BaseInterval), models.WithTitle("rule-3"))()
 		ruleStore.PutRule(ctx, alertRule3)
 		tick = tick.Add(cfg.BaseInterval)
 
@@ -188,6 +190,38 @@ func TestProcessTicks(t *testing.T) {
 
 		assertEvalRun(t, evalAppliedCh, tick, alertRule3.GetKey())
 	})
+
+	t.Run("on 8th tick paused alert rules should not be evaluated", func(t *testing.T) {
+		// delete the second rule, so that only the paused rule is due in the next ticks
+		ruleStore.DeleteRule(alertRule2)
+		paused := models.CopyRule(alertRule3)
+		paused.Version++
+		paused.IsPaused = true
+		ruleStore.PutRule(ctx, paused)
+		tick = tick.Add(cfg.BaseInterval)
+
+		scheduled, stopped := sched.processTick(ctx, dispatcherGroup, tick)
+
+		require.Empty(t, scheduled)
+		require.Len(t, stopped, 1)
+		require.Contains(t, stopped, alertRule2.GetKey())
+		assertStopRun(t, stopAppliedCh, alertRule2.GetKey())
+		assertEvalRun(t, evalAppliedCh, tick)
+	})
+
+	t.Run("on 9th tick resumed alert rules should be evaluated", func(t *testing.T) {
+		resumed := models.CopyRule(alertRule3)
+		resumed.Version += 2
+		ruleStore.PutRule(ctx, resumed)
+		tick = tick.Add(cfg.BaseInterval)
+
+		scheduled, stopped := sched.processTick(ctx, dispatcherGroup, tick)
+
+		require.Len(t, scheduled, 1)
+		require.Equal(t, resumed, scheduled[0].rule)
+		require.Emptyf(t, stopped, "None rules are expected to be stopped")
+		assertEvalRun(t, evalAppliedCh, tick, alertRule3.GetKey())
+	})
 }
 
 func TestSchedule_ruleRoutine(t *testing.T) {
@@ -223,7 +257,7 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 			go func() {
 				ctx, cancel := context.WithCancel(context.Background())
 				t.Cleanup(cancel)
-				_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersion))
+				_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersionAndPauseStatus))
 			}()
 
 			expectedTime := time.UnixMicro(rand.Int63())
@@ -335,7 +369,7 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 
 			ctx, cancel := context.WithCancel(context.Background())
 			go func() {
-				err := sch.ruleRoutine(ctx, models.AlertRuleKey{}, make(chan *evaluation), make(chan ruleVersion))
+				err := sch.ruleRoutine(ctx, models.AlertRuleKey{}, make(chan *evaluation), make(chan ruleVersionAndPauseStatus))
 				stoppedChan <- err
 			}()
 
@@ -354,7 +388,7 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 
 			ctx, cancel := util.WithCancelCause(context.Background())
 			go func() {
-				err := sch.ruleRoutine(ctx, rule.GetKey(), make(chan *evaluation), make(chan ruleVersion))
+				err := sch.ruleRoutine(ctx, rule.GetKey(), make(chan *evaluation), make(chan ruleVersionAndPauseStatus))
 				stoppedChan <- err
 			}()
 
@@ -371,7 +405,7 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 
 		evalChan := make(chan *evaluation)
 		evalAppliedChan := make(chan time.Time)
-		updateChan := make(chan ruleVersion)
+		updateChan := make(chan ruleVersionAndPauseStatus)
 
 		sender := AlertsSenderMock{}
 		sender.EXPECT().Send(rule.GetKey(), mock.Anything).Return()
@@ -421,9 +455,9 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 		require.Greaterf(t, expectedToBeSent, 0, "State manger was expected to return at least one state that can be expired")
 
 		t.Run("should do nothing if version in channel is the same", func(t *testing.T) {
-			updateChan <- ruleVersion(rule.Version - 1)
-			updateChan <- ruleVersion(rule.Version)
-			updateChan <- ruleVersion(rule.Version) // second time just to make sure that previous messages were handled
+			updateChan <- ruleVersionAndPauseStatus{ruleVersion(rule.Version - 1), false}
+			updateChan <- ruleVersionAndPauseStatus{ruleVersion(rule.Version), false}
+			updateChan <- ruleVersionAndPauseStatus{ruleVersion(rule.Version), false} // second time just to make sure that previous messages were handled
 
 			actualStates := sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)
 			require.Len(t, actualStates, len(states))
@@ -432,7 +466,7 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 		})
 
 		t.Run("should clear the state and expire firing alerts if version in channel is greater", func(t *testing.T) {
-			updateChan <- ruleVersion(rule.Version + rand.Int63n(1000) + 1)
+			updateChan <- ruleVersionAndPauseStatus{ruleVersion(rule.Version + rand.Int63n(1000) + 1), false}
 
 			require.Eventually(t, func() bool {
 				return len(sender.Calls) > 0
@@ -462,7 +496,7 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 		go func() {
 			ctx, cancel := context.WithCancel(context.Background())
 			t.Cleanup(cancel)
-			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersion))
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersionAndPauseStatus))
 		}()
 
 		evalChan <- &evaluation{
@@ -532,7 +566,7 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 			go func() {
 				ctx, cancel := context.WithCancel(context.Background())
 				t.Cleanup(cancel)
-				_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersion))
+				_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersionAndPauseStatus))
 			}()
 
 			evalChan <- &evaluation{
@@ -565,7 +599,7 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 		go func() {
 			ctx, cancel := context.WithCancel(context.Background())
 			t.Cleanup(cancel)
-			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersion))
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersionAndPauseStatus))
 		}()
 
 		evalChan <- &evaluation{
@@ -579,6 +613,451 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 
 		require.NotEmpty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
 	})
+
+	t.Run("when the rule is a recording rule", func(t *testing.T) {
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting), models.WithRecord("job:up:sum", models.LiveRecordTarget))()
+		rule.Labels = map[string]string{"team": "a"}
+
+		evalChan := make(chan *evaluation)
+		evalAppliedChan := make(chan time.Time)
+
+		sender := AlertsSenderMock{}
+		sch, ruleStore, _, _ := createSchedule(evalAppliedChan, &sender)
+		ruleStore.PutRule(context.Background(), rule)
+		recorder := &fakeRecordingWriter{}
+		sch.recordingWriter = recorder
+
+		go func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersionAndPauseStatus))
+		}()
+
+		expectedTime := time.UnixMicro(rand.Int63())
+		evalChan <- &evaluation{
+			scheduledAt: expectedTime,
+			rule:        rule,
+		}
+
+		waitForTimeChannel(t, evalAppliedChan)
+
+		t.Run("it should write the series of the record", func(t *testing.T) {
+			require.Len(t, recorder.writes, 1)
+			w := recorder.writes[0]
+			require.Equal(t, rule.OrgID, w.orgID)
+			require.Equal(t, *rule.Record, w.record)
+			require.Equal(t, expectedTime, w.t)
+			require.Equal(t, []writer.Sample{{Labels: data.Labels{"team": "a"}, Value: 1}}, w.samples)
+		})
+
+		t.Run("it should not create state or send alerts", func(t *testing.T) {
+			require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+			sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+		})
+	})
+
+	t.Run("when the rule has enrichments", func(t *testing.T) {
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting), models.WithFor(0), models.WithEnrichments(models.Enrichment{
+			Type:        models.TableEnrichmentType,
+			Keys:        []string{models.RuleUIDLabel},
+			Annotations: map[string]string{"runbook_url": "runbook"},
+		}))()
+
+		evalChan := make(chan *evaluation)
+		evalAppliedChan := make(chan time.Time)
+
+		sch, ruleStore, _, _ := createSchedule(evalAppliedChan, nil)
+		ruleStore.PutRule(context.Background(), rule)
+		enricher := &fakeEnricher{labels: data.Labels{"owner": "backend"}, annotations: data.Labels{"runbook_url": "https://runbooks/api"}}
+		sch.enricher = enricher
+
+		go func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersionAndPauseStatus))
+		}()
+
+		evalChan <- &evaluation{
+			scheduledAt: time.UnixMicro(rand.Int63()),
+			rule:        rule,
+		}
+
+		waitForTimeChannel(t, evalAppliedChan)
+
+		require.Equal(t, []string{rule.UID}, enricher.rules)
+		states := sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		require.Len(t, states, 1)
+		require.Equal(t, "backend", states[0].Labels["owner"])
+		require.Equal(t, "https://runbooks/api", states[0].Annotations["runbook_url"])
+	})
+
+	t.Run("when the rule depends on another rule", func(t *testing.T) {
+		network := models.AlertRuleGen(withQueryForState(t, eval.Alerting), models.WithFor(0))()
+		database := models.AlertRuleGen(models.WithOrgID(network.OrgID), models.WithFor(0), func(rule *models.AlertRule) {
+			rule.NamespaceUID = network.NamespaceUID
+			rule.RuleGroup = network.RuleGroup
+			rule.Condition = "A"
+			rule.Data = []models.AlertQuery{models.CreateRuleStateExpression("A", network.UID)}
+		})()
+
+		networkEvalChan := make(chan *evaluation)
+		databaseEvalChan := make(chan *evaluation)
+		evalAppliedChan := make(chan time.Time)
+
+		sch, ruleStore, _, _ := createSchedule(evalAppliedChan, nil)
+		ruleStore.PutRule(context.Background(), network, database)
+		sch.schedulableAlertRules.set([]*models.AlertRule{network, database}, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		go func() {
+			_ = sch.ruleRoutine(ctx, network.GetKey(), networkEvalChan, make(chan ruleVersionAndPauseStatus))
+		}()
+		go func() {
+			_ = sch.ruleRoutine(ctx, database.GetKey(), databaseEvalChan, make(chan ruleVersionAndPauseStatus))
+		}()
+
+		networkDone := make(chan struct{})
+		databaseTime := sch.clock.Now().Add(time.Second)
+		databaseEvalChan <- &evaluation{
+			scheduledAt:  databaseTime,
+			rule:         database,
+			dependencies: []<-chan struct{}{networkDone},
+		}
+
+		t.Run("it should wait for the evaluation of the dependency", func(t *testing.T) {
+			select {
+			case <-evalAppliedChan:
+				t.Fatal("the rule should not be evaluated before its dependency")
+			case <-time.After(100 * time.Millisecond):
+			}
+		})
+
+		networkEvalChan <- &evaluation{
+			scheduledAt: sch.clock.Now(),
+			rule:        network,
+			done:        networkDone,
+		}
+		waitForTimeChannel(t, evalAppliedChan)
+		require.Equal(t, databaseTime, waitForTimeChannel(t, evalAppliedChan))
+
+		t.Run("it should read the current state of the dependency", func(t *testing.T) {
+			states := sch.stateManager.GetStatesForRuleUID(database.OrgID, database.UID)
+			require.Len(t, states, 1)
+			require.Equal(t, eval.Alerting, states[0].State)
+		})
+	})
+
+	t.Run("when the rule is paused", func(t *testing.T) {
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting))()
+
+		evalChan := make(chan *evaluation)
+		evalAppliedChan := make(chan time.Time)
+		updateChan := make(chan ruleVersionAndPauseStatus)
+
+		sender := AlertsSenderMock{}
+		sender.EXPECT().Send(rule.GetKey(), mock.Anything).Return()
+
+		sch, ruleStore, _, _ := createSchedule(evalAppliedChan, &sender)
+		ruleStore.PutRule(context.Background(), rule)
+
+		go func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, updateChan)
+		}()
+
+		evalChan <- &evaluation{
+			scheduledAt: sch.clock.Now(),
+			rule:        rule,
+		}
+		waitForTimeChannel(t, evalAppliedChan)
+		require.NotEmpty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+		sender.AssertNumberOfCalls(t, "Send", 1)
+
+		paused := models.CopyRule(rule)
+		paused.Version++
+		paused.IsPaused = true
+
+		t.Run("it should keep the state if the update says the rule is paused", func(t *testing.T) {
+			updateChan <- ruleVersionAndPauseStatus{ruleVersion(paused.Version), true}
+			updateChan <- ruleVersionAndPauseStatus{ruleVersion(paused.Version), true} // second time just to make sure that previous messages were handled
+
+			require.NotEmpty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+			sender.AssertNumberOfCalls(t, "Send", 1)
+		})
+
+		t.Run("it should not evaluate the rule but pause its states and resolve its alerts", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			dispatcherGroup, ctx := errgroup.WithContext(ctx)
+			ruleStore.PutRule(ctx, paused)
+
+			scheduled, _ := sch.processTick(ctx, dispatcherGroup, sch.clock.Now())
+			require.Empty(t, scheduled)
+
+			states := sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)
+			require.Len(t, states, 1)
+			require.Equal(t, eval.Normal, states[0].State)
+			require.Equal(t, models.StateReasonPaused, states[0].StateReason)
+
+			sender.AssertNumberOfCalls(t, "Send", 2)
+			args, ok := sender.Calls[1].Arguments[1].(definitions.PostableAlerts)
+			require.Truef(t, ok, fmt.Sprintf("expected argument of function was supposed to be 'definitions.PostableAlerts' but got %T", sender.Calls[1].Arguments[1]))
+			require.Len(t, args.PostableAlerts, 1)
+			require.Equal(t, models.StateReasonPaused, args.PostableAlerts[0].Annotations[models.StateReasonAnnotation])
+		})
+
+		t.Run("it should not resolve the alerts again in the next ticks", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+			scheduled, stopped := sch.processTick(ctx, dispatcherGroup, sch.clock.Now().Add(time.Second))
+			require.Empty(t, scheduled)
+			require.Empty(t, stopped)
+			sender.AssertNumberOfCalls(t, "Send", 2)
+		})
+	})
+}
+
+func TestAlertingResultsFromRuleState(t *testing.T) {
+	sch := setupScheduler(t, nil, nil, nil, nil, nil)
+	rule := models.AlertRuleGen()()
+	other := models.AlertRuleGen(models.WithOrgID(rule.OrgID))()
+
+	var states []*state.State
+	for _, s := range []eval.State{eval.Normal, eval.Alerting, eval.Pending, eval.NoData, eval.Error} {
+		states = append(states, &state.State{
+			AlertRuleUID: rule.UID,
+			OrgID:        rule.OrgID,
+			CacheID:      s.String(),
+			State:        s,
+			Labels:       data.Labels{"state": s.String()},
+		})
+	}
+	states = append(states, &state.State{
+		AlertRuleUID: other.UID,
+		OrgID:        other.OrgID,
+		CacheID:      "other",
+		State:        eval.Alerting,
+		Labels:       data.Labels{"rule": "other"},
+	})
+	sch.stateManager.Put(states)
+
+	reader := alertingResultsFromRuleState{manager: sch.stateManager, rule: rule}
+	result := reader.Read()
+	require.ElementsMatch(t, []data.Labels{{"state": "Alerting"}, {"state": "Pending"}}, result)
+
+	// the labels are copies, so that the expressions can not change the state
+	result[0]["state"] = "changed"
+	require.ElementsMatch(t, []data.Labels{{"state": "Alerting"}, {"state": "Pending"}}, reader.Read())
+
+	require.Empty(t, alertingResultsFromRuleState{manager: sch.stateManager, rule: models.AlertRuleGen()()}.Read())
+}
+
+func TestSchedule_linkDependencies(t *testing.T) {
+	sch := setupScheduler(t, nil, nil, nil, nil, nil)
+	group := models.AlertRuleGen()()
+	gen := func(uid string, dependencies ...string) readyToRunItem {
+		rule := models.AlertRuleGen(func(rule *models.AlertRule) {
+			rule.UID = uid
+			rule.OrgID = group.OrgID
+			rule.NamespaceUID = group.NamespaceUID
+			rule.RuleGroup = group.RuleGroup
+		}, models.WithDependencies(dependencies...))()
+		return readyToRunItem{evaluation: evaluation{rule: rule}}
+	}
+
+	t.Run("rules wait for the rules they depend on", func(t *testing.T) {
+		other := gen("other")
+		other.rule.RuleGroup = "other"
+		items := []readyToRunItem{gen("database", "network", "disk"), gen("network", "disk"), gen("disk"), gen("cpu"), other}
+
+		sch.linkDependencies(items)
+
+		database, network, disk, cpu := items[0], items[1], items[2], items[3]
+		require.NotNil(t, network.done)
+		require.NotNil(t, disk.done)
+		require.Nil(t, database.done)
+		require.Nil(t, cpu.done)
+		require.Nil(t, items[4].done)
+		require.Equal(t, []<-chan struct{}{network.done, disk.done}, database.dependencies)
+		require.Equal(t, []<-chan struct{}{disk.done}, network.dependencies)
+		require.Empty(t, disk.dependencies)
+		require.Empty(t, cpu.dependencies)
+	})
+
+	t.Run("rules do not wait for the rules that are not evaluated in the tick", func(t *testing.T) {
+		// the disk rule is paused or not due in the tick
+		items := []readyToRunItem{gen("database", "network", "disk"), gen("network", "disk")}
+
+		sch.linkDependencies(items)
+
+		database, network := items[0], items[1]
+		require.NotNil(t, network.done)
+		require.Equal(t, []<-chan struct{}{network.done}, database.dependencies)
+		require.Empty(t, network.dependencies)
+	})
+
+	t.Run("rules are evaluated independently if they depend on each other in a cycle", func(t *testing.T) {
+		items := []readyToRunItem{gen("database", "network"), gen("network", "database")}
+
+		sch.linkDependencies(items)
+
+		for _, item := range items {
+			require.Nil(t, item.done)
+			require.Empty(t, item.dependencies)
+		}
+	})
+}
+
+type recordingWrite struct {
+	orgID   int64
+	record  models.Record
+	t       time.Time
+	samples []writer.Sample
+}
+
+type fakeRecordingWriter struct {
+	writes []recordingWrite
+}
+
+func (f *fakeRecordingWriter) Write(_ context.Context, orgID int64, record models.Record, t time.Time, samples []writer.Sample) error {
+	f.writes = append(f.writes, recordingWrite{orgID: orgID, record: record, t: t, samples: samples})
+	return nil
+}
+
+type fakeEnricher struct {
+	labels      data.Labels
+	annotations data.Labels
+	rules       []string
+}
+
+func (f *fakeEnricher) Enrich(_ eval.EvaluationContext, rule *models.AlertRule, results eval.Results) eval.Results {
+	f.rules = append(f.rules, rule.UID)
+	enriched := make(eval.Results, 0, len(results))
+	for _, r := range results {
+		r.Instance = r.Instance.Copy()
+		for k, v := range f.labels {
+			r.Instance[k] = v
+		}
+		r.Annotations = f.annotations
+		enriched = append(enriched, r)
+	}
+	return enriched
+}
+
+func TestSchedule_sharding(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	ruleStore := newFakeRulesStore()
+	groups := make([]models.AlertRuleGroupKey, 0, 10)
+	for i := 0; i < 10; i++ {
+		groups = append(groups, models.GenerateGroupKey(1))
+	}
+	var rules []*models.AlertRule
+	for i := 0; i < 30; i++ {
+		group := groups[i%len(groups)]
+		rule := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(time.Second), withQueryForState(t, eval.Normal), func(rule *models.AlertRule) {
+			rule.NamespaceUID = group.NamespaceUID
+			rule.RuleGroup = group.RuleGroup
+		})()
+		rules = append(rules, rule)
+		ruleStore.PutRule(ctx, rule)
+	}
+
+	// instances of the cluster run in the same process and share the rule store
+	memberships := map[string]*fakeClusterMembership{}
+	schedulers := map[string]*schedule{}
+	instanceStores := map[string]*state.FakeInstanceStore{}
+	for _, member := range []string{"a", "b", "c"} {
+		memberships[member] = &fakeClusterMembership{self: member, members: []string{"a", "b", "c"}}
+		instanceStores[member] = &state.FakeInstanceStore{}
+		sch := setupScheduler(t, ruleStore, instanceStores[member], nil, nil, nil)
+		sch.sharding = newRuleSharding(memberships[member])
+		schedulers[member] = sch
+	}
+
+	tick := time.Time{}
+	processTick := func(members ...string) map[models.AlertRuleKey]string {
+		tick = tick.Add(time.Second)
+		owners := map[models.AlertRuleKey]string{}
+		for _, member := range members {
+			memberships[member].members = members
+			scheduled, stopped := schedulers[member].processTick(ctx, dispatcherGroup, tick)
+			require.Emptyf(t, stopped, "rules of other members should not be reported as deleted")
+			for _, item := range scheduled {
+				key := item.rule.GetKey()
+				other, ok := owners[key]
+				require.Falsef(t, ok, "rule %s is evaluated by %s and %s", key.UID, member, other)
+				owners[key] = member
+			}
+		}
+		require.Len(t, owners, len(rules), "every rule should be evaluated by one member")
+		return owners
+	}
+
+	before := processTick("a", "b", "c")
+
+	t.Run("rules of the same group are evaluated by the same member", func(t *testing.T) {
+		byGroup := map[models.AlertRuleGroupKey]string{}
+		for _, rule := range rules {
+			if member, ok := byGroup[rule.GetGroupKey()]; ok {
+				require.Equal(t, member, before[rule.GetKey()])
+			}
+			byGroup[rule.GetGroupKey()] = before[rule.GetKey()]
+		}
+	})
+
+	t.Run("states of rules evaluated by other members are read from the database", func(t *testing.T) {
+		for key, member := range before {
+			for other, sch := range schedulers {
+				countQueries := func() int {
+					n := 0
+					for _, q := range instanceStores[other].ListQueries() {
+						if q.RuleUID == key.UID {
+							n++
+						}
+					}
+					return n
+				}
+				previous := countQueries()
+				sch.stateManager.GetStatesForRuleUID(key.OrgID, key.UID)
+				queries := countQueries() - previous
+				if other == member {
+					require.Zerof(t, queries, "member %s should read the states of rule %s from the cache", other, key.UID)
+				} else {
+					require.Equalf(t, 1, queries, "member %s should read the states of rule %s evaluated by %s from the database", other, key.UID, member)
+				}
+			}
+		}
+	})
+
+	t.Run("rules are reassigned when a member leaves", func(t *testing.T) {
+		after := processTick("a", "b")
+		for key, member := range before {
+			if member != "c" {
+				require.Equal(t, member, after[key])
+			}
+		}
+	})
+
+	t.Run("evaluation of reassigned rules is stopped", func(t *testing.T) {
+		processTick("a", "b")
+		after := processTick("a", "b", "c")
+		for key, member := range after {
+			for other, sch := range schedulers {
+				if other == "c" || other == member {
+					continue
+				}
+				_, err := sch.registry.get(key)
+				require.Errorf(t, err, "member %s should not run the routine of rule %s evaluated by %s", other, key.UID, member)
+			}
+		}
+	})
 }
 
 func TestSchedule_UpdateAlertRule(t *testing.T) {
@@ -589,12 +1068,12 @@ func TestSchedule_UpdateAlertRule(t *testing.T) {
 			info, _ := sch.registry.getOrCreateInfo(context.Background(), key)
 			version := rand.Int63()
 			go func() {
-				sch.UpdateAlertRule(key, version)
+				sch.UpdateAlertRule(key, version, false)
 			}()
 
 			select {
 			case v := <-info.updateCh:
-				require.Equal(t, ruleVersion(version), v)
+				require.Equal(t, ruleVersionAndPauseStatus{ruleVersion(version), false}, v)
 			case <-time.After(5 * time.Second):
 				t.Fatal("No message was received on update channel")
 			}
@@ -604,14 +1083,14 @@ func TestSchedule_UpdateAlertRule(t *testing.T) {
 			key := models.GenerateRuleKey(rand.Int63())
 			info, _ := sch.registry.getOrCreateInfo(context.Background(), key)
 			info.stop(nil)
-			sch.UpdateAlertRule(key, rand.Int63())
+			sch.UpdateAlertRule(key, rand.Int63(), false)
 		})
 	})
 	t.Run("when rule does not exist", func(t *testing.T) {
 		t.Run("should exit", func(t *testing.T) {
 			sch := setupScheduler(t, nil, nil, nil, nil, nil)
 			key := models.GenerateRuleKey(rand.Int63())
-			sch.UpdateAlertRule(key, rand.Int63())
+			sch.UpdateAlertRule(key, rand.Int63(), false)
 		})
 	})
 }
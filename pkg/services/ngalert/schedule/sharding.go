@@ -0,0 +1,117 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// errRuleNotOwned is the reason to stop the evaluation of a rule that is assigned to another instance of the cluster.
+var errRuleNotOwned = errors.New("rule is evaluated by another instance")
+
+// ringTokensPerMember is the number of points of each member on the hash ring. More points spread
+// the rule groups more evenly between the members.
+const ringTokensPerMember = 128
+
+// ClusterMembership provides the members of the cluster of Grafana instances that share the evaluation of alert rules.
+type ClusterMembership interface {
+	// Self returns the name of this instance.
+	Self() string
+	// Members returns the names of all instances of the cluster, including this instance.
+	Members() []string
+}
+
+type ringToken struct {
+	hash   uint64
+	member string
+}
+
+// ruleSharding assigns each rule group to one member of the cluster with consistent hashing, so that
+// only the rule groups of the members that join or leave the cluster are reassigned.
+// All rules of a group are assigned to the same member because they can depend on each other.
+type ruleSharding struct {
+	membership ClusterMembership
+	self       string
+	members    []string
+	tokens     []ringToken
+	// rebalances is the number of times the members of the cluster changed after the first update.
+	rebalances int
+}
+
+func newRuleSharding(membership ClusterMembership) *ruleSharding {
+	return &ruleSharding{membership: membership}
+}
+
+// update rebuilds the ring if the members of the cluster changed since the last update. It returns true if they changed.
+func (s *ruleSharding) update() bool {
+	self := s.membership.Self()
+	members := append([]string(nil), s.membership.Members()...)
+	sort.Strings(members)
+	if s.tokens != nil && self == s.self && equalMembers(members, s.members) {
+		return false
+	}
+	if s.tokens != nil {
+		s.rebalances++
+	}
+
+	tokens := make([]ringToken, 0, len(members)*ringTokensPerMember)
+	for _, member := range members {
+		for i := 0; i < ringTokensPerMember; i++ {
+			tokens = append(tokens, ringToken{hash: hashString(fmt.Sprintf("%s-%d", member, i)), member: member})
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].hash == tokens[j].hash {
+			return tokens[i].member < tokens[j].member
+		}
+		return tokens[i].hash < tokens[j].hash
+	})
+	s.self = self
+	s.members = members
+	s.tokens = tokens
+	return true
+}
+
+// owner returns the member of the cluster that evaluates the rule group.
+func (s *ruleSharding) owner(key ngmodels.AlertRuleGroupKey) string {
+	if len(s.tokens) == 0 {
+		return s.self
+	}
+	h := hashString(fmt.Sprintf("%d/%s/%s", key.OrgID, key.NamespaceUID, key.RuleGroup))
+	i := sort.Search(len(s.tokens), func(i int) bool {
+		return s.tokens[i].hash >= h
+	})
+	if i == len(s.tokens) {
+		i = 0
+	}
+	return s.tokens[i].member
+}
+
+// owns returns true if the rule group is evaluated by this instance.
+// An instance that does not know the members of the cluster evaluates all rule groups.
+func (s *ruleSharding) owns(key ngmodels.AlertRuleGroupKey) bool {
+	return s.owner(key) == s.self
+}
+
+func equalMembers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	// We can ignore err as fnv64 does not return an error
+	// nolint:errcheck,gosec
+	h.Write([]byte(s))
+	return h.Sum64()
+}
@@ -0,0 +1,87 @@
+package schedule
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestRuleSharding(t *testing.T) {
+	groups := make([]models.AlertRuleGroupKey, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		groups = append(groups, models.AlertRuleGroupKey{OrgID: int64(i%3 + 1), NamespaceUID: fmt.Sprintf("folder-%d", i%10), RuleGroup: fmt.Sprintf("group-%d", i)})
+	}
+
+	owners := func(members ...string) map[models.AlertRuleGroupKey]string {
+		result := make(map[models.AlertRuleGroupKey]string, len(groups))
+		for _, member := range members {
+			s := newRuleSharding(&fakeClusterMembership{self: member, members: members})
+			require.True(t, s.update())
+			for _, key := range groups {
+				if s.owns(key) {
+					_, ok := result[key]
+					require.Falsef(t, ok, "rule group %v is owned by more than one member", key)
+					result[key] = member
+				}
+			}
+		}
+		require.Len(t, result, len(groups), "every rule group should be owned by a member")
+		return result
+	}
+
+	before := owners("a", "b", "c")
+
+	t.Run("rule groups are spread between members", func(t *testing.T) {
+		count := map[string]int{}
+		for _, member := range before {
+			count[member]++
+		}
+		for _, member := range []string{"a", "b", "c"} {
+			require.Greaterf(t, count[member], len(groups)/5, "member %s owns too few rule groups", member)
+			require.Lessf(t, count[member], len(groups)/2, "member %s owns too many rule groups", member)
+		}
+	})
+
+	t.Run("only rule groups of the member that left are reassigned", func(t *testing.T) {
+		after := owners("a", "b")
+		for key, member := range before {
+			if member != "c" {
+				require.Equal(t, member, after[key])
+			}
+		}
+	})
+
+	t.Run("only rule groups of the member that joined are reassigned", func(t *testing.T) {
+		after := owners("a", "b", "c", "d")
+		for key, member := range after {
+			if member != "d" {
+				require.Equal(t, before[key], member)
+			}
+		}
+	})
+
+	t.Run("member that does not know the cluster owns all rule groups", func(t *testing.T) {
+		s := newRuleSharding(&fakeClusterMembership{self: "a"})
+		s.update()
+		for _, key := range groups {
+			require.True(t, s.owns(key))
+		}
+	})
+
+	t.Run("update detects changes of members", func(t *testing.T) {
+		membership := &fakeClusterMembership{self: "a", members: []string{"b", "a"}}
+		s := newRuleSharding(membership)
+		require.True(t, s.update())
+		require.Equal(t, 0, s.rebalances)
+
+		membership.members = []string{"a", "b"}
+		require.False(t, s.update())
+
+		membership.members = []string{"a", "b", "c"}
+		require.True(t, s.update())
+		require.Equal(t, 1, s.rebalances)
+	})
+}
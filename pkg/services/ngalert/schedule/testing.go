@@ -80,3 +80,16 @@ func (f *fakeRulesStore) DeleteRule(rules ...*models.AlertRule) {
 func (f *fakeRulesStore) getNamespaceTitle(uid string) string {
 	return "TEST-FOLDER-" + uid
 }
+
+type fakeClusterMembership struct {
+	self    string
+	members []string
+}
+
+func (f *fakeClusterMembership) Self() string {
+	return f.self
+}
+
+func (f *fakeClusterMembership) Members() []string {
+	return f.members
+}
@@ -50,6 +50,10 @@ func (c *cache) getOrCreate(ctx context.Context, log log.Logger, alertRule *ngMo
 
 func (rs *ruleStates) getOrCreate(ctx context.Context, log log.Logger, alertRule *ngModels.AlertRule, result eval.Result, extraLabels data.Labels, externalURL *url.URL) *State {
 	ruleLabels, annotations := rs.expandRuleLabelsAndAnnotations(ctx, log, alertRule, result, extraLabels, externalURL)
+	// annotations added to the result after the evaluation, such as those of enrichments, take precedence
+	for key, val := range result.Annotations {
+		annotations[key] = val
+	}
 
 	values := make(map[string]float64)
 	for _, v := range result.Values {
@@ -221,6 +225,16 @@ func (c *cache) getAll(orgID int64) []*State {
 	return states
 }
 
+func (c *cache) getOrgIDs() []int64 {
+	c.mtxStates.RLock()
+	defer c.mtxStates.RUnlock()
+	orgIDs := make([]int64, 0, len(c.states))
+	for orgID := range c.states {
+		orgIDs = append(orgIDs, orgID)
+	}
+	return orgIDs
+}
+
 func (c *cache) getStatesForRuleUID(orgID int64, alertRuleUID string) []*State {
 	var result []*State
 	c.mtxStates.RLock()
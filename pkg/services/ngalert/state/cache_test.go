@@ -134,6 +134,18 @@ func Test_getOrCreate(t *testing.T) {
 			assert.Equal(t, expected, state.Annotations["rule-"+key])
 		}
 	})
+	t.Run("result annotations should take precedence over rule annotations", func(t *testing.T) {
+		result := eval.Result{
+			Instance:    models.GenerateAlertLabels(5, "result-"),
+			Annotations: data.Labels{"runbook_url": "https://runbooks/api", "owner": "backend"},
+		}
+
+		rule := generateRule()
+		rule.Annotations = map[string]string{"runbook_url": "https://runbooks/default", "summary": "test"}
+
+		state := c.getOrCreate(context.Background(), l, rule, result, nil, url)
+		assert.Equal(t, map[string]string{"runbook_url": "https://runbooks/api", "owner": "backend", "summary": "test"}, state.Annotations)
+	})
 }
 
 func Test_mergeLabels(t *testing.T) {
@@ -0,0 +1,158 @@
+package historian
+
+import (
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// defaultQueryLimit is the maximum number of evaluations returned by a query that does not have a limit.
+const defaultQueryLimit = 1000
+
+// maxQueryPages is the maximum number of pages of evaluations that are read by a query, when the evaluations are
+// filtered by labels after they are read. Each page has up to the limit of the query.
+const maxQueryPages = 10
+
+// evaluation is the result of an evaluation of an alert instance, as recorded by the history backends.
+type evaluation struct {
+	OrgID         int64
+	RuleUID       string
+	Labels        data.Labels
+	State         string
+	PreviousState string
+	EvaluatedAt   time.Time
+	Duration      time.Duration
+	Values        map[string]float64
+	Error         string
+}
+
+// buildEvaluations copies the state transitions of an evaluation of a rule, to make sure that the data
+// won't mutate underneath the backend when it is written asynchronously.
+func buildEvaluations(rule *ngmodels.AlertRule, states []state.StateTransition) []evaluation {
+	evals := make([]evaluation, 0, len(states))
+	for _, s := range states {
+		e := evaluation{
+			OrgID:         rule.OrgID,
+			RuleUID:       rule.UID,
+			Labels:        removePrivateLabels(s.Labels),
+			State:         s.Formatted(),
+			PreviousState: s.PreviousFormatted(),
+			EvaluatedAt:   s.LastEvaluationTime,
+			Duration:      s.EvaluationDuration,
+			Values:        make(map[string]float64, len(s.Values)),
+		}
+		for k, v := range s.Values {
+			e.Values[k] = v
+		}
+		if s.Error != nil {
+			e.Error = s.Error.Error()
+		}
+		evals = append(evals, e)
+	}
+	return evals
+}
+
+// matchesLabels returns true if the labels have all the labels of the query.
+func matchesLabels(labels data.Labels, query map[string]string) bool {
+	for k, v := range query {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// limitEvaluations returns the latest evaluations, up to the limit of the query or defaultQueryLimit.
+// The evaluations must be sorted by time.
+func limitEvaluations(evals []evaluation, limit int) []evaluation {
+	if limit <= 0 {
+		limit = defaultQueryLimit
+	}
+	if len(evals) > limit {
+		return evals[len(evals)-limit:]
+	}
+	return evals
+}
+
+// buildFrames returns a frame for each alert instance, with a row for each of its evaluations.
+// The frames have the time, state, and error of the evaluations, the duration in milliseconds,
+// and a number field for each value. The fields are labeled with the labels of the instance,
+// so that the values can be graphed. The evaluations must be sorted by time.
+func buildFrames(evals []evaluation) data.Frames {
+	type instance struct {
+		labels data.Labels
+		evals  []evaluation
+	}
+	instances := make(map[string]*instance)
+	keys := make([]string, 0)
+	for _, e := range evals {
+		key := e.Labels.String()
+		i, ok := instances[key]
+		if !ok {
+			i = &instance{labels: e.Labels}
+			instances[key] = i
+			keys = append(keys, key)
+		}
+		i.evals = append(i.evals, e)
+	}
+	sort.Strings(keys)
+
+	frames := make(data.Frames, 0, len(keys))
+	for _, key := range keys {
+		frames = append(frames, buildInstanceFrame(instances[key].labels, instances[key].evals))
+	}
+	return frames
+}
+
+func buildInstanceFrame(labels data.Labels, evals []evaluation) *data.Frame {
+	valueKeys := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, e := range evals {
+		for k := range e.Values {
+			if _, ok := seen[k]; !ok {
+				seen[k] = struct{}{}
+				valueKeys = append(valueKeys, k)
+			}
+		}
+	}
+	sort.Strings(valueKeys)
+
+	times := make([]time.Time, len(evals))
+	states := make([]string, len(evals))
+	previousStates := make([]string, len(evals))
+	durations := make([]float64, len(evals))
+	errors := make([]string, len(evals))
+	values := make([][]*float64, len(valueKeys))
+	for i := range values {
+		values[i] = make([]*float64, len(evals))
+	}
+	for i, e := range evals {
+		times[i] = e.EvaluatedAt
+		states[i] = e.State
+		previousStates[i] = e.PreviousState
+		durations[i] = float64(e.Duration) / float64(time.Millisecond)
+		errors[i] = e.Error
+		for j, k := range valueKeys {
+			if v, ok := e.Values[k]; ok {
+				v := v
+				values[j][i] = &v
+			}
+		}
+	}
+
+	fields := []*data.Field{
+		data.NewField("time", nil, times),
+		data.NewField("state", labels, states),
+		data.NewField("previous_state", labels, previousStates),
+		data.NewField("duration", labels, durations).SetConfig(&data.FieldConfig{Unit: "ms"}),
+		data.NewField("error", labels, errors),
+	}
+	for j, k := range valueKeys {
+		fields = append(fields, data.NewField(k, labels, values[j]))
+	}
+	return data.NewFrame(labels.String(), fields...)
+}
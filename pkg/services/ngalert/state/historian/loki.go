@@ -0,0 +1,303 @@
+package historian
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+const (
+	lokiPushPath  = "/loki/api/v1/push"
+	lokiQueryPath = "/loki/api/v1/query_range"
+
+	lokiTimeout = 30 * time.Second
+
+	// The labels of the streams of state history. The labels of the alert instances are in the log lines,
+	// so that they do not increase the number of streams.
+	lokiSourceLabel  = "from"
+	lokiSourceValue  = "state-history"
+	lokiOrgIDLabel   = "orgID"
+	lokiRuleUIDLabel = "ruleUID"
+)
+
+// lokiLine is a log line of state history in Loki.
+type lokiLine struct {
+	Labels        data.Labels       `json:"labels"`
+	State         string            `json:"state"`
+	PreviousState string            `json:"previous"`
+	DurationMs    float64           `json:"durationMs"`
+	Values        map[string]string `json:"values"`
+	Error         string            `json:"error,omitempty"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	// Values are pairs of a timestamp in nanoseconds and a log line.
+	Values [][2]string `json:"values"`
+}
+
+type lokiPushRequest struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiQueryResponse struct {
+	Data struct {
+		ResultType string       `json:"resultType"`
+		Result     []lokiStream `json:"result"`
+	} `json:"data"`
+}
+
+// LokiStateHistorian is an implementation of state.Historian and state.HistoryReader that sends
+// every evaluation of the alert instances to Loki.
+type LokiStateHistorian struct {
+	url    *url.URL
+	client *http.Client
+	log    log.Logger
+}
+
+func NewLokiHistorian(remoteURL string) (*LokiStateHistorian, error) {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse loki remote url: %w", err)
+	}
+	return &LokiStateHistorian{
+		url:    u,
+		client: &http.Client{Timeout: lokiTimeout},
+		log:    log.New("ngalert.state.historian.loki"),
+	}, nil
+}
+
+// RecordStatesAsync sends the evaluations of the alert instances of a rule to Loki.
+func (h *LokiStateHistorian) RecordStatesAsync(ctx context.Context, rule *ngmodels.AlertRule, states []state.StateTransition) {
+	logger := h.log.FromContext(ctx)
+	// Build the stream before starting goroutine, to make sure all data is copied and won't mutate underneath us.
+	stream, err := buildLokiStream(rule, buildEvaluations(rule, states))
+	if err != nil {
+		logger.Error("Error building state history stream", "error", err)
+		return
+	}
+	if len(stream.Values) == 0 {
+		return
+	}
+	go func() {
+		if err := h.push(ctx, stream); err != nil {
+			logger.Error("Error sending state history to loki", "error", err)
+			return
+		}
+		logger.Debug("Done sending state history to loki", "count", len(stream.Values))
+	}()
+}
+
+// QueryStates returns the evaluations of the alert instances of a rule that match the query.
+// The labels of the query are matched by line filters in Loki and then by the labels of the decoded lines,
+// because the line filters can match other fields of the lines. The lines are read in pages, from the latest,
+// until there are enough evaluations or maxQueryPages pages have been read.
+func (h *LokiStateHistorian) QueryStates(ctx context.Context, query ngmodels.HistoryQuery) (data.Frames, error) {
+	end := query.To
+	if end.IsZero() {
+		end = time.Now()
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultQueryLimit
+	}
+	logQL, err := lokiQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	evals := make([]evaluation, 0)
+	seen := map[[2]string]bool{}
+	endNs := end.UnixNano()
+	for page := 0; page < maxQueryPages && len(evals) < limit; page++ {
+		entries, err := h.queryRange(ctx, logQL, query.From, endNs, limit)
+		if err != nil {
+			return nil, err
+		}
+		added := 0
+		oldest := endNs
+		for _, entry := range entries {
+			if seen[entry] {
+				continue
+			}
+			seen[entry] = true
+			added++
+			e, err := parseLokiEntry(query, entry)
+			if err != nil {
+				return nil, err
+			}
+			if ts := e.EvaluatedAt.UnixNano(); ts < oldest {
+				oldest = ts
+			}
+			if matchesLabels(e.Labels, query.Labels) {
+				evals = append(evals, e)
+			}
+		}
+		if len(entries) < limit || added == 0 {
+			break
+		}
+		// the next page ends at the oldest line of this page, which is read again in case the end is exclusive
+		endNs = oldest + 1
+	}
+	sort.SliceStable(evals, func(i, j int) bool {
+		return evals[i].EvaluatedAt.Before(evals[j].EvaluatedAt)
+	})
+	return buildFrames(limitEvaluations(evals, query.Limit)), nil
+}
+
+// queryRange returns up to limit entries of the query that are before the end, from the latest.
+func (h *LokiStateHistorian) queryRange(ctx context.Context, logQL string, start time.Time, endNs int64, limit int) ([][2]string, error) {
+	params := url.Values{}
+	params.Set("query", logQL)
+	params.Set("direction", "backward")
+	params.Set("end", strconv.FormatInt(endNs, 10))
+	if !start.IsZero() {
+		params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	}
+	params.Set("limit", strconv.Itoa(limit))
+
+	u := h.url.JoinPath(lokiQueryPath)
+	u.RawQuery = params.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create loki query request: %w", err)
+	}
+	body, err := h.do(req)
+	if err != nil {
+		return nil, err
+	}
+	var res lokiQueryResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal loki query response: %w", err)
+	}
+	var entries [][2]string
+	for _, stream := range res.Data.Result {
+		entries = append(entries, stream.Values...)
+	}
+	return entries, nil
+}
+
+func (h *LokiStateHistorian) push(ctx context.Context, stream lokiStream) error {
+	body, err := json.Marshal(lokiPushRequest{Streams: []lokiStream{stream}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal loki push request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url.JoinPath(lokiPushPath).String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create loki push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	_, err = h.do(req)
+	return err
+}
+
+func (h *LokiStateHistorian) do(req *http.Request) ([]byte, error) {
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to loki: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			h.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read loki response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("loki returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+func lokiSelector(orgID int64, ruleUID string) string {
+	return fmt.Sprintf("{%s=%q,%s=%q,%s=%q}", lokiSourceLabel, lokiSourceValue, lokiOrgIDLabel, strconv.FormatInt(orgID, 10), lokiRuleUIDLabel, ruleUID)
+}
+
+// lokiQuery returns the LogQL query of the lines of the rule, with a line filter for each label of the query.
+// The filters are the labels as they are encoded in the lines, for example "host":"a".
+func lokiQuery(query ngmodels.HistoryQuery) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(lokiSelector(query.OrgID, query.RuleUID))
+	keys := make([]string, 0, len(query.Labels))
+	for k := range query.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		key, err := json.Marshal(k)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal label name: %w", err)
+		}
+		value, err := json.Marshal(query.Labels[k])
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal label value: %w", err)
+		}
+		sb.WriteString(" |= ")
+		sb.WriteString(strconv.Quote(string(key) + ":" + string(value)))
+	}
+	return sb.String(), nil
+}
+
+func buildLokiStream(rule *ngmodels.AlertRule, evals []evaluation) (lokiStream, error) {
+	stream := lokiStream{
+		Stream: map[string]string{
+			lokiSourceLabel:  lokiSourceValue,
+			lokiOrgIDLabel:   strconv.FormatInt(rule.OrgID, 10),
+			lokiRuleUIDLabel: rule.UID,
+		},
+		Values: make([][2]string, 0, len(evals)),
+	}
+	for _, e := range evals {
+		line, err := json.Marshal(lokiLine{
+			Labels:        e.Labels,
+			State:         e.State,
+			PreviousState: e.PreviousState,
+			DurationMs:    float64(e.Duration) / float64(time.Millisecond),
+			Values:        encodeValues(e.Values),
+			Error:         e.Error,
+		})
+		if err != nil {
+			return lokiStream{}, fmt.Errorf("failed to marshal log line: %w", err)
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(e.EvaluatedAt.UnixNano(), 10), string(line)})
+	}
+	return stream, nil
+}
+
+func parseLokiEntry(query ngmodels.HistoryQuery, entry [2]string) (evaluation, error) {
+	ts, err := strconv.ParseInt(entry[0], 10, 64)
+	if err != nil {
+		return evaluation{}, fmt.Errorf("failed to parse timestamp of loki entry: %w", err)
+	}
+	var line lokiLine
+	if err := json.Unmarshal([]byte(entry[1]), &line); err != nil {
+		return evaluation{}, fmt.Errorf("failed to unmarshal loki entry: %w", err)
+	}
+	return evaluation{
+		OrgID:         query.OrgID,
+		RuleUID:       query.RuleUID,
+		Labels:        line.Labels,
+		State:         line.State,
+		PreviousState: line.PreviousState,
+		EvaluatedAt:   time.Unix(0, ts),
+		Duration:      time.Duration(line.DurationMs * float64(time.Millisecond)),
+		Values:        decodeValues(line.Values),
+		Error:         line.Error,
+	}, nil
+}
@@ -0,0 +1,140 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestLokiStateHistorian(t *testing.T) {
+	rule := &models.AlertRule{OrgID: 1, UID: "rule"}
+	at := time.Unix(1000, 0)
+
+	t.Run("pushes the evaluations to a stream of the rule", func(t *testing.T) {
+		pushed := make(chan lokiPushRequest, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, lokiPushPath, r.URL.Path)
+			var req lokiPushRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			w.WriteHeader(http.StatusNoContent)
+			pushed <- req
+		}))
+		t.Cleanup(srv.Close)
+
+		h, err := NewLokiHistorian(srv.URL)
+		require.NoError(t, err)
+		h.RecordStatesAsync(context.Background(), rule, []state.StateTransition{
+			transition(eval.Normal, eval.Alerting, data.Labels{"host": "a"}, at, map[string]float64{"B": 1}, nil),
+		})
+
+		req := <-pushed
+		require.Len(t, req.Streams, 1)
+		require.Equal(t, map[string]string{"from": "state-history", "orgID": "1", "ruleUID": "rule"}, req.Streams[0].Stream)
+		require.Len(t, req.Streams[0].Values, 1)
+		require.Equal(t, "1000000000000", req.Streams[0].Values[0][0])
+
+		var line lokiLine
+		require.NoError(t, json.Unmarshal([]byte(req.Streams[0].Values[0][1]), &line))
+		require.Equal(t, lokiLine{
+			Labels:        data.Labels{"host": "a"},
+			State:         "Alerting",
+			PreviousState: "Normal",
+			DurationMs:    10,
+			Values:        map[string]string{"B": "1"},
+		}, line)
+	})
+
+	t.Run("queries the stream of the rule", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, lokiQueryPath, r.URL.Path)
+			require.Equal(t, `{from="state-history",orgID="1",ruleUID="rule"} |= "\"host\":\"a\""`, r.URL.Query().Get("query"))
+			require.Equal(t, "1000000000000", r.URL.Query().Get("start"))
+			require.Equal(t, "1000", r.URL.Query().Get("limit"))
+			_, err := w.Write([]byte(`{"data": {"resultType": "streams", "result": [{"stream": {}, "values": [
+				["1060000000000", "{\"labels\": {\"host\": \"b\"}, \"state\": \"Normal\", \"previous\": \"Normal\", \"durationMs\": 5, \"values\": {\"B\": \"0\"}}"],
+				["1060000000000", "{\"labels\": {\"host\": \"a\"}, \"state\": \"Error\", \"previous\": \"Alerting\", \"durationMs\": 5, \"values\": {}, \"error\": \"failed\"}"],
+				["1000000000000", "{\"labels\": {\"host\": \"a\"}, \"state\": \"Alerting\", \"previous\": \"Normal\", \"durationMs\": 5, \"values\": {\"B\": \"1\"}}"]
+			]}]}}`))
+			require.NoError(t, err)
+		}))
+		t.Cleanup(srv.Close)
+
+		h, err := NewLokiHistorian(srv.URL)
+		require.NoError(t, err)
+		frames, err := h.QueryStates(context.Background(), models.HistoryQuery{
+			OrgID:   1,
+			RuleUID: "rule",
+			Labels:  map[string]string{"host": "a"},
+			From:    at,
+		})
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, 2, frames[0].Rows())
+		require.Equal(t, at, frames[0].Fields[0].At(0).(time.Time))
+		require.Equal(t, "Alerting", frames[0].Fields[1].At(0))
+		require.Equal(t, "failed", frames[0].Fields[4].At(1))
+		require.Equal(t, 1.0, *frames[0].Fields[5].At(0).(*float64))
+		require.Nil(t, frames[0].Fields[5].At(1))
+	})
+
+	t.Run("reads older pages until the limit of evaluations that match the labels", func(t *testing.T) {
+		line := func(host string) string {
+			b, err := json.Marshal(lokiLine{Labels: data.Labels{"host": host}, State: "Normal", PreviousState: "Normal", Values: map[string]string{"host": "a"}})
+			require.NoError(t, err)
+			return string(b)
+		}
+		// the line filter of host a also matches the values of host b, so the first page has one evaluation of host a
+		pages := map[string][][2]string{
+			"1070000000000": {{"1060000000000", line("b")}, {"1050000000000", line("a")}},
+			"1050000000001": {{"1050000000000", line("a")}, {"1040000000000", line("a")}},
+		}
+		var ends []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "2", r.URL.Query().Get("limit"))
+			end := r.URL.Query().Get("end")
+			ends = append(ends, end)
+			var res lokiQueryResponse
+			res.Data.Result = []lokiStream{{Values: pages[end]}}
+			require.NoError(t, json.NewEncoder(w).Encode(res))
+		}))
+		t.Cleanup(srv.Close)
+
+		h, err := NewLokiHistorian(srv.URL)
+		require.NoError(t, err)
+		frames, err := h.QueryStates(context.Background(), models.HistoryQuery{
+			OrgID:   1,
+			RuleUID: "rule",
+			Labels:  map[string]string{"host": "a"},
+			To:      time.Unix(1070, 0),
+			Limit:   2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"1070000000000", "1050000000001"}, ends)
+		require.Len(t, frames, 1)
+		require.Equal(t, 2, frames[0].Rows())
+		require.Equal(t, time.Unix(1040, 0), frames[0].Fields[0].At(0).(time.Time))
+		require.Equal(t, time.Unix(1050, 0), frames[0].Fields[0].At(1).(time.Time))
+	})
+
+	t.Run("returns the error of loki", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "parse error", http.StatusBadRequest)
+		}))
+		t.Cleanup(srv.Close)
+
+		h, err := NewLokiHistorian(srv.URL)
+		require.NoError(t, err)
+		_, err = h.QueryStates(context.Background(), models.HistoryQuery{OrgID: 1, RuleUID: "rule"})
+		require.ErrorContains(t, err, "parse error")
+	})
+}
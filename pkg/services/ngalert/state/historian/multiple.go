@@ -0,0 +1,24 @@
+package historian
+
+import (
+	"context"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// MultipleStateHistorian is an implementation of state.Historian that writes state history to several historians.
+type MultipleStateHistorian struct {
+	historians []state.Historian
+}
+
+func NewMultipleHistorian(historians ...state.Historian) *MultipleStateHistorian {
+	return &MultipleStateHistorian{historians: historians}
+}
+
+// RecordStatesAsync writes a number of state transitions for a given rule to each historian.
+func (h *MultipleStateHistorian) RecordStatesAsync(ctx context.Context, rule *ngmodels.AlertRule, states []state.StateTransition) {
+	for _, historian := range h.historians {
+		historian.RecordStatesAsync(ctx, rule, states)
+	}
+}
@@ -0,0 +1,272 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+const (
+	// retentionInterval is how often the evaluations that are older than the retention are deleted.
+	retentionInterval = 10 * time.Minute
+	// insertBatchSize is the maximum number of rows of an insert statement.
+	insertBatchSize = 100
+	// deleteBatchSize is the maximum number of rows of a delete statement, so that the deletion of the expired
+	// evaluations does not lock the table for long.
+	deleteBatchSize = 1000
+)
+
+// stateHistoryRow is a row of the alert_state_history table.
+type stateHistoryRow struct {
+	ID                 int64  `xorm:"pk autoincr 'id'"`
+	OrgID              int64  `xorm:"org_id"`
+	RuleUID            string `xorm:"rule_uid"`
+	Labels             string `xorm:"labels"`
+	State              string `xorm:"state"`
+	PreviousState      string `xorm:"previous_state"`
+	EvaluatedAt        int64  `xorm:"evaluated_at"`
+	EvaluationDuration int64  `xorm:"evaluation_duration"`
+	EvaluationValues   string `xorm:"evaluation_values"`
+	EvaluationError    string `xorm:"evaluation_error"`
+}
+
+func (stateHistoryRow) TableName() string {
+	return "alert_state_history"
+}
+
+// SQLStateHistorian is an implementation of state.Historian and state.HistoryReader that stores
+// every evaluation of the alert instances in the Grafana database.
+type SQLStateHistorian struct {
+	db              db.DB
+	retention       time.Duration
+	deleteBatchSize int
+	clock           clock.Clock
+	log             log.Logger
+}
+
+func NewSQLHistorian(db db.DB, retention time.Duration, clock clock.Clock) *SQLStateHistorian {
+	return &SQLStateHistorian{
+		db:              db,
+		retention:       retention,
+		deleteBatchSize: deleteBatchSize,
+		clock:           clock,
+		log:             log.New("ngalert.state.historian.sql"),
+	}
+}
+
+// RecordStatesAsync writes the evaluations of the alert instances of a rule to state history.
+func (h *SQLStateHistorian) RecordStatesAsync(ctx context.Context, rule *ngmodels.AlertRule, states []state.StateTransition) {
+	logger := h.log.FromContext(ctx)
+	// Build the rows before starting goroutine, to make sure all data is copied and won't mutate underneath us.
+	rows, err := buildStateHistoryRows(buildEvaluations(rule, states))
+	if err != nil {
+		logger.Error("Error building state history rows", "error", err)
+		return
+	}
+	go h.recordStatesSync(ctx, rows, logger)
+}
+
+func (h *SQLStateHistorian) recordStatesSync(ctx context.Context, rows []stateHistoryRow, logger log.Logger) {
+	if len(rows) == 0 {
+		return
+	}
+	if err := h.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for start := 0; start < len(rows); start += insertBatchSize {
+			end := start + insertBatchSize
+			if end > len(rows) {
+				end = len(rows)
+			}
+			batch := rows[start:end]
+			if _, err := sess.InsertMulti(&batch); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		logger.Error("Error saving state history batch", "error", err)
+		return
+	}
+	logger.Debug("Done saving state history batch", "count", len(rows))
+}
+
+// QueryStates returns the evaluations of the alert instances of a rule that match the query.
+// The labels of the query are matched after the rows are decoded, so the rows are read in pages, from the latest,
+// until there are enough evaluations or maxQueryPages pages have been read.
+func (h *SQLStateHistorian) QueryStates(ctx context.Context, query ngmodels.HistoryQuery) (data.Frames, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultQueryLimit
+	}
+
+	// the evaluations are collected from the latest and reversed at the end
+	evals := make([]evaluation, 0)
+	var last *stateHistoryRow
+	for page := 0; page < maxQueryPages && len(evals) < limit; page++ {
+		var rows []stateHistoryRow
+		if err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+			q := sess.Where("org_id = ? AND rule_uid = ?", query.OrgID, query.RuleUID)
+			if !query.From.IsZero() {
+				q = q.And("evaluated_at >= ?", query.From.UnixMilli())
+			}
+			if !query.To.IsZero() {
+				q = q.And("evaluated_at <= ?", query.To.UnixMilli())
+			}
+			if last != nil {
+				q = q.And("(evaluated_at < ? OR (evaluated_at = ? AND id < ?))", last.EvaluatedAt, last.EvaluatedAt, last.ID)
+			}
+			return q.Desc("evaluated_at", "id").Limit(limit).Find(&rows)
+		}); err != nil {
+			return nil, fmt.Errorf("failed to query state history: %w", err)
+		}
+
+		for _, row := range rows {
+			e, err := row.toEvaluation()
+			if err != nil {
+				return nil, err
+			}
+			if matchesLabels(e.Labels, query.Labels) {
+				evals = append(evals, e)
+			}
+		}
+		if len(rows) < limit {
+			break
+		}
+		last = &rows[len(rows)-1]
+	}
+
+	for i, j := 0, len(evals)-1; i < j; i, j = i+1, j-1 {
+		evals[i], evals[j] = evals[j], evals[i]
+	}
+	return buildFrames(limitEvaluations(evals, query.Limit)), nil
+}
+
+// Run deletes the evaluations that are older than the retention, until the context is canceled.
+func (h *SQLStateHistorian) Run(ctx context.Context) error {
+	ticker := h.clock.Ticker(retentionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			n, err := h.DeleteExpired(ctx)
+			if err != nil {
+				h.log.Error("Error deleting expired state history", "error", err)
+				continue
+			}
+			h.log.Debug("Deleted expired state history", "count", n)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// DeleteExpired deletes the evaluations that are older than the retention in batches, until none is left or the
+// context is canceled. It returns the number of deleted evaluations, including the ones deleted before an error.
+func (h *SQLStateHistorian) DeleteExpired(ctx context.Context) (int64, error) {
+	// the ids are selected in a derived table because MySQL does not support LIMIT in IN subqueries
+	deleteQuery := `DELETE FROM alert_state_history WHERE id IN (SELECT id FROM (SELECT id FROM alert_state_history WHERE evaluated_at < ? ORDER BY id %s) a)`
+	sql := fmt.Sprintf(deleteQuery, h.db.GetDialect().Limit(int64(h.deleteBatchSize)))
+	cutoff := h.clock.Now().Add(-h.retention).UnixMilli()
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		var affected int64
+		err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+			res, err := sess.Exec(sql, cutoff)
+			if err != nil {
+				return err
+			}
+			affected, err = res.RowsAffected()
+			return err
+		})
+		if err != nil {
+			return total, fmt.Errorf("failed to delete expired state history: %w", err)
+		}
+		total += affected
+		if affected == 0 {
+			return total, nil
+		}
+	}
+}
+
+func buildStateHistoryRows(evals []evaluation) ([]stateHistoryRow, error) {
+	rows := make([]stateHistoryRow, 0, len(evals))
+	for _, e := range evals {
+		labels, err := json.Marshal(e.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal labels: %w", err)
+		}
+		values, err := json.Marshal(encodeValues(e.Values))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal values: %w", err)
+		}
+		rows = append(rows, stateHistoryRow{
+			OrgID:              e.OrgID,
+			RuleUID:            e.RuleUID,
+			Labels:             string(labels),
+			State:              e.State,
+			PreviousState:      e.PreviousState,
+			EvaluatedAt:        e.EvaluatedAt.UnixMilli(),
+			EvaluationDuration: int64(e.Duration),
+			EvaluationValues:   string(values),
+			EvaluationError:    e.Error,
+		})
+	}
+	return rows, nil
+}
+
+func (r stateHistoryRow) toEvaluation() (evaluation, error) {
+	e := evaluation{
+		OrgID:         r.OrgID,
+		RuleUID:       r.RuleUID,
+		State:         r.State,
+		PreviousState: r.PreviousState,
+		EvaluatedAt:   time.UnixMilli(r.EvaluatedAt),
+		Duration:      time.Duration(r.EvaluationDuration),
+		Error:         r.EvaluationError,
+	}
+	if err := json.Unmarshal([]byte(r.Labels), &e.Labels); err != nil {
+		return evaluation{}, fmt.Errorf("failed to unmarshal labels of state history %d: %w", r.ID, err)
+	}
+	if r.EvaluationValues != "" {
+		var values map[string]string
+		if err := json.Unmarshal([]byte(r.EvaluationValues), &values); err != nil {
+			return evaluation{}, fmt.Errorf("failed to unmarshal values of state history %d: %w", r.ID, err)
+		}
+		e.Values = decodeValues(values)
+	}
+	return e, nil
+}
+
+// encodeValues formats the values as strings, as JSON does not support NaN and infinite numbers.
+func encodeValues(values map[string]float64) map[string]string {
+	res := make(map[string]string, len(values))
+	for k, v := range values {
+		res[k] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return res
+}
+
+func decodeValues(values map[string]string) map[string]float64 {
+	res := make(map[string]float64, len(values))
+	for k, v := range values {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			continue
+		}
+		res[k] = f
+	}
+	return res
+}
@@ -0,0 +1,131 @@
+package historian
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestIntegrationSQLStateHistorian(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	clk := clock.NewMock()
+	clk.Set(time.Unix(100000, 0))
+	h := NewSQLHistorian(db.InitTestDB(t), time.Hour, clk)
+	rule := &models.AlertRule{OrgID: 1, UID: "rule"}
+
+	record := func(states ...state.StateTransition) {
+		rows, err := buildStateHistoryRows(buildEvaluations(rule, states))
+		require.NoError(t, err)
+		h.recordStatesSync(ctx, rows, log.NewNopLogger())
+	}
+	start := clk.Now()
+	for i := 0; i < 3; i++ {
+		at := start.Add(time.Duration(i) * time.Minute)
+		record(
+			transition(eval.Normal, eval.Normal, data.Labels{"host": "a", "__alert_rule_uid__": "rule"}, at, map[string]float64{"B": float64(i)}, nil),
+			transition(eval.Normal, eval.Alerting, data.Labels{"host": "b"}, at, map[string]float64{"B": math.NaN()}, nil),
+		)
+	}
+	record(transition(eval.Alerting, eval.Error, data.Labels{"host": "b"}, start.Add(3*time.Minute), nil, errors.New("failed to query")))
+
+	t.Run("returns a frame for each alert instance", func(t *testing.T) {
+		frames, err := h.QueryStates(ctx, models.HistoryQuery{OrgID: 1, RuleUID: "rule"})
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+
+		a := frames[0]
+		require.Equal(t, 3, a.Rows())
+		require.Equal(t, data.Labels{"host": "a"}, a.Fields[1].Labels)
+		require.Equal(t, start, a.Fields[0].At(0).(time.Time))
+		require.Equal(t, "Normal", a.Fields[1].At(2))
+		require.Equal(t, 2.0, *a.Fields[5].At(2).(*float64))
+
+		b := frames[1]
+		require.Equal(t, 4, b.Rows())
+		require.Equal(t, "Error", b.Fields[1].At(3))
+		require.Equal(t, "Alerting", b.Fields[2].At(3))
+		require.Equal(t, "failed to query", b.Fields[4].At(3))
+		require.True(t, math.IsNaN(*b.Fields[5].At(0).(*float64)))
+		require.Nil(t, b.Fields[5].At(3))
+	})
+
+	t.Run("filters by time range and labels", func(t *testing.T) {
+		frames, err := h.QueryStates(ctx, models.HistoryQuery{
+			OrgID:   1,
+			RuleUID: "rule",
+			Labels:  map[string]string{"host": "b"},
+			From:    start.Add(time.Minute),
+			To:      start.Add(2 * time.Minute),
+		})
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, 2, frames[0].Rows())
+
+		frames, err = h.QueryStates(ctx, models.HistoryQuery{OrgID: 2, RuleUID: "rule"})
+		require.NoError(t, err)
+		require.Empty(t, frames)
+	})
+
+	t.Run("returns the latest evaluations up to the limit", func(t *testing.T) {
+		frames, err := h.QueryStates(ctx, models.HistoryQuery{OrgID: 1, RuleUID: "rule", Labels: map[string]string{"host": "b"}, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, 1, frames[0].Rows())
+		require.Equal(t, "Error", frames[0].Fields[1].At(0))
+	})
+
+	t.Run("reads older pages until the limit of evaluations that match the labels", func(t *testing.T) {
+		// the latest evaluation is of host b, so host a only matches in the following pages
+		frames, err := h.QueryStates(ctx, models.HistoryQuery{OrgID: 1, RuleUID: "rule", Labels: map[string]string{"host": "a"}, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, 2, frames[0].Rows())
+		require.Equal(t, start.Add(time.Minute), frames[0].Fields[0].At(0).(time.Time))
+		require.Equal(t, start.Add(2*time.Minute), frames[0].Fields[0].At(1).(time.Time))
+	})
+
+	t.Run("deletes the evaluations older than the retention", func(t *testing.T) {
+		clk.Add(time.Hour + 90*time.Second)
+		// the evaluations are deleted in two batches
+		h.deleteBatchSize = 3
+		n, err := h.DeleteExpired(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(4), n)
+
+		frames, err := h.QueryStates(ctx, models.HistoryQuery{OrgID: 1, RuleUID: "rule"})
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+		require.Equal(t, 1, frames[0].Rows())
+		require.Equal(t, 2, frames[1].Rows())
+	})
+}
+
+func transition(from, to eval.State, labels data.Labels, at time.Time, values map[string]float64, err error) state.StateTransition {
+	return state.StateTransition{
+		State: &state.State{
+			State:              to,
+			Labels:             labels,
+			LastEvaluationTime: at,
+			EvaluationDuration: 10 * time.Millisecond,
+			Values:             values,
+			Error:              err,
+		},
+		PreviousState: from,
+	}
+}
@@ -2,12 +2,16 @@ package state
 
 import (
 	"context"
+	"math"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/benbjohnson/clock"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
@@ -37,6 +41,15 @@ type Manager struct {
 	images        ImageCapturer
 	historian     Historian
 	externalURL   *url.URL
+
+	// snapshots stores the snapshots of the state cache. If it is nil, snapshots are disabled.
+	snapshots        SnapshotStore
+	snapshotInterval time.Duration
+
+	// remoteRules are the rules that are evaluated by other instances of the cluster, by organization and UID.
+	// Their states are not in the cache and are read from the database.
+	remoteRulesMtx sync.RWMutex
+	remoteRules    map[int64]map[string]*ngModels.AlertRule
 }
 
 func NewManager(metrics *metrics.State, externalURL *url.URL, instanceStore InstanceStore, images ImageCapturer, clock clock.Clock, historian Historian) *Manager {
@@ -55,14 +68,28 @@ func NewManager(metrics *metrics.State, externalURL *url.URL, instanceStore Inst
 
 func (st *Manager) Run(ctx context.Context) error {
 	ticker := st.clock.Ticker(MetricsScrapeInterval)
+	var snapshotC <-chan time.Time
+	if st.snapshots != nil {
+		snapshotTicker := st.clock.Ticker(st.snapshotInterval)
+		defer snapshotTicker.Stop()
+		snapshotC = snapshotTicker.C
+	}
 	for {
 		select {
 		case <-ticker.C:
 			st.log.Debug("Recording state cache metrics", "now", st.clock.Now())
 			st.cache.recordMetrics(st.metrics)
+		case <-snapshotC:
+			st.SaveSnapshots(ctx)
 		case <-ctx.Done():
 			st.log.Debug("Stopping")
 			ticker.Stop()
+			if st.snapshots != nil {
+				// The context is canceled, so a new one is used to write the last snapshot.
+				snapshotCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				st.SaveSnapshots(snapshotCtx)
+				cancel()
+			}
 			return ctx.Err()
 		}
 	}
@@ -97,13 +124,26 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 			ruleByUID[rule.UID] = rule
 		}
 
-		orgStates := make(map[string]*ruleStates, len(ruleByUID))
-		states[orgId] = orgStates
-
 		// Get Instances
 		cmd := ngModels.ListAlertInstancesQuery{
 			RuleOrgID: orgId,
 		}
+
+		// If there is a snapshot, only the instances that changed after it are loaded.
+		orgStates, snapshotTime, ok := st.readSnapshot(ctx, orgId, ruleByUID)
+		if ok {
+			cmd.EvaluatedAfter = snapshotTime.Add(-snapshotDeltaMargin)
+			if err := st.dropDeletedSnapshotStates(ctx, orgId, orgStates); err != nil {
+				st.log.Error("Unable to fetch the keys of alert instances, the state is loaded from the alert instances", "org", orgId, "error", err)
+				ok = false
+				cmd.EvaluatedAfter = time.Time{}
+			}
+		}
+		if !ok {
+			orgStates = make(map[string]*ruleStates, len(ruleByUID))
+		}
+		states[orgId] = orgStates
+
 		if err := st.instanceStore.ListAlertInstances(ctx, &cmd); err != nil {
 			st.log.Error("Unable to fetch previous state", "error", err)
 		}
@@ -121,31 +161,68 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				orgStates[entry.RuleUID] = rulesStates
 			}
 
-			lbs := map[string]string(entry.Labels)
-			cacheID, err := entry.Labels.StringKey()
-			if err != nil {
-				st.log.Error("Error getting cacheId for entry", "error", err)
-			}
-			rulesStates.states[cacheID] = &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheID:              cacheID,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          ruleForEntry.Annotations,
+			s := st.stateFromInstance(entry, ruleForEntry)
+			if existing, ok := rulesStates.states[s.CacheID]; ok && existing.LastEvaluationTime.After(s.LastEvaluationTime) {
+				continue
 			}
-			statesCount++
+			rulesStates.states[s.CacheID] = s
+		}
+		for _, rulesStates := range orgStates {
+			statesCount += len(rulesStates.states)
 		}
 	}
 	st.cache.setAllStates(states)
 	st.log.Info("State cache has been initialized", "states", statesCount, "duration", time.Since(startTime))
 }
 
+// WarmRule loads the states of the rule from the database to the cache, replacing the states of the rule in the cache.
+// It is used when this instance starts evaluating a rule that was evaluated by another instance.
+func (st *Manager) WarmRule(ctx context.Context, alertRule *ngModels.AlertRule) {
+	if st.instanceStore == nil {
+		return
+	}
+	logger := st.log.New(alertRule.GetKey().LogContext()...)
+	cmd := ngModels.ListAlertInstancesQuery{
+		RuleOrgID: alertRule.OrgID,
+		RuleUID:   alertRule.UID,
+	}
+	if err := st.instanceStore.ListAlertInstances(ctx, &cmd); err != nil {
+		logger.Error("Unable to fetch previous state of the rule", "error", err)
+		return
+	}
+	st.cache.removeByRuleUID(alertRule.OrgID, alertRule.UID)
+	for _, entry := range cmd.Result {
+		st.cache.set(st.stateFromInstance(entry, alertRule))
+	}
+	logger.Debug("State of the rule has been loaded", "states", len(cmd.Result))
+}
+
+// ForgetStateByRuleUID removes the states of the rule from the cache but, unlike ResetStateByRuleUID, keeps them
+// in the database. It is used when the rule is evaluated by another instance.
+func (st *Manager) ForgetStateByRuleUID(ruleKey ngModels.AlertRuleKey) []*State {
+	return st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+}
+
+func (st *Manager) stateFromInstance(entry *ngModels.AlertInstance, alertRule *ngModels.AlertRule) *State {
+	cacheID, err := entry.Labels.StringKey()
+	if err != nil {
+		st.log.Error("Error getting cacheId for entry", "error", err)
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheID:              cacheID,
+		Labels:               map[string]string(entry.Labels),
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          alertRule.Annotations,
+	}
+}
+
 func (st *Manager) Get(orgID int64, alertRuleUID, stateId string) *State {
 	return st.cache.get(orgID, alertRuleUID, stateId)
 }
@@ -165,6 +242,46 @@ func (st *Manager) ResetStateByRuleUID(ctx context.Context, ruleKey ngModels.Ale
 	return states
 }
 
+// PauseStatesByRule marks the states of the paused rule as Normal with the reason Paused, in the state manager and
+// the database, and returns their transitions. States that were firing, or that were NoData or Error, are marked as
+// resolved so that the Alertmanager stops notifying about them. States that are already paused are not changed, so
+// that the states are paused only once.
+func (st *Manager) PauseStatesByRule(ctx context.Context, pausedAt time.Time, alertRule *ngModels.AlertRule) []StateTransition {
+	ruleKey := alertRule.GetKey()
+	logger := st.log.New(ruleKey.LogContext()...)
+
+	var transitions []StateTransition
+	for _, s := range st.cache.getStatesForRuleUID(ruleKey.OrgID, ruleKey.UID) {
+		if s.StateReason == ngModels.StateReasonPaused {
+			continue
+		}
+		paused := *s
+		paused.State = eval.Normal
+		paused.StateReason = ngModels.StateReasonPaused
+		paused.EndsAt = pausedAt
+		if s.State != eval.Normal && s.State != eval.Pending {
+			paused.Resolved = true
+		}
+		st.cache.set(&paused)
+
+		transitions = append(transitions, StateTransition{
+			State:               &paused,
+			PreviousState:       s.State,
+			PreviousStateReason: s.StateReason,
+		})
+	}
+	if len(transitions) == 0 {
+		return nil
+	}
+
+	st.saveAlertStates(ctx, logger, transitions...)
+	if st.historian != nil {
+		st.historian.RecordStatesAsync(ctx, alertRule, transitions)
+	}
+	logger.Info("States of the rule were paused", "states", len(transitions))
+	return transitions
+}
+
 // ProcessEvalResults updates the current states that belong to a rule with the evaluation results.
 // if extraLabels is not empty, those labels will be added to every state. The extraLabels take precedence over rule labels and result labels
 func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time, alertRule *ngModels.AlertRule, results eval.Results, extraLabels data.Labels) []StateTransition {
@@ -191,6 +308,7 @@ func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time
 // Set the current state based on evaluation results
 func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result, extraLabels data.Labels, logger log.Logger) StateTransition {
 	currentState := st.cache.getOrCreate(ctx, st.log, alertRule, result, extraLabels, st.externalURL)
+	created := currentState.LastEvaluationTime.IsZero()
 
 	currentState.LastEvaluationTime = result.EvaluatedAt
 	currentState.EvaluationDuration = result.EvaluationDuration
@@ -256,19 +374,114 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		State:               currentState,
 		PreviousState:       oldState,
 		PreviousStateReason: oldReason,
+		created:             created,
 	}
 
 	return nextState
 }
 
+// GetAll returns the states of all rules of the organization, including the rules that are evaluated
+// by other instances of the cluster.
 func (st *Manager) GetAll(orgID int64) []*State {
-	return st.cache.getAll(orgID)
+	states := st.cache.getAll(orgID)
+	st.remoteRulesMtx.RLock()
+	rules := st.remoteRules[orgID]
+	st.remoteRulesMtx.RUnlock()
+	if len(rules) == 0 {
+		return states
+	}
+	return append(states, st.getRemoteStates(orgID, "", rules)...)
 }
 
+// GetStatesForRuleUID returns the states of the rule. The states of a rule that is evaluated by another
+// instance of the cluster are read from the database.
 func (st *Manager) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*State {
+	st.remoteRulesMtx.RLock()
+	rule, ok := st.remoteRules[orgID][alertRuleUID]
+	st.remoteRulesMtx.RUnlock()
+	if ok {
+		return st.getRemoteStates(orgID, alertRuleUID, map[string]*ngModels.AlertRule{alertRuleUID: rule})
+	}
 	return st.cache.getStatesForRuleUID(orgID, alertRuleUID)
 }
 
+// SetRemoteRules sets the rules that are evaluated by other instances of the cluster, replacing the previous ones.
+// The states of these rules are read from the database, where the instances that evaluate them save them.
+func (st *Manager) SetRemoteRules(rules []*ngModels.AlertRule) {
+	remoteRules := make(map[int64]map[string]*ngModels.AlertRule)
+	for _, rule := range rules {
+		if _, ok := remoteRules[rule.OrgID]; !ok {
+			remoteRules[rule.OrgID] = make(map[string]*ngModels.AlertRule)
+		}
+		remoteRules[rule.OrgID][rule.UID] = rule
+	}
+	st.remoteRulesMtx.Lock()
+	defer st.remoteRulesMtx.Unlock()
+	st.remoteRules = remoteRules
+}
+
+// getRemoteStates reads the states of the rules of the organization from the database. If ruleUID is not empty,
+// only the states of that rule are read.
+func (st *Manager) getRemoteStates(orgID int64, ruleUID string, rules map[string]*ngModels.AlertRule) []*State {
+	if st.instanceStore == nil {
+		return nil
+	}
+	cmd := ngModels.ListAlertInstancesQuery{
+		RuleOrgID: orgID,
+		RuleUID:   ruleUID,
+	}
+	if err := st.instanceStore.ListAlertInstances(context.Background(), &cmd); err != nil {
+		st.log.Error("Unable to fetch the states of rules evaluated by other instances", "org", orgID, "error", err)
+		return nil
+	}
+	states := make([]*State, 0, len(cmd.Result))
+	for _, entry := range cmd.Result {
+		rule, ok := rules[entry.RuleUID]
+		if !ok {
+			continue
+		}
+		states = append(states, st.stateFromInstance(entry, rule))
+	}
+	return states
+}
+
+// GetRuleInstanceStates returns the alert instances of the rule in the form used by rule state expressions.
+// The labels that are added to the instances by the scheduler and the labels of the rule are removed, so that
+// the instances have only the labels of the series, and values that are NaN or infinite are left out.
+func (st *Manager) GetRuleInstanceStates(alertRule *ngModels.AlertRule) []expr.RuleInstanceState {
+	states := st.cache.getStatesForRuleUID(alertRule.OrgID, alertRule.UID)
+	result := make([]expr.RuleInstanceState, 0, len(states))
+	for _, s := range states {
+		labels := make(data.Labels, len(s.Labels))
+		for k, v := range s.Labels {
+			if _, ok := alertRule.Labels[k]; ok {
+				continue
+			}
+			switch k {
+			case ngModels.NamespaceUIDLabel, model.AlertNameLabel, ngModels.RuleUIDLabel, ngModels.FolderTitleLabel:
+				continue
+			}
+			labels[k] = v
+		}
+		var values map[string]float64
+		for refID, v := range s.Values {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			if values == nil {
+				values = make(map[string]float64, len(s.Values))
+			}
+			values[refID] = v
+		}
+		result = append(result, expr.RuleInstanceState{
+			Labels: labels,
+			Firing: s.State == eval.Alerting,
+			Values: values,
+		})
+	}
+	return result
+}
+
 func (st *Manager) Put(states []*State) {
 	for _, s := range states {
 		st.cache.set(s)
@@ -285,6 +498,11 @@ func (st *Manager) saveAlertStates(ctx context.Context, logger log.Logger, state
 	instances := make([]ngModels.AlertInstance, 0, len(states))
 
 	for _, s := range states {
+		// The snapshots keep the states that did not change, so only the changes are written. New states are
+		// always written because the states of the snapshots that are not in the database are dropped by Warm.
+		if st.snapshots != nil && !s.Changed() && !s.created {
+			continue
+		}
 		key, err := s.GetAlertInstanceKey()
 		if err != nil {
 			logger.Error("Failed to create a key for alert state to save it to database. The state will be ignored ", "cacheID", s.CacheID, "error", err)
@@ -301,6 +519,9 @@ func (st *Manager) saveAlertStates(ctx context.Context, logger log.Logger, state
 		}
 		instances = append(instances, fields)
 	}
+	if len(instances) == 0 {
+		return
+	}
 
 	if err := st.instanceStore.SaveAlertInstances(ctx, instances...); err != nil {
 		type debugInfo struct {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"sort"
 	"testing"
@@ -14,6 +15,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -117,6 +119,61 @@ func TestWarmStateCache(t *testing.T) {
 	})
 }
 
+func TestWarmRule(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, 1)
+
+	const mainOrgID int64 = 1
+	rule := tests.CreateTestAlertRule(t, ctx, dbstore, 600, mainOrgID)
+
+	// two instances of the cluster share the database, the first one evaluates the rule
+	first := state.NewManager(testMetrics.GetStateMetrics(), nil, dbstore, &state.NoopImageService{}, clock.NewMock(), &state.FakeHistorian{})
+	second := state.NewManager(testMetrics.GetStateMetrics(), nil, dbstore, &state.NoopImageService{}, clock.NewMock(), &state.FakeHistorian{})
+	first.Warm(ctx, dbstore)
+	second.Warm(ctx, dbstore)
+
+	_ = first.ProcessEvalResults(ctx, evaluationTime, rule, eval.Results{{
+		Instance:    data.Labels{"instance": "a"},
+		State:       eval.Alerting,
+		EvaluatedAt: evaluationTime,
+	}}, data.Labels{"alertname": rule.Title})
+	expected := first.GetStatesForRuleUID(mainOrgID, rule.UID)
+	require.Len(t, expected, 1)
+	require.Empty(t, second.GetStatesForRuleUID(mainOrgID, rule.UID))
+
+	t.Run("ForgetStateByRuleUID removes states only from the cache", func(t *testing.T) {
+		forgotten := first.ForgetStateByRuleUID(rule.GetKey())
+		require.Len(t, forgotten, 1)
+		require.Empty(t, first.GetStatesForRuleUID(mainOrgID, rule.UID))
+	})
+
+	t.Run("states of remote rules are read from the database", func(t *testing.T) {
+		second.SetRemoteRules([]*models.AlertRule{rule})
+		actual := second.GetStatesForRuleUID(mainOrgID, rule.UID)
+		require.Len(t, actual, 1)
+		require.Equal(t, expected[0].CacheID, actual[0].CacheID)
+		require.Equal(t, eval.Alerting, actual[0].State)
+		require.Equal(t, rule.Annotations, actual[0].Annotations)
+		require.Len(t, second.GetAll(mainOrgID), 1)
+
+		second.SetRemoteRules(nil)
+		require.Empty(t, second.GetStatesForRuleUID(mainOrgID, rule.UID))
+		require.Empty(t, second.GetAll(mainOrgID))
+	})
+
+	t.Run("WarmRule loads states of the rule from the database", func(t *testing.T) {
+		second.WarmRule(ctx, rule)
+		actual := second.GetStatesForRuleUID(mainOrgID, rule.UID)
+		require.Len(t, actual, 1)
+		require.Equal(t, expected[0].CacheID, actual[0].CacheID)
+		require.Equal(t, eval.Alerting, actual[0].State)
+		require.Equal(t, expected[0].StartsAt, actual[0].StartsAt)
+		require.Equal(t, rule.Annotations, actual[0].Annotations)
+	})
+}
+
 func TestDashboardAnnotations(t *testing.T) {
 	evaluationTime, err := time.Parse("2006-01-02", "2022-01-01")
 	require.NoError(t, err)
@@ -2409,3 +2466,102 @@ func TestStaleResults(t *testing.T) {
 		}
 	})
 }
+
+func TestPauseStatesByRule(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+
+	instanceStore := &state.FakeInstanceStore{}
+	st := state.NewManager(testMetrics.GetStateMetrics(), nil, instanceStore, &state.NoopImageService{}, clk, &state.FakeHistorian{})
+
+	rule := models.AlertRuleGen(models.WithFor(0))()
+	results := eval.Results{
+		eval.ResultGen(eval.WithState(eval.Alerting), eval.WithEvaluatedAt(clk.Now()))(),
+		eval.ResultGen(eval.WithState(eval.Normal), eval.WithEvaluatedAt(clk.Now()))(),
+		eval.ResultGen(eval.WithState(eval.Error), eval.WithEvaluatedAt(clk.Now()))(),
+	}
+	st.ProcessEvalResults(ctx, clk.Now(), rule, results, nil)
+	previous := make(map[string]eval.State)
+	for _, s := range st.GetStatesForRuleUID(rule.OrgID, rule.UID) {
+		previous[s.CacheID] = s.State
+	}
+	require.Len(t, previous, 3)
+
+	clk.Add(time.Minute)
+	instanceStore.RecordedOps = nil
+	transitions := st.PauseStatesByRule(ctx, clk.Now(), rule)
+
+	t.Run("should return transitions to Normal with reason Paused", func(t *testing.T) {
+		require.Len(t, transitions, 3)
+		for _, s := range transitions {
+			assert.Equal(t, previous[s.CacheID], s.PreviousState)
+			assert.Equal(t, eval.Normal, s.State.State)
+			assert.Equal(t, models.StateReasonPaused, s.StateReason)
+			assert.Equal(t, clk.Now(), s.EndsAt)
+			assert.Equalf(t, s.PreviousState != eval.Normal, s.Resolved, "only states that were not Normal should be resolved")
+		}
+	})
+
+	t.Run("should keep the paused states in cache", func(t *testing.T) {
+		states := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		require.Len(t, states, 3)
+		for _, s := range states {
+			assert.Equal(t, eval.Normal, s.State)
+			assert.Equal(t, models.StateReasonPaused, s.StateReason)
+		}
+	})
+
+	t.Run("should save the paused states to database", func(t *testing.T) {
+		var saved []models.AlertInstance
+		for _, op := range instanceStore.RecordedOps {
+			if inst, ok := op.(models.AlertInstance); ok {
+				saved = append(saved, inst)
+			}
+		}
+		require.Len(t, saved, 3)
+		for _, inst := range saved {
+			assert.Equal(t, models.InstanceStateNormal, inst.CurrentState)
+			assert.Equal(t, models.StateReasonPaused, inst.CurrentReason)
+		}
+	})
+
+	t.Run("should return nothing if the states are already paused", func(t *testing.T) {
+		require.Empty(t, st.PauseStatesByRule(ctx, clk.Now(), rule))
+	})
+}
+
+func TestGetRuleInstanceStates(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+
+	st := state.NewManager(testMetrics.GetStateMetrics(), nil, &state.FakeInstanceStore{}, &state.NoopImageService{}, clk, &state.FakeHistorian{})
+
+	rule := models.AlertRuleGen(models.WithFor(0))()
+	rule.Labels = map[string]string{"team": "network"}
+
+	value := func(v float64) map[string]eval.NumberValueCapture {
+		return map[string]eval.NumberValueCapture{"A": {Var: "A", Value: &v}}
+	}
+	firing := eval.ResultGen(eval.WithState(eval.Alerting), eval.WithEvaluatedAt(clk.Now()), eval.WithLabels(data.Labels{"host": "a"}))()
+	firing.Values = value(3)
+	normal := eval.ResultGen(eval.WithState(eval.Normal), eval.WithEvaluatedAt(clk.Now()), eval.WithLabels(data.Labels{"host": "b"}))()
+	normal.Values = value(math.NaN())
+	extraLabels := data.Labels{
+		models.NamespaceUIDLabel: rule.NamespaceUID,
+		model.AlertNameLabel:     rule.Title,
+		models.RuleUIDLabel:      rule.UID,
+		models.FolderTitleLabel:  "folder",
+	}
+	st.ProcessEvalResults(ctx, clk.Now(), rule, eval.Results{firing, normal}, extraLabels)
+
+	states := st.GetRuleInstanceStates(rule)
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Labels["host"] < states[j].Labels["host"]
+	})
+	require.Equal(t, []expr.RuleInstanceState{
+		{Labels: data.Labels{"host": "a"}, Firing: true, Values: map[string]float64{"A": 3}},
+		{Labels: data.Labels{"host": "b"}},
+	}, states)
+
+	require.Empty(t, st.GetRuleInstanceStates(models.AlertRuleGen()()))
+}
@@ -3,6 +3,8 @@ package state
 import (
 	"context"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
@@ -10,6 +12,8 @@ import (
 type InstanceStore interface {
 	FetchOrgIds(ctx context.Context) ([]int64, error)
 	ListAlertInstances(ctx context.Context, cmd *models.ListAlertInstancesQuery) error
+	// ListAlertInstanceKeys returns the keys of all alert instances of the organization.
+	ListAlertInstanceKeys(ctx context.Context, orgID int64) ([]models.AlertInstanceKey, error)
 	SaveAlertInstances(ctx context.Context, cmd ...models.AlertInstance) error
 	DeleteAlertInstances(ctx context.Context, keys ...models.AlertInstanceKey) error
 	DeleteAlertInstancesByRule(ctx context.Context, key models.AlertRuleKey) error
@@ -26,6 +30,13 @@ type Historian interface {
 	RecordStatesAsync(ctx context.Context, rule *models.AlertRule, states []StateTransition)
 }
 
+// HistoryReader queries the history of the evaluations of alert rules.
+type HistoryReader interface {
+	// QueryStates returns the evaluations of the alert instances of a rule that match the query,
+	// with a frame for each alert instance.
+	QueryStates(ctx context.Context, query models.HistoryQuery) (data.Frames, error)
+}
+
 // ImageCapturer captures images.
 //
 //go:generate mockgen -destination=image_mock.go -package=state github.com/grafana/grafana/pkg/services/ngalert/state ImageCapturer
@@ -0,0 +1,239 @@
+package state
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// snapshotVersion is the version of the format of the snapshots. Snapshots of other versions are ignored
+	// and the state is loaded from the alert instances instead.
+	snapshotVersion = 1
+
+	// snapshotDeltaMargin is subtracted from the time of the snapshot when the alert instances that changed after
+	// the snapshot are loaded, so that the changes made by the evaluations that were running while the snapshot
+	// was taken are not missed.
+	snapshotDeltaMargin = 10 * time.Minute
+
+	snapshotKVNamespace = "alerting.state"
+	snapshotKVKey       = "snapshot"
+)
+
+// SnapshotStore persists the snapshots of the state cache of each organization.
+type SnapshotStore interface {
+	// GetSnapshot returns the last snapshot of the organization, or false if there is no snapshot.
+	GetSnapshot(ctx context.Context, orgID int64) ([]byte, bool, error)
+	SaveSnapshot(ctx context.Context, orgID int64, snapshot []byte) error
+}
+
+// KVSnapshotStore stores the snapshots in the key-value store as base64 encoded strings.
+type KVSnapshotStore struct {
+	kv kvstore.KVStore
+}
+
+func NewKVSnapshotStore(kv kvstore.KVStore) *KVSnapshotStore {
+	return &KVSnapshotStore{kv: kv}
+}
+
+func (s *KVSnapshotStore) GetSnapshot(ctx context.Context, orgID int64) ([]byte, bool, error) {
+	content, exists, err := s.kv.Get(ctx, orgID, snapshotKVNamespace, snapshotKVKey)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+	b, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	return b, true, nil
+}
+
+func (s *KVSnapshotStore) SaveSnapshot(ctx context.Context, orgID int64, snapshot []byte) error {
+	return s.kv.Set(ctx, orgID, snapshotKVNamespace, snapshotKVKey, base64.StdEncoding.EncodeToString(snapshot))
+}
+
+// snapshot is the state cache of an organization at a point in time.
+type snapshot struct {
+	Version   int             `json:"version"`
+	CreatedAt time.Time       `json:"createdAt"`
+	States    []snapshotState `json:"states"`
+}
+
+type snapshotState struct {
+	RuleUID            string      `json:"ruleUid"`
+	Labels             data.Labels `json:"labels"`
+	State              eval.State  `json:"state"`
+	StateReason        string      `json:"reason,omitempty"`
+	StartsAt           time.Time   `json:"startsAt"`
+	EndsAt             time.Time   `json:"endsAt"`
+	LastEvaluationTime time.Time   `json:"lastEvaluationTime"`
+}
+
+// EnableSnapshots makes the manager write a snapshot of the state cache of each organization at the interval,
+// and when it stops. When snapshots are enabled, the state of an alert instance is written to the database only
+// when it changes, and the state cache is restored from the snapshot and the instances that changed after it.
+func (st *Manager) EnableSnapshots(store SnapshotStore, interval time.Duration) {
+	st.snapshots = store
+	st.snapshotInterval = interval
+}
+
+// SaveSnapshots writes a snapshot of the state cache of each organization.
+func (st *Manager) SaveSnapshots(ctx context.Context) {
+	if st.snapshots == nil {
+		return
+	}
+	for _, orgID := range st.cache.getOrgIDs() {
+		logger := st.log.New("org", orgID)
+		startTime := time.Now()
+		states := st.cache.getAll(orgID)
+		s := snapshot{
+			Version:   snapshotVersion,
+			CreatedAt: st.clock.Now(),
+			States:    make([]snapshotState, 0, len(states)),
+		}
+		for _, state := range states {
+			s.States = append(s.States, snapshotState{
+				RuleUID:            state.AlertRuleUID,
+				Labels:             state.Labels,
+				State:              state.State,
+				StateReason:        state.StateReason,
+				StartsAt:           state.StartsAt,
+				EndsAt:             state.EndsAt,
+				LastEvaluationTime: state.LastEvaluationTime,
+			})
+		}
+		b, err := encodeSnapshot(s)
+		if err != nil {
+			logger.Error("Failed to encode snapshot of the state", "error", err)
+			continue
+		}
+		if err := st.snapshots.SaveSnapshot(ctx, orgID, b); err != nil {
+			logger.Error("Failed to save snapshot of the state", "error", err)
+			continue
+		}
+		logger.Debug("Snapshot of the state has been saved", "states", len(s.States), "bytes", len(b), "duration", time.Since(startTime))
+	}
+}
+
+// readSnapshot returns the states of the rules of the organization from its snapshot, and the time of the snapshot.
+// It returns false if there is no snapshot that can be used.
+func (st *Manager) readSnapshot(ctx context.Context, orgID int64, ruleByUID map[string]*ngModels.AlertRule) (map[string]*ruleStates, time.Time, bool) {
+	if st.snapshots == nil {
+		return nil, time.Time{}, false
+	}
+	logger := st.log.New("org", orgID)
+	b, ok, err := st.snapshots.GetSnapshot(ctx, orgID)
+	if err != nil {
+		logger.Error("Unable to fetch snapshot of the state, the state is loaded from the alert instances", "error", err)
+		return nil, time.Time{}, false
+	}
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	s, err := decodeSnapshot(b)
+	if err != nil {
+		logger.Warn("Unable to read snapshot of the state, the state is loaded from the alert instances", "error", err)
+		return nil, time.Time{}, false
+	}
+
+	orgStates := make(map[string]*ruleStates, len(ruleByUID))
+	for _, entry := range s.States {
+		rule, ok := ruleByUID[entry.RuleUID]
+		if !ok {
+			continue
+		}
+		labels := ngModels.InstanceLabels(entry.Labels)
+		cacheID, err := labels.StringKey()
+		if err != nil {
+			logger.Error("Error getting cacheId for entry", "error", err)
+			continue
+		}
+		rulesStates, ok := orgStates[entry.RuleUID]
+		if !ok {
+			rulesStates = &ruleStates{states: make(map[string]*State)}
+			orgStates[entry.RuleUID] = rulesStates
+		}
+		rulesStates.states[cacheID] = &State{
+			AlertRuleUID:       entry.RuleUID,
+			OrgID:              orgID,
+			CacheID:            cacheID,
+			Labels:             entry.Labels,
+			State:              entry.State,
+			StateReason:        entry.StateReason,
+			StartsAt:           entry.StartsAt,
+			EndsAt:             entry.EndsAt,
+			LastEvaluationTime: entry.LastEvaluationTime,
+			Annotations:        rule.Annotations,
+		}
+	}
+	return orgStates, s.CreatedAt, true
+}
+
+// dropDeletedSnapshotStates removes the states of the snapshot whose alert instances are not in the database anymore,
+// because they were deleted after the snapshot was taken, for example because they became stale or the state of
+// their rule was reset.
+func (st *Manager) dropDeletedSnapshotStates(ctx context.Context, orgID int64, orgStates map[string]*ruleStates) error {
+	keys, err := st.instanceStore.ListAlertInstanceKeys(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	existing := make(map[ngModels.AlertInstanceKey]struct{}, len(keys))
+	for _, key := range keys {
+		existing[key] = struct{}{}
+	}
+	for ruleUID, rulesStates := range orgStates {
+		for cacheID, s := range rulesStates.states {
+			key, err := s.GetAlertInstanceKey()
+			if err != nil {
+				return err
+			}
+			if _, ok := existing[key]; !ok {
+				delete(rulesStates.states, cacheID)
+			}
+		}
+		if len(rulesStates.states) == 0 {
+			delete(orgStates, ruleUID)
+		}
+	}
+	return nil
+}
+
+func encodeSnapshot(s snapshot) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(w).Encode(s); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeSnapshot(b []byte) (snapshot, error) {
+	var s snapshot
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return s, err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return s, err
+	}
+	if s.Version != snapshotVersion {
+		return s, fmt.Errorf("unsupported snapshot version %d, expected %d", s.Version, snapshotVersion)
+	}
+	return s, nil
+}
@@ -0,0 +1,144 @@
+package state_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestSnapshots(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, 1)
+
+	const mainOrgID int64 = 1
+	rule := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+
+	t0 := time.Unix(1680000000, 0)
+	t1 := t0.Add(time.Minute)
+	t2 := t1.Add(time.Minute)
+
+	results := func(evaluatedAt time.Time, b eval.State) eval.Results {
+		return eval.Results{
+			{Instance: data.Labels{"instance": "a"}, State: eval.Alerting, EvaluatedAt: evaluatedAt},
+			{Instance: data.Labels{"instance": "b"}, State: b, EvaluatedAt: evaluatedAt},
+		}
+	}
+	listInstances := func() map[string]*models.AlertInstance {
+		q := models.ListAlertInstancesQuery{RuleOrgID: mainOrgID, RuleUID: rule.UID}
+		require.NoError(t, dbstore.ListAlertInstances(ctx, &q))
+		result := make(map[string]*models.AlertInstance, len(q.Result))
+		for _, inst := range q.Result {
+			result[inst.Labels["instance"]] = inst
+		}
+		return result
+	}
+	statesByInstance := func(st *state.Manager) map[string]*state.State {
+		result := map[string]*state.State{}
+		for _, s := range st.GetStatesForRuleUID(mainOrgID, rule.UID) {
+			result[s.Labels["instance"]] = s
+		}
+		return result
+	}
+
+	snapshots := &state.FakeSnapshotStore{}
+	clk := clock.NewMock()
+	st := state.NewManager(testMetrics.GetStateMetrics(), nil, dbstore, &state.NoopImageService{}, clk, &state.FakeHistorian{})
+	st.EnableSnapshots(snapshots, time.Minute)
+	st.Warm(ctx, dbstore)
+
+	_ = st.ProcessEvalResults(ctx, t0, rule, results(t0, eval.Normal), data.Labels{"alertname": rule.Title})
+	_ = st.ProcessEvalResults(ctx, t1, rule, results(t1, eval.Normal), data.Labels{"alertname": rule.Title})
+
+	t.Run("only the instances that are new or changed are written", func(t *testing.T) {
+		instances := listInstances()
+		require.Len(t, instances, 2)
+		require.Equal(t, t0.Unix(), instances["a"].LastEvalTime.Unix())
+		require.Equal(t, t0.Unix(), instances["b"].LastEvalTime.Unix())
+	})
+
+	clk.Set(t1)
+	st.SaveSnapshots(ctx)
+	require.Contains(t, snapshots.Snapshots, mainOrgID)
+
+	_ = st.ProcessEvalResults(ctx, t2, rule, results(t2, eval.Alerting), data.Labels{"alertname": rule.Title})
+
+	t.Run("Warm restores the snapshot and the instances that changed after it", func(t *testing.T) {
+		restored := state.NewManager(testMetrics.GetStateMetrics(), nil, dbstore, &state.NoopImageService{}, clock.NewMock(), &state.FakeHistorian{})
+		restored.EnableSnapshots(snapshots, time.Minute)
+		restored.Warm(ctx, dbstore)
+
+		states := statesByInstance(restored)
+		require.Len(t, states, 2)
+		require.Equal(t, eval.Alerting, states["a"].State)
+		require.Equal(t, t1.Unix(), states["a"].LastEvaluationTime.Unix())
+		require.Equal(t, t0.Unix(), states["a"].StartsAt.Unix())
+		require.Equal(t, rule.Annotations, states["a"].Annotations)
+		require.Equal(t, eval.Alerting, states["b"].State)
+		require.Equal(t, t2.Unix(), states["b"].LastEvaluationTime.Unix())
+	})
+
+	t.Run("Warm loads all instances when the snapshot cannot be read", func(t *testing.T) {
+		broken := &state.FakeSnapshotStore{Snapshots: map[int64][]byte{mainOrgID: []byte("not a snapshot")}}
+		restored := state.NewManager(testMetrics.GetStateMetrics(), nil, dbstore, &state.NoopImageService{}, clock.NewMock(), &state.FakeHistorian{})
+		restored.EnableSnapshots(broken, time.Minute)
+		restored.Warm(ctx, dbstore)
+
+		states := statesByInstance(restored)
+		require.Len(t, states, 2)
+		require.Equal(t, t0.Unix(), states["a"].LastEvaluationTime.Unix())
+		require.Equal(t, t2.Unix(), states["b"].LastEvaluationTime.Unix())
+	})
+
+	t.Run("Warm drops the states of the snapshot that were deleted after it", func(t *testing.T) {
+		st.SaveSnapshots(ctx)
+		_ = st.ResetStateByRuleUID(ctx, rule.GetKey())
+
+		restored := state.NewManager(testMetrics.GetStateMetrics(), nil, dbstore, &state.NoopImageService{}, clock.NewMock(), &state.FakeHistorian{})
+		restored.EnableSnapshots(snapshots, time.Minute)
+		restored.Warm(ctx, dbstore)
+
+		require.Empty(t, statesByInstance(restored))
+	})
+}
+
+func BenchmarkProcessEvalResults(b *testing.B) {
+	ctx := context.Background()
+	rule := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(time.Minute))()
+
+	for _, instancesCount := range []int{100, 1000} {
+		results := make(eval.Results, 0, instancesCount)
+		for i := 0; i < instancesCount; i++ {
+			results = append(results, eval.Result{
+				Instance: data.Labels{"instance": fmt.Sprintf("instance-%d", i)},
+				State:    eval.Alerting,
+			})
+		}
+		for _, snapshots := range []bool{false, true} {
+			b.Run(fmt.Sprintf("instances=%d/snapshots=%t", instancesCount, snapshots), func(b *testing.B) {
+				st := state.NewManager(testMetrics.GetStateMetrics(), nil, &state.FakeInstanceStore{}, &state.NoopImageService{}, clock.NewMock(), &state.FakeHistorian{})
+				if snapshots {
+					st.EnableSnapshots(&state.FakeSnapshotStore{}, time.Minute)
+				}
+				evaluatedAt := time.Now()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					evaluatedAt = evaluatedAt.Add(time.Minute)
+					for j := range results {
+						results[j].EvaluatedAt = evaluatedAt
+					}
+					_ = st.ProcessEvalResults(ctx, evaluatedAt, rule, results, nil)
+				}
+			})
+		}
+	}
+}
@@ -145,6 +145,9 @@ type StateTransition struct {
 	*State
 	PreviousState       eval.State
 	PreviousStateReason string
+
+	// created is true if the state did not exist before the transition.
+	created bool
 }
 
 func (c StateTransition) Formatted() string {
@@ -27,6 +27,23 @@ func (f *FakeInstanceStore) ListAlertInstances(_ context.Context, q *models.List
 	return nil
 }
 
+// ListQueries returns the recorded queries of ListAlertInstances.
+func (f *FakeInstanceStore) ListQueries() []models.ListAlertInstancesQuery {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var result []models.ListAlertInstancesQuery
+	for _, op := range f.RecordedOps {
+		if q, ok := op.(models.ListAlertInstancesQuery); ok {
+			result = append(result, q)
+		}
+	}
+	return result
+}
+
+func (f *FakeInstanceStore) ListAlertInstanceKeys(_ context.Context, _ int64) ([]models.AlertInstanceKey, error) {
+	return nil, nil
+}
+
 func (f *FakeInstanceStore) SaveAlertInstances(_ context.Context, q ...models.AlertInstance) error {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
@@ -78,3 +95,27 @@ type NoopImageService struct{}
 func (s *NoopImageService) NewImage(_ context.Context, _ *models.AlertRule) (*models.Image, error) {
 	return &models.Image{}, nil
 }
+
+var _ SnapshotStore = &FakeSnapshotStore{}
+
+type FakeSnapshotStore struct {
+	mtx       sync.Mutex
+	Snapshots map[int64][]byte
+}
+
+func (f *FakeSnapshotStore) GetSnapshot(_ context.Context, orgID int64) ([]byte, bool, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	b, ok := f.Snapshots[orgID]
+	return b, ok, nil
+}
+
+func (f *FakeSnapshotStore) SaveSnapshot(_ context.Context, orgID int64, snapshot []byte) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.Snapshots == nil {
+		f.Snapshots = make(map[int64][]byte)
+	}
+	f.Snapshots[orgID] = snapshot
+	return nil
+}
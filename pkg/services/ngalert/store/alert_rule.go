@@ -149,6 +149,9 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				For:              r.For,
 				Annotations:      r.Annotations,
 				Labels:           r.Labels,
+				Record:           r.Record,
+				IsPaused:         r.IsPaused,
+				Enrichments:      r.Enrichments,
 			})
 		}
 		if len(newRules) > 0 {
@@ -217,6 +220,9 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				For:              r.New.For,
 				Annotations:      r.New.Annotations,
 				Labels:           r.New.Labels,
+				Record:           r.New.Record,
+				IsPaused:         r.New.IsPaused,
+				Enrichments:      r.New.Enrichments,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -401,6 +407,38 @@ func (st DBstore) GetNamespaceByUID(ctx context.Context, uid string, orgID int64
 	return folder, nil
 }
 
+// GetNamespaceTitlesByUID returns the titles of the namespaces with the given UIDs, keyed by UID.
+// Unlike the other namespace getters it does not check the permissions of a user.
+// Namespaces that do not exist are not part of the result.
+func (st DBstore) GetNamespaceTitlesByUID(ctx context.Context, orgID int64, uids ...string) (map[string]string, error) {
+	result := make(map[string]string, len(uids))
+	if len(uids) == 0 {
+		return result, nil
+	}
+	var folders []struct {
+		Uid   string
+		Title string
+	}
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		args := make([]interface{}, 0, len(uids))
+		for _, uid := range uids {
+			args = append(args, uid)
+		}
+		return sess.Table("dashboard").
+			Cols("uid", "title").
+			Where("org_id = ? AND is_folder = ?", orgID, st.SQLStore.GetDialect().BooleanStr(true)).
+			In("uid", args...).
+			Find(&folders)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch titles of namespaces: %w", err)
+	}
+	for _, f := range folders {
+		result[f.Uid] = f.Title
+	}
+	return result, nil
+}
+
 func (st DBstore) getFilterByOrgsString() (string, []interface{}) {
 	if len(st.Cfg.DisabledOrgs) == 0 {
 		return "", nil
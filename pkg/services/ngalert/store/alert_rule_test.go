@@ -12,6 +12,7 @@ import (
 	"golang.org/x/exp/rand"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	grafana_models "github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
@@ -66,6 +67,85 @@ func TestIntegrationUpdateAlertRules(t *testing.T) {
 
 		require.ErrorIs(t, err, ErrOptimisticLock)
 	})
+
+	t.Run("should store the record of recording rules", func(t *testing.T) {
+		rule := createRule(t, store)
+		getRule := func() *models.AlertRule {
+			dbrule := &models.AlertRule{}
+			err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+				_, err := sess.Table(models.AlertRule{}).ID(rule.ID).Get(dbrule)
+				return err
+			})
+			require.NoError(t, err)
+			return dbrule
+		}
+		require.Nil(t, getRule().Record)
+
+		recording := models.CopyRule(rule)
+		recording.Record = &models.Record{Metric: "job:up:sum", From: recording.Condition, Target: models.LiveRecordTarget}
+		err := store.UpdateAlertRules(context.Background(), []models.UpdateRule{{Existing: rule, New: *recording}})
+		require.NoError(t, err)
+		stored := getRule()
+		require.Equal(t, recording.Record, stored.Record)
+
+		alerting := models.CopyRule(stored)
+		alerting.Record = nil
+		err = store.UpdateAlertRules(context.Background(), []models.UpdateRule{{Existing: stored, New: *alerting}})
+		require.NoError(t, err)
+		require.Nil(t, getRule().Record)
+	})
+
+	t.Run("should store whether the rule is paused", func(t *testing.T) {
+		rule := createRule(t, store)
+		getRule := func() *models.AlertRule {
+			dbrule := &models.AlertRule{}
+			err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+				_, err := sess.Table(models.AlertRule{}).ID(rule.ID).Get(dbrule)
+				return err
+			})
+			require.NoError(t, err)
+			return dbrule
+		}
+		require.False(t, getRule().IsPaused)
+
+		paused := models.CopyRule(rule)
+		paused.IsPaused = true
+		err := store.UpdateAlertRules(context.Background(), []models.UpdateRule{{Existing: rule, New: *paused}})
+		require.NoError(t, err)
+		stored := getRule()
+		require.True(t, stored.IsPaused)
+
+		resumed := models.CopyRule(stored)
+		resumed.IsPaused = false
+		err = store.UpdateAlertRules(context.Background(), []models.UpdateRule{{Existing: stored, New: *resumed}})
+		require.NoError(t, err)
+		require.False(t, getRule().IsPaused)
+	})
+
+	t.Run("should store the enrichments of the rule", func(t *testing.T) {
+		rule := createRule(t, store)
+		getRule := func() *models.AlertRule {
+			dbrule := &models.AlertRule{}
+			err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+				_, err := sess.Table(models.AlertRule{}).ID(rule.ID).Get(dbrule)
+				return err
+			})
+			require.NoError(t, err)
+			return dbrule
+		}
+		require.Empty(t, getRule().Enrichments)
+
+		enriched := models.CopyRule(rule)
+		enriched.Enrichments = []models.Enrichment{{
+			Type:   models.TableEnrichmentType,
+			Keys:   []string{"service"},
+			Labels: map[string]string{"owner": "team"},
+			Rows:   []map[string]string{{"service": "api", "team": "backend"}},
+		}}
+		err := store.UpdateAlertRules(context.Background(), []models.UpdateRule{{Existing: rule, New: *enriched}})
+		require.NoError(t, err)
+		require.Equal(t, enriched.Enrichments, getRule().Enrichments)
+	})
 }
 
 func withIntervalMatching(baseInterval time.Duration) func(*models.AlertRule) {
@@ -175,6 +255,57 @@ func TestIntegration_CountAlertRules(t *testing.T) {
 	}
 }
 
+func TestIntegration_GetNamespaceTitlesByUID(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	store := &DBstore{SQLStore: sqlStore}
+	orgID := rand.Int63()
+	folder1 := createFolder(t, store, orgID, "folder-1")
+	folder2 := createFolder(t, store, orgID, "folder-2")
+	_ = createFolder(t, store, orgID+1, "folder-in-another-org")
+
+	t.Run("should return titles of requested folders", func(t *testing.T) {
+		titles, err := store.GetNamespaceTitlesByUID(context.Background(), orgID, folder1.Uid, folder2.Uid)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			folder1.Uid: folder1.Title,
+			folder2.Uid: folder2.Title,
+		}, titles)
+	})
+
+	t.Run("should skip unknown folders and folders of other orgs", func(t *testing.T) {
+		titles, err := store.GetNamespaceTitlesByUID(context.Background(), orgID, folder1.Uid, "folder-in-another-org", "unknown")
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			folder1.Uid: folder1.Title,
+		}, titles)
+	})
+
+	t.Run("should return empty map if no UIDs are given", func(t *testing.T) {
+		titles, err := store.GetNamespaceTitlesByUID(context.Background(), orgID)
+		require.NoError(t, err)
+		require.Empty(t, titles)
+	})
+}
+
+func createFolder(t *testing.T, store *DBstore, orgID int64, uid string) *grafana_models.Dashboard {
+	t.Helper()
+	folder := grafana_models.NewDashboardFolder("Title of " + uid)
+	folder.Uid = uid
+	folder.OrgId = orgID
+	folder.Created = time.Now()
+	folder.Updated = time.Now()
+	err := store.SQLStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+		_, err := sess.Insert(folder)
+		return err
+	})
+	require.NoError(t, err)
+	return folder
+}
+
 func createRule(t *testing.T, store *DBstore) *models.AlertRule {
 	rule := models.AlertRuleGen(withIntervalMatching(store.Cfg.BaseInterval))()
 	err := store.SQLStore.WithDbSession(context.Background(), func(sess *db.Session) error {
@@ -39,6 +39,10 @@ func (st DBstore) ListAlertInstances(ctx context.Context, cmd *models.ListAlertI
 			addToQuery(` AND current_reason = ?`, cmd.StateReason)
 		}
 
+		if !cmd.EvaluatedAfter.IsZero() {
+			addToQuery(` AND last_eval_time > ?`, cmd.EvaluatedAfter.Unix())
+		}
+
 		if err := sess.SQL(s.String(), params...).Find(&alertInstances); err != nil {
 			return err
 		}
@@ -48,6 +52,15 @@ func (st DBstore) ListAlertInstances(ctx context.Context, cmd *models.ListAlertI
 	})
 }
 
+// ListAlertInstanceKeys returns the keys of all alert instances of the organization.
+func (st DBstore) ListAlertInstanceKeys(ctx context.Context, orgID int64) ([]models.AlertInstanceKey, error) {
+	keys := make([]models.AlertInstanceKey, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT rule_org_id, rule_uid, labels_hash FROM alert_instance WHERE rule_org_id = ?", orgID).Find(&keys)
+	})
+	return keys, err
+}
+
 // SaveAlertInstances saves all the provided alert instances to the store.
 func (st DBstore) SaveAlertInstances(ctx context.Context, cmd ...models.AlertInstance) error {
 	if !st.FeatureToggles.IsEnabled(featuremgmt.FlagAlertingBigTransactions) {
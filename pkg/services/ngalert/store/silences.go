@@ -0,0 +1,178 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const (
+	silencesKVNamespace   = "alerting.silences"
+	silenceTemplatesKVKey = "templates"
+)
+
+// ErrRecurringSilenceExists is returned when a recurring silence is inserted with the name of another one.
+var ErrRecurringSilenceExists = errors.New("a recurring silence with this name already exists")
+
+// SilenceStore is the database interface of the silence templates and the recurring silences of an organization.
+// The recurring silences are stored one by one, so that concurrent changes of different recurring silences do not
+// overwrite each other.
+type SilenceStore interface {
+	GetSilenceTemplates(ctx context.Context, orgID int64) ([]definitions.SilenceTemplate, error)
+	SaveSilenceTemplates(ctx context.Context, orgID int64, templates []definitions.SilenceTemplate) error
+	GetRecurringSilences(ctx context.Context, orgID int64) ([]definitions.RecurringSilence, error)
+	InsertRecurringSilence(ctx context.Context, orgID int64, s definitions.RecurringSilence) error
+	UpdateRecurringSilence(ctx context.Context, orgID int64, s definitions.RecurringSilence) (bool, error)
+	DeleteRecurringSilence(ctx context.Context, orgID int64, name string) (bool, error)
+}
+
+// GetSilenceTemplates returns the silence templates of the organization.
+func (st DBstore) GetSilenceTemplates(ctx context.Context, orgID int64) ([]definitions.SilenceTemplate, error) {
+	var templates []definitions.SilenceTemplate
+	if err := st.getSilencesValue(ctx, orgID, silenceTemplatesKVKey, &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// SaveSilenceTemplates replaces the silence templates of the organization. The provenance is not stored.
+func (st DBstore) SaveSilenceTemplates(ctx context.Context, orgID int64, templates []definitions.SilenceTemplate) error {
+	stored := make([]definitions.SilenceTemplate, 0, len(templates))
+	for _, t := range templates {
+		t.Provenance = ""
+		stored = append(stored, t)
+	}
+	return st.setSilencesValue(ctx, orgID, silenceTemplatesKVKey, stored)
+}
+
+type recurringSilenceRecord struct {
+	ID      int64  `xorm:"pk autoincr 'id'"`
+	OrgID   int64  `xorm:"org_id"`
+	Name    string `xorm:"name"`
+	Silence string `xorm:"silence"`
+}
+
+func (r recurringSilenceRecord) TableName() string {
+	return "alert_recurring_silence"
+}
+
+// GetRecurringSilences returns the recurring silences of the organization in the order they were created.
+func (st DBstore) GetRecurringSilences(ctx context.Context, orgID int64) ([]definitions.RecurringSilence, error) {
+	var records []recurringSilenceRecord
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("id").Find(&records)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recurring silences: %w", err)
+	}
+	silences := make([]definitions.RecurringSilence, 0, len(records))
+	for _, r := range records {
+		var s definitions.RecurringSilence
+		if err := json.Unmarshal([]byte(r.Silence), &s); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal recurring silence '%s': %w", r.Name, err)
+		}
+		silences = append(silences, s)
+	}
+	return silences, nil
+}
+
+// InsertRecurringSilence adds a recurring silence to the organization. It returns ErrRecurringSilenceExists if the
+// organization has a recurring silence with the same name. The provenance is not stored.
+func (st DBstore) InsertRecurringSilence(ctx context.Context, orgID int64, s definitions.RecurringSilence) error {
+	record, err := newRecurringSilenceRecord(orgID, s)
+	if err != nil {
+		return err
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND name = ?", orgID, s.Name).Exist(&recurringSilenceRecord{})
+		if err != nil {
+			return fmt.Errorf("failed to check the recurring silence: %w", err)
+		}
+		if exists {
+			return ErrRecurringSilenceExists
+		}
+		if _, err := sess.Insert(&record); err != nil {
+			return fmt.Errorf("failed to insert the recurring silence: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateRecurringSilence replaces the recurring silence of the organization with the same name. It returns false if
+// the recurring silence does not exist. The provenance is not stored.
+func (st DBstore) UpdateRecurringSilence(ctx context.Context, orgID int64, s definitions.RecurringSilence) (bool, error) {
+	record, err := newRecurringSilenceRecord(orgID, s)
+	if err != nil {
+		return false, err
+	}
+	updated := false
+	err = st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		// the existence is checked first because some databases do not count the rows that are not changed
+		exists, err := sess.Where("org_id = ? AND name = ?", orgID, s.Name).Exist(&recurringSilenceRecord{})
+		if err != nil || !exists {
+			return err
+		}
+		if _, err := sess.Where("org_id = ? AND name = ?", orgID, s.Name).Cols("silence").Update(&record); err != nil {
+			return fmt.Errorf("failed to update the recurring silence: %w", err)
+		}
+		updated = true
+		return nil
+	})
+	return updated, err
+}
+
+// DeleteRecurringSilence deletes the recurring silence of the organization with the given name. It returns false if
+// the recurring silence does not exist.
+func (st DBstore) DeleteRecurringSilence(ctx context.Context, orgID int64, name string) (bool, error) {
+	deleted := false
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		rows, err := sess.Where("org_id = ? AND name = ?", orgID, name).Delete(&recurringSilenceRecord{})
+		if err != nil {
+			return fmt.Errorf("failed to delete the recurring silence: %w", err)
+		}
+		deleted = rows > 0
+		return nil
+	})
+	return deleted, err
+}
+
+func newRecurringSilenceRecord(orgID int64, s definitions.RecurringSilence) (recurringSilenceRecord, error) {
+	s.Provenance = ""
+	b, err := json.Marshal(s)
+	if err != nil {
+		return recurringSilenceRecord{}, fmt.Errorf("failed to marshal the recurring silence: %w", err)
+	}
+	return recurringSilenceRecord{OrgID: orgID, Name: s.Name, Silence: string(b)}, nil
+}
+
+func (st DBstore) getSilencesValue(ctx context.Context, orgID int64, key string, v interface{}) error {
+	kv := kvstore.WithNamespace(kvstore.ProvideService(st.SQLStore), orgID, silencesKVNamespace)
+	content, exists, err := kv.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to get %s silences: %w", key, err)
+	}
+	if !exists {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(content), v); err != nil {
+		return fmt.Errorf("failed to unmarshal %s silences: %w", key, err)
+	}
+	return nil
+}
+
+func (st DBstore) setSilencesValue(ctx context.Context, orgID int64, key string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s silences: %w", key, err)
+	}
+	kv := kvstore.WithNamespace(kvstore.ProvideService(st.SQLStore), orgID, silencesKVNamespace)
+	if err := kv.Set(ctx, key, string(b)); err != nil {
+		return fmt.Errorf("failed to save %s silences: %w", key, err)
+	}
+	return nil
+}
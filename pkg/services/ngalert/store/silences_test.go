@@ -0,0 +1,83 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationRecurringSilences(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	_, dbstore := tests.SetupTestEnv(t, testAlertingIntervalSeconds)
+	ctx := context.Background()
+	silence := func(name, comment string) definitions.RecurringSilence {
+		return definitions.RecurringSilence{
+			Name:       name,
+			Matchers:   definitions.ObjectMatchers{{Type: labels.MatchEqual, Name: "job", Value: name}},
+			Schedule:   "0 1 * * *",
+			Comment:    comment,
+			Provenance: models.ProvenanceAPI,
+		}
+	}
+
+	require.NoError(t, dbstore.InsertRecurringSilence(ctx, 1, silence("nightly-batch", "")))
+	require.NoError(t, dbstore.InsertRecurringSilence(ctx, 1, silence("weekly-backup", "")))
+	require.NoError(t, dbstore.InsertRecurringSilence(ctx, 2, silence("nightly-batch", "")))
+
+	t.Run("rejects a recurring silence with the name of another one", func(t *testing.T) {
+		err := dbstore.InsertRecurringSilence(ctx, 1, silence("nightly-batch", ""))
+		require.ErrorIs(t, err, store.ErrRecurringSilenceExists)
+	})
+
+	t.Run("updates only the recurring silence with the name", func(t *testing.T) {
+		updated, err := dbstore.UpdateRecurringSilence(ctx, 1, silence("nightly-batch", "updated"))
+		require.NoError(t, err)
+		require.True(t, updated)
+		// a change that does not change the stored silence is still an update
+		updated, err = dbstore.UpdateRecurringSilence(ctx, 1, silence("nightly-batch", "updated"))
+		require.NoError(t, err)
+		require.True(t, updated)
+
+		silences, err := dbstore.GetRecurringSilences(ctx, 1)
+		require.NoError(t, err)
+		expected := silence("nightly-batch", "updated")
+		expected.Provenance = ""
+		require.Len(t, silences, 2)
+		require.Equal(t, expected, silences[0])
+		require.Equal(t, "weekly-backup", silences[1].Name)
+		require.Empty(t, silences[1].Comment)
+		other, err := dbstore.GetRecurringSilences(ctx, 2)
+		require.NoError(t, err)
+		require.Empty(t, other[0].Comment)
+
+		updated, err = dbstore.UpdateRecurringSilence(ctx, 1, silence("unknown", ""))
+		require.NoError(t, err)
+		require.False(t, updated)
+	})
+
+	t.Run("deletes only the recurring silence with the name", func(t *testing.T) {
+		deleted, err := dbstore.DeleteRecurringSilence(ctx, 1, "nightly-batch")
+		require.NoError(t, err)
+		require.True(t, deleted)
+		deleted, err = dbstore.DeleteRecurringSilence(ctx, 1, "nightly-batch")
+		require.NoError(t, err)
+		require.False(t, deleted)
+
+		silences, err := dbstore.GetRecurringSilences(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, silences, 1)
+		require.Equal(t, "weekly-backup", silences[0].Name)
+		other, err := dbstore.GetRecurringSilences(ctx, 2)
+		require.NoError(t, err)
+		require.Len(t, other, 1)
+	})
+}
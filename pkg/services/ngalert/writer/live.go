@@ -0,0 +1,76 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// LiveNamespace is the namespace of the Grafana Live streams the recording rules publish to.
+const LiveNamespace = "recording_rules"
+
+// StreamRunner returns the managed streams of Grafana Live.
+type StreamRunner interface {
+	GetOrCreateStream(orgID int64, scope string, namespace string) (*managedstream.NamespaceStream, error)
+}
+
+// LiveWriter publishes the samples to the Grafana Live channel stream/recording_rules/<metric>
+// of the organization. Colons in the metric name are replaced by underscores as they are not
+// allowed in channel paths.
+type LiveWriter struct {
+	runner StreamRunner
+}
+
+func NewLiveWriter(runner StreamRunner) *LiveWriter {
+	return &LiveWriter{runner: runner}
+}
+
+func (w *LiveWriter) Write(ctx context.Context, orgID int64, record models.Record, t time.Time, samples []Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	stream, err := w.runner.GetOrCreateStream(orgID, live.ScopeStream, LiveNamespace)
+	if err != nil {
+		return fmt.Errorf("failed to get stream: %w", err)
+	}
+	if err := stream.Push(ctx, LivePath(record.Metric), samplesToFrame(record.Metric, t, samples)); err != nil {
+		return fmt.Errorf("failed to push to stream: %w", err)
+	}
+	return nil
+}
+
+// LivePath returns the path of the channel that the series of the metric are published to.
+func LivePath(metric string) string {
+	return strings.ReplaceAll(metric, ":", "_")
+}
+
+// samplesToFrame returns a frame in the labels column format of Grafana Live, with a row for each sample.
+func samplesToFrame(metric string, t time.Time, samples []Sample) *data.Frame {
+	sorted := make([]Sample, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Labels.String() < sorted[j].Labels.String()
+	})
+
+	labels := make([]string, 0, len(sorted))
+	times := make([]time.Time, 0, len(sorted))
+	values := make([]float64, 0, len(sorted))
+	for _, s := range sorted {
+		labels = append(labels, s.Labels.String())
+		times = append(times, t)
+		values = append(values, s.Value)
+	}
+	return data.NewFrame(metric,
+		data.NewField("labels", nil, labels),
+		data.NewField("time", nil, times),
+		data.NewField("value", nil, values),
+	)
+}
@@ -0,0 +1,50 @@
+package writer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type publishedFrame struct {
+	orgID   int64
+	channel string
+	data    []byte
+}
+
+func TestLiveWriter(t *testing.T) {
+	var published []publishedFrame
+	runner := managedstream.NewRunner(func(orgID int64, channel string, data []byte) error {
+		published = append(published, publishedFrame{orgID: orgID, channel: channel, data: data})
+		return nil
+	}, nil, managedstream.NewMemoryFrameCache())
+
+	w := NewLiveWriter(runner)
+	record := models.Record{Metric: "job:up:sum", From: "A", Target: models.LiveRecordTarget}
+	at := time.Unix(1000, 0)
+	err := w.Write(context.Background(), 2, record, at, []Sample{
+		{Labels: data.Labels{"job": "web"}, Value: 2},
+		{Labels: data.Labels{"job": "api"}, Value: 1},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, published, 1)
+	require.Equal(t, int64(2), published[0].orgID)
+	require.Equal(t, "stream/recording_rules/job_up_sum", published[0].channel)
+
+	var frame data.Frame
+	require.NoError(t, json.Unmarshal(published[0].data, &frame))
+	require.Equal(t, "job:up:sum", frame.Name)
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, "job=api", frame.Fields[0].At(0))
+	require.True(t, at.Equal(frame.Fields[1].At(0).(time.Time)))
+	require.Equal(t, 1.0, frame.Fields[2].At(0))
+	require.Equal(t, "job=web", frame.Fields[0].At(1))
+}
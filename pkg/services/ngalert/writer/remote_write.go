@@ -0,0 +1,99 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// RemoteWriter writes the samples to a Prometheus remote write endpoint.
+type RemoteWriter struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+	logger   log.Logger
+}
+
+// NewRemoteWriter returns a writer for the remote write endpoint of the settings.
+func NewRemoteWriter(cfg setting.UnifiedAlertingRecordingRulesSettings, logger log.Logger) *RemoteWriter {
+	return &RemoteWriter{
+		url:      cfg.RemoteWriteURL,
+		username: cfg.RemoteWriteBasicAuthUsername,
+		password: cfg.RemoteWriteBasicAuthPassword,
+		client:   &http.Client{Timeout: cfg.RemoteWriteTimeout},
+		logger:   logger,
+	}
+}
+
+func (w *RemoteWriter) Write(ctx context.Context, orgID int64, record models.Record, t time.Time, samples []Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	series := make([]prompb.TimeSeries, 0, len(samples))
+	for _, s := range samples {
+		series = append(series, prompb.TimeSeries{
+			Labels:  promLabels(record.Metric, s),
+			Samples: []prompb.Sample{{Value: s.Value, Timestamp: t.UnixMilli()}},
+		})
+	}
+	body, err := remotewrite.TimeSeriesToBytes(series)
+	if err != nil {
+		return fmt.Errorf("failed to encode series: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.username != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send remote write request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			w.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("remote write endpoint returned status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	w.logger.Debug("Wrote series to remote write endpoint", "org_id", orgID, "metric", record.Metric, "series", len(series))
+	return nil
+}
+
+// promLabels returns the labels of the sample and the metric name, sorted by name as remote write requires.
+func promLabels(metric string, s Sample) []prompb.Label {
+	labels := make([]prompb.Label, 0, len(s.Labels)+1)
+	labels = append(labels, prompb.Label{Name: "__name__", Value: metric})
+	for k, v := range s.Labels {
+		if k == "__name__" {
+			continue
+		}
+		labels = append(labels, prompb.Label{Name: k, Value: v})
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].Name < labels[j].Name
+	})
+	return labels
+}
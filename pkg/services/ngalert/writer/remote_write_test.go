@@ -0,0 +1,82 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRemoteWriter(t *testing.T) {
+	record := models.Record{Metric: "job:up:sum", From: "A", Target: models.RemoteWriteRecordTarget}
+	at := time.Unix(1000, 0)
+
+	t.Run("writes a series for each sample", func(t *testing.T) {
+		received := make(chan prompb.WriteRequest, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+			require.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+			user, password, ok := r.BasicAuth()
+			require.True(t, ok)
+			require.Equal(t, "user", user)
+			require.Equal(t, "secret", password)
+
+			compressed, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			body, err := snappy.Decode(nil, compressed)
+			require.NoError(t, err)
+			var req prompb.WriteRequest
+			require.NoError(t, proto.Unmarshal(body, &req))
+			w.WriteHeader(http.StatusNoContent)
+			received <- req
+		}))
+		t.Cleanup(srv.Close)
+
+		w := NewRemoteWriter(setting.UnifiedAlertingRecordingRulesSettings{
+			RemoteWriteURL:               srv.URL,
+			RemoteWriteBasicAuthUsername: "user",
+			RemoteWriteBasicAuthPassword: "secret",
+			RemoteWriteTimeout:           time.Second,
+		}, log.NewNopLogger())
+		err := w.Write(context.Background(), 1, record, at, []Sample{
+			{Labels: data.Labels{"job": "api", "__name__": "ignored"}, Value: 3},
+			{Labels: data.Labels{}, Value: 4},
+		})
+		require.NoError(t, err)
+
+		req := <-received
+		require.Equal(t, []prompb.TimeSeries{
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "job:up:sum"}, {Name: "job", Value: "api"}},
+				Samples: []prompb.Sample{{Value: 3, Timestamp: 1000000}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "job:up:sum"}},
+				Samples: []prompb.Sample{{Value: 4, Timestamp: 1000000}},
+			},
+		}, req.Timeseries)
+	})
+
+	t.Run("returns the error of the endpoint", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "out of order sample", http.StatusBadRequest)
+		}))
+		t.Cleanup(srv.Close)
+
+		w := NewRemoteWriter(setting.UnifiedAlertingRecordingRulesSettings{RemoteWriteURL: srv.URL, RemoteWriteTimeout: time.Second}, log.NewNopLogger())
+		err := w.Write(context.Background(), 1, record, at, []Sample{{Value: 1}})
+		require.ErrorContains(t, err, "status 400: out of order sample")
+	})
+}
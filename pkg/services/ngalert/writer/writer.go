@@ -0,0 +1,69 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// Sample is the value of a series recorded by a recording rule at the time of the evaluation.
+type Sample struct {
+	Labels data.Labels
+	Value  float64
+}
+
+// Writer writes the samples of a recording rule to its target.
+type Writer interface {
+	Write(ctx context.Context, orgID int64, record models.Record, t time.Time, samples []Sample) error
+}
+
+// TargetWriter writes the samples to the writer of the target of the record.
+type TargetWriter struct {
+	writers map[models.RecordTarget]Writer
+}
+
+// NewTargetWriter returns a writer that routes the samples by the target of the record.
+// Targets with a nil writer are not configured and fail to write.
+func NewTargetWriter(remoteWrite Writer, live Writer) *TargetWriter {
+	writers := make(map[models.RecordTarget]Writer, 2)
+	if remoteWrite != nil {
+		writers[models.RemoteWriteRecordTarget] = remoteWrite
+	}
+	if live != nil {
+		writers[models.LiveRecordTarget] = live
+	}
+	return &TargetWriter{writers: writers}
+}
+
+func (w *TargetWriter) Write(ctx context.Context, orgID int64, record models.Record, t time.Time, samples []Sample) error {
+	writer, ok := w.writers[record.Target]
+	if !ok {
+		return fmt.Errorf("record target %s is not configured", record.Target)
+	}
+	return writer.Write(ctx, orgID, record, t, samples)
+}
+
+// SamplesFromFrames returns a sample for each numeric field of the frames, with the labels of the field
+// and its last value. Fields without a value, or whose last value is null or NaN, are skipped.
+func SamplesFromFrames(frames data.Frames) []Sample {
+	var samples []Sample
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() || field.Len() == 0 {
+				continue
+			}
+			// FloatAt returns NaN for null values
+			v, err := field.FloatAt(field.Len() - 1)
+			if err != nil || math.IsNaN(v) {
+				continue
+			}
+			samples = append(samples, Sample{Labels: field.Labels.Copy(), Value: v})
+		}
+	}
+	return samples
+}
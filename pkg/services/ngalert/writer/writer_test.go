@@ -0,0 +1,54 @@
+package writer
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeWriter struct {
+	writes []models.Record
+}
+
+func (f *fakeWriter) Write(_ context.Context, _ int64, record models.Record, _ time.Time, _ []Sample) error {
+	f.writes = append(f.writes, record)
+	return nil
+}
+
+func TestTargetWriter(t *testing.T) {
+	remote := &fakeWriter{}
+	w := NewTargetWriter(remote, nil)
+
+	record := models.Record{Metric: "up", From: "A", Target: models.RemoteWriteRecordTarget}
+	require.NoError(t, w.Write(context.Background(), 1, record, time.Now(), nil))
+	require.Equal(t, []models.Record{record}, remote.writes)
+
+	err := w.Write(context.Background(), 1, models.Record{Metric: "up", From: "A", Target: models.LiveRecordTarget}, time.Now(), nil)
+	require.ErrorContains(t, err, "record target live is not configured")
+}
+
+func TestSamplesFromFrames(t *testing.T) {
+	one, nan := 1.0, math.NaN()
+	frames := data.Frames{
+		data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.Unix(0, 0), time.Unix(60, 0)}),
+			data.NewField("value", data.Labels{"host": "a"}, []float64{5, 6}),
+		),
+		data.NewFrame("", data.NewField("B", data.Labels{"host": "b"}, []*float64{&one})),
+		data.NewFrame("", data.NewField("B", data.Labels{"host": "c"}, []*float64{nil})),
+		data.NewFrame("", data.NewField("B", data.Labels{"host": "d"}, []*float64{&nan})),
+		data.NewFrame("", data.NewField("B", data.Labels{"host": "e"}, []float64{})),
+		data.NewFrame("", data.NewField("name", nil, []string{"f"})),
+	}
+
+	require.Equal(t, []Sample{
+		{Labels: data.Labels{"host": "a"}, Value: 6},
+		{Labels: data.Labels{"host": "b"}, Value: 1},
+	}, SamplesFromFrames(frames))
+}
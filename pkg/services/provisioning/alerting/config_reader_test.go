@@ -2,10 +2,21 @@ package alerting
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
-	"github.com/grafana/grafana/pkg/infra/log"
+	prometheus "github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 const (
@@ -32,6 +43,7 @@ const (
 	testFileCorrectProperties_t         = "./testdata/templates/correct-properties"
 	testFileCorrectPropertiesWithOrg_t  = "./testdata/templates/correct-properties-with-org"
 	testFileMultipleTs                  = "./testdata/templates/multiple-templates"
+	testFileCorrectPropertiesWithOrg_rs = "./testdata/recurring_silences/correct-properties-with-org"
 )
 
 func TestConfigReader(t *testing.T) {
@@ -158,4 +170,157 @@ func TestConfigReader(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, file[0].Templates, 2)
 	})
+	t.Run("a recurring silences file with correct properties and specific org should not error", func(t *testing.T) {
+		file, err := configReader.readConfig(ctx, testFileCorrectPropertiesWithOrg_rs)
+		require.NoError(t, err)
+		require.Len(t, file[0].RecurringSilences, 1)
+		require.Equal(t, int64(1337), file[0].RecurringSilences[0].OrgID)
+		require.Equal(t, "nightly-batch", file[0].RecurringSilences[0].RecurringSilence.Name)
+		require.Equal(t, model.Duration(2*time.Hour), file[0].RecurringSilences[0].RecurringSilence.Duration)
+		require.Len(t, file[0].RecurringSilences[0].RecurringSilence.Matchers, 1)
+		require.Equal(t, []DeleteRecurringSilence{{OrgID: 1337, Name: "weekly-backup"}}, file[0].DeleteRecurringSilences)
+	})
+}
+
+func TestConfigReader_Export(t *testing.T) {
+	configReader := newRulesConfigReader(log.NewNopLogger())
+	ctx := context.Background()
+
+	var tr timeinterval.TimeRange
+	require.NoError(t, yaml.Unmarshal([]byte(`{start_time: "10:00", end_time: "12:00"}`), &tr))
+	receiver := "receiver"
+	export := definitions.AlertingFileExport{
+		APIVersion: 1,
+		Groups: []definitions.AlertRuleGroupExport{
+			{
+				OrgID:    2,
+				Name:     "my-group",
+				Folder:   "My Folder",
+				Interval: model.Duration(time.Minute),
+				Rules: []definitions.AlertRuleExport{
+					{
+						UID:       "rule-uid",
+						Title:     "my-rule",
+						Condition: "A",
+						Data: []definitions.AlertQueryExport{
+							{
+								RefID:         "A",
+								DatasourceUID: "datasource-uid",
+								Model:         map[string]interface{}{"expr": "up"},
+								RelativeTimeRange: models.RelativeTimeRange{
+									From: models.Duration(10 * time.Minute),
+								},
+							},
+						},
+						DashboardUID: "dashboard-uid",
+						PanelID:      3,
+						NoDataState:  models.OK,
+						ExecErrState: models.OkErrState,
+						For:          model.Duration(5 * time.Minute),
+						Annotations:  map[string]string{"summary": "test"},
+						Labels:       map[string]string{"team": "alerting"},
+						IsPaused:     true,
+					},
+				},
+			},
+		},
+		ContactPoints: []definitions.ContactPointExport{
+			{
+				OrgID: 2,
+				Name:  "my-contact-point",
+				Receivers: []definitions.ReceiverExport{
+					{UID: "cp-uid", Type: "email", Settings: map[string]interface{}{"addresses": "test@example.com"}, DisableResolveMessage: true},
+				},
+			},
+		},
+		Policies: []definitions.NotificationPolicyExport{
+			{
+				OrgID: 2,
+				Route: definitions.Route{
+					Receiver:   receiver,
+					GroupByStr: []string{"alertname"},
+					Routes:     []*definitions.Route{{Receiver: receiver, MuteTimeIntervals: []string{"my-mute-timing"}}},
+				},
+			},
+		},
+		MuteTimes: []definitions.MuteTimeIntervalExport{
+			{
+				OrgID: 2,
+				MuteTimeInterval: prometheus.MuteTimeInterval{
+					Name:          "my-mute-timing",
+					TimeIntervals: []timeinterval.TimeInterval{{Times: []timeinterval.TimeRange{tr}}},
+				},
+			},
+		},
+		Templates: []definitions.MessageTemplateExport{
+			{OrgID: 2, Name: "my-template", Template: "{{ define \"my-template\" }}test{{ end }}"},
+		},
+	}
+
+	yamlData, err := yaml.Marshal(export)
+	require.NoError(t, err)
+	jsonData, err := json.Marshal(export)
+	require.NoError(t, err)
+
+	for name, data := range map[string][]byte{"export.yaml": yamlData, "export.json": jsonData} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0600))
+
+			files, err := configReader.readConfig(ctx, dir)
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+			file := files[0]
+
+			require.Len(t, file.Groups, 1)
+			group := file.Groups[0]
+			require.Equal(t, int64(2), group.OrgID)
+			require.Equal(t, "my-group", group.Name)
+			require.Equal(t, "My Folder", group.Folder)
+			require.Equal(t, time.Minute, group.Interval)
+			require.Len(t, group.Rules, 1)
+			rule := group.Rules[0]
+			require.Equal(t, "rule-uid", rule.UID)
+			require.Equal(t, "my-rule", rule.Title)
+			require.Equal(t, "A", rule.Condition)
+			require.Equal(t, "dashboard-uid", *rule.DashboardUID)
+			require.Equal(t, int64(3), *rule.PanelID)
+			require.Equal(t, models.OK, rule.NoDataState)
+			require.Equal(t, models.OkErrState, rule.ExecErrState)
+			require.Equal(t, 5*time.Minute, rule.For)
+			require.Equal(t, map[string]string{"summary": "test"}, rule.Annotations)
+			require.Equal(t, map[string]string{"team": "alerting"}, rule.Labels)
+			require.True(t, rule.IsPaused)
+			require.Len(t, rule.Data, 1)
+			require.Equal(t, "datasource-uid", rule.Data[0].DatasourceUID)
+			require.Equal(t, models.Duration(10*time.Minute), rule.Data[0].RelativeTimeRange.From)
+			require.JSONEq(t, `{"expr":"up"}`, string(rule.Data[0].Model))
+
+			require.Len(t, file.ContactPoints, 1)
+			require.Equal(t, int64(2), file.ContactPoints[0].OrgID)
+			require.Len(t, file.ContactPoints[0].ContactPoints, 1)
+			cp := file.ContactPoints[0].ContactPoints[0]
+			require.Equal(t, "my-contact-point", cp.Name)
+			require.Equal(t, "cp-uid", cp.UID)
+			require.Equal(t, "email", cp.Type)
+			require.True(t, cp.DisableResolveMessage)
+			require.Equal(t, "test@example.com", cp.Settings.Get("addresses").MustString())
+
+			require.Len(t, file.Policies, 1)
+			require.Equal(t, int64(2), file.Policies[0].OrgID)
+			require.Equal(t, receiver, file.Policies[0].Policy.Receiver)
+			require.Equal(t, []string{"alertname"}, file.Policies[0].Policy.GroupByStr)
+			require.Len(t, file.Policies[0].Policy.Routes, 1)
+			require.Equal(t, []string{"my-mute-timing"}, file.Policies[0].Policy.Routes[0].MuteTimeIntervals)
+
+			require.Len(t, file.MuteTimes, 1)
+			require.Equal(t, int64(2), file.MuteTimes[0].OrgID)
+			require.Equal(t, export.MuteTimes[0].MuteTimeInterval, file.MuteTimes[0].MuteTime.MuteTimeInterval)
+
+			require.Len(t, file.Templates, 1)
+			require.Equal(t, int64(2), file.Templates[0].OrgID)
+			require.Equal(t, "my-template", file.Templates[0].Data.Name)
+			require.Equal(t, export.Templates[0].Template, file.Templates[0].Data.Template)
+		})
+	}
 }
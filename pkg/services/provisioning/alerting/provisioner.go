@@ -18,6 +18,7 @@ type ProvisionerConfig struct {
 	NotificiationPolicyService provisioning.NotificationPolicyService
 	MuteTimingService          provisioning.MuteTimingService
 	TemplateService            provisioning.TemplateService
+	SilenceService             provisioning.SilenceService
 }
 
 func Provision(ctx context.Context, cfg ProvisionerConfig) error {
@@ -53,6 +54,11 @@ func Provision(ctx context.Context, cfg ProvisionerConfig) error {
 	if err != nil {
 		return fmt.Errorf("text templates: %w", err)
 	}
+	rsProvisioner := NewRecurringSilencesProvisioner(logger, cfg.SilenceService)
+	err = rsProvisioner.Provision(ctx, files)
+	if err != nil {
+		return fmt.Errorf("recurring silences: %w", err)
+	}
 	npProvisioner := NewNotificationPolicyProvisoner(logger, cfg.NotificiationPolicyService)
 	err = npProvisioner.Provision(ctx, files)
 	if err != nil {
@@ -74,6 +80,10 @@ func Provision(ctx context.Context, cfg ProvisionerConfig) error {
 	if err != nil {
 		return fmt.Errorf("text templates: %w", err)
 	}
+	err = rsProvisioner.Unprovision(ctx, files)
+	if err != nil {
+		return fmt.Errorf("recurring silences: %w", err)
+	}
 	logger.Info("finished to provision alerting")
 	return nil
 }
@@ -0,0 +1,74 @@
+package alerting
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+)
+
+type RecurringSilencesProvisioner interface {
+	Provision(ctx context.Context, files []*AlertingFile) error
+	Unprovision(ctx context.Context, files []*AlertingFile) error
+}
+
+type defaultRecurringSilencesProvisioner struct {
+	logger         log.Logger
+	silenceService provisioning.SilenceService
+}
+
+func NewRecurringSilencesProvisioner(logger log.Logger,
+	silenceService provisioning.SilenceService) RecurringSilencesProvisioner {
+	return &defaultRecurringSilencesProvisioner{
+		logger:         logger,
+		silenceService: silenceService,
+	}
+}
+
+func (c *defaultRecurringSilencesProvisioner) Provision(ctx context.Context,
+	files []*AlertingFile) error {
+	cache := map[int64]map[string]definitions.RecurringSilence{}
+	for _, file := range files {
+		for _, recurring := range file.RecurringSilences {
+			if _, exists := cache[recurring.OrgID]; !exists {
+				silences, err := c.silenceService.GetRecurringSilences(ctx, recurring.OrgID)
+				if err != nil {
+					return err
+				}
+				cache[recurring.OrgID] = make(map[string]definitions.RecurringSilence, len(silences))
+				for _, s := range silences {
+					cache[recurring.OrgID][s.Name] = s
+				}
+			}
+			recurring.RecurringSilence.Provenance = models.ProvenanceFile
+			if _, exists := cache[recurring.OrgID][recurring.RecurringSilence.Name]; exists {
+				_, err := c.silenceService.UpdateRecurringSilence(ctx, recurring.RecurringSilence, recurring.OrgID)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			_, err := c.silenceService.CreateRecurringSilence(ctx, recurring.RecurringSilence, recurring.OrgID)
+			if err != nil {
+				return err
+			}
+			cache[recurring.OrgID][recurring.RecurringSilence.Name] = recurring.RecurringSilence
+		}
+	}
+	return nil
+}
+
+func (c *defaultRecurringSilencesProvisioner) Unprovision(ctx context.Context,
+	files []*AlertingFile) error {
+	for _, file := range files {
+		for _, deleteRecurring := range file.DeleteRecurringSilences {
+			err := c.silenceService.DeleteRecurringSilence(ctx, deleteRecurring.Name, deleteRecurring.OrgID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
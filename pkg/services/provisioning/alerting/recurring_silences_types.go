@@ -0,0 +1,55 @@
+package alerting
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+type RecurringSilenceV1 struct {
+	OrgID            values.Int64Value            `json:"orgId" yaml:"orgId"`
+	RecurringSilence definitions.RecurringSilence `json:",inline" yaml:",inline"`
+}
+
+func (v1 *RecurringSilenceV1) mapToModel() RecurringSilence {
+	orgID := v1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	return RecurringSilence{
+		OrgID:            orgID,
+		RecurringSilence: v1.RecurringSilence,
+	}
+}
+
+type RecurringSilence struct {
+	OrgID            int64
+	RecurringSilence definitions.RecurringSilence
+}
+
+type DeleteRecurringSilenceV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name  values.StringValue `json:"name" yaml:"name"`
+}
+
+func (v1 *DeleteRecurringSilenceV1) mapToModel() (DeleteRecurringSilence, error) {
+	name := strings.TrimSpace(v1.Name.Value())
+	if name == "" {
+		return DeleteRecurringSilence{}, errors.New("delete recurring silence missing name")
+	}
+	orgID := v1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	return DeleteRecurringSilence{
+		OrgID: orgID,
+		Name:  name,
+	}, nil
+}
+
+type DeleteRecurringSilence struct {
+	OrgID int64
+	Name  string
+}
@@ -28,6 +28,7 @@ type AlertRuleGroupV1 struct {
 	Name     values.StringValue `json:"name" yaml:"name"`
 	Folder   values.StringValue `json:"folder" yaml:"folder"`
 	Interval values.StringValue `json:"interval" yaml:"interval"`
+	IsPaused values.BoolValue   `json:"isPaused" yaml:"isPaused"`
 	Rules    []AlertRuleV1      `json:"rules" yaml:"rules"`
 }
 
@@ -55,6 +56,10 @@ func (ruleGroupV1 *AlertRuleGroupV1) MapToModel() (AlertRuleGroup, error) {
 		if err != nil {
 			return AlertRuleGroup{}, err
 		}
+		// a paused group pauses all its rules
+		if ruleGroupV1.IsPaused.Value() {
+			rule.IsPaused = true
+		}
 		ruleGroup.Rules = append(ruleGroup.Rules, rule)
 	}
 	return ruleGroup, nil
@@ -80,6 +85,8 @@ type AlertRuleV1 struct {
 	For          values.StringValue    `json:"for" yaml:"for"`
 	Annotations  values.StringMapValue `json:"annotations" yaml:"annotations"`
 	Labels       values.StringMapValue `json:"labels" yaml:"labels"`
+	IsPaused     values.BoolValue      `json:"isPaused" yaml:"isPaused"`
+	Enrichments  []models.Enrichment   `json:"enrichments" yaml:"enrichments"`
 }
 
 func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
@@ -126,6 +133,13 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 	}
 	alertRule.Annotations = rule.Annotations.Raw
 	alertRule.Labels = rule.Labels.Value()
+	alertRule.IsPaused = rule.IsPaused.Value()
+	for i := range rule.Enrichments {
+		if err := rule.Enrichments[i].Validate(); err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+	}
+	alertRule.Enrichments = rule.Enrichments
 	for _, queryV1 := range rule.Data {
 		query, err := queryV1.mapToModel()
 		if err != nil {
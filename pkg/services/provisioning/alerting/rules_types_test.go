@@ -69,6 +69,20 @@ func TestRuleGroup(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, int64(1), rgMapped.OrgID)
 	})
+	t.Run("a paused rule group should pause all rules", func(t *testing.T) {
+		rg := validRuleGroupV1(t)
+		rg.Rules = []AlertRuleV1{validRuleV1(t), validRuleV1(t)}
+		var isPaused values.BoolValue
+		err := yaml.Unmarshal([]byte("true"), &isPaused)
+		require.NoError(t, err)
+		rg.IsPaused = isPaused
+		rgMapped, err := rg.MapToModel()
+		require.NoError(t, err)
+		require.Len(t, rgMapped.Rules, 2)
+		for _, rule := range rgMapped.Rules {
+			require.True(t, rule.IsPaused)
+		}
+	})
 	t.Run("a rule group with a negative org id should default to 1", func(t *testing.T) {
 		rg := validRuleGroupV1(t)
 		orgID := values.Int64Value{}
@@ -161,6 +175,40 @@ func TestRules(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, ruleMapped.ExecErrState, models.OkErrState)
 	})
+	t.Run("a rule with out isPaused should not be paused", func(t *testing.T) {
+		rule := validRuleV1(t)
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.False(t, ruleMapped.IsPaused)
+	})
+	t.Run("a rule with isPaused should be paused", func(t *testing.T) {
+		rule := validRuleV1(t)
+		isPaused := values.BoolValue{}
+		err := yaml.Unmarshal([]byte("true"), &isPaused)
+		require.NoError(t, err)
+		rule.IsPaused = isPaused
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.True(t, ruleMapped.IsPaused)
+	})
+	t.Run("a rule with enrichments should keep them", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Enrichments = []models.Enrichment{{
+			Type:   models.TableEnrichmentType,
+			Keys:   []string{"service"},
+			Labels: map[string]string{"owner": "team"},
+			CSV:    "service,team\napi,backend",
+		}}
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, rule.Enrichments, ruleMapped.Enrichments)
+	})
+	t.Run("a rule with an invalid enrichment should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Enrichments = []models.Enrichment{{Type: models.TeamEnrichmentType, Keys: []string{"owner"}}}
+		_, err := rule.mapToModel(1)
+		require.Error(t, err)
+	})
 	t.Run("a rule with out noDataState should have sane defaults", func(t *testing.T) {
 		rule := validRuleV1(t)
 		ruleMapped, err := rule.mapToModel(1)
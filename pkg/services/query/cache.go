@@ -0,0 +1,273 @@
+package query
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// HeaderCacheStatus is the response header with the cache status of the queries of the request.
+const HeaderCacheStatus = "X-Cache"
+
+// CacheStatus is whether the response of a query was served from the cache.
+type CacheStatus string
+
+const (
+	// CacheStatusHit is the status of responses served from the cache.
+	CacheStatusHit CacheStatus = "HIT"
+	// CacheStatusMiss is the status of responses that were not in the cache and were stored after querying the data source.
+	CacheStatusMiss CacheStatus = "MISS"
+	// CacheStatusBypass is the status of responses that are not cacheable, or that were requested without cache.
+	CacheStatusBypass CacheStatus = "BYPASS"
+)
+
+// volatileQueryFields are the fields of a query that change with every request without changing its response.
+var volatileQueryFields = []string{"requestId", "key", "datasourceId"}
+
+// CacheStatuses collects the cache statuses of the queries to each data source of a request.
+type CacheStatuses struct {
+	mu       sync.Mutex
+	statuses []CacheStatus
+}
+
+type cacheStatusesKey struct{}
+
+// WithCacheStatuses returns a context that collects the cache statuses of the queries executed with it.
+func WithCacheStatuses(ctx context.Context) (context.Context, *CacheStatuses) {
+	s := &CacheStatuses{}
+	return context.WithValue(ctx, cacheStatusesKey{}, s), s
+}
+
+// cacheStatusesFromContext returns the cache statuses of the context, or nil if the context does not collect them.
+func cacheStatusesFromContext(ctx context.Context) *CacheStatuses {
+	s, _ := ctx.Value(cacheStatusesKey{}).(*CacheStatuses)
+	return s
+}
+
+// add adds the status of the queries to a data source. It is safe to call on nil statuses.
+func (s *CacheStatuses) add(status CacheStatus) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses = append(s.statuses, status)
+}
+
+// Status returns the status of the request. It is a hit or a bypass if the queries to all data sources are,
+// a miss if the request was served partially from the cache or not at all, and empty if no queries were executed.
+func (s *CacheStatuses) Status() CacheStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.statuses) == 0 {
+		return ""
+	}
+	status := s.statuses[0]
+	for _, st := range s.statuses[1:] {
+		if st != status {
+			return CacheStatusMiss
+		}
+	}
+	return status
+}
+
+// queryCache caches the responses of the queries to a data source in the remote cache.
+type queryCache struct {
+	cfg     setting.QueryCachingSettings
+	storage remotecache.CacheStorage
+	// userScoped is true if the identity of the user is forwarded to all data sources.
+	userScoped bool
+	log        log.Logger
+}
+
+func newQueryCache(cfg *setting.Cfg, storage remotecache.CacheStorage, log log.Logger) *queryCache {
+	if !cfg.QueryCaching.Enabled || storage == nil {
+		return nil
+	}
+	return &queryCache{
+		cfg:        cfg.QueryCaching,
+		storage:    storage,
+		userScoped: cfg.SendUserHeader,
+		log:        log,
+	}
+}
+
+// ttl returns how long the responses of the data source are cached, and false if they are not cached.
+// Data sources can override the default TTL with the queryCachingTTL setting, where 0 disables caching.
+func (c *queryCache) ttl(ds *datasources.DataSource) (time.Duration, bool) {
+	ttl := c.cfg.TTL
+	if ds.JsonData != nil {
+		if v, ok := ds.JsonData.CheckGet("queryCachingTTL"); ok {
+			d, err := gtime.ParseDuration(v.MustString())
+			if err != nil {
+				c.log.Warn("Invalid query caching TTL of data source, using the default", "datasource", ds.Uid, "ttl", v.MustString(), "error", err)
+			} else {
+				ttl = d
+			}
+		}
+	}
+	return ttl, ttl > 0
+}
+
+// cacheKey is the content of the key of a cached response.
+type cacheKey struct {
+	OrgID             int64             `json:"orgId"`
+	User              string            `json:"user,omitempty"`
+	DatasourceUID     string            `json:"datasourceUid"`
+	DatasourceVersion int               `json:"datasourceVersion"`
+	From              int64             `json:"from"`
+	To                int64             `json:"to"`
+	Queries           []json.RawMessage `json:"queries"`
+}
+
+// key returns the cache key of the queries, see queriesHash.
+func (c *queryCache) key(u *user.SignedInUser, ds *datasources.DataSource, queries []backend.DataQuery) (string, error) {
+	hash, err := queriesHash(u, ds, queries, c.cfg.TimeRangeRounding, c.userScoped)
+	if err != nil {
+		return "", err
+	}
+	return "query-cache-" + hash, nil
+}
+
+// queriesHash returns the hash of the queries to a data source. It is made of the data source and its version, the
+// normalized queries, the time range of the queries rounded down, and the org of the user. It also has the user if
+// the response can depend on the caller, so that their responses are not shared with other users, see dependsOnUser.
+// The access of the user to the data source is checked before the queries are hashed, so it is not part of the hash.
+func queriesHash(u *user.SignedInUser, ds *datasources.DataSource, queries []backend.DataQuery, rounding time.Duration, userScoped bool) (string, error) {
+	if len(queries) == 0 {
+		return "", errors.New("no queries")
+	}
+	key := cacheKey{
+		OrgID:             ds.OrgId,
+		DatasourceUID:     ds.Uid,
+		DatasourceVersion: ds.Version,
+		From:              queries[0].TimeRange.From.Truncate(rounding).UnixMilli(),
+		To:                queries[0].TimeRange.To.Truncate(rounding).UnixMilli(),
+		Queries:           make([]json.RawMessage, 0, len(queries)),
+	}
+	if userScoped || dependsOnUser(ds) {
+		scope, err := userScope(u)
+		if err != nil {
+			return "", err
+		}
+		key.User = scope
+	}
+
+	sorted := make([]backend.DataQuery, len(queries))
+	copy(sorted, queries)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].RefID < sorted[j].RefID
+	})
+	for _, q := range sorted {
+		normalized, err := normalizeQuery(q.JSON)
+		if err != nil {
+			return "", fmt.Errorf("failed to normalize query %s: %w", q.RefID, err)
+		}
+		key.Queries = append(key.Queries, normalized)
+	}
+
+	b, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// dependsOnUser reports whether the data source forwards the credentials or the identity of the caller, with OAuth
+// pass-through, forwarded cookies or team headers, in which case its responses can differ between users.
+func dependsOnUser(ds *datasources.DataSource) bool {
+	if ds.JsonData == nil {
+		return false
+	}
+	if ds.JsonData.Get("oauthPassThru").MustBool() || len(ds.AllowedCookies()) > 0 {
+		return true
+	}
+	teamHeaders, ok := ds.JsonData.CheckGet("teamHttpHeaders")
+	return ok && len(teamHeaders.MustMap()) > 0
+}
+
+// userScope returns the caller of a request whose response depends on the caller. Requests that are neither made by a
+// user nor with an API key, for example by anonymous users, have no scope, because they could forward different
+// credentials.
+func userScope(u *user.SignedInUser) (string, error) {
+	switch {
+	case u == nil:
+		return "", errors.New("the response depends on the user but there is no user")
+	case u.UserID > 0:
+		return fmt.Sprintf("user/%d", u.UserID), nil
+	case u.ApiKeyID > 0:
+		return fmt.Sprintf("apikey/%d", u.ApiKeyID), nil
+	}
+	return "", errors.New("the response depends on the user but the request is not made by a user or an API key")
+}
+
+// normalizeQuery returns the query without its volatile fields and with its fields sorted.
+func normalizeQuery(query json.RawMessage) (json.RawMessage, error) {
+	var model map[string]interface{}
+	if err := json.Unmarshal(query, &model); err != nil {
+		return nil, err
+	}
+	for _, field := range volatileQueryFields {
+		delete(model, field)
+	}
+	return json.Marshal(model)
+}
+
+// get returns the cached response of the key, or nil if there is none.
+func (c *queryCache) get(ctx context.Context, key string) *backend.QueryDataResponse {
+	v, err := c.storage.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			c.log.Warn("Failed to read the cached response of the query", "key", key, "error", err)
+		}
+		return nil
+	}
+	b, ok := v.([]byte)
+	if !ok {
+		c.log.Warn("Unexpected type of the cached response of the query", "key", key, "type", fmt.Sprintf("%T", v))
+		return nil
+	}
+	resp := &backend.QueryDataResponse{}
+	if err := json.Unmarshal(b, resp); err != nil {
+		c.log.Warn("Failed to decode the cached response of the query", "key", key, "error", err)
+		return nil
+	}
+	return resp
+}
+
+// set caches the response of the key, unless it has errors or is larger than the maximum size.
+func (c *queryCache) set(ctx context.Context, key string, resp *backend.QueryDataResponse, ttl time.Duration) {
+	for _, r := range resp.Responses {
+		if r.Error != nil {
+			return
+		}
+	}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		c.log.Warn("Failed to encode the response of the query", "key", key, "error", err)
+		return
+	}
+	if c.cfg.MaxValueBytes > 0 && int64(len(b)) > c.cfg.MaxValueBytes {
+		c.log.Debug("Response of the query is too large to be cached", "key", key, "size", len(b), "limit", c.cfg.MaxValueBytes)
+		return
+	}
+	if err := c.storage.Set(ctx, key, b, ttl); err != nil {
+		c.log.Warn("Failed to cache the response of the query", "key", key, "error", err)
+	}
+}
@@ -0,0 +1,164 @@
+package query
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestQueryDataCache(t *testing.T) {
+	setupCache := func(t *testing.T) *testContext {
+		t.Helper()
+		tc := setup(t)
+		cfg := setting.NewCfg()
+		cfg.QueryCaching = setting.QueryCachingSettings{
+			Enabled:           true,
+			TTL:               time.Minute,
+			TimeRangeRounding: time.Hour,
+		}
+		tc.queryService.queryCache = newQueryCache(cfg, remotecache.NewFakeStore(t), log.NewNopLogger())
+		return tc
+	}
+	queryData := func(t *testing.T, tc *testContext, u *user.SignedInUser, skipCache bool, reqDTO dtos.MetricRequest) CacheStatus {
+		t.Helper()
+		ctx, statuses := WithCacheStatuses(context.Background())
+		_, err := tc.queryService.QueryData(ctx, u, skipCache, reqDTO)
+		require.NoError(t, err)
+		return statuses.Status()
+	}
+	request := func(t *testing.T, rawQueries ...string) dtos.MetricRequest {
+		mr := metricRequestWithQueries(t, rawQueries...)
+		mr.From = "1672531200000"
+		mr.To = "1672534800000"
+		return mr
+	}
+
+	t.Run("serves the second request from the cache", func(t *testing.T) {
+		tc := setupCache(t)
+		mr := request(t, `{"refId": "A", "datasource": {"uid": "ds1"}, "expr": "up"}`)
+
+		require.Equal(t, CacheStatusMiss, queryData(t, tc, tc.signedInUser, false, mr))
+		require.Equal(t, CacheStatusHit, queryData(t, tc, tc.signedInUser, false, mr))
+		require.Equal(t, 1, tc.pluginContext.calls)
+	})
+
+	t.Run("ignores the volatile fields, the order of the queries and the rounded time range", func(t *testing.T) {
+		tc := setupCache(t)
+		mr := request(t,
+			`{"refId": "A", "datasource": {"uid": "ds1"}, "expr": "up", "requestId": "1"}`,
+			`{"refId": "B", "datasource": {"uid": "ds1"}, "expr": "down", "requestId": "2"}`,
+		)
+		require.Equal(t, CacheStatusMiss, queryData(t, tc, tc.signedInUser, false, mr))
+
+		other := request(t,
+			`{"refId": "B", "expr": "down", "datasource": {"uid": "ds1"}, "requestId": "3"}`,
+			`{"refId": "A", "datasource": {"uid": "ds1"}, "expr": "up", "requestId": "4"}`,
+		)
+		other.From = "1672531260000"
+		require.Equal(t, CacheStatusHit, queryData(t, tc, tc.signedInUser, false, other))
+	})
+
+	t.Run("does not share responses of different queries", func(t *testing.T) {
+		tc := setupCache(t)
+		require.Equal(t, CacheStatusMiss, queryData(t, tc, tc.signedInUser, false, request(t, `{"refId": "A", "datasource": {"uid": "ds1"}, "expr": "up"}`)))
+		require.Equal(t, CacheStatusMiss, queryData(t, tc, tc.signedInUser, false, request(t, `{"refId": "A", "datasource": {"uid": "ds1"}, "expr": "down"}`)))
+		require.Equal(t, CacheStatusMiss, queryData(t, tc, tc.signedInUser, false, request(t, `{"refId": "A", "datasource": {"uid": "ds2"}, "expr": "up"}`)))
+		require.Equal(t, 3, tc.pluginContext.calls)
+	})
+
+	t.Run("does not share responses between users if the data source forwards their identity", func(t *testing.T) {
+		tc := setupCache(t)
+		tc.dataSourceCache.ds = &datasources.DataSource{Uid: "oauth", JsonData: simplejson.NewFromAny(map[string]interface{}{"oauthPassThru": true})}
+		mr := request(t, `{"refId": "A", "datasourceId": 1, "expr": "up"}`)
+		other := &user.SignedInUser{UserID: 2, OrgID: 1}
+
+		require.Equal(t, CacheStatusMiss, queryData(t, tc, tc.signedInUser, false, mr))
+		require.Equal(t, CacheStatusMiss, queryData(t, tc, other, false, mr))
+		require.Equal(t, CacheStatusHit, queryData(t, tc, other, false, mr))
+	})
+
+	t.Run("does not share responses between users if the data source forwards their cookies", func(t *testing.T) {
+		tc := setupCache(t)
+		tc.dataSourceCache.ds = &datasources.DataSource{Uid: "cookies", JsonData: simplejson.NewFromAny(map[string]interface{}{"keepCookies": []interface{}{"session"}})}
+		mr := request(t, `{"refId": "A", "datasourceId": 1, "expr": "up"}`)
+		other := &user.SignedInUser{UserID: 2, OrgID: 1}
+
+		require.Equal(t, CacheStatusMiss, queryData(t, tc, tc.signedInUser, false, mr))
+		require.Equal(t, CacheStatusMiss, queryData(t, tc, other, false, mr))
+		require.Equal(t, CacheStatusHit, queryData(t, tc, other, false, mr))
+		require.Equal(t, CacheStatusHit, queryData(t, tc, tc.signedInUser, false, mr))
+		require.Equal(t, 2, tc.pluginContext.calls)
+	})
+
+	t.Run("does not cache the responses that depend on anonymous callers", func(t *testing.T) {
+		tc := setupCache(t)
+		tc.dataSourceCache.ds = &datasources.DataSource{Uid: "cookies", JsonData: simplejson.NewFromAny(map[string]interface{}{"keepCookies": []interface{}{"session"}})}
+		mr := request(t, `{"refId": "A", "datasourceId": 1, "expr": "up"}`)
+		anonymous := &user.SignedInUser{OrgID: 1, IsAnonymous: true}
+
+		require.Equal(t, CacheStatusBypass, queryData(t, tc, anonymous, false, mr))
+		require.Equal(t, CacheStatusBypass, queryData(t, tc, anonymous, false, mr))
+		require.Equal(t, 2, tc.pluginContext.calls)
+	})
+
+	t.Run("refreshes the cached response if the request skips the cache", func(t *testing.T) {
+		tc := setupCache(t)
+		mr := request(t, `{"refId": "A", "datasource": {"uid": "ds1"}, "expr": "up"}`)
+
+		require.Equal(t, CacheStatusBypass, queryData(t, tc, tc.signedInUser, true, mr))
+		require.Equal(t, CacheStatusHit, queryData(t, tc, tc.signedInUser, false, mr))
+		require.Equal(t, 1, tc.pluginContext.calls)
+	})
+
+	t.Run("sends the request to the data source if the request skips the cache", func(t *testing.T) {
+		tc := setupCache(t)
+		mr := request(t, `{"refId": "A", "datasource": {"uid": "ds1"}, "expr": "up"}`)
+
+		require.Equal(t, CacheStatusMiss, queryData(t, tc, tc.signedInUser, false, mr))
+		require.Equal(t, CacheStatusBypass, queryData(t, tc, tc.signedInUser, true, mr))
+		require.Equal(t, 2, tc.pluginContext.calls)
+	})
+
+	t.Run("does not cache the responses of data sources that disable caching", func(t *testing.T) {
+		tc := setupCache(t)
+		tc.dataSourceCache.ds = &datasources.DataSource{Uid: "uncached", JsonData: simplejson.NewFromAny(map[string]interface{}{"queryCachingTTL": "0"})}
+		mr := request(t, `{"refId": "A", "datasourceId": 1, "expr": "up"}`)
+
+		require.Equal(t, CacheStatusBypass, queryData(t, tc, tc.signedInUser, false, mr))
+		require.Equal(t, CacheStatusBypass, queryData(t, tc, tc.signedInUser, false, mr))
+		require.Equal(t, 2, tc.pluginContext.calls)
+	})
+
+	t.Run("does not cache failed queries", func(t *testing.T) {
+		tc := setupCache(t)
+		mr := request(t, `{"refId": "A", "datasource": {"uid": "ds1"}, "queryType": "FAIL"}`)
+
+		_, err := tc.queryService.QueryData(context.Background(), tc.signedInUser, false, mr)
+		require.Error(t, err)
+		_, err = tc.queryService.QueryData(context.Background(), tc.signedInUser, false, mr)
+		require.Error(t, err)
+		require.Equal(t, 2, tc.pluginContext.calls)
+	})
+
+	t.Run("a request with queries to several data sources is a miss unless all are hits", func(t *testing.T) {
+		tc := setupCache(t)
+		require.Equal(t, CacheStatusMiss, queryData(t, tc, tc.signedInUser, false, request(t, `{"refId": "A", "datasource": {"uid": "ds1"}, "expr": "up"}`)))
+
+		mixed := request(t,
+			`{"refId": "A", "datasource": {"uid": "ds1"}, "expr": "up"}`,
+			`{"refId": "B", "datasource": {"uid": "ds2"}, "expr": "up"}`,
+		)
+		require.Equal(t, CacheStatusMiss, queryData(t, tc, tc.signedInUser, false, mixed))
+		require.Equal(t, CacheStatusHit, queryData(t, tc, tc.signedInUser, false, mixed))
+	})
+}
@@ -0,0 +1,126 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+)
+
+// queryDeduplicator coalesces identical queries to a data source that are in flight at the same time, so that the
+// data source is queried once and all callers receive the same response. The shared query is canceled only when
+// all of its callers have gone away, so that a caller that disconnects does not fail the query of the others.
+type queryDeduplicator struct {
+	mu    sync.Mutex
+	calls map[string]*inflightQuery
+	log   log.Logger
+}
+
+type inflightQuery struct {
+	done    chan struct{}
+	resp    *backend.QueryDataResponse
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+func newQueryDeduplicator(log log.Logger) *queryDeduplicator {
+	return &queryDeduplicator{
+		calls: map[string]*inflightQuery{},
+		log:   log,
+	}
+}
+
+// do executes the query of the key with the given function, or waits for the identical query that is in flight.
+// The function is called with a context that has the values and the deadline of the context of the first caller, and
+// is canceled once all callers that wait for it are canceled.
+func (d *queryDeduplicator) do(ctx context.Context, key string, pluginID string, fn func(ctx context.Context) (*backend.QueryDataResponse, error)) (*backend.QueryDataResponse, error) {
+	d.mu.Lock()
+	call, ok := d.calls[key]
+	if ok {
+		call.waiters++
+		d.mu.Unlock()
+		metrics.MQueryDataCoalesced.WithLabelValues(pluginID).Inc()
+		return d.wait(ctx, key, call)
+	}
+
+	var callCtx context.Context
+	var cancel context.CancelFunc
+	if deadline, ok := ctx.Deadline(); ok {
+		// the query must not outlive the timeout of the request, even if it is not canceled with the first caller
+		callCtx, cancel = context.WithDeadline(detachedContext{parent: ctx}, deadline)
+	} else {
+		callCtx, cancel = context.WithCancel(detachedContext{parent: ctx})
+	}
+	call = &inflightQuery{
+		done:    make(chan struct{}),
+		waiters: 1,
+		cancel:  cancel,
+	}
+	d.calls[key] = call
+	d.mu.Unlock()
+
+	go func() {
+		defer func() {
+			// the query runs in its own goroutine, so its panics must be recovered here and returned to all callers
+			if r := recover(); r != nil {
+				d.log.Error("query datasource panic", "error", r, "stack", log.Stack(1))
+				call.resp, call.err = nil, errors.New("unexpected error, see the server log for details")
+			}
+			cancel()
+			d.mu.Lock()
+			if d.calls[key] == call {
+				delete(d.calls, key)
+			}
+			d.mu.Unlock()
+			close(call.done)
+		}()
+		call.resp, call.err = fn(callCtx)
+	}()
+	return d.wait(ctx, key, call)
+}
+
+func (d *queryDeduplicator) wait(ctx context.Context, key string, call *inflightQuery) (*backend.QueryDataResponse, error) {
+	select {
+	case <-call.done:
+		return call.resp, call.err
+	case <-ctx.Done():
+		d.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// nobody waits for the query anymore, later callers must not join the canceled query
+			call.cancel()
+			if d.calls[key] == call {
+				delete(d.calls, key)
+			}
+		}
+		d.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// detachedContext is a context with the values of its parent that is not canceled with it.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (c detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,195 @@
+package query
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestQueryDeduplicator(t *testing.T) {
+	// blockingQuery returns a query function that counts its calls and blocks until release is closed or its context is canceled
+	blockingQuery := func(calls *int, release chan struct{}, canceled chan struct{}) func(ctx context.Context) (*backend.QueryDataResponse, error) {
+		var mu sync.Mutex
+		return func(ctx context.Context) (*backend.QueryDataResponse, error) {
+			mu.Lock()
+			*calls++
+			mu.Unlock()
+			select {
+			case <-release:
+				return &backend.QueryDataResponse{Responses: backend.Responses{"A": backend.DataResponse{}}}, nil
+			case <-ctx.Done():
+				close(canceled)
+				return nil, ctx.Err()
+			}
+		}
+	}
+	// waitForWaiters waits until the query of the key has the given number of waiters
+	waitForWaiters := func(t *testing.T, d *queryDeduplicator, key string, waiters int) {
+		t.Helper()
+		require.Eventually(t, func() bool {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			call, ok := d.calls[key]
+			return ok && call.waiters == waiters
+		}, time.Second, time.Millisecond)
+	}
+	type result struct {
+		resp *backend.QueryDataResponse
+		err  error
+	}
+
+	t.Run("coalesces identical queries that are in flight", func(t *testing.T) {
+		d := newQueryDeduplicator(log.NewNopLogger())
+		calls, release, canceled := 0, make(chan struct{}), make(chan struct{})
+		fn := blockingQuery(&calls, release, canceled)
+		coalesced := testutil.ToFloat64(metrics.MQueryDataCoalesced.WithLabelValues("prometheus"))
+
+		results := make(chan result, 3)
+		for i := 0; i < 3; i++ {
+			go func() {
+				resp, err := d.do(context.Background(), "key", "prometheus", fn)
+				results <- result{resp, err}
+			}()
+		}
+		waitForWaiters(t, d, "key", 3)
+		close(release)
+
+		first := <-results
+		require.NoError(t, first.err)
+		for i := 0; i < 2; i++ {
+			r := <-results
+			require.NoError(t, r.err)
+			require.Same(t, first.resp, r.resp)
+		}
+		require.Equal(t, 1, calls)
+		require.Equal(t, coalesced+2, testutil.ToFloat64(metrics.MQueryDataCoalesced.WithLabelValues("prometheus")))
+		require.Empty(t, d.calls)
+	})
+
+	t.Run("does not coalesce queries that are not in flight at the same time", func(t *testing.T) {
+		d := newQueryDeduplicator(log.NewNopLogger())
+		calls, release, canceled := 0, make(chan struct{}), make(chan struct{})
+		close(release)
+		fn := blockingQuery(&calls, release, canceled)
+
+		_, err := d.do(context.Background(), "key", "prometheus", fn)
+		require.NoError(t, err)
+		_, err = d.do(context.Background(), "key", "prometheus", fn)
+		require.NoError(t, err)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("a canceled caller does not cancel the query of the others", func(t *testing.T) {
+		d := newQueryDeduplicator(log.NewNopLogger())
+		calls, release, canceled := 0, make(chan struct{}), make(chan struct{})
+		fn := blockingQuery(&calls, release, canceled)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		first := make(chan result, 1)
+		go func() {
+			resp, err := d.do(ctx, "key", "prometheus", fn)
+			first <- result{resp, err}
+		}()
+		waitForWaiters(t, d, "key", 1)
+		second := make(chan result, 1)
+		go func() {
+			resp, err := d.do(context.Background(), "key", "prometheus", fn)
+			second <- result{resp, err}
+		}()
+		waitForWaiters(t, d, "key", 2)
+
+		cancel()
+		require.ErrorIs(t, (<-first).err, context.Canceled)
+		close(release)
+		r := <-second
+		require.NoError(t, r.err)
+		require.Contains(t, r.resp.Responses, "A")
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("the query is canceled when all callers are canceled", func(t *testing.T) {
+		d := newQueryDeduplicator(log.NewNopLogger())
+		calls, release, canceled := 0, make(chan struct{}), make(chan struct{})
+		fn := blockingQuery(&calls, release, canceled)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			_, _ = d.do(ctx, "key", "prometheus", fn)
+		}()
+		waitForWaiters(t, d, "key", 1)
+		cancel()
+
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			require.Fail(t, "the query was not canceled")
+		}
+
+		// the next caller does not join the canceled query
+		close(release)
+		_, err := d.do(context.Background(), "key", "prometheus", blockingQuery(&calls, release, make(chan struct{})))
+		require.NoError(t, err)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("the query has the deadline of the first caller", func(t *testing.T) {
+		d := newQueryDeduplicator(log.NewNopLogger())
+		calls, canceled := 0, make(chan struct{})
+		fn := blockingQuery(&calls, make(chan struct{}), canceled)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := d.do(ctx, "key", "prometheus", fn)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			require.Fail(t, "the query was not canceled at the deadline")
+		}
+	})
+
+	t.Run("does not coalesce the queries of different users if the data source forwards their cookies", func(t *testing.T) {
+		d := newQueryDeduplicator(log.NewNopLogger())
+		calls, release, canceled := 0, make(chan struct{}), make(chan struct{})
+		fn := blockingQuery(&calls, release, canceled)
+		ds := &datasources.DataSource{Uid: "cookies", JsonData: simplejson.NewFromAny(map[string]interface{}{"keepCookies": []interface{}{"session"}})}
+		queries := []backend.DataQuery{{RefID: "A", JSON: []byte(`{"expr": "up"}`)}}
+
+		results := make(chan result, 2)
+		for _, u := range []*user.SignedInUser{{UserID: 1, OrgID: 1}, {UserID: 2, OrgID: 1}} {
+			key, err := queriesHash(u, ds, queries, 0, false)
+			require.NoError(t, err)
+			go func() {
+				resp, err := d.do(context.Background(), key, "prometheus", fn)
+				results <- result{resp, err}
+			}()
+			waitForWaiters(t, d, key, 1)
+		}
+		close(release)
+		for i := 0; i < 2; i++ {
+			require.NoError(t, (<-results).err)
+		}
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("returns an error to all callers if the query panics", func(t *testing.T) {
+		d := newQueryDeduplicator(log.NewNopLogger())
+		_, err := d.do(context.Background(), "key", "prometheus", func(ctx context.Context) (*backend.QueryDataResponse, error) {
+			panic("boom")
+		})
+		require.Error(t, err)
+		require.Empty(t, d.calls)
+	})
+}
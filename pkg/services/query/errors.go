@@ -9,4 +9,12 @@ var (
 	ErrInvalidDatasourceID   = errutil.NewBase(errutil.StatusBadRequest, "query.invalidDatasourceId", errutil.WithPublicMessage("Query does not contain a valid data source identifier")).Errorf("invalid data source identifier")
 	ErrMissingDataSourceInfo = errutil.NewBase(errutil.StatusBadRequest, "query.missingDataSourceInfo").MustTemplate("query missing datasource info: {{ .Public.RefId }}", errutil.WithPublic("Query {{ .Public.RefId }} is missing datasource information"))
 	ErrQueryParamMismatch    = errutil.NewBase(errutil.StatusBadRequest, "query.headerMismatch", errutil.WithPublicMessage("The request headers point to a different plugin than is defined in the request body")).Errorf("plugin header/body mismatch")
+	ErrTooManyQueries        = errutil.NewBase(errutil.StatusBadRequest, "query.tooManyQueries").MustTemplate("request has {{ .Public.Count }} queries, the limit is {{ .Public.Limit }}", errutil.WithPublic("The request has {{ .Public.Count }} queries, the limit is {{ .Public.Limit }}"))
+	ErrTimeRangeTooLarge     = errutil.NewBase(errutil.StatusBadRequest, "query.timeRangeTooLarge").MustTemplate("time range of {{ .Public.Span }} exceeds the limit of {{ .Public.Limit }}", errutil.WithPublic("The time range of {{ .Public.Span }} exceeds the limit of {{ .Public.Limit }}"))
+	ErrUserRateLimited       = errutil.NewBase(errutil.StatusTooManyRequests, "query.userRateLimited", errutil.WithPublicMessage("Too many queries, try again later")).Errorf("user exceeded the rate of queries")
+	ErrConcurrencyLimited    = errutil.NewBase(errutil.StatusTooManyRequests, "query.concurrencyLimited").MustTemplate("too many concurrent queries to the {{ .Public.Scope }}", errutil.WithPublic("Too many concurrent queries to the {{ .Public.Scope }}, try again later"))
+	ErrStreamingDisabled     = errutil.NewBase(errutil.StatusNotFound, "query.streamingDisabled", errutil.WithPublicMessage("Streaming of query results is not enabled")).Errorf("query streaming is disabled")
+	ErrStreamingSingleQuery  = errutil.NewBase(errutil.StatusBadRequest, "query.streamingSingleQuery", errutil.WithPublicMessage("A streamed request must have a single query to a data source")).Errorf("streamed request does not have a single query")
+	ErrStreamingNotSupported = errutil.NewBase(errutil.StatusBadRequest, "query.streamingNotSupported", errutil.WithPublicMessage("The data source does not support streaming of query results")).Errorf("data source does not support streaming")
+	ErrStreamFailed          = errutil.NewBase(errutil.StatusBadGateway, "query.streamFailed").MustTemplate("query stream failed: {{ .Public.Error }}", errutil.WithPublic("{{ .Public.Error }}"))
 )
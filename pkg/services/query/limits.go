@@ -0,0 +1,270 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// userRateWindow is the window of the request rate of users. A rate limiter that has not been used for that long
+// is full again, and is removed because it is the same as a new one.
+const userRateWindow = time.Minute
+
+// queryLimiter enforces the limits of the queries to data sources.
+type queryLimiter struct {
+	cfg setting.QueryLimitsSettings
+
+	mu          sync.Mutex
+	orgs        map[int64]*semaphore.Weighted
+	datasources map[string]*semaphore.Weighted
+	users       map[string]*userRateLimiter
+	lastPrune   time.Time
+}
+
+type userRateLimiter struct {
+	*rate.Limiter
+	lastSeen time.Time
+}
+
+// orgSlotKey is the context key that marks the requests that already hold a concurrency slot of their org.
+type orgSlotKey struct{}
+
+func newQueryLimiter(cfg setting.QueryLimitsSettings) *queryLimiter {
+	return &queryLimiter{
+		cfg:         cfg,
+		orgs:        map[int64]*semaphore.Weighted{},
+		datasources: map[string]*semaphore.Weighted{},
+		users:       map[string]*userRateLimiter{},
+	}
+}
+
+// checkRequest checks the limits of a request before its queries are executed: the rate of the requests of the user,
+// the number of queries, and the span of the time range.
+func (l *queryLimiter) checkRequest(ctx context.Context, u *user.SignedInUser, reqDTO dtos.MetricRequest) error {
+	if l.cfg.MaxRequestsPerUserPerMinute > 0 && u != nil && !l.userLimiter(ctx, u).Allow() {
+		metrics.MQueryLimitRejections.WithLabelValues("user_rate").Inc()
+		return ErrUserRateLimited
+	}
+
+	if l.cfg.MaxQueriesPerRequest > 0 && len(reqDTO.Queries) > l.cfg.MaxQueriesPerRequest {
+		metrics.MQueryLimitRejections.WithLabelValues("queries_per_request").Inc()
+		return ErrTooManyQueries.Build(errutil.TemplateData{
+			Public: map[string]interface{}{
+				"Count": len(reqDTO.Queries),
+				"Limit": l.cfg.MaxQueriesPerRequest,
+			},
+		})
+	}
+
+	if l.cfg.MaxTimeRange > 0 {
+		timeRange := legacydata.NewDataTimeRange(reqDTO.From, reqDTO.To)
+		span := timeRange.GetToAsTimeUTC().Sub(timeRange.GetFromAsTimeUTC())
+		if span > l.cfg.MaxTimeRange {
+			metrics.MQueryLimitRejections.WithLabelValues("time_range").Inc()
+			return ErrTimeRangeTooLarge.Build(errutil.TemplateData{
+				Public: map[string]interface{}{
+					"Span":  span.Round(time.Second).String(),
+					"Limit": l.cfg.MaxTimeRange.String(),
+				},
+			})
+		}
+	}
+	return nil
+}
+
+// userLimiter returns the rate limiter of the user. The requests that are not made by a user, for example with
+// an API key or by anonymous users, are limited per API key or per client IP address.
+func (l *queryLimiter) userLimiter(ctx context.Context, u *user.SignedInUser) *rate.Limiter {
+	key := userLimiterKey(ctx, u)
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pruneUserLimiters(now)
+	limiter, ok := l.users[key]
+	if !ok {
+		limit := l.cfg.MaxRequestsPerUserPerMinute
+		limiter = &userRateLimiter{Limiter: rate.NewLimiter(rate.Every(userRateWindow/time.Duration(limit)), limit)}
+		l.users[key] = limiter
+	}
+	limiter.lastSeen = now
+	return limiter.Limiter
+}
+
+// pruneUserLimiters removes the rate limiters that have not been used for the rate window, at most once per window.
+// It must be called with the lock held.
+func (l *queryLimiter) pruneUserLimiters(now time.Time) {
+	if now.Sub(l.lastPrune) < userRateWindow {
+		return
+	}
+	l.lastPrune = now
+	for key, limiter := range l.users {
+		if now.Sub(limiter.lastSeen) >= userRateWindow {
+			delete(l.users, key)
+		}
+	}
+}
+
+func userLimiterKey(ctx context.Context, u *user.SignedInUser) string {
+	switch {
+	case u.UserID > 0:
+		return fmt.Sprintf("user/%d/%d", u.OrgID, u.UserID)
+	case u.ApiKeyID > 0:
+		return fmt.Sprintf("apikey/%d/%d", u.OrgID, u.ApiKeyID)
+	}
+	if c := web.FromContext(ctx); c != nil {
+		if addr := c.RemoteAddr(); addr != "" {
+			return fmt.Sprintf("ip/%d/%s", u.OrgID, addr)
+		}
+	}
+	return fmt.Sprintf("anonymous/%d", u.OrgID)
+}
+
+// acquireOrg waits for a concurrency slot of the org for a request that is split in several requests to data
+// sources, and returns the function that releases it. In the returned context, acquire takes no other slot of the org.
+func (l *queryLimiter) acquireOrg(ctx context.Context, orgID int64) (context.Context, func(), error) {
+	if l.cfg.MaxConcurrentQueriesPerOrg <= 0 || ctx.Value(orgSlotKey{}) != nil {
+		return ctx, func() {}, nil
+	}
+	l.mu.Lock()
+	orgSem := l.orgSemaphore(orgID)
+	l.mu.Unlock()
+
+	waitCtx := ctx
+	if l.cfg.ConcurrencyWaitTimeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, l.cfg.ConcurrencyWaitTimeout)
+		defer cancel()
+	}
+	if err := l.wait(ctx, waitCtx, orgSem, "org_concurrency", "organization"); err != nil {
+		return nil, nil, err
+	}
+	return context.WithValue(ctx, orgSlotKey{}, true), func() { orgSem.Release(1) }, nil
+}
+
+// orgSemaphore returns the semaphore of the concurrent queries of the org. It must be called with the lock held.
+func (l *queryLimiter) orgSemaphore(orgID int64) *semaphore.Weighted {
+	orgSem := l.orgs[orgID]
+	if orgSem == nil {
+		orgSem = semaphore.NewWeighted(l.cfg.MaxConcurrentQueriesPerOrg)
+		l.orgs[orgID] = orgSem
+	}
+	return orgSem
+}
+
+// acquire waits for a concurrency slot of the org of the data sources and of each data source, and returns the
+// function that releases them. The request is rejected if it waits for longer than the wait timeout. The data sources
+// must belong to the same org, the request takes a single slot of the org whatever the number of data sources, and
+// none if the context already holds one, see acquireOrg.
+func (l *queryLimiter) acquire(ctx context.Context, dss ...*datasources.DataSource) (func(), error) {
+	if len(dss) == 0 {
+		return func() {}, nil
+	}
+	var orgSem *semaphore.Weighted
+	var dsSems []*semaphore.Weighted
+	l.mu.Lock()
+	if l.cfg.MaxConcurrentQueriesPerOrg > 0 && ctx.Value(orgSlotKey{}) == nil {
+		orgSem = l.orgSemaphore(dss[0].OrgId)
+	}
+	if l.cfg.MaxConcurrentQueriesPerDatasource > 0 {
+		// the slots of the data sources are acquired in the order of their UIDs, so that requests to several
+		// data sources do not wait for each other's slots
+		uids := make([]string, 0, len(dss))
+		for _, ds := range dss {
+			if ds.Uid != "" {
+				uids = append(uids, ds.Uid)
+			}
+		}
+		sort.Strings(uids)
+		for i, uid := range uids {
+			if i > 0 && uids[i-1] == uid {
+				continue
+			}
+			dsSem := l.datasources[uid]
+			if dsSem == nil {
+				dsSem = semaphore.NewWeighted(l.cfg.MaxConcurrentQueriesPerDatasource)
+				l.datasources[uid] = dsSem
+			}
+			dsSems = append(dsSems, dsSem)
+		}
+	}
+	l.mu.Unlock()
+
+	waitCtx := ctx
+	if l.cfg.ConcurrencyWaitTimeout > 0 && (orgSem != nil || len(dsSems) > 0) {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, l.cfg.ConcurrencyWaitTimeout)
+		defer cancel()
+	}
+
+	release := func(acquired []*semaphore.Weighted) {
+		for _, sem := range acquired {
+			sem.Release(1)
+		}
+		if orgSem != nil {
+			orgSem.Release(1)
+		}
+	}
+
+	// the slot of the org is always acquired first, so that requests do not wait for each other's slots
+	if orgSem != nil {
+		if err := l.wait(ctx, waitCtx, orgSem, "org_concurrency", "organization"); err != nil {
+			return nil, err
+		}
+	}
+	for i, dsSem := range dsSems {
+		if err := l.wait(ctx, waitCtx, dsSem, "datasource_concurrency", "data source"); err != nil {
+			release(dsSems[:i])
+			return nil, err
+		}
+	}
+
+	gauges := make([]prometheus.Gauge, 0, len(dss))
+	for _, ds := range dss {
+		gauge := metrics.MQueryConcurrentRequests.WithLabelValues(ds.Type)
+		gauge.Inc()
+		gauges = append(gauges, gauge)
+	}
+	return func() {
+		for _, gauge := range gauges {
+			gauge.Dec()
+		}
+		release(dsSems)
+	}, nil
+}
+
+// wait acquires a slot of the semaphore. It returns the error of the request context if it is done,
+// and a concurrency limit error if the wait timed out.
+func (l *queryLimiter) wait(ctx context.Context, waitCtx context.Context, sem *semaphore.Weighted, limit string, scope string) error {
+	err := sem.Acquire(waitCtx, 1)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		metrics.MQueryLimitRejections.WithLabelValues(limit).Inc()
+		return ErrConcurrencyLimited.Build(errutil.TemplateData{
+			Public: map[string]interface{}{
+				"Scope": scope,
+			},
+		})
+	}
+	return err
+}
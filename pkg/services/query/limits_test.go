@@ -0,0 +1,229 @@
+package query
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestQueryLimits(t *testing.T) {
+	t.Run("rejects requests with too many queries", func(t *testing.T) {
+		tc := setup(t)
+		tc.queryService.queryLimiter = newQueryLimiter(setting.QueryLimitsSettings{MaxQueriesPerRequest: 1})
+		mr := metricRequestWithQueries(t,
+			`{"refId": "A", "datasource": {"uid": "ds1"}}`,
+			`{"refId": "B", "datasource": {"uid": "ds1"}}`,
+		)
+
+		_, err := tc.queryService.QueryData(context.Background(), tc.signedInUser, false, mr)
+		require.ErrorIs(t, err, ErrTooManyQueries)
+		require.Equal(t, 0, tc.pluginContext.calls)
+
+		mr.Queries = mr.Queries[:1]
+		_, err = tc.queryService.QueryData(context.Background(), tc.signedInUser, false, mr)
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects requests with a too large time range", func(t *testing.T) {
+		tc := setup(t)
+		tc.queryService.queryLimiter = newQueryLimiter(setting.QueryLimitsSettings{MaxTimeRange: 24 * time.Hour})
+		mr := metricRequestWithQueries(t, `{"refId": "A", "datasource": {"uid": "ds1"}}`)
+		mr.From = "now-7d"
+
+		_, err := tc.queryService.QueryData(context.Background(), tc.signedInUser, false, mr)
+		require.ErrorIs(t, err, ErrTimeRangeTooLarge)
+
+		mr.From = "now-6h"
+		_, err = tc.queryService.QueryData(context.Background(), tc.signedInUser, false, mr)
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects requests of a user that exceed the rate", func(t *testing.T) {
+		tc := setup(t)
+		tc.queryService.queryLimiter = newQueryLimiter(setting.QueryLimitsSettings{MaxRequestsPerUserPerMinute: 2})
+		mr := metricRequestWithQueries(t, `{"refId": "A", "datasource": {"uid": "ds1"}}`)
+
+		for i := 0; i < 2; i++ {
+			_, err := tc.queryService.QueryData(context.Background(), tc.signedInUser, false, mr)
+			require.NoError(t, err)
+		}
+		_, err := tc.queryService.QueryData(context.Background(), tc.signedInUser, false, mr)
+		require.ErrorIs(t, err, ErrUserRateLimited)
+
+		// the rate is per user
+		_, err = tc.queryService.QueryData(context.Background(), &user.SignedInUser{OrgID: 1, UserID: 2}, false, mr)
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects requests without a user that exceed the rate per API key and per client IP", func(t *testing.T) {
+		limiter := newQueryLimiter(setting.QueryLimitsSettings{MaxRequestsPerUserPerMinute: 1})
+		mr := metricRequestWithQueries(t, `{"refId": "A", "datasource": {"uid": "ds1"}}`)
+		check := func(u *user.SignedInUser, clientIP string) error {
+			var err error
+			m := web.New()
+			m.Get("/", func(c *web.Context) {
+				err = limiter.checkRequest(c.Req.Context(), u, mr)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Real-IP", clientIP)
+			m.ServeHTTP(httptest.NewRecorder(), req)
+			return err
+		}
+		anonymous := &user.SignedInUser{OrgID: 1, IsAnonymous: true}
+
+		require.NoError(t, check(anonymous, "10.0.0.1"))
+		require.ErrorIs(t, check(anonymous, "10.0.0.1"), ErrUserRateLimited)
+		require.NoError(t, check(anonymous, "10.0.0.2"))
+
+		require.NoError(t, check(&user.SignedInUser{OrgID: 1, ApiKeyID: 1}, "10.0.0.3"))
+		require.ErrorIs(t, check(&user.SignedInUser{OrgID: 1, ApiKeyID: 1}, "10.0.0.4"), ErrUserRateLimited)
+		require.NoError(t, check(&user.SignedInUser{OrgID: 1, ApiKeyID: 2}, "10.0.0.3"))
+	})
+
+	t.Run("mixed requests count as a single request", func(t *testing.T) {
+		tc := setup(t)
+		tc.queryService.queryLimiter = newQueryLimiter(setting.QueryLimitsSettings{MaxRequestsPerUserPerMinute: 1})
+		mr := metricRequestWithQueries(t,
+			`{"refId": "A", "datasource": {"uid": "ds1"}}`,
+			`{"refId": "B", "datasource": {"uid": "ds2"}}`,
+		)
+
+		res, err := tc.queryService.QueryData(context.Background(), tc.signedInUser, false, mr)
+		require.NoError(t, err)
+		for _, r := range res.Responses {
+			require.NoError(t, r.Error)
+		}
+	})
+
+	t.Run("removes the rate limiters of users that have been idle for the rate window", func(t *testing.T) {
+		limiter := newQueryLimiter(setting.QueryLimitsSettings{MaxRequestsPerUserPerMinute: 1})
+		mr := metricRequestWithQueries(t, `{"refId": "A", "datasource": {"uid": "ds1"}}`)
+
+		require.NoError(t, limiter.checkRequest(context.Background(), &user.SignedInUser{OrgID: 1, UserID: 1}, mr))
+		require.NoError(t, limiter.checkRequest(context.Background(), &user.SignedInUser{OrgID: 1, UserID: 2}, mr))
+		require.Len(t, limiter.users, 2)
+
+		limiter.users["user/1/1"].lastSeen = time.Now().Add(-userRateWindow)
+		limiter.lastPrune = time.Now().Add(-userRateWindow)
+		require.NoError(t, limiter.checkRequest(context.Background(), &user.SignedInUser{OrgID: 1, UserID: 3}, mr))
+		require.Len(t, limiter.users, 2)
+		require.NotContains(t, limiter.users, "user/1/1")
+		require.ErrorIs(t, limiter.checkRequest(context.Background(), &user.SignedInUser{OrgID: 1, UserID: 2}, mr), ErrUserRateLimited)
+	})
+
+	t.Run("mixed requests take a single slot of the org", func(t *testing.T) {
+		tc := setup(t)
+		tc.queryService.queryLimiter = newQueryLimiter(setting.QueryLimitsSettings{MaxConcurrentQueriesPerOrg: 1, ConcurrencyWaitTimeout: 10 * time.Millisecond})
+		mr := metricRequestWithQueries(t,
+			`{"refId": "A", "datasource": {"uid": "ds1"}}`,
+			`{"refId": "B", "datasource": {"uid": "ds2"}}`,
+		)
+
+		res, err := tc.queryService.QueryData(context.Background(), tc.signedInUser, false, mr)
+		require.NoError(t, err)
+		for _, r := range res.Responses {
+			require.NoError(t, r.Error)
+		}
+		require.Equal(t, 2, tc.pluginContext.calls)
+
+		release, err := tc.queryService.queryLimiter.acquire(context.Background(), &datasources.DataSource{Uid: "ds3"})
+		require.NoError(t, err)
+		defer release()
+		_, err = tc.queryService.QueryData(context.Background(), tc.signedInUser, false, mr)
+		require.ErrorIs(t, err, ErrConcurrencyLimited)
+		require.Equal(t, 2, tc.pluginContext.calls)
+	})
+
+	t.Run("limits the concurrent requests to a data source", func(t *testing.T) {
+		limiter := newQueryLimiter(setting.QueryLimitsSettings{MaxConcurrentQueriesPerDatasource: 1, ConcurrencyWaitTimeout: 10 * time.Millisecond})
+		ds1 := &datasources.DataSource{OrgId: 1, Uid: "ds1", Type: "prometheus"}
+		ds2 := &datasources.DataSource{OrgId: 1, Uid: "ds2", Type: "prometheus"}
+
+		release, err := limiter.acquire(context.Background(), ds1)
+		require.NoError(t, err)
+		_, err = limiter.acquire(context.Background(), ds1)
+		require.ErrorIs(t, err, ErrConcurrencyLimited)
+
+		other, err := limiter.acquire(context.Background(), ds2)
+		require.NoError(t, err)
+		other()
+
+		release()
+		release, err = limiter.acquire(context.Background(), ds1)
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("limits the concurrent requests to the data sources of expressions", func(t *testing.T) {
+		tc := setup(t)
+		tc.queryService.queryLimiter = newQueryLimiter(setting.QueryLimitsSettings{MaxConcurrentQueriesPerDatasource: 1, ConcurrencyWaitTimeout: 10 * time.Millisecond})
+		mr := metricRequestWithQueries(t,
+			`{"refId": "A", "datasource": {"uid": "ds1"}}`,
+			`{"refId": "B", "datasource": {"uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A - 50"}`,
+		)
+
+		release, err := tc.queryService.queryLimiter.acquire(context.Background(), &datasources.DataSource{Uid: "ds1"})
+		require.NoError(t, err)
+		_, err = tc.queryService.QueryData(context.Background(), tc.signedInUser, false, mr)
+		require.ErrorIs(t, err, ErrConcurrencyLimited)
+		require.Equal(t, 0, tc.pluginContext.calls)
+
+		release()
+		_, err = tc.queryService.QueryData(context.Background(), tc.signedInUser, false, mr)
+		require.NoError(t, err)
+		require.Equal(t, 1, tc.pluginContext.calls)
+	})
+
+	t.Run("requests to several data sources take a single slot of the org", func(t *testing.T) {
+		limiter := newQueryLimiter(setting.QueryLimitsSettings{MaxConcurrentQueriesPerOrg: 1, MaxConcurrentQueriesPerDatasource: 1, ConcurrencyWaitTimeout: 10 * time.Millisecond})
+		ds1 := &datasources.DataSource{OrgId: 1, Uid: "ds1"}
+		ds2 := &datasources.DataSource{OrgId: 1, Uid: "ds2"}
+
+		release, err := limiter.acquire(context.Background(), ds2, ds1)
+		require.NoError(t, err)
+		_, err = limiter.acquire(context.Background(), ds1)
+		require.ErrorIs(t, err, ErrConcurrencyLimited)
+		release()
+
+		release, err = limiter.acquire(context.Background(), ds1)
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("limits the concurrent requests of an org", func(t *testing.T) {
+		limiter := newQueryLimiter(setting.QueryLimitsSettings{MaxConcurrentQueriesPerOrg: 1, ConcurrencyWaitTimeout: 10 * time.Millisecond})
+
+		release, err := limiter.acquire(context.Background(), &datasources.DataSource{OrgId: 1, Uid: "ds1"})
+		require.NoError(t, err)
+		_, err = limiter.acquire(context.Background(), &datasources.DataSource{OrgId: 1, Uid: "ds2"})
+		require.ErrorIs(t, err, ErrConcurrencyLimited)
+
+		other, err := limiter.acquire(context.Background(), &datasources.DataSource{OrgId: 2, Uid: "ds3"})
+		require.NoError(t, err)
+		other()
+		release()
+	})
+
+	t.Run("returns the error of the request if it is canceled while waiting", func(t *testing.T) {
+		limiter := newQueryLimiter(setting.QueryLimitsSettings{MaxConcurrentQueriesPerDatasource: 1})
+		ds := &datasources.DataSource{OrgId: 1, Uid: "ds1"}
+
+		release, err := limiter.acquire(context.Background(), ds)
+		require.NoError(t, err)
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = limiter.acquire(ctx, ds)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
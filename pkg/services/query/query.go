@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/adapters"
@@ -36,6 +37,7 @@ func ProvideService(
 	pluginRequestValidator models.PluginRequestValidator,
 	dataSourceService datasources.DataSourceService,
 	pluginClient plugins.Client,
+	remoteCache *remotecache.RemoteCache,
 ) *Service {
 	g := &Service{
 		cfg:                    cfg,
@@ -46,6 +48,13 @@ func ProvideService(
 		pluginClient:           pluginClient,
 		log:                    log.New("query_data"),
 	}
+	if remoteCache != nil {
+		g.queryCache = newQueryCache(cfg, remoteCache, g.log)
+	}
+	g.queryLimiter = newQueryLimiter(cfg.QueryLimits)
+	if cfg.QueryCaching.DeduplicationEnabled {
+		g.queryDeduplicator = newQueryDeduplicator(g.log)
+	}
 	g.log.Info("Query Service initialization")
 	return g
 }
@@ -57,6 +66,9 @@ type Service struct {
 	pluginRequestValidator models.PluginRequestValidator
 	dataSourceService      datasources.DataSourceService
 	pluginClient           plugins.Client
+	queryCache             *queryCache
+	queryDeduplicator      *queryDeduplicator
+	queryLimiter           *queryLimiter
 	log                    log.Logger
 }
 
@@ -67,7 +79,21 @@ func (s *Service) Run(ctx context.Context) error {
 }
 
 // QueryData processes queries and returns query responses. It handles queries to single or mixed datasources, as well as expressions.
+// The request is rejected if it exceeds the query limits.
 func (s *Service) QueryData(ctx context.Context, user *user.SignedInUser, skipCache bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error) {
+	if err := s.queryLimiter.checkRequest(ctx, user, reqDTO); err != nil {
+		return nil, err
+	}
+	if timeout := s.cfg.QueryLimits.RequestTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return s.queryData(ctx, user, skipCache, reqDTO)
+}
+
+// queryData processes queries without checking the limits of the request.
+func (s *Service) queryData(ctx context.Context, user *user.SignedInUser, skipCache bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error) {
 	// Parse the request into parsed queries grouped by datasource uid
 	parsedReq, err := s.parseMetricRequest(ctx, user, skipCache, reqDTO)
 	if err != nil {
@@ -76,11 +102,12 @@ func (s *Service) QueryData(ctx context.Context, user *user.SignedInUser, skipCa
 
 	// If there are expressions, handle them and return
 	if parsedReq.hasExpression {
+		cacheStatusesFromContext(ctx).add(CacheStatusBypass)
 		return s.handleExpressions(ctx, user, parsedReq)
 	}
 	// If there is only one datasource, query it and return
 	if len(parsedReq.parsedQueries) == 1 {
-		return s.handleQuerySingleDatasource(ctx, user, parsedReq)
+		return s.handleQuerySingleDatasource(ctx, user, skipCache, parsedReq)
 	}
 	// If there are multiple datasources, handle their queries concurrently and return the aggregate result
 	return s.executeConcurrentQueries(ctx, user, skipCache, reqDTO, parsedReq.parsedQueries)
@@ -88,6 +115,18 @@ func (s *Service) QueryData(ctx context.Context, user *user.SignedInUser, skipCa
 
 // executeConcurrentQueries executes queries to multiple datasources concurrently and returns the aggregate result.
 func (s *Service) executeConcurrentQueries(ctx context.Context, user *user.SignedInUser, skipCache bool, reqDTO dtos.MetricRequest, queriesbyDs map[string][]parsedQuery) (*backend.QueryDataResponse, error) {
+	// the request takes a single concurrency slot of the org, the queries to each data source take a slot of the data source
+	var orgID int64
+	for _, queries := range queriesbyDs {
+		orgID = queries[0].datasource.OrgId
+		break
+	}
+	ctx, release, err := s.queryLimiter.acquireOrg(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	g, ctx := errgroup.WithContext(ctx)
 	g.SetLimit(8) // arbitrary limit to prevent too many concurrent requests
 	rchan := make(chan backend.Responses, len(queriesbyDs))
@@ -120,7 +159,7 @@ func (s *Service) executeConcurrentQueries(ctx context.Context, user *user.Signe
 			// Handle panics in the datasource qery
 			defer recoveryFn(subDTO.Queries)
 
-			subResp, err := s.QueryData(ctx, user, skipCache, subDTO)
+			subResp, err := s.queryData(ctx, user, skipCache, subDTO)
 			if err == nil {
 				rchan <- subResp.Responses
 			} else {
@@ -167,6 +206,9 @@ func (s *Service) handleExpressions(ctx context.Context, user *user.SignedInUser
 		exprReq.OrgId = user.OrgID
 	}
 
+	// the expressions query the data sources directly, the concurrency slots of the data sources are acquired for the whole request
+	var dss []*datasources.DataSource
+	seen := map[string]bool{}
 	for _, pq := range parsedReq.getFlattenedQueries() {
 		if pq.datasource == nil {
 			return nil, ErrMissingDataSourceInfo.Build(errutil.TemplateData{
@@ -175,6 +217,10 @@ func (s *Service) handleExpressions(ctx context.Context, user *user.SignedInUser
 				},
 			})
 		}
+		if !expr.IsDataSource(pq.datasource.Uid) && !seen[pq.datasource.Uid] {
+			seen[pq.datasource.Uid] = true
+			dss = append(dss, pq.datasource)
+		}
 
 		exprReq.Queries = append(exprReq.Queries, expr.Query{
 			JSON:          pq.query.JSON,
@@ -190,6 +236,12 @@ func (s *Service) handleExpressions(ctx context.Context, user *user.SignedInUser
 		})
 	}
 
+	release, err := s.queryLimiter.acquire(ctx, dss...)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	qdr, err := s.expressionService.TransformData(ctx, time.Now(), &exprReq) // use time now because all queries have absolute time range
 	if err != nil {
 		return nil, fmt.Errorf("expression request error: %w", err)
@@ -197,8 +249,9 @@ func (s *Service) handleExpressions(ctx context.Context, user *user.SignedInUser
 	return qdr, nil
 }
 
-// handleQuerySingleDatasource handles one or more queries to a single datasource
-func (s *Service) handleQuerySingleDatasource(ctx context.Context, user *user.SignedInUser, parsedReq *parsedRequest) (*backend.QueryDataResponse, error) {
+// handleQuerySingleDatasource handles one or more queries to a single datasource. The response is served from
+// the query cache if it is enabled, unless skipCache is set, in which case the cached response is refreshed.
+func (s *Service) handleQuerySingleDatasource(ctx context.Context, user *user.SignedInUser, skipCache bool, parsedReq *parsedRequest) (*backend.QueryDataResponse, error) {
 	queries := parsedReq.getFlattenedQueries()
 	ds := queries[0].datasource
 	if err := s.pluginRequestValidator.Validate(ds.Url, nil); err != nil {
@@ -232,7 +285,61 @@ func (s *Service) handleQuerySingleDatasource(ctx context.Context, user *user.Si
 		req.Queries = append(req.Queries, q.query)
 	}
 
-	return s.pluginClient.QueryData(ctx, req)
+	if s.queryCache == nil {
+		return s.queryDataSource(ctx, user, ds, req)
+	}
+	ttl, ok := s.queryCache.ttl(ds)
+	if !ok {
+		cacheStatusesFromContext(ctx).add(CacheStatusBypass)
+		return s.queryDataSource(ctx, user, ds, req)
+	}
+	key, err := s.queryCache.key(user, ds, req.Queries)
+	if err != nil {
+		s.log.Warn("Failed to build the cache key of the queries, skipping the cache", "datasource", ds.Uid, "error", err)
+		cacheStatusesFromContext(ctx).add(CacheStatusBypass)
+		return s.queryDataSource(ctx, user, ds, req)
+	}
+	if !skipCache {
+		if resp := s.queryCache.get(ctx, key); resp != nil {
+			cacheStatusesFromContext(ctx).add(CacheStatusHit)
+			return resp, nil
+		}
+	}
+
+	resp, err := s.queryDataSource(ctx, user, ds, req)
+	if err != nil {
+		return nil, err
+	}
+	s.queryCache.set(ctx, key, resp, ttl)
+	if skipCache {
+		cacheStatusesFromContext(ctx).add(CacheStatusBypass)
+	} else {
+		cacheStatusesFromContext(ctx).add(CacheStatusMiss)
+	}
+	return resp, nil
+}
+
+// queryDataSource queries the data source. If deduplication is enabled, the request is coalesced with the identical
+// requests that are in flight, so that the data source is queried once for all of them.
+func (s *Service) queryDataSource(ctx context.Context, user *user.SignedInUser, ds *datasources.DataSource, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	// the concurrency slots are acquired by the query that is sent to the data source, so that coalesced requests do not take them
+	query := func(ctx context.Context) (*backend.QueryDataResponse, error) {
+		release, err := s.queryLimiter.acquire(ctx, ds)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return s.pluginClient.QueryData(ctx, req)
+	}
+	if s.queryDeduplicator == nil {
+		return query(ctx)
+	}
+	key, err := queriesHash(user, ds, req.Queries, 0, s.cfg.SendUserHeader)
+	if err != nil {
+		s.log.Warn("Failed to hash the queries, skipping deduplication", "datasource", ds.Uid, "error", err)
+		return query(ctx)
+	}
+	return s.queryDeduplicator.do(ctx, key, ds.Type, query)
 }
 
 // parseRequest parses a request into parsed queries grouped by datasource uid
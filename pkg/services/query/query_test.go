@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -400,14 +401,14 @@ func setup(t *testing.T) *testContext {
 		SimulatePluginFailure: false,
 	}
 	exprService := expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, pc, fakeDatasourceService)
-	queryService := ProvideService(setting.NewCfg(), dc, exprService, rv, ds, pc) // provider belonging to this package
+	queryService := ProvideService(setting.NewCfg(), dc, exprService, rv, ds, pc, nil) // provider belonging to this package
 	return &testContext{
 		pluginContext:          pc,
 		secretStore:            ss,
 		dataSourceCache:        dc,
 		pluginRequestValidator: rv,
 		queryService:           queryService,
-		signedInUser:           &user.SignedInUser{OrgID: 1, Login: "login", Name: "name", Email: "email", OrgRole: roletype.RoleAdmin},
+		signedInUser:           &user.SignedInUser{OrgID: 1, UserID: 1, Login: "login", Name: "name", Email: "email", OrgRole: roletype.RoleAdmin},
 	}
 }
 
@@ -460,11 +461,29 @@ func (c *fakeDataSourceCache) GetDatasourceByUID(ctx context.Context, datasource
 
 type fakePluginClient struct {
 	plugins.Client
-	req *backend.QueryDataRequest
+	req       *backend.QueryDataRequest
+	calls     int
+	streamReq *backend.RunStreamRequest
+	streamErr error
+}
+
+func (c *fakePluginClient) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	c.streamReq = req
+	c.calls++
+	if c.streamErr != nil {
+		return c.streamErr
+	}
+	for i := 0; i < 2; i++ {
+		if err := sender.SendFrame(data.NewFrame("", data.NewField("value", nil, []int64{int64(i)})), data.IncludeAll); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (c *fakePluginClient) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	c.req = req
+	c.calls++
 
 	// If an expression query ends up getting directly queried, we want it to return an error in our test.
 	if req.PluginContext.PluginID == "__expr__" {
@@ -0,0 +1,88 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/plugins/adapters"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// queryStreamPath is the path of the streams of data sources that run a query and send its results in chunks.
+// The data of the request of the stream is the JSON of the backend.DataQuery.
+const queryStreamPath = "query"
+
+// QueryDataStream runs the single query of a request and sends its results to the sender in chunks, as the data source
+// produces them. Sending blocks until the chunk is received, so that a slow receiver slows down the query, and the
+// query is canceled when the context is done. The request is rejected if it exceeds the query limits, and it holds
+// a concurrency slot of the data source until the stream ends.
+func (s *Service) QueryDataStream(ctx context.Context, user *user.SignedInUser, reqDTO dtos.MetricRequest, sender *backend.StreamSender) error {
+	if !s.cfg.QueryStreaming.Enabled {
+		return ErrStreamingDisabled
+	}
+	if len(reqDTO.Queries) > 1 {
+		return ErrStreamingSingleQuery
+	}
+	if err := s.queryLimiter.checkRequest(ctx, user, reqDTO); err != nil {
+		return err
+	}
+
+	parsedReq, err := s.parseMetricRequest(ctx, user, false, reqDTO)
+	if err != nil {
+		return err
+	}
+	if parsedReq.hasExpression {
+		return ErrStreamingSingleQuery
+	}
+	pq := parsedReq.getFlattenedQueries()[0]
+	ds := pq.datasource
+	if err := s.pluginRequestValidator.Validate(ds.Url, nil); err != nil {
+		return datasources.ErrDataSourceAccessDenied
+	}
+
+	instanceSettings, err := adapters.ModelToInstanceSettings(ds, s.decryptSecureJsonDataFn(ctx))
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(pq.query)
+	if err != nil {
+		return err
+	}
+
+	release, err := s.queryLimiter.acquire(ctx, ds)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	err = s.pluginClient.RunStream(ctx, &backend.RunStreamRequest{
+		PluginContext: backend.PluginContext{
+			OrgID:                      ds.OrgId,
+			PluginID:                   ds.Type,
+			User:                       adapters.BackendUserFromSignedInUser(user),
+			DataSourceInstanceSettings: instanceSettings,
+		},
+		Path: queryStreamPath,
+		Data: data,
+	}, sender)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	if errors.Is(err, backendplugin.ErrMethodNotImplemented) {
+		return ErrStreamingNotSupported
+	}
+	// the errors of the data source are returned to the user, as they are in the responses of queries
+	return ErrStreamFailed.Build(errutil.TemplateData{
+		Public: map[string]interface{}{
+			"Error": err.Error(),
+		},
+		Error: err,
+	})
+}
@@ -0,0 +1,107 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestQueryDataStream(t *testing.T) {
+	setupStreaming := func(t *testing.T) *testContext {
+		tc := setup(t)
+		tc.queryService.cfg.QueryStreaming = setting.QueryStreamingSettings{Enabled: true}
+		return tc
+	}
+
+	t.Run("rejects requests if streaming is disabled", func(t *testing.T) {
+		tc := setup(t)
+		mr := metricRequestWithQueries(t, `{"refId": "A", "datasource": {"uid": "ds1"}}`)
+
+		err := tc.queryService.QueryDataStream(context.Background(), tc.signedInUser, mr, backend.NewStreamSender(&fakeStreamPacketSender{}))
+		require.ErrorIs(t, err, ErrStreamingDisabled)
+		require.Equal(t, 0, tc.pluginContext.calls)
+	})
+
+	t.Run("sends the query to the stream of the data source", func(t *testing.T) {
+		tc := setupStreaming(t)
+		mr := metricRequestWithQueries(t, `{"refId": "A", "datasource": {"uid": "ds1"}, "rawSql": "SELECT 1"}`)
+		packets := &fakeStreamPacketSender{}
+
+		err := tc.queryService.QueryDataStream(context.Background(), tc.signedInUser, mr, backend.NewStreamSender(packets))
+		require.NoError(t, err)
+		require.Len(t, packets.packets, 2)
+
+		req := tc.pluginContext.streamReq
+		require.Equal(t, queryStreamPath, req.Path)
+		require.Equal(t, tc.signedInUser.Login, req.PluginContext.User.Login)
+		require.Equal(t, "ds1", req.PluginContext.DataSourceInstanceSettings.UID)
+
+		var query backend.DataQuery
+		require.NoError(t, json.Unmarshal(req.Data, &query))
+		require.Equal(t, "A", query.RefID)
+		require.False(t, query.TimeRange.From.IsZero())
+		require.JSONEq(t, `{"refId": "A", "datasource": {"uid": "ds1"}, "rawSql": "SELECT 1"}`, string(query.JSON))
+	})
+
+	t.Run("rejects requests without a single query to a data source", func(t *testing.T) {
+		tc := setupStreaming(t)
+
+		mr := metricRequestWithQueries(t,
+			`{"refId": "A", "datasource": {"uid": "ds1"}}`,
+			`{"refId": "B", "datasource": {"uid": "ds1"}}`,
+		)
+		err := tc.queryService.QueryDataStream(context.Background(), tc.signedInUser, mr, backend.NewStreamSender(&fakeStreamPacketSender{}))
+		require.ErrorIs(t, err, ErrStreamingSingleQuery)
+
+		mr = metricRequestWithQueries(t, `{"refId": "A", "datasource": {"uid": "__expr__"}, "type": "math", "expression": "1"}`)
+		err = tc.queryService.QueryDataStream(context.Background(), tc.signedInUser, mr, backend.NewStreamSender(&fakeStreamPacketSender{}))
+		require.ErrorIs(t, err, ErrStreamingSingleQuery)
+		require.Equal(t, 0, tc.pluginContext.calls)
+	})
+
+	t.Run("returns an error if the data source does not support streaming", func(t *testing.T) {
+		tc := setupStreaming(t)
+		tc.pluginContext.streamErr = backendplugin.ErrMethodNotImplemented
+		mr := metricRequestWithQueries(t, `{"refId": "A", "datasource": {"uid": "ds1"}}`)
+
+		err := tc.queryService.QueryDataStream(context.Background(), tc.signedInUser, mr, backend.NewStreamSender(&fakeStreamPacketSender{}))
+		require.ErrorIs(t, err, ErrStreamingNotSupported)
+	})
+
+	t.Run("returns the error of the data source", func(t *testing.T) {
+		tc := setupStreaming(t)
+		tc.pluginContext.streamErr = errors.New("table does not exist")
+		mr := metricRequestWithQueries(t, `{"refId": "A", "datasource": {"uid": "ds1"}}`)
+
+		err := tc.queryService.QueryDataStream(context.Background(), tc.signedInUser, mr, backend.NewStreamSender(&fakeStreamPacketSender{}))
+		require.ErrorIs(t, err, ErrStreamFailed)
+		require.ErrorIs(t, err, tc.pluginContext.streamErr)
+	})
+
+	t.Run("rejects requests that exceed the query limits", func(t *testing.T) {
+		tc := setupStreaming(t)
+		tc.queryService.queryLimiter = newQueryLimiter(setting.QueryLimitsSettings{MaxRequestsPerUserPerMinute: 1})
+		mr := metricRequestWithQueries(t, `{"refId": "A", "datasource": {"uid": "ds1"}}`)
+
+		err := tc.queryService.QueryDataStream(context.Background(), tc.signedInUser, mr, backend.NewStreamSender(&fakeStreamPacketSender{}))
+		require.NoError(t, err)
+		err = tc.queryService.QueryDataStream(context.Background(), tc.signedInUser, mr, backend.NewStreamSender(&fakeStreamPacketSender{}))
+		require.ErrorIs(t, err, ErrUserRateLimited)
+	})
+}
+
+type fakeStreamPacketSender struct {
+	packets []*backend.StreamPacket
+}
+
+func (s *fakeStreamPacketSender) Send(packet *backend.StreamPacket) error {
+	s.packets = append(s.packets, packet)
+	return nil
+}
@@ -361,6 +361,7 @@ type Cfg struct {
 	DataProxyIdleConnTimeout       int
 	ResponseLimit                  int64
 	DataProxyRowLimit              int64
+	DataProxyByteLimit             int64
 
 	// DistributedCache
 	RemoteCacheOptions *RemoteCacheOptions
@@ -479,6 +480,12 @@ type Cfg struct {
 
 	Search SearchSettings
 
+	QueryCaching QueryCachingSettings
+
+	QueryLimits QueryLimitsSettings
+
+	QueryStreaming QueryStreamingSettings
+
 	SecureSocksDSProxy SecureSocksDSProxySettings
 
 	// Access Control
@@ -1089,6 +1096,9 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	cfg.DashboardPreviews = readDashboardPreviewsSettings(iniFile)
 	cfg.Storage = readStorageSettings(iniFile)
 	cfg.Search = readSearchSettings(iniFile)
+	cfg.QueryCaching = readQueryCachingSettings(iniFile)
+	cfg.QueryLimits = readQueryLimitsSettings(iniFile)
+	cfg.QueryStreaming = readQueryStreamingSettings(iniFile)
 
 	cfg.SecureSocksDSProxy, err = readSecureSocksDSProxySettings(iniFile)
 	if err != nil {
@@ -18,6 +18,7 @@ func readDataProxySettings(iniFile *ini.File, cfg *Cfg) error {
 	cfg.DataProxyIdleConnTimeout = dataproxy.Key("idle_conn_timeout_seconds").MustInt(90)
 	cfg.ResponseLimit = dataproxy.Key("response_limit").MustInt64(0)
 	cfg.DataProxyRowLimit = dataproxy.Key("row_limit").MustInt64(defaultDataProxyRowLimit)
+	cfg.DataProxyByteLimit = dataproxy.Key("byte_limit").MustInt64(0)
 
 	if cfg.DataProxyRowLimit <= 0 {
 		cfg.DataProxyRowLimit = defaultDataProxyRowLimit
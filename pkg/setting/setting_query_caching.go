@@ -0,0 +1,34 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type QueryCachingSettings struct {
+	// Enabled enables the caching of the responses of data source queries in the remote cache.
+	Enabled bool
+	// TTL is how long the responses are cached, unless the data source sets its own TTL.
+	TTL time.Duration
+	// TimeRangeRounding is the precision of the time range of the queries in the cache key.
+	// Queries whose time ranges are equal after rounding down share the cached response.
+	TimeRangeRounding time.Duration
+	// MaxValueBytes is the maximum size of a cached response. Larger responses are not cached.
+	MaxValueBytes int64
+	// DeduplicationEnabled enables the coalescing of identical queries to a data source that are in flight
+	// at the same time. It is independent of the caching of the responses.
+	DeduplicationEnabled bool
+}
+
+func readQueryCachingSettings(iniFile *ini.File) QueryCachingSettings {
+	s := QueryCachingSettings{}
+
+	section := iniFile.Section("query_caching")
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.TTL = section.Key("ttl").MustDuration(time.Minute)
+	s.TimeRangeRounding = section.Key("time_range_rounding").MustDuration(time.Minute)
+	s.MaxValueBytes = section.Key("max_value_bytes").MustInt64(1024 * 1024)
+	s.DeduplicationEnabled = section.Key("deduplication_enabled").MustBool(false)
+	return s
+}
@@ -0,0 +1,40 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// QueryLimitsSettings are the limits of the queries to data sources. A zero value disables the limit.
+type QueryLimitsSettings struct {
+	// MaxConcurrentQueriesPerOrg is the maximum number of concurrent requests of an org to its data sources.
+	MaxConcurrentQueriesPerOrg int64
+	// MaxConcurrentQueriesPerDatasource is the maximum number of concurrent requests to a data source.
+	MaxConcurrentQueriesPerDatasource int64
+	// ConcurrencyWaitTimeout is how long a request waits for a concurrency slot before it is rejected.
+	// A zero value waits until the request is canceled or times out.
+	ConcurrencyWaitTimeout time.Duration
+	// MaxQueriesPerRequest is the maximum number of queries of a request.
+	MaxQueriesPerRequest int
+	// MaxTimeRange is the maximum span of the time range of a request.
+	MaxTimeRange time.Duration
+	// MaxRequestsPerUserPerMinute is the maximum rate of the requests of a user.
+	MaxRequestsPerUserPerMinute int
+	// RequestTimeout is the maximum duration of a request, including the time it waits for concurrency slots.
+	RequestTimeout time.Duration
+}
+
+func readQueryLimitsSettings(iniFile *ini.File) QueryLimitsSettings {
+	s := QueryLimitsSettings{}
+
+	section := iniFile.Section("query_limits")
+	s.MaxConcurrentQueriesPerOrg = section.Key("max_concurrent_queries_per_org").MustInt64(0)
+	s.MaxConcurrentQueriesPerDatasource = section.Key("max_concurrent_queries_per_datasource").MustInt64(0)
+	s.ConcurrencyWaitTimeout = section.Key("concurrency_wait_timeout").MustDuration(10 * time.Second)
+	s.MaxQueriesPerRequest = section.Key("max_queries_per_request").MustInt(0)
+	s.MaxTimeRange = section.Key("max_time_range").MustDuration(0)
+	s.MaxRequestsPerUserPerMinute = section.Key("max_requests_per_user_per_minute").MustInt(0)
+	s.RequestTimeout = section.Key("request_timeout").MustDuration(0)
+	return s
+}
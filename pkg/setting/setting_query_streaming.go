@@ -0,0 +1,29 @@
+package setting
+
+import (
+	"gopkg.in/ini.v1"
+)
+
+// QueryStreamingSettings are the settings of the streaming of query results, which sends the rows of a query
+// in chunks instead of a single response.
+type QueryStreamingSettings struct {
+	// Enabled allows the streaming of query results.
+	Enabled bool
+	// ChunkSize is the number of rows of each chunk.
+	ChunkSize int64
+	// RowLimit is the maximum number of rows of a streamed query. A zero value disables the limit.
+	RowLimit int64
+}
+
+func readQueryStreamingSettings(iniFile *ini.File) QueryStreamingSettings {
+	s := QueryStreamingSettings{}
+
+	section := iniFile.Section("query_streaming")
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.ChunkSize = section.Key("chunk_size").MustInt64(1000)
+	if s.ChunkSize <= 0 {
+		s.ChunkSize = 1000
+	}
+	s.RowLimit = section.Key("row_limit").MustInt64(0)
+	return s
+}
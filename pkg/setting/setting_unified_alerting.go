@@ -52,6 +52,10 @@ const (
 	screenshotsDefaultCapture               = false
 	screenshotsDefaultMaxConcurrent         = 5
 	screenshotsDefaultUploadImageStorage    = false
+	stateHistoryDefaultEnabled              = false
+	stateHistoryDefaultBackend              = "sql"
+	stateHistoryDefaultRetention            = 30 * 24 * time.Hour
+	recordingRulesDefaultRemoteWriteTimeout = 30 * time.Second
 	// SchedulerBaseInterval base interval of the scheduler. Controls how often the scheduler fetches database for new changes as well as schedules evaluation of a rule
 	// changing this value is discouraged because this could cause existing alert definition
 	// with intervals that are not exactly divided by this number not to be evaluated
@@ -69,6 +73,7 @@ type UnifiedAlertingSettings struct {
 	HAPeerTimeout                  time.Duration
 	HAGossipInterval               time.Duration
 	HAPushPullInterval             time.Duration
+	HARuleSharding                 bool // determines whether each rule group is evaluated by only one instance of the HA cluster.
 	MaxAttempts                    int64
 	MinInterval                    time.Duration
 	EvaluationTimeout              time.Duration
@@ -83,6 +88,10 @@ type UnifiedAlertingSettings struct {
 	DefaultRuleEvaluationInterval time.Duration
 	Screenshots                   UnifiedAlertingScreenshotSettings
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
+	StateHistory                  UnifiedAlertingStateHistorySettings
+	RecordingRules                UnifiedAlertingRecordingRulesSettings
+	// StateSnapshotInterval is the interval between the snapshots of the state of the alerts. Snapshots are disabled if it is 0.
+	StateSnapshotInterval time.Duration
 }
 
 type UnifiedAlertingScreenshotSettings struct {
@@ -95,6 +104,26 @@ type UnifiedAlertingReservedLabelSettings struct {
 	DisabledLabels map[string]struct{}
 }
 
+// UnifiedAlertingStateHistorySettings configures the history of the evaluations of the alert rules.
+// State changes are always recorded as annotations, the history records every evaluation.
+type UnifiedAlertingStateHistorySettings struct {
+	Enabled bool
+	// Backend is where the evaluations are stored, either "sql" or "loki".
+	Backend       string
+	LokiRemoteURL string
+	// Retention is how long evaluations are kept by the sql backend.
+	Retention time.Duration
+}
+
+// UnifiedAlertingRecordingRulesSettings configures where the recording rules write their series.
+type UnifiedAlertingRecordingRulesSettings struct {
+	// RemoteWriteURL is the Prometheus remote write endpoint of the recording rules with the remote_write target.
+	RemoteWriteURL               string
+	RemoteWriteBasicAuthUsername string
+	RemoteWriteBasicAuthPassword string
+	RemoteWriteTimeout           time.Duration
+}
+
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
 // It hides the implementation details of the Enabled and simplifies its usage.
 func (u *UnifiedAlertingSettings) IsEnabled() bool {
@@ -212,6 +241,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 			uaCfg.HAPeers = append(uaCfg.HAPeers, peer)
 		}
 	}
+	uaCfg.HARuleSharding = ua.Key("ha_rule_sharding").MustBool(false)
 
 	// TODO load from ini file
 	uaCfg.DefaultConfiguration = alertmanagerDefaultConfiguration
@@ -277,6 +307,14 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		uaCfg.DefaultRuleEvaluationInterval = uaMinInterval
 	}
 
+	uaCfg.StateSnapshotInterval, err = gtime.ParseDuration(valueAsString(ua, "state_snapshot_interval", "0s"))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'state_snapshot_interval': %w", err)
+	}
+	if uaCfg.StateSnapshotInterval < 0 {
+		return errors.New("value of setting 'state_snapshot_interval' should not be negative")
+	}
+
 	screenshots := iniFile.Section("unified_alerting.screenshots")
 	uaCfgScreenshots := uaCfg.Screenshots
 
@@ -294,6 +332,41 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	}
 	uaCfg.ReservedLabels = uaCfgReservedLabels
 
+	stateHistory := iniFile.Section("unified_alerting.state_history")
+	uaCfgStateHistory := UnifiedAlertingStateHistorySettings{
+		Enabled:       stateHistory.Key("enabled").MustBool(stateHistoryDefaultEnabled),
+		Backend:       stateHistory.Key("backend").MustString(stateHistoryDefaultBackend),
+		LokiRemoteURL: stateHistory.Key("loki_remote_url").MustString(""),
+	}
+	uaCfgStateHistory.Retention, err = gtime.ParseDuration(valueAsString(stateHistory, "retention", (stateHistoryDefaultRetention).String()))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'retention' in section 'unified_alerting.state_history': %w", err)
+	}
+	if uaCfgStateHistory.Enabled {
+		switch uaCfgStateHistory.Backend {
+		case "sql":
+		case "loki":
+			if uaCfgStateHistory.LokiRemoteURL == "" {
+				return errors.New("setting 'loki_remote_url' in section 'unified_alerting.state_history' is required for the loki backend")
+			}
+		default:
+			return fmt.Errorf("unsupported state history backend %q, expected one of sql, loki", uaCfgStateHistory.Backend)
+		}
+	}
+	uaCfg.StateHistory = uaCfgStateHistory
+
+	recordingRules := iniFile.Section("unified_alerting.recording_rules")
+	uaCfgRecordingRules := UnifiedAlertingRecordingRulesSettings{
+		RemoteWriteURL:               recordingRules.Key("remote_write_url").MustString(""),
+		RemoteWriteBasicAuthUsername: recordingRules.Key("remote_write_basic_auth_username").MustString(""),
+		RemoteWriteBasicAuthPassword: recordingRules.Key("remote_write_basic_auth_password").MustString(""),
+	}
+	uaCfgRecordingRules.RemoteWriteTimeout, err = gtime.ParseDuration(valueAsString(recordingRules, "remote_write_timeout", recordingRulesDefaultRemoteWriteTimeout.String()))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'remote_write_timeout' in section 'unified_alerting.recording_rules': %w", err)
+	}
+	uaCfg.RecordingRules = uaCfgRecordingRules
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
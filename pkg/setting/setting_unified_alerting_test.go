@@ -25,6 +25,8 @@ func TestCfg_ReadUnifiedAlertingSettings(t *testing.T) {
 		require.Len(t, cfg.UnifiedAlerting.HAPeers, 0)
 		require.Equal(t, 200*time.Millisecond, cfg.UnifiedAlerting.HAGossipInterval)
 		require.Equal(t, time.Minute, cfg.UnifiedAlerting.HAPushPullInterval)
+		require.False(t, cfg.UnifiedAlerting.HARuleSharding)
+		require.Equal(t, time.Duration(0), cfg.UnifiedAlerting.StateSnapshotInterval)
 	}
 
 	// With peers set, it correctly parses them.
@@ -172,6 +174,125 @@ func TestUnifiedAlertingSettings(t *testing.T) {
 	}
 }
 
+func TestStateHistorySettings(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		settings    map[string]string
+		expected    UnifiedAlertingStateHistorySettings
+		expectedErr string
+	}{
+		{
+			desc:     "defaults",
+			settings: map[string]string{},
+			expected: UnifiedAlertingStateHistorySettings{Backend: "sql", Retention: 30 * 24 * time.Hour},
+		},
+		{
+			desc:     "sql backend",
+			settings: map[string]string{"enabled": "true", "retention": "7d"},
+			expected: UnifiedAlertingStateHistorySettings{Enabled: true, Backend: "sql", Retention: 7 * 24 * time.Hour},
+		},
+		{
+			desc:     "loki backend",
+			settings: map[string]string{"enabled": "true", "backend": "loki", "loki_remote_url": "http://localhost:3100"},
+			expected: UnifiedAlertingStateHistorySettings{Enabled: true, Backend: "loki", LokiRemoteURL: "http://localhost:3100", Retention: 30 * 24 * time.Hour},
+		},
+		{
+			desc:        "loki backend without url",
+			settings:    map[string]string{"enabled": "true", "backend": "loki"},
+			expectedErr: "loki_remote_url",
+		},
+		{
+			desc:        "unknown backend",
+			settings:    map[string]string{"enabled": "true", "backend": "file"},
+			expectedErr: "unsupported state history backend",
+		},
+		{
+			desc:        "invalid retention",
+			settings:    map[string]string{"retention": "forever"},
+			expectedErr: "retention",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := ini.Empty()
+			s, err := f.NewSection("unified_alerting.state_history")
+			require.NoError(t, err)
+			for k, v := range tc.settings {
+				_, err := s.NewKey(k, v)
+				require.NoError(t, err)
+			}
+
+			cfg := NewCfg()
+			cfg.IsFeatureToggleEnabled = func(key string) bool { return false }
+			err = cfg.ReadUnifiedAlertingSettings(f)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cfg.UnifiedAlerting.StateHistory)
+		})
+	}
+}
+
+func TestRecordingRulesSettings(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		settings    map[string]string
+		expected    UnifiedAlertingRecordingRulesSettings
+		expectedErr string
+	}{
+		{
+			desc:     "defaults",
+			settings: map[string]string{},
+			expected: UnifiedAlertingRecordingRulesSettings{RemoteWriteTimeout: 30 * time.Second},
+		},
+		{
+			desc: "remote write",
+			settings: map[string]string{
+				"remote_write_url":                 "http://localhost:9090/api/v1/write",
+				"remote_write_basic_auth_username": "user",
+				"remote_write_basic_auth_password": "secret",
+				"remote_write_timeout":             "5s",
+			},
+			expected: UnifiedAlertingRecordingRulesSettings{
+				RemoteWriteURL:               "http://localhost:9090/api/v1/write",
+				RemoteWriteBasicAuthUsername: "user",
+				RemoteWriteBasicAuthPassword: "secret",
+				RemoteWriteTimeout:           5 * time.Second,
+			},
+		},
+		{
+			desc:        "invalid timeout",
+			settings:    map[string]string{"remote_write_timeout": "soon"},
+			expectedErr: "remote_write_timeout",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := ini.Empty()
+			s, err := f.NewSection("unified_alerting.recording_rules")
+			require.NoError(t, err)
+			for k, v := range tc.settings {
+				_, err := s.NewKey(k, v)
+				require.NoError(t, err)
+			}
+
+			cfg := NewCfg()
+			cfg.IsFeatureToggleEnabled = func(key string) bool { return false }
+			err = cfg.ReadUnifiedAlertingSettings(f)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cfg.UnifiedAlerting.RecordingRules)
+		})
+	}
+}
+
 func TestMinInterval(t *testing.T) {
 	randPredicate := func(predicate func(dur time.Duration) bool) *time.Duration {
 		for {
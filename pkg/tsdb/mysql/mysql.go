@@ -117,12 +117,16 @@ func newInstanceSettings(cfg *setting.Cfg, httpClientProvider httpclient.Provide
 		}
 
 		config := sqleng.DataPluginConfiguration{
-			DriverName:        "mysql",
-			ConnectionString:  cnnstr,
-			DSInfo:            dsInfo,
-			TimeColumnNames:   []string{"time", "time_sec"},
-			MetricColumnTypes: []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
-			RowLimit:          cfg.DataProxyRowLimit,
+			DriverName:          "mysql",
+			ConnectionString:    cnnstr,
+			DSInfo:              dsInfo,
+			TimeColumnNames:     []string{"time", "time_sec"},
+			MetricColumnTypes:   []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
+			RowLimit:            cfg.DataProxyRowLimit,
+			ByteLimit:           cfg.DataProxyByteLimit,
+			StreamChunkSize:     cfg.QueryStreaming.ChunkSize,
+			StreamRowLimit:      cfg.QueryStreaming.RowLimit,
+			SupportsLimitClause: true,
 		}
 
 		rowTransformer := mysqlQueryResultTransformer{}
@@ -167,6 +171,33 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	return dsHandler.QueryData(ctx, req)
 }
 
+// SubscribeStream denies the subscriptions to streams, see sqleng.DataSourceHandler.SubscribeStream.
+func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	dsHandler, err := s.getDataSourceHandler(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.SubscribeStream(ctx, req)
+}
+
+// PublishStream denies the publications to streams.
+func (s *Service) PublishStream(ctx context.Context, req *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	dsHandler, err := s.getDataSourceHandler(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.PublishStream(ctx, req)
+}
+
+// RunStream streams the rows of a query in chunks.
+func (s *Service) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	dsHandler, err := s.getDataSourceHandler(req.PluginContext)
+	if err != nil {
+		return err
+	}
+	return dsHandler.RunStream(ctx, req, sender)
+}
+
 type mysqlQueryResultTransformer struct {
 }
 
@@ -51,6 +51,33 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	return dsInfo.QueryData(ctx, req)
 }
 
+// SubscribeStream denies the subscriptions to streams, see sqleng.DataSourceHandler.SubscribeStream.
+func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	dsInfo, err := s.getDSInfo(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsInfo.SubscribeStream(ctx, req)
+}
+
+// PublishStream denies the publications to streams.
+func (s *Service) PublishStream(ctx context.Context, req *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	dsInfo, err := s.getDSInfo(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsInfo.PublishStream(ctx, req)
+}
+
+// RunStream streams the rows of a query in chunks.
+func (s *Service) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	dsInfo, err := s.getDSInfo(req.PluginContext)
+	if err != nil {
+		return err
+	}
+	return dsInfo.RunStream(ctx, req, sender)
+}
+
 func (s *Service) newInstanceSettings(cfg *setting.Cfg) datasource.InstanceFactoryFunc {
 	return func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
 		logger.Debug("Creating Postgres query endpoint")
@@ -93,11 +120,15 @@ func (s *Service) newInstanceSettings(cfg *setting.Cfg) datasource.InstanceFacto
 		}
 
 		config := sqleng.DataPluginConfiguration{
-			DriverName:        "postgres",
-			ConnectionString:  cnnstr,
-			DSInfo:            dsInfo,
-			MetricColumnTypes: []string{"UNKNOWN", "TEXT", "VARCHAR", "CHAR"},
-			RowLimit:          cfg.DataProxyRowLimit,
+			DriverName:          "postgres",
+			ConnectionString:    cnnstr,
+			DSInfo:              dsInfo,
+			MetricColumnTypes:   []string{"UNKNOWN", "TEXT", "VARCHAR", "CHAR"},
+			RowLimit:            cfg.DataProxyRowLimit,
+			ByteLimit:           cfg.DataProxyByteLimit,
+			StreamChunkSize:     cfg.QueryStreaming.ChunkSize,
+			StreamRowLimit:      cfg.QueryStreaming.RowLimit,
+			SupportsLimitClause: true,
 		}
 
 		queryResultTransformer := postgresQueryResultTransformer{}
@@ -0,0 +1,117 @@
+package sqleng
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+)
+
+// limitClauseExclusions are the keywords of the queries whose results could change if a LIMIT clause was appended,
+// that could already limit their rows, or after which a LIMIT clause is invalid, for example the locking clauses
+// FOR UPDATE and LOCK IN SHARE MODE of MySQL.
+var limitClauseExclusions = regexp.MustCompile(`(?i)\b(limit|offset|fetch|into|for|lock|procedure|insert|update|delete)\b`)
+
+// limitClauseStatements are the statements whose rows can be limited with a LIMIT clause. Statements with common
+// table expressions are not limited, because their main statement can also modify data.
+var limitClauseStatements = regexp.MustCompile(`(?i)^\s*select\b`)
+
+// addLimitClause appends a LIMIT clause to the query, so that the database does not send more rows than the limit.
+// The query is not changed unless it is a single plain SELECT statement that does not limit its rows itself.
+func addLimitClause(query string, limit int64) string {
+	trimmed := strings.TrimRight(strings.TrimSpace(query), ";")
+	if !limitClauseStatements.MatchString(trimmed) || strings.Contains(trimmed, ";") || limitClauseExclusions.MatchString(trimmed) {
+		return query
+	}
+	// the clause is on its own line, so that it is not commented out by a comment on the last line of the query
+	return fmt.Sprintf("%s\nLIMIT %d", trimmed, limit)
+}
+
+// frameFromRows reads the rows into a frame like sqlutil.FrameFromRows. The rows are read until the row limit is
+// reached, or until the next row would exceed the byte limit, in which case the frame has the rows that do not exceed
+// it and frameFromRows also returns true. The byte limit is checked as the rows are read, so that the rows after it
+// are neither read nor kept in memory. A limit that is not positive disables the byte limit, and a negative row limit
+// disables the row limit.
+func frameFromRows(rows *sql.Rows, rowLimit int64, byteLimit int64, converters ...sqlutil.Converter) (*data.Frame, bool, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, false, err
+	}
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, false, err
+	}
+	scanner, converters, err := sqlutil.MakeScanRow(types, names, converters...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	frame := sqlutil.NewFrame(names, converters...)
+	var count, size int64
+	for rows.Next() {
+		if count == rowLimit {
+			frame.AppendNotices(data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Results have been limited to %v because the SQL row limit was reached", rowLimit),
+			})
+			break
+		}
+
+		r := scanner.NewScannableRow()
+		if err := rows.Scan(r...); err != nil {
+			return nil, false, err
+		}
+		if err := sqlutil.Append(frame, r, converters...); err != nil {
+			return nil, false, err
+		}
+		if byteLimit > 0 {
+			last := frame.Rows() - 1
+			size += rowSize(frame, last)
+			if size > byteLimit {
+				frame.DeleteRow(last)
+				return frame, true, nil
+			}
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return frame, false, err
+	}
+	return frame, false, nil
+}
+
+// rowSize returns the size of the row of the frame at the index. The size of a row is the length of its text and
+// binary values, and 8 bytes for other values.
+func rowSize(frame *data.Frame, i int) int64 {
+	var size int64
+	for _, field := range frame.Fields {
+		size += valueSize(field.At(i))
+	}
+	return size
+}
+
+func valueSize(v interface{}) int64 {
+	switch v := v.(type) {
+	case string:
+		return int64(len(v))
+	case *string:
+		if v == nil {
+			return 0
+		}
+		return int64(len(*v))
+	case []byte:
+		return int64(len(v))
+	case json.RawMessage:
+		return int64(len(v))
+	case *json.RawMessage:
+		if v == nil {
+			return 0
+		}
+		return int64(len(*v))
+	}
+	return 8
+}
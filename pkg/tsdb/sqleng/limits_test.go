@@ -0,0 +1,225 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestAddLimitClause(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected string
+	}{
+		{query: "SELECT * FROM t", expected: "SELECT * FROM t\nLIMIT 11"},
+		{query: "  select a, b from t order by a; ", expected: "select a, b from t order by a\nLIMIT 11"},
+		{query: "SELECT * FROM t -- comment", expected: "SELECT * FROM t -- comment\nLIMIT 11"},
+		{query: "WITH x AS (SELECT 1) SELECT * FROM x", expected: "WITH x AS (SELECT 1) SELECT * FROM x"},
+		{query: "WITH x AS (SELECT id FROM t) DELETE FROM u WHERE id IN (SELECT id FROM x)", expected: "WITH x AS (SELECT id FROM t) DELETE FROM u WHERE id IN (SELECT id FROM x)"},
+		{query: "WITH x AS (SELECT 1) UPDATE t SET a = 1", expected: "WITH x AS (SELECT 1) UPDATE t SET a = 1"},
+		{query: "SELECT * FROM t LIMIT 5", expected: "SELECT * FROM t LIMIT 5"},
+		{query: "SELECT * FROM t OFFSET 5 ROWS FETCH NEXT 5 ROWS ONLY", expected: "SELECT * FROM t OFFSET 5 ROWS FETCH NEXT 5 ROWS ONLY"},
+		{query: "SELECT * FROM t FOR UPDATE", expected: "SELECT * FROM t FOR UPDATE"},
+		{query: "SELECT * FROM t WHERE a = 1 LOCK IN SHARE MODE", expected: "SELECT * FROM t WHERE a = 1 LOCK IN SHARE MODE"},
+		{query: "SELECT 1; SELECT 2", expected: "SELECT 1; SELECT 2"},
+		{query: "SHOW TABLES", expected: "SHOW TABLES"},
+		{query: "CALL report()", expected: "CALL report()"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			require.Equal(t, tc.expected, addLimitClause(tc.query, 11))
+		})
+	}
+}
+
+func TestFrameFromRows(t *testing.T) {
+	handler := newSQLiteTestHandler(t, DataPluginConfiguration{})
+	converters := sqlutil.ToConverters((&sqliteQueryResultTransformer{}).GetConverterList()...)
+	read := func(t *testing.T, rowLimit int64, byteLimit int64) (*data.Frame, bool, *sql.Rows) {
+		t.Helper()
+		rows, err := handler.engine.DB().Query("SELECT time, metric, value FROM metrics ORDER BY time")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = rows.Close() })
+		frame, truncated, err := frameFromRows(rows.Rows, rowLimit, byteLimit, converters...)
+		require.NoError(t, err)
+		return frame, truncated, rows.Rows
+	}
+
+	t.Run("reads all rows if they do not exceed the limits", func(t *testing.T) {
+		frame, truncated, _ := read(t, -1, 170)
+		require.False(t, truncated)
+		require.Equal(t, 10, frame.Rows())
+		require.Nil(t, frame.Meta)
+	})
+
+	t.Run("stops reading at the row limit", func(t *testing.T) {
+		frame, truncated, _ := read(t, 3, 0)
+		require.False(t, truncated)
+		require.Equal(t, 3, frame.Rows())
+		require.Len(t, frame.Meta.Notices, 1)
+	})
+
+	t.Run("stops reading once the byte limit is exceeded", func(t *testing.T) {
+		// each row is 8 + 1 + 8 bytes
+		frame, truncated, rows := read(t, -1, 40)
+		require.True(t, truncated)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, int64(1600000000), *frame.Fields[0].At(1).(*int64))
+		// the rows after the limit are not read
+		require.True(t, rows.Next())
+	})
+}
+
+func TestExecuteQueryLimits(t *testing.T) {
+	query := func(t *testing.T, handler *DataSourceHandler, model string) backend.DataResponse {
+		t.Helper()
+		resp, err := handler.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				JSON:      []byte(model),
+				TimeRange: backend.TimeRange{From: time.Unix(1600000000, 0), To: time.Unix(1600000300, 0)},
+				Interval:  time.Minute,
+			}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, resp.Responses["A"].Error)
+		return resp.Responses["A"]
+	}
+
+	t.Run("pushes the row limit down to the database", func(t *testing.T) {
+		handler := newSQLiteTestHandler(t, DataPluginConfiguration{RowLimit: 3, SupportsLimitClause: true})
+		res := query(t, handler, `{"rawSql": "SELECT time, metric, value FROM metrics ORDER BY time", "format": "table"}`)
+
+		frame := res.Frames[0]
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, "SELECT time, metric, value FROM metrics ORDER BY time\nLIMIT 4", frame.Meta.ExecutedQueryString)
+		require.Len(t, frame.Meta.Notices, 1)
+		require.Equal(t, data.NoticeSeverityWarning, frame.Meta.Notices[0].Severity)
+	})
+
+	t.Run("keeps the notices of the truncation in time series", func(t *testing.T) {
+		handler := newSQLiteTestHandler(t, DataPluginConfiguration{RowLimit: 6})
+		res := query(t, handler, `{"rawSql": "SELECT time, metric, value FROM metrics ORDER BY time", "format": "time_series"}`)
+
+		frame := res.Frames[0]
+		require.Equal(t, 3, frame.Rows())
+		require.Len(t, frame.Meta.Notices, 1)
+	})
+
+	t.Run("the limits of the data source override the limits of the configuration", func(t *testing.T) {
+		handler := newSQLiteTestHandler(t, DataPluginConfiguration{
+			RowLimit:  100,
+			ByteLimit: 1000,
+			DSInfo:    DataSourceInfo{JsonData: JsonData{ByteLimit: 40}},
+		})
+		res := query(t, handler, `{"rawSql": "SELECT time, metric, value FROM metrics ORDER BY time", "format": "table"}`)
+
+		// each row is 8 + 1 + 8 bytes
+		frame := res.Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		require.Len(t, frame.Meta.Notices, 1)
+		require.Contains(t, frame.Meta.Notices[0].Text, "byte limit of 40 bytes")
+	})
+
+	t.Run("does not add notices if the results are not truncated", func(t *testing.T) {
+		handler := newSQLiteTestHandler(t, DataPluginConfiguration{RowLimit: 100, ByteLimit: 1000, SupportsLimitClause: true})
+		res := query(t, handler, `{"rawSql": "SELECT time, metric, value FROM metrics ORDER BY time", "format": "table"}`)
+
+		frame := res.Frames[0]
+		require.Equal(t, 10, frame.Rows())
+		require.Empty(t, frame.Meta.Notices)
+	})
+}
+
+// newSQLiteTestHandler returns a handler of an in-memory SQLite database with a metrics table of 10 rows.
+func newSQLiteTestHandler(t *testing.T, config DataPluginConfiguration) *DataSourceHandler {
+	t.Helper()
+	origXormEngine := NewXormEngine
+	t.Cleanup(func() {
+		NewXormEngine = origXormEngine
+	})
+	NewXormEngine = func(string, string) (*xorm.Engine, error) {
+		return xorm.NewEngine("sqlite3", ":memory:")
+	}
+
+	config.MetricColumnTypes = []string{"TEXT"}
+	// a single connection that is kept open, so that all queries use the same in-memory database
+	config.DSInfo.JsonData.MaxOpenConns = 1
+	config.DSInfo.JsonData.MaxIdleConns = 1
+	handler, err := NewQueryDataHandler(config, &sqliteQueryResultTransformer{}, &noopMacroEngine{}, log.New("test"))
+	require.NoError(t, err)
+	_, err = handler.engine.Exec("CREATE TABLE metrics (time INTEGER, metric TEXT, value REAL)")
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		for _, metric := range []string{"a", "b"} {
+			_, err = handler.engine.Exec("INSERT INTO metrics VALUES (?, ?, ?)", 1600000000+i*60, metric, float64(i))
+			require.NoError(t, err)
+		}
+	}
+	t.Cleanup(handler.Dispose)
+	return handler
+}
+
+// sqliteQueryResultTransformer converts the INTEGER and REAL columns of SQLite, which are scanned as strings by default.
+type sqliteQueryResultTransformer struct {
+	testQueryResultTransformer
+}
+
+func (t *sqliteQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	return []sqlutil.StringConverter{
+		{
+			Name:           "handle INTEGER",
+			InputScanKind:  reflect.Struct,
+			InputTypeName:  "INTEGER",
+			ConversionFunc: func(in *string) (*string, error) { return in, nil },
+			Replacer: &sqlutil.StringFieldReplacer{
+				OutputFieldType: data.FieldTypeNullableInt64,
+				ReplaceFunc: func(in *string) (interface{}, error) {
+					if in == nil {
+						return nil, nil
+					}
+					v, err := strconv.ParseInt(*in, 10, 64)
+					return &v, err
+				},
+			},
+		},
+		{
+			Name:           "handle REAL",
+			InputScanKind:  reflect.Struct,
+			InputTypeName:  "REAL",
+			ConversionFunc: func(in *string) (*string, error) { return in, nil },
+			Replacer: &sqlutil.StringFieldReplacer{
+				OutputFieldType: data.FieldTypeNullableFloat64,
+				ReplaceFunc: func(in *string) (interface{}, error) {
+					if in == nil {
+						return nil, nil
+					}
+					v, err := strconv.ParseFloat(*in, 64)
+					return &v, err
+				},
+			},
+		},
+	}
+}
+
+type noopMacroEngine struct{}
+
+func (m *noopMacroEngine) Interpolate(_ *backend.DataQuery, _ backend.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
+
+func strPtr(s string) *string {
+	return &s
+}
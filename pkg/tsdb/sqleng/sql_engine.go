@@ -67,6 +67,8 @@ type JsonData struct {
 	Servername          string `json:"servername"`
 	TimeInterval        string `json:"timeInterval"`
 	Database            string `json:"database"`
+	RowLimit            int64  `json:"rowLimit"`
+	ByteLimit           int64  `json:"byteLimit"`
 }
 
 type DataSourceInfo struct {
@@ -86,7 +88,15 @@ type DataPluginConfiguration struct {
 	ConnectionString  string
 	TimeColumnNames   []string
 	MetricColumnTypes []string
-	RowLimit          int64
+	// RowLimit and ByteLimit are the limits of the results of the queries, unless the data source sets its own.
+	RowLimit  int64
+	ByteLimit int64
+	// SupportsLimitClause is true if the dialect limits the rows of a query with a LIMIT clause at its end.
+	SupportsLimitClause bool
+	// StreamChunkSize is the number of rows of each frame of a streamed query, and StreamRowLimit the maximum
+	// number of rows of a streamed query.
+	StreamChunkSize int64
+	StreamRowLimit  int64
 }
 type DataSourceHandler struct {
 	macroEngine            SQLMacroEngine
@@ -97,6 +107,10 @@ type DataSourceHandler struct {
 	log                    log.Logger
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
+	byteLimit              int64
+	limitClause            bool
+	streamChunkSize        int64
+	streamRowLimit         int64
 }
 type QueryJson struct {
 	RawSql       string  `json:"rawSql"`
@@ -135,6 +149,17 @@ func NewQueryDataHandler(config DataPluginConfiguration, queryResultTransformer
 		log:                    log,
 		dsInfo:                 config.DSInfo,
 		rowLimit:               config.RowLimit,
+		byteLimit:              config.ByteLimit,
+		limitClause:            config.SupportsLimitClause,
+		streamChunkSize:        config.StreamChunkSize,
+		streamRowLimit:         config.StreamRowLimit,
+	}
+
+	if config.DSInfo.JsonData.RowLimit > 0 {
+		queryDataHandler.rowLimit = config.DSInfo.JsonData.RowLimit
+	}
+	if config.DSInfo.JsonData.ByteLimit > 0 {
+		queryDataHandler.byteLimit = config.DSInfo.JsonData.ByteLimit
 	}
 
 	if len(config.TimeColumnNames) > 0 {
@@ -265,6 +290,11 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
+	// the limit is one row more than the row limit, so that the truncation of the rows is detected
+	if e.limitClause && e.rowLimit > 0 {
+		interpolatedQuery = addLimitClause(interpolatedQuery, e.rowLimit+1)
+	}
+
 	session := e.engine.NewSession()
 	defer session.Close()
 	db := session.DB()
@@ -288,7 +318,7 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	// Convert row.Rows to dataframe
 	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows.Rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	frame, truncated, err := frameFromRows(rows.Rows, e.rowLimit, e.byteLimit, sqlutil.ToConverters(stringConverters...)...)
 	if err != nil {
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery)
 		return
@@ -300,9 +330,24 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	frame.Meta.ExecutedQueryString = interpolatedQuery
 
+	// The notices of the truncation of the rows are added to the final frame, because the conversions
+	// of time series replace the frame.
+	notices := frame.Meta.Notices
+	frame.Meta.Notices = nil
+	if truncated {
+		notices = append(notices, data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("Results have been limited to %d rows because the SQL byte limit of %d bytes was reached", frame.Rows(), e.byteLimit),
+		})
+	}
+
 	// If no rows were returned, no point checking anything else.
 	if frame.Rows() == 0 {
 		queryResult.dataResponse.Frames = data.Frames{}
+		if len(notices) > 0 {
+			frame.AppendNotices(notices...)
+			queryResult.dataResponse.Frames = data.Frames{frame}
+		}
 		ch <- queryResult
 		return
 	}
@@ -373,6 +418,7 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		}
 	}
 
+	frame.AppendNotices(notices...)
 	queryResult.dataResponse.Frames = data.Frames{frame}
 	ch <- queryResult
 }
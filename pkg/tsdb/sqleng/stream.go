@@ -0,0 +1,185 @@
+package sqleng
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+)
+
+// QueryStreamPath is the path of the streams that run a query and send its rows in chunks. The data of the request
+// of the stream is the JSON of the backend.DataQuery.
+const QueryStreamPath = "query"
+
+const defaultStreamChunkSize = 1000
+
+// ErrStreamFormatNotSupported is returned when a query in time series format is streamed, because its frame is
+// built from all the rows of the query.
+var ErrStreamFormatNotSupported = errors.New("only queries in table format can be streamed")
+
+// SubscribeStream denies the subscriptions to streams, because the streams of queries are run by the query API
+// and not through Grafana Live.
+func (e *DataSourceHandler) SubscribeStream(_ context.Context, _ *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	return &backend.SubscribeStreamResponse{
+		Status: backend.SubscribeStreamStatusPermissionDenied,
+	}, nil
+}
+
+// PublishStream denies the publications to streams.
+func (e *DataSourceHandler) PublishStream(_ context.Context, _ *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return &backend.PublishStreamResponse{
+		Status: backend.PublishStreamStatusPermissionDenied,
+	}, nil
+}
+
+// RunStream runs the query of the request and sends its rows in frames of up to the chunk size. The rows are read
+// from the database only when the previous frame has been sent, so that a slow receiver slows down the query instead
+// of the rows being buffered, and the query is canceled when the context is done.
+func (e *DataSourceHandler) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	if req.Path != QueryStreamPath {
+		return fmt.Errorf("unknown stream path: %s", req.Path)
+	}
+
+	var query backend.DataQuery
+	if err := json.Unmarshal(req.Data, &query); err != nil {
+		return fmt.Errorf("error unmarshal stream query: %w", err)
+	}
+	queryJson := QueryJson{
+		Format: "time_series",
+	}
+	if err := json.Unmarshal(query.JSON, &queryJson); err != nil {
+		return fmt.Errorf("error unmarshal query json: %w", err)
+	}
+	if queryJson.RawSql == "" {
+		return errors.New("query model property rawSql is empty")
+	}
+	if queryJson.Format != string(dataQueryFormatTable) {
+		return ErrStreamFormatNotSupported
+	}
+
+	logger := e.log.FromContext(ctx)
+
+	interpolatedQuery, err := Interpolate(query, query.TimeRange, e.dsInfo.JsonData.TimeInterval, queryJson.RawSql)
+	if err != nil {
+		return fmt.Errorf("interpolation failed: %w", e.TransformQueryError(logger, err))
+	}
+	interpolatedQuery, err = e.macroEngine.Interpolate(&query, query.TimeRange, interpolatedQuery)
+	if err != nil {
+		return fmt.Errorf("interpolation failed: %w", e.TransformQueryError(logger, err))
+	}
+	// the row limit of the queries does not apply to the streamed queries, unless it is set by the data source
+	rowLimit := e.streamRowLimit
+	if limit := e.dsInfo.JsonData.RowLimit; limit > 0 && (rowLimit <= 0 || limit < rowLimit) {
+		rowLimit = limit
+	}
+	if e.limitClause && rowLimit > 0 {
+		interpolatedQuery = addLimitClause(interpolatedQuery, rowLimit+1)
+	}
+
+	session := e.engine.NewSession()
+	defer session.Close()
+	db := session.DB()
+
+	rows, err := db.QueryContext(ctx, interpolatedQuery)
+	if err != nil {
+		return fmt.Errorf("db query error: %w", e.TransformQueryError(logger, err))
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Warn("Failed to close rows", "err", err)
+		}
+	}()
+
+	qm, err := e.newProcessCfg(query, ctx, rows, interpolatedQuery)
+	if err != nil {
+		return fmt.Errorf("failed to get configurations: %w", err)
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	scanner, converters, err := sqlutil.MakeScanRow(types, qm.columnNames, sqlutil.ToConverters(e.queryResultTransformer.GetConverterList()...)...)
+	if err != nil {
+		return fmt.Errorf("convert frame from rows error: %w", err)
+	}
+
+	chunkSize := e.streamChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultStreamChunkSize
+	}
+
+	sent := 0
+	send := func(frame *data.Frame) error {
+		if err := convertSQLTimeColumnsToEpochMS(frame, qm); err != nil {
+			return fmt.Errorf("converting time columns failed: %w", err)
+		}
+		frame.RefID = query.RefID
+		// the executed query is only added to the first frame, so that it is not repeated in every chunk
+		if sent == 0 {
+			if frame.Meta == nil {
+				frame.Meta = &data.FrameMeta{}
+			}
+			frame.Meta.ExecutedQueryString = interpolatedQuery
+		}
+		sent++
+		return sender.SendFrame(frame, data.IncludeAll)
+	}
+
+	frame := sqlutil.NewFrame(qm.columnNames, converters...)
+	var count, size int64
+	for rows.Next() {
+		if rowLimit > 0 && count == rowLimit {
+			frame.AppendNotices(data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Results have been limited to %v because the SQL row limit of streamed queries was reached", rowLimit),
+			})
+			break
+		}
+
+		r := scanner.NewScannableRow()
+		if err := rows.Scan(r...); err != nil {
+			return err
+		}
+		if err := sqlutil.Append(frame, r, converters...); err != nil {
+			return err
+		}
+		// the byte limit applies to the rows of all the frames of the stream
+		if e.byteLimit > 0 {
+			last := frame.Rows() - 1
+			size += rowSize(frame, last)
+			if size > e.byteLimit {
+				frame.DeleteRow(last)
+				frame.AppendNotices(data.Notice{
+					Severity: data.NoticeSeverityWarning,
+					Text:     fmt.Sprintf("Results have been limited to %d rows because the SQL byte limit of %d bytes was reached", count, e.byteLimit),
+				})
+				break
+			}
+		}
+		count++
+
+		if int64(frame.Rows()) == chunkSize {
+			if err := send(frame); err != nil {
+				return err
+			}
+			frame = sqlutil.NewFrame(qm.columnNames, converters...)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := rows.Err(); err != nil {
+		return e.TransformQueryError(logger, err)
+	}
+
+	// the last frame is sent even if it is empty, so that the receiver gets the schema of a query without rows
+	// and the notices of the truncation
+	if frame.Rows() > 0 || sent == 0 || frame.Meta != nil {
+		return send(frame)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package sqleng
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunStream(t *testing.T) {
+	run := func(t *testing.T, handler *DataSourceHandler, ctx context.Context, model string, sender backend.StreamPacketSender) error {
+		t.Helper()
+		query, err := json.Marshal(backend.DataQuery{
+			RefID:     "A",
+			JSON:      []byte(model),
+			TimeRange: backend.TimeRange{From: time.Unix(1600000000, 0), To: time.Unix(1600000300, 0)},
+			Interval:  time.Minute,
+		})
+		require.NoError(t, err)
+		return handler.RunStream(ctx, &backend.RunStreamRequest{Path: QueryStreamPath, Data: query}, backend.NewStreamSender(sender))
+	}
+
+	t.Run("sends the rows in frames of the chunk size", func(t *testing.T) {
+		handler := newSQLiteTestHandler(t, DataPluginConfiguration{RowLimit: 2, StreamChunkSize: 4})
+		sender := &fakeStreamPacketSender{}
+		err := run(t, handler, context.Background(), `{"rawSql": "SELECT time, metric, value FROM metrics ORDER BY time", "format": "table"}`, sender)
+		require.NoError(t, err)
+
+		frames := sender.frames(t)
+		require.Len(t, frames, 3)
+		require.Equal(t, 4, frames[0].Rows())
+		require.Equal(t, 4, frames[1].Rows())
+		require.Equal(t, 2, frames[2].Rows())
+		require.Equal(t, "A", frames[0].RefID)
+		require.Equal(t, "SELECT time, metric, value FROM metrics ORDER BY time", frames[0].Meta.ExecutedQueryString)
+		require.Nil(t, frames[1].Meta)
+		// the row limit of the queries does not apply to the streamed queries
+		require.Nil(t, frames[2].Meta)
+	})
+
+	t.Run("truncates the rows at the row limit of the streamed queries", func(t *testing.T) {
+		handler := newSQLiteTestHandler(t, DataPluginConfiguration{StreamChunkSize: 4, StreamRowLimit: 6, SupportsLimitClause: true})
+		sender := &fakeStreamPacketSender{}
+		err := run(t, handler, context.Background(), `{"rawSql": "SELECT time, metric, value FROM metrics ORDER BY time", "format": "table"}`, sender)
+		require.NoError(t, err)
+
+		frames := sender.frames(t)
+		require.Len(t, frames, 2)
+		require.Equal(t, "SELECT time, metric, value FROM metrics ORDER BY time\nLIMIT 7", frames[0].Meta.ExecutedQueryString)
+		require.Equal(t, 2, frames[1].Rows())
+		require.Len(t, frames[1].Meta.Notices, 1)
+		require.Equal(t, data.NoticeSeverityWarning, frames[1].Meta.Notices[0].Severity)
+	})
+
+	t.Run("truncates the rows at the row limit of the data source", func(t *testing.T) {
+		config := DataPluginConfiguration{StreamChunkSize: 4, StreamRowLimit: 8}
+		config.DSInfo.JsonData.RowLimit = 5
+		handler := newSQLiteTestHandler(t, config)
+		sender := &fakeStreamPacketSender{}
+		err := run(t, handler, context.Background(), `{"rawSql": "SELECT time, metric, value FROM metrics ORDER BY time", "format": "table"}`, sender)
+		require.NoError(t, err)
+
+		frames := sender.frames(t)
+		require.Len(t, frames, 2)
+		require.Equal(t, 4, frames[0].Rows())
+		require.Equal(t, 1, frames[1].Rows())
+		require.Len(t, frames[1].Meta.Notices, 1)
+		require.Contains(t, frames[1].Meta.Notices[0].Text, "limited to 5")
+	})
+
+	t.Run("truncates the rows of all frames at the byte limit", func(t *testing.T) {
+		// each row has 17 bytes: 8 bytes for the time and the value, and 1 byte for the metric
+		config := DataPluginConfiguration{StreamChunkSize: 2, ByteLimit: 1000}
+		config.DSInfo.JsonData.ByteLimit = 60
+		handler := newSQLiteTestHandler(t, config)
+		sender := &fakeStreamPacketSender{}
+		err := run(t, handler, context.Background(), `{"rawSql": "SELECT time, metric, value FROM metrics ORDER BY time", "format": "table"}`, sender)
+		require.NoError(t, err)
+
+		frames := sender.frames(t)
+		require.Len(t, frames, 2)
+		require.Equal(t, 2, frames[0].Rows())
+		require.Equal(t, 1, frames[1].Rows())
+		require.Len(t, frames[1].Meta.Notices, 1)
+		require.Equal(t, data.NoticeSeverityWarning, frames[1].Meta.Notices[0].Severity)
+		require.Equal(t, "Results have been limited to 3 rows because the SQL byte limit of 60 bytes was reached", frames[1].Meta.Notices[0].Text)
+	})
+
+	t.Run("sends an empty frame if the query has no rows", func(t *testing.T) {
+		handler := newSQLiteTestHandler(t, DataPluginConfiguration{StreamChunkSize: 4})
+		sender := &fakeStreamPacketSender{}
+		err := run(t, handler, context.Background(), `{"rawSql": "SELECT time, metric, value FROM metrics WHERE metric = 'c'", "format": "table"}`, sender)
+		require.NoError(t, err)
+
+		frames := sender.frames(t)
+		require.Len(t, frames, 1)
+		require.Equal(t, 0, frames[0].Rows())
+		require.Len(t, frames[0].Fields, 3)
+	})
+
+	t.Run("stops the query if the frame cannot be sent", func(t *testing.T) {
+		handler := newSQLiteTestHandler(t, DataPluginConfiguration{StreamChunkSize: 4})
+		errSend := errors.New("client gone")
+		sender := &fakeStreamPacketSender{err: errSend}
+		err := run(t, handler, context.Background(), `{"rawSql": "SELECT time, metric, value FROM metrics", "format": "table"}`, sender)
+		require.ErrorIs(t, err, errSend)
+		require.Len(t, sender.packets, 1)
+	})
+
+	t.Run("returns the error of the context if it is canceled", func(t *testing.T) {
+		handler := newSQLiteTestHandler(t, DataPluginConfiguration{StreamChunkSize: 4})
+		ctx, cancel := context.WithCancel(context.Background())
+		sender := &fakeStreamPacketSender{onSend: cancel}
+		err := run(t, handler, ctx, `{"rawSql": "SELECT time, metric, value FROM metrics", "format": "table"}`, sender)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Len(t, sender.packets, 1)
+	})
+
+	t.Run("rejects queries in time series format", func(t *testing.T) {
+		handler := newSQLiteTestHandler(t, DataPluginConfiguration{})
+		err := run(t, handler, context.Background(), `{"rawSql": "SELECT time, metric, value FROM metrics", "format": "time_series"}`, &fakeStreamPacketSender{})
+		require.ErrorIs(t, err, ErrStreamFormatNotSupported)
+	})
+}
+
+type fakeStreamPacketSender struct {
+	packets []*backend.StreamPacket
+	err     error
+	onSend  func()
+}
+
+func (s *fakeStreamPacketSender) Send(packet *backend.StreamPacket) error {
+	s.packets = append(s.packets, packet)
+	if s.onSend != nil {
+		s.onSend()
+	}
+	return s.err
+}
+
+func (s *fakeStreamPacketSender) frames(t *testing.T) []*data.Frame {
+	t.Helper()
+	frames := make([]*data.Frame, 0, len(s.packets))
+	for _, packet := range s.packets {
+		frame := &data.Frame{}
+		require.NoError(t, json.Unmarshal(packet.Data, frame))
+		frames = append(frames, frame)
+	}
+	return frames
+}
@@ -37,6 +37,10 @@ const (
 	// an error, but that there is nothing the client can do to fix it.
 	// HTTP status code 500.
 	StatusInternal CoreStatus = "Internal server error"
+	// StatusBadGateway means that the server, while acting as a proxy,
+	// received an invalid response from the downstream server.
+	// HTTP status code 502.
+	StatusBadGateway CoreStatus = "Bad gateway"
 	// StatusTimeout means that the server did not complete the request
 	// within the required time and aborted the action.
 	// HTTP status code 504.
@@ -69,6 +73,8 @@ func (s CoreStatus) HTTPStatus() int {
 		return http.StatusForbidden
 	case StatusNotFound:
 		return http.StatusNotFound
+	case StatusBadGateway:
+		return http.StatusBadGateway
 	case StatusTimeout:
 		return http.StatusGatewayTimeout
 	case StatusTooManyRequests:
@@ -103,7 +109,7 @@ func (s CoreStatus) LogLevel() LogLevel {
 		return LevelDebug
 	case StatusNotImplemented:
 		return LevelDebug
-	case StatusUnknown, StatusInternal:
+	case StatusUnknown, StatusInternal, StatusBadGateway:
 		return LevelError
 	default:
 		return LevelUnknown
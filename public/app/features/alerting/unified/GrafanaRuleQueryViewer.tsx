@@ -18,6 +18,7 @@ import {
   reducerModes,
   ReducerMode,
   reducerTypes,
+  ruleStateOutputs,
   thresholdFunctions,
   upsamplingTypes,
 } from '../../expressions/types';
@@ -158,6 +159,7 @@ function ExpressionPreview({ refId, model, evalData, isAlertCondition }: Express
   function renderPreview() {
     switch (model.type) {
       case ExpressionQueryType.math:
+      case ExpressionQueryType.sql:
         return <MathExpressionViewer model={model} />;
 
       case ExpressionQueryType.reduce:
@@ -166,6 +168,12 @@ function ExpressionPreview({ refId, model, evalData, isAlertCondition }: Express
       case ExpressionQueryType.resample:
         return <ResampleExpressionViewer model={model} />;
 
+      case ExpressionQueryType.anomaly:
+        return <MathExpressionViewer model={model} />;
+
+      case ExpressionQueryType.ruleState:
+        return <RuleStateExpressionViewer model={model} />;
+
       case ExpressionQueryType.classic:
         return <ClassicConditionViewer model={model} />;
 
@@ -357,6 +365,30 @@ const getResampleExpressionViewerStyles = (theme: GrafanaTheme2) => ({
   ...getCommonQueryStyles(theme),
 });
 
+function RuleStateExpressionViewer({ model }: { model: ExpressionQuery }) {
+  const styles = useStyles2(getResampleExpressionViewerStyles);
+
+  const { ruleUid, output, valueFrom } = model;
+  const outputType = ruleStateOutputs.find((o) => o.value === output);
+
+  return (
+    <div className={styles.container}>
+      <div className={styles.label}>Rule</div>
+      <div className={styles.value}>{ruleUid}</div>
+
+      <div className={styles.label}>Output</div>
+      <div className={styles.value}>{outputType?.label}</div>
+
+      {output === 'value' && (
+        <>
+          <div className={styles.label}>Value from</div>
+          <div className={styles.value}>{valueFrom}</div>
+        </>
+      )}
+    </div>
+  );
+}
+
 function ThresholdExpressionViewer({ model }: { model: ExpressionQuery }) {
   const styles = useStyles2(getExpressionViewerStyles);
 
@@ -6,10 +6,13 @@ import { DataFrame, dateTimeFormat, GrafanaTheme2, LoadingState, PanelData } fro
 import { isTimeSeries } from '@grafana/data/src/dataframe/utils';
 import { Stack } from '@grafana/experimental';
 import { AutoSizeInput, Icon, IconButton, Select, useStyles2 } from '@grafana/ui';
+import { Anomaly } from 'app/features/expressions/components/Anomaly';
 import { ClassicConditions } from 'app/features/expressions/components/ClassicConditions';
 import { Math } from 'app/features/expressions/components/Math';
 import { Reduce } from 'app/features/expressions/components/Reduce';
+import { RuleState } from 'app/features/expressions/components/RuleState';
 import { Resample } from 'app/features/expressions/components/Resample';
+import { SqlExpr } from 'app/features/expressions/components/SqlExpr';
 import { Threshold } from 'app/features/expressions/components/Threshold';
 import { ExpressionQuery, ExpressionQueryType, gelTypes } from 'app/features/expressions/types';
 import { AlertQuery, PromAlertingRuleState } from 'app/types/unified-alerting-dto';
@@ -87,6 +90,15 @@ export const Expression: FC<ExpressionProps> = ({
         case ExpressionQueryType.threshold:
           return <Threshold onChange={onChangeQuery} query={query} labelWidth={'auto'} refIds={availableRefIds} />;
 
+        case ExpressionQueryType.anomaly:
+          return <Anomaly onChange={onChangeQuery} query={query} labelWidth={'auto'} refIds={availableRefIds} />;
+
+        case ExpressionQueryType.ruleState:
+          return <RuleState onChange={onChangeQuery} query={query} labelWidth={'auto'} />;
+
+        case ExpressionQueryType.sql:
+          return <SqlExpr onChange={onChangeQuery} query={query} labelWidth={'auto'} onRunQuery={() => {}} />;
+
         default:
           return <>Expression not supported: {query.type}</>;
       }
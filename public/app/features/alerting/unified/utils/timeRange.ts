@@ -29,11 +29,16 @@ const getReferencedIds = (model: ExpressionQuery, queries: AlertQuery[]): string
     case ExpressionQueryType.classic:
       return getReferencedIdsForClassicCondition(model);
     case ExpressionQueryType.math:
+    case ExpressionQueryType.sql:
       return getReferencedIdsForMath(model, queries);
     case ExpressionQueryType.resample:
     case ExpressionQueryType.reduce:
     case ExpressionQueryType.threshold:
+    case ExpressionQueryType.anomaly:
       return getReferencedIdsForReduce(model);
+    case ExpressionQueryType.ruleState:
+      // the state of the other rule is not queried in the time range of the rule
+      return [];
   }
 };
 
@@ -3,10 +3,13 @@ import React, { useCallback, useEffect, useRef } from 'react';
 import { DataSourceApi, QueryEditorProps, SelectableValue } from '@grafana/data';
 import { InlineField, Select } from '@grafana/ui';
 
+import { Anomaly } from './components/Anomaly';
 import { ClassicConditions } from './components/ClassicConditions';
 import { Math } from './components/Math';
 import { Reduce } from './components/Reduce';
+import { RuleState } from './components/RuleState';
 import { Resample } from './components/Resample';
+import { SqlExpr } from './components/SqlExpr';
 import { Threshold } from './components/Threshold';
 import { ExpressionQuery, ExpressionQueryType, gelTypes } from './types';
 import { getDefaults } from './utils/expressionTypes';
@@ -27,6 +30,9 @@ function useExpressionsCache() {
       case ExpressionQueryType.reduce:
       case ExpressionQueryType.resample:
       case ExpressionQueryType.threshold:
+      case ExpressionQueryType.sql:
+      case ExpressionQueryType.anomaly:
+      case ExpressionQueryType.ruleState:
         return expressionCache.current[queryType];
       case ExpressionQueryType.classic:
         return undefined;
@@ -39,6 +45,10 @@ function useExpressionsCache() {
         expressionCache.current.math = value;
         break;
 
+      case ExpressionQueryType.sql:
+        expressionCache.current.sql = value;
+        break;
+
       // We want to use the same value for Reduce, Resample and Threshold
       case ExpressionQueryType.reduce:
       case ExpressionQueryType.resample:
@@ -89,6 +99,15 @@ export function ExpressionQueryEditor(props: Props) {
 
       case ExpressionQueryType.threshold:
         return <Threshold onChange={onChange} query={query} labelWidth={labelWidth} refIds={refIds} />;
+
+      case ExpressionQueryType.sql:
+        return <SqlExpr onChange={onChange} query={query} labelWidth={labelWidth} onRunQuery={onRunQuery} />;
+
+      case ExpressionQueryType.anomaly:
+        return <Anomaly onChange={onChange} query={query} labelWidth={labelWidth} refIds={refIds} />;
+
+      case ExpressionQueryType.ruleState:
+        return <RuleState onChange={onChange} query={query} labelWidth={labelWidth} />;
     }
   };
 
@@ -0,0 +1,62 @@
+import React, { ChangeEvent, FC } from 'react';
+
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+
+import { anomalyMethods, anomalyOutputs, ExpressionQuery } from '../types';
+
+interface Props {
+  refIds: Array<SelectableValue<string>>;
+  query: ExpressionQuery;
+  labelWidth?: number | 'auto';
+  onChange: (query: ExpressionQuery) => void;
+}
+
+export const Anomaly: FC<Props> = ({ labelWidth = 'auto', onChange, refIds, query }) => {
+  const method = anomalyMethods.find((o) => o.value === query.method);
+  const output = anomalyOutputs.find((o) => o.value === query.output);
+
+  const onRefIdChange = (value: SelectableValue<string>) => {
+    onChange({ ...query, expression: value.value });
+  };
+
+  const onSelectMethod = (value: SelectableValue<string>) => {
+    onChange({ ...query, method: value.value });
+  };
+
+  const onSelectOutput = (value: SelectableValue<string>) => {
+    onChange({ ...query, output: value.value });
+  };
+
+  const onSeasonChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, season: event.target.value });
+  };
+
+  const onDeviationsChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, deviations: parseFloat(event.target.value) });
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField label="Input" labelWidth={labelWidth}>
+          <Select onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
+        </InlineField>
+        <InlineField label="Method">
+          <Select options={anomalyMethods} value={method} onChange={onSelectMethod} width={25} />
+        </InlineField>
+      </InlineFieldRow>
+      <InlineFieldRow>
+        <InlineField label="Season" labelWidth={labelWidth} tooltip="1h, 1d, 1w">
+          <Input onChange={onSeasonChange} value={query.season} width={15} />
+        </InlineField>
+        <InlineField label="Deviations" tooltip="The width of the bands, in spreads of the values around the baseline">
+          <Input type="number" onChange={onDeviationsChange} value={query.deviations} width={10} />
+        </InlineField>
+        <InlineField label="Output">
+          <Select options={anomalyOutputs} value={output} onChange={onSelectOutput} width={25} />
+        </InlineField>
+      </InlineFieldRow>
+    </>
+  );
+};
@@ -1,12 +1,12 @@
 import { css } from '@emotion/css';
 import React, { ChangeEvent, FC } from 'react';
 
-import { GrafanaTheme2 } from '@grafana/data';
+import { GrafanaTheme2, SelectableValue } from '@grafana/data';
 import { Stack } from '@grafana/experimental';
-import { Icon, InlineField, InlineLabel, TextArea, useStyles2 } from '@grafana/ui';
+import { Icon, InlineField, InlineLabel, Select, TextArea, useStyles2 } from '@grafana/ui';
 import { HoverCard } from 'app/features/alerting/unified/components/HoverCard';
 
-import { ExpressionQuery } from '../types';
+import { ExpressionQuery, NullPolicy, nullPolicies } from '../types';
 
 interface Props {
   labelWidth: number | 'auto';
@@ -24,6 +24,10 @@ export const Math: FC<Props> = ({ labelWidth, onChange, query, onRunQuery }) =>
     onChange({ ...query, expression: event.target.value });
   };
 
+  const onNullPolicyChange = (value: SelectableValue<NullPolicy>) => {
+    onChange({ ...query, nullPolicy: value.value });
+  };
+
   const styles = useStyles2(getStyles);
 
   const executeQuery = () => {
@@ -126,6 +130,14 @@ export const Math: FC<Props> = ({ labelWidth, onChange, query, onRunQuery }) =>
           style={{ minWidth: 250, lineHeight: '26px', minHeight: 32 }}
         />
       </InlineField>
+      <InlineField label="Null and NaN" tooltip="How the operators handle null and NaN values">
+        <Select
+          options={nullPolicies}
+          value={query.nullPolicy ?? NullPolicy.Propagate}
+          onChange={onNullPolicyChange}
+          width={20}
+        />
+      </InlineField>
     </Stack>
   );
 };
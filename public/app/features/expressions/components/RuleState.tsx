@@ -0,0 +1,44 @@
+import React, { ChangeEvent, FC } from 'react';
+
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+
+import { ExpressionQuery, ruleStateOutputs } from '../types';
+
+interface Props {
+  query: ExpressionQuery;
+  labelWidth?: number | 'auto';
+  onChange: (query: ExpressionQuery) => void;
+}
+
+export const RuleState: FC<Props> = ({ labelWidth = 'auto', onChange, query }) => {
+  const output = ruleStateOutputs.find((o) => o.value === query.output);
+
+  const onRuleUidChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, ruleUid: event.target.value });
+  };
+
+  const onSelectOutput = (value: SelectableValue<string>) => {
+    onChange({ ...query, output: value.value });
+  };
+
+  const onValueFromChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, valueFrom: event.target.value });
+  };
+
+  return (
+    <InlineFieldRow>
+      <InlineField label="Rule" labelWidth={labelWidth} tooltip="The UID of an alert rule of the same rule group">
+        <Input onChange={onRuleUidChange} value={query.ruleUid} width={25} />
+      </InlineField>
+      <InlineField label="Output">
+        <Select options={ruleStateOutputs} value={output} onChange={onSelectOutput} width={15} />
+      </InlineField>
+      {query.output === 'value' && (
+        <InlineField label="Value from" tooltip="The refID of the query or expression of the rule">
+          <Input onChange={onValueFromChange} value={query.valueFrom} width={10} />
+        </InlineField>
+      )}
+    </InlineFieldRow>
+  );
+};
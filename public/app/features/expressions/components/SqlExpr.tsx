@@ -0,0 +1,41 @@
+import React, { ChangeEvent, FC } from 'react';
+
+import { InlineField, TextArea } from '@grafana/ui';
+
+import { ExpressionQuery } from '../types';
+
+interface Props {
+  labelWidth: number | 'auto';
+  query: ExpressionQuery;
+  onChange: (query: ExpressionQuery) => void;
+  onRunQuery: () => void;
+}
+
+const sqlPlaceholder =
+  'SQL query over the results of other queries. You reference the results of a query as a table by its refId ie. A, B, C etc\n' +
+  'SELECT A.host, avg(A.value) AS cpu FROM A JOIN B ON A.host = B.host GROUP BY A.host';
+
+export const SqlExpr: FC<Props> = ({ labelWidth, onChange, query, onRunQuery }) => {
+  const onExpressionChange = (event: ChangeEvent<HTMLTextAreaElement>) => {
+    onChange({ ...query, expression: event.target.value });
+  };
+
+  const executeQuery = () => {
+    if (query.expression) {
+      onRunQuery();
+    }
+  };
+
+  return (
+    <InlineField label="Query" labelWidth={labelWidth} grow={true} shrink={true}>
+      <TextArea
+        value={query.expression}
+        onChange={onExpressionChange}
+        rows={4}
+        placeholder={sqlPlaceholder}
+        onBlur={executeQuery}
+        style={{ minWidth: 250, fontFamily: 'monospace' }}
+      />
+    </InlineField>
+  );
+};
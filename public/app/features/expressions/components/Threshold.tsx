@@ -5,7 +5,7 @@ import { GrafanaTheme2, SelectableValue } from '@grafana/data';
 import { ButtonSelect, InlineField, InlineFieldRow, Input, Select, useStyles2 } from '@grafana/ui';
 import { EvalFunction } from 'app/features/alerting/state/alertDef';
 
-import { ClassicCondition, ExpressionQuery, thresholdFunctions } from '../types';
+import { ClassicCondition, ExpressionQuery, NullPolicy, nullPolicies, thresholdFunctions } from '../types';
 
 interface Props {
   labelWidth: number | 'auto';
@@ -63,6 +63,10 @@ export const Threshold: FC<Props> = ({ labelWidth, onChange, refIds, query }) =>
     });
   };
 
+  const onNullPolicyChange = (value: SelectableValue<NullPolicy>) => {
+    onChange({ ...query, nullPolicy: value.value });
+  };
+
   const isRange =
     condition.evaluator.type === EvalFunction.IsWithinRange || condition.evaluator.type === EvalFunction.IsOutsideRange;
 
@@ -101,6 +105,14 @@ export const Threshold: FC<Props> = ({ labelWidth, onChange, refIds, query }) =>
           defaultValue={conditions[0].evaluator.params[0] || 0}
         />
       )}
+      <InlineField label="Null and NaN" tooltip="How the comparisons handle null and NaN values">
+        <Select
+          options={nullPolicies}
+          value={query.nullPolicy ?? NullPolicy.Propagate}
+          onChange={onNullPolicyChange}
+          width={20}
+        />
+      </InlineField>
     </InlineFieldRow>
   );
 };
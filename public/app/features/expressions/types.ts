@@ -8,6 +8,9 @@ export enum ExpressionQueryType {
   resample = 'resample',
   classic = 'classic_conditions',
   threshold = 'threshold',
+  sql = 'sql',
+  anomaly = 'anomaly',
+  ruleState = 'rule_state',
 }
 
 export const gelTypes: Array<SelectableValue<ExpressionQueryType>> = [
@@ -39,6 +42,21 @@ export const gelTypes: Array<SelectableValue<ExpressionQueryType>> = [
     description:
       'Takes one or more time series returned from a query or an expression and checks if any of the series match the threshold condition.',
   },
+  {
+    value: ExpressionQueryType.sql,
+    label: 'SQL',
+    description: 'Runs a SQL query over the results of queries and expressions, for example to join them.',
+  },
+  {
+    value: ExpressionQueryType.anomaly,
+    label: 'Anomaly',
+    description: 'Compares each time series with a seasonal baseline and returns its bands or a deviation score.',
+  },
+  {
+    value: ExpressionQueryType.ruleState,
+    label: 'Rule state',
+    description: 'Returns the current state or value of the alert instances of another alert rule of the same group.',
+  },
 ];
 
 export const reducerTypes: Array<SelectableValue<string>> = [
@@ -88,6 +106,58 @@ export const upsamplingTypes: Array<SelectableValue<string>> = [
   { value: 'fillna', label: 'fillna', description: 'Fill with NaNs' },
 ];
 
+export const anomalyMethods: Array<SelectableValue<string>> = [
+  {
+    value: 'seasonal_median',
+    label: 'Seasonal median',
+    description: 'Median of the values at the same time in the earlier seasons',
+  },
+  {
+    value: 'holt_winters',
+    label: 'Holt-Winters',
+    description: 'Forecast with triple exponential smoothing, the points must be regularly spaced',
+  },
+];
+
+export const anomalyOutputs: Array<SelectableValue<string>> = [
+  { value: 'score', label: 'Score', description: 'The number of spreads between the value and the baseline' },
+  { value: 'baseline', label: 'Baseline', description: 'The expected value' },
+  { value: 'upper', label: 'Upper band' },
+  { value: 'lower', label: 'Lower band' },
+  { value: 'all', label: 'All', description: 'The baseline, bands, and score, with an anomaly label' },
+];
+
+export enum NullPolicy {
+  Propagate = 'propagate',
+  TreatAsZero = 'treatAsZero',
+  DropPoint = 'dropPoint',
+  TreatAsFalse = 'treatAsFalse',
+}
+
+export const nullPolicies: Array<SelectableValue<NullPolicy>> = [
+  {
+    value: NullPolicy.Propagate,
+    label: 'Propagate',
+    description: 'The result of an operation on null or NaN is null or NaN',
+  },
+  { value: NullPolicy.TreatAsZero, label: 'Treat as zero', description: 'Null and NaN values are replaced with 0' },
+  {
+    value: NullPolicy.DropPoint,
+    label: 'Drop point',
+    description: 'Points and numbers with null or NaN values are dropped',
+  },
+  {
+    value: NullPolicy.TreatAsFalse,
+    label: 'Treat as false',
+    description: 'Comparisons with null or NaN values are false, so missing data never meets a condition',
+  },
+];
+
+export const ruleStateOutputs: Array<SelectableValue<string>> = [
+  { value: 'state', label: 'State', description: '1 for each alert instance that is firing and 0 for the others' },
+  { value: 'value', label: 'Value', description: 'The value of a query or expression of the rule' },
+];
+
 export const thresholdFunctions: Array<SelectableValue<EvalFunction>> = [
   { value: EvalFunction.IsAbove, label: 'Is above' },
   { value: EvalFunction.IsBelow, label: 'Is below' },
@@ -108,6 +178,13 @@ export interface ExpressionQuery extends DataQuery {
   upsampler?: string;
   conditions?: ClassicCondition[];
   settings?: ExpressionQuerySettings;
+  method?: string;
+  season?: string;
+  deviations?: number;
+  output?: string;
+  nullPolicy?: NullPolicy;
+  ruleUid?: string;
+  valueFrom?: string;
 }
 
 export interface ExpressionQuerySettings {
@@ -25,6 +25,32 @@ export const getDefaults = (query: ExpressionQuery) => {
       break;
 
     case ExpressionQueryType.math:
+    case ExpressionQueryType.sql:
+      query.expression = undefined;
+      break;
+
+    case ExpressionQueryType.anomaly:
+      if (!query.method) {
+        query.method = 'seasonal_median';
+      }
+
+      if (!query.season) {
+        query.season = '1d';
+      }
+
+      if (!query.output) {
+        query.output = 'score';
+      }
+
+      query.reducer = undefined;
+      break;
+
+    case ExpressionQueryType.ruleState:
+      if (!query.output) {
+        query.output = 'state';
+      }
+
+      query.reducer = undefined;
       query.expression = undefined;
       break;
 
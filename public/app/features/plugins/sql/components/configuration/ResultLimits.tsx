@@ -0,0 +1,51 @@
+import React from 'react';
+
+import { FieldSet, InlineField } from '@grafana/ui';
+import { NumberInput } from 'app/core/components/OptionsUI/NumberInput';
+
+import { SQLResultLimits } from '../../types';
+
+interface Props<T> {
+  onPropertyChanged: (property: keyof T, value?: number) => void;
+  labelWidth: number;
+  jsonData: SQLResultLimits;
+}
+
+export const ResultLimits = <T extends SQLResultLimits>(props: Props<T>) => {
+  const { onPropertyChanged, labelWidth, jsonData } = props;
+
+  const onJSONDataNumberChanged = (property: keyof SQLResultLimits) => {
+    return (number?: number) => {
+      if (onPropertyChanged) {
+        onPropertyChanged(property, number);
+      }
+    };
+  };
+
+  return (
+    <FieldSet label="Result limits">
+      <InlineField
+        tooltip="The maximum number of rows of the result of a query. Larger results are truncated. If not set, the row limit of the Grafana server is used."
+        labelWidth={labelWidth}
+        label="Max rows"
+      >
+        <NumberInput
+          placeholder="server default"
+          value={jsonData.rowLimit}
+          onChange={onJSONDataNumberChanged('rowLimit')}
+        ></NumberInput>
+      </InlineField>
+      <InlineField
+        tooltip="The maximum size in bytes of the result of a query. Larger results are truncated. If not set, the byte limit of the Grafana server is used."
+        labelWidth={labelWidth}
+        label="Max bytes"
+      >
+        <NumberInput
+          placeholder="server default"
+          value={jsonData.byteLimit}
+          onChange={onJSONDataNumberChanged('byteLimit')}
+        ></NumberInput>
+      </InlineField>
+    </FieldSet>
+  );
+};
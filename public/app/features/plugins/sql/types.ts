@@ -33,7 +33,12 @@ export interface SQLConnectionLimits {
   connMaxLifetime: number;
 }
 
-export interface SQLOptions extends SQLConnectionLimits, DataSourceJsonData {
+export interface SQLResultLimits {
+  rowLimit?: number;
+  byteLimit?: number;
+}
+
+export interface SQLOptions extends SQLConnectionLimits, SQLResultLimits, DataSourceJsonData {
   tlsAuth: boolean;
   tlsAuthWithCACert: boolean;
   timezone: string;
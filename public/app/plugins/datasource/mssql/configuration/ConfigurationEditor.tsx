@@ -24,6 +24,7 @@ import {
 } from '@grafana/ui';
 import { NumberInput } from 'app/core/components/OptionsUI/NumberInput';
 import { ConnectionLimits } from 'app/features/plugins/sql/components/configuration/ConnectionLimits';
+import { ResultLimits } from 'app/features/plugins/sql/components/configuration/ResultLimits';
 import { useMigrateDatabaseField } from 'app/features/plugins/sql/components/configuration/useMigrateDatabaseField';
 
 import { MSSQLAuthenticationType, MSSQLEncryptOptions, MssqlOptions } from '../types';
@@ -234,6 +235,14 @@ export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<Ms
         }}
       ></ConnectionLimits>
 
+      <ResultLimits
+        labelWidth={shortWidth}
+        jsonData={jsonData}
+        onPropertyChanged={(property, value) => {
+          updateDatasourcePluginJsonDataOption(props, property, value);
+        }}
+      ></ResultLimits>
+
       <FieldSet label="MS SQL details">
         <InlineField
           tooltip={
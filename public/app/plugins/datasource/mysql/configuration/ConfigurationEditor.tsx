@@ -9,6 +9,7 @@ import {
 } from '@grafana/data';
 import { Alert, FieldSet, InlineField, InlineFieldRow, InlineSwitch, Input, Link, SecretInput } from '@grafana/ui';
 import { ConnectionLimits } from 'app/features/plugins/sql/components/configuration/ConnectionLimits';
+import { ResultLimits } from 'app/features/plugins/sql/components/configuration/ResultLimits';
 import { TLSSecretsConfig } from 'app/features/plugins/sql/components/configuration/TLSSecretsConfig';
 import { useMigrateDatabaseField } from 'app/features/plugins/sql/components/configuration/useMigrateDatabaseField';
 
@@ -150,6 +151,14 @@ export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<My
         }}
       ></ConnectionLimits>
 
+      <ResultLimits
+        labelWidth={shortWidth}
+        jsonData={jsonData}
+        onPropertyChanged={(property, value) => {
+          updateDatasourcePluginJsonDataOption(props, property, value);
+        }}
+      ></ResultLimits>
+
       <FieldSet label="MySQL details">
         <InlineField
           tooltip={
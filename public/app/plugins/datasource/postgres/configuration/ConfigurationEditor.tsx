@@ -20,6 +20,7 @@ import {
   Link,
 } from '@grafana/ui';
 import { ConnectionLimits } from 'app/features/plugins/sql/components/configuration/ConnectionLimits';
+import { ResultLimits } from 'app/features/plugins/sql/components/configuration/ResultLimits';
 import { TLSSecretsConfig } from 'app/features/plugins/sql/components/configuration/TLSSecretsConfig';
 import { useMigrateDatabaseField } from 'app/features/plugins/sql/components/configuration/useMigrateDatabaseField';
 
@@ -238,6 +239,14 @@ export const PostgresConfigEditor = (props: DataSourcePluginOptionsEditorProps<P
         }}
       ></ConnectionLimits>
 
+      <ResultLimits
+        labelWidth={labelWidthShort}
+        jsonData={jsonData}
+        onPropertyChanged={(property, value) => {
+          updateDatasourcePluginJsonDataOption(props, property, value);
+        }}
+      ></ResultLimits>
+
       <FieldSet label="PostgreSQL details">
         <InlineField
           tooltip="This option controls what functions are available in the PostgreSQL query builder"
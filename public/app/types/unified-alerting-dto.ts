@@ -196,6 +196,24 @@ export interface PostableGrafanaRuleDefinition {
   no_data_state: GrafanaAlertStateDecision;
   exec_err_state: GrafanaAlertStateDecision;
   data: AlertQuery[];
+  record?: GrafanaRuleRecord;
+  isPaused?: boolean;
+  enrichments?: GrafanaRuleEnrichment[];
+}
+export interface GrafanaRuleRecord {
+  metric: string;
+  from: string;
+  target: 'remote_write' | 'live';
+}
+export interface GrafanaRuleEnrichment {
+  type: 'table' | 'sql' | 'team' | 'user';
+  keys: string[];
+  labels?: Record<string, string>;
+  annotations?: Record<string, string>;
+  rows?: Array<Record<string, string>>;
+  csv?: string;
+  datasourceUid?: string;
+  query?: string;
 }
 export interface GrafanaRuleDefinition extends PostableGrafanaRuleDefinition {
   id?: string;
@@ -226,6 +244,7 @@ export type PostableRuleDTO = RulerAlertingRuleDTO | RulerRecordingRuleDTO | Pos
 export type RulerRuleGroupDTO<R = RulerRuleDTO> = {
   name: string;
   interval?: string;
+  isPaused?: boolean;
   source_tenants?: string[];
   rules: R[];
 };